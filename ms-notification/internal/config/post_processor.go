@@ -14,6 +14,16 @@ func (p *DefaultConfigPostProcessor) processEmailConfig(cfg *Config) {
 	cfg.Email.Templates.WeatherForecastHourly = commonCfg.GetOriginalPath(
 		cfg.Email.Templates.WeatherForecastHourly,
 	)
+	cfg.Email.Templates.WeatherForecastEvery3Hours = commonCfg.GetOriginalPath(
+		cfg.Email.Templates.WeatherForecastEvery3Hours,
+	)
+	cfg.Email.Templates.WeatherForecastEvery6Hours = commonCfg.GetOriginalPath(
+		cfg.Email.Templates.WeatherForecastEvery6Hours,
+	)
+	cfg.Email.Templates.WeatherForecastWeekly = commonCfg.GetOriginalPath(
+		cfg.Email.Templates.WeatherForecastWeekly,
+	)
+	cfg.Email.Templates.WeatherAlert = commonCfg.GetOriginalPath(cfg.Email.Templates.WeatherAlert)
 }
 
 func (p *DefaultConfigPostProcessor) ProcessConfig(cfg *Config) {
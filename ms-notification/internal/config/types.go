@@ -38,12 +38,18 @@ type EmailConfig struct {
 }
 
 type EmailTemplates struct {
-	Confirmation          string `mapstructure:"confirmation_email"`
-	WeatherForecastDaily  string `mapstructure:"weather_forecast_daily"`
-	WeatherForecastHourly string `mapstructure:"weather_forecast_hourly"`
+	Confirmation               string `mapstructure:"confirmation_email"`
+	WeatherForecastDaily       string `mapstructure:"weather_forecast_daily"`
+	WeatherForecastHourly      string `mapstructure:"weather_forecast_hourly"`
+	WeatherForecastEvery3Hours string `mapstructure:"weather_forecast_every_3h"`
+	WeatherForecastEvery6Hours string `mapstructure:"weather_forecast_every_6h"`
+	WeatherForecastWeekly      string `mapstructure:"weather_forecast_weekly"`
+	WeatherAlert               string `mapstructure:"weather_alert"`
 }
 
 type EmailSubjects struct {
-	Confirmation    string `mapstructure:"confirmation_email"`
-	WeatherForecast string `mapstructure:"weather_forecast"`
+	Confirmation          string `mapstructure:"confirmation_email"`
+	WeatherForecast       string `mapstructure:"weather_forecast"`
+	WeatherForecastWeekly string `mapstructure:"weather_forecast_weekly"`
+	WeatherAlert          string `mapstructure:"weather_alert"`
 }
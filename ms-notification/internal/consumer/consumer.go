@@ -1,17 +1,15 @@
 package consumer
 
 import (
+	"common/frequency"
 	"common/logger"
+	"fmt"
 	"ms-notification/internal/service"
 
 	amqp "github.com/rabbitmq/amqp091-go"
 )
 
-const (
-	EmailConfirmationQueue   = "email.confirmation"
-	EmailDailyForecastQueue  = "email.daily_forecast"
-	EmailHourlyForecastQueue = "email.hourly_forecast"
-)
+const EmailConfirmationQueue = "email.confirmation"
 
 type Consumer struct {
 	ch           *amqp.Channel
@@ -20,12 +18,22 @@ type Consumer struct {
 }
 
 func NewConsumer(conn *amqp.Connection, emailService service.Email) (*Consumer, error) {
+	c := &Consumer{conn: conn, emailService: emailService}
+
+	// Every frequency must have a handler, otherwise its emails would pile up in the queue.
+	handlers := c.frequencyHandlers()
+	for _, f := range frequency.All {
+		if _, ok := handlers[f]; !ok {
+			return nil, fmt.Errorf("no handler for %s emails", f)
+		}
+	}
+
 	ch, err := conn.Channel()
 	if err != nil {
 		return nil, err
 	}
 
-	queues := []string{EmailConfirmationQueue, EmailDailyForecastQueue, EmailHourlyForecastQueue}
+	queues := append([]string{EmailConfirmationQueue}, frequency.Queues()...)
 	for _, q := range queues {
 		_, err := ch.QueueDeclare(q, true, false, false, false, nil)
 		if err != nil {
@@ -33,13 +41,27 @@ func NewConsumer(conn *amqp.Connection, emailService service.Email) (*Consumer,
 		}
 	}
 
-	return &Consumer{ch: ch, conn: conn, emailService: emailService}, nil
+	c.ch = ch
+	return c, nil
+}
+
+// frequencyHandlers returns the handler of the emails of each subscription frequency.
+func (c *Consumer) frequencyHandlers() map[string]MessageHandlerFunc {
+	return map[string]MessageHandlerFunc{
+		frequency.Hourly:      c.handleHourlyForecast,
+		frequency.Every3Hours: c.handleEvery3HoursForecast,
+		frequency.Every6Hours: c.handleEvery6HoursForecast,
+		frequency.Daily:       c.handleDailyForecast,
+		frequency.Weekly:      c.handleWeeklyForecast,
+		frequency.Alerts:      c.handleWeatherAlert,
+	}
 }
 
 func (c *Consumer) Start() {
 	go c.consume(EmailConfirmationQueue, c.wrapHandler(c.handleConfirmationEmail))
-	go c.consume(EmailDailyForecastQueue, c.wrapHandler(c.handleDailyForecast))
-	go c.consume(EmailHourlyForecastQueue, c.wrapHandler(c.handleHourlyForecast))
+	for f, handler := range c.frequencyHandlers() {
+		go c.consume(frequency.Queue(f), c.wrapHandler(handler))
+	}
 }
 
 func (c *Consumer) Stop() error {
@@ -19,6 +19,7 @@ type baseForecastCommand struct {
 	Subscription    domain.Subscription `json:"subscription"`
 	Date            string              `json:"date"`
 	UnsubscribeLink string              `json:"unsubscribe_link"`
+	AirQuality      *domain.AirQuality  `json:"air_quality"`
 }
 
 type dailyForecastCommand struct {
@@ -31,6 +32,29 @@ type hourlyForecastCommand struct {
 	Weather domain.Weather `json:"weather"`
 }
 
+type every3HoursForecastCommand struct {
+	baseForecastCommand
+	Weather domain.Every3HoursWeather `json:"weather"`
+}
+
+type every6HoursForecastCommand struct {
+	baseForecastCommand
+	Weather domain.Every6HoursWeather `json:"weather"`
+}
+
+type weeklyForecastCommand struct {
+	Subscription    domain.Subscription `json:"subscription"`
+	Date            string              `json:"date"`
+	UnsubscribeLink string              `json:"unsubscribe_link"`
+	Weather         domain.Forecast     `json:"weather"`
+}
+
+type weatherAlertCommand struct {
+	Subscription    domain.Subscription `json:"subscription"`
+	Alert           domain.WeatherAlert `json:"alert"`
+	UnsubscribeLink string              `json:"unsubscribe_link"`
+}
+
 type MessageHandlerFunc func(msg amqp.Delivery) error
 
 func (c *Consumer) wrapHandler(handler MessageHandlerFunc) func(amqp.Delivery) {
@@ -83,6 +107,7 @@ func (c *Consumer) handleDailyForecast(msg amqp.Delivery) error {
 		Weather:         &cmd.Weather,
 		Date:            cmd.Date,
 		UnsubscribeLink: cmd.UnsubscribeLink,
+		AirQuality:      cmd.AirQuality,
 	}
 	if err := c.emailService.SendWeatherForecastDailyEmail(inp); err != nil {
 		return fmt.Errorf("daily forecast email send error: %w", err)
@@ -102,6 +127,7 @@ func (c *Consumer) handleHourlyForecast(msg amqp.Delivery) error {
 		Weather:         &cmd.Weather,
 		Date:            cmd.Date,
 		UnsubscribeLink: cmd.UnsubscribeLink,
+		AirQuality:      cmd.AirQuality,
 	}
 	if err := c.emailService.SendWeatherForecastHourlyEmail(inp); err != nil {
 		return fmt.Errorf("hourly forecast email send error: %w", err)
@@ -109,3 +135,76 @@ func (c *Consumer) handleHourlyForecast(msg amqp.Delivery) error {
 
 	return nil
 }
+
+func (c *Consumer) handleEvery3HoursForecast(msg amqp.Delivery) error {
+	var cmd every3HoursForecastCommand
+	if err := json.Unmarshal(msg.Body, &cmd); err != nil {
+		return fmt.Errorf("invalid every 3 hours forecast email payload: %w", err)
+	}
+
+	inp := domain.WeatherForecastEmailInput[*domain.Every3HoursWeather]{
+		Subscription:    cmd.Subscription,
+		Weather:         &cmd.Weather,
+		Date:            cmd.Date,
+		UnsubscribeLink: cmd.UnsubscribeLink,
+		AirQuality:      cmd.AirQuality,
+	}
+	if err := c.emailService.SendWeatherForecastEvery3HoursEmail(inp); err != nil {
+		return fmt.Errorf("every 3 hours forecast email send error: %w", err)
+	}
+
+	return nil
+}
+
+func (c *Consumer) handleEvery6HoursForecast(msg amqp.Delivery) error {
+	var cmd every6HoursForecastCommand
+	if err := json.Unmarshal(msg.Body, &cmd); err != nil {
+		return fmt.Errorf("invalid every 6 hours forecast email payload: %w", err)
+	}
+
+	inp := domain.WeatherForecastEmailInput[*domain.Every6HoursWeather]{
+		Subscription:    cmd.Subscription,
+		Weather:         &cmd.Weather,
+		Date:            cmd.Date,
+		UnsubscribeLink: cmd.UnsubscribeLink,
+		AirQuality:      cmd.AirQuality,
+	}
+	if err := c.emailService.SendWeatherForecastEvery6HoursEmail(inp); err != nil {
+		return fmt.Errorf("every 6 hours forecast email send error: %w", err)
+	}
+
+	return nil
+}
+
+func (c *Consumer) handleWeeklyForecast(msg amqp.Delivery) error {
+	var cmd weeklyForecastCommand
+	if err := json.Unmarshal(msg.Body, &cmd); err != nil {
+		return fmt.Errorf("invalid weekly forecast email payload: %w", err)
+	}
+
+	inp := domain.WeatherForecastEmailInput[*domain.Forecast]{
+		Subscription:    cmd.Subscription,
+		Weather:         &cmd.Weather,
+		Date:            cmd.Date,
+		UnsubscribeLink: cmd.UnsubscribeLink,
+	}
+	if err := c.emailService.SendWeatherForecastWeeklyEmail(inp); err != nil {
+		return fmt.Errorf("weekly forecast email send error: %w", err)
+	}
+
+	return nil
+}
+
+func (c *Consumer) handleWeatherAlert(msg amqp.Delivery) error {
+	var cmd weatherAlertCommand
+	if err := json.Unmarshal(msg.Body, &cmd); err != nil {
+		return fmt.Errorf("invalid weather alert email payload: %w", err)
+	}
+
+	inp := domain.WeatherAlertEmailInput(cmd)
+	if err := c.emailService.SendWeatherAlertEmail(inp); err != nil {
+		return fmt.Errorf("weather alert email send error: %w", err)
+	}
+
+	return nil
+}
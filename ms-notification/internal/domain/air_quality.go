@@ -0,0 +1,28 @@
+package domain
+
+// AirQuality holds the US EPA air quality index (0-500) and pollutant concentrations in μg/m³.
+type AirQuality struct {
+	AQI  int     `json:"aqi"`
+	PM25 float32 `json:"pm2_5"`
+	PM10 float32 `json:"pm10"`
+	O3   float32 `json:"o3"`
+	NO2  float32 `json:"no2"`
+}
+
+// Category returns the US EPA level of health concern of the index.
+func (a *AirQuality) Category() string {
+	switch {
+	case a.AQI <= 50:
+		return "Good"
+	case a.AQI <= 100:
+		return "Moderate"
+	case a.AQI <= 150:
+		return "Unhealthy for sensitive groups"
+	case a.AQI <= 200:
+		return "Unhealthy"
+	case a.AQI <= 300:
+		return "Very unhealthy"
+	default:
+		return "Hazardous"
+	}
+}
@@ -3,6 +3,7 @@ package domain
 type Subscription struct {
 	Email string `json:"email"`
 	City  string `json:"city"`
+	Units string `json:"units"`
 }
 
 type ConfirmationEmailInput struct {
@@ -0,0 +1,32 @@
+package domain
+
+const (
+	UnitsMetric   = "metric"
+	UnitsImperial = "imperial"
+	UnitsStandard = "standard"
+)
+
+// UnitLabels are the symbols of the units the weather values are sent in.
+// The values are converted by the weather service according to the subscription units.
+type UnitLabels struct {
+	Temperature   string
+	Speed         string
+	Precipitation string
+	Pressure      string
+	Visibility    string
+}
+
+var unitLabels = map[string]UnitLabels{
+	UnitsMetric:   {Temperature: "°C", Speed: "km/h", Precipitation: "mm", Pressure: "hPa", Visibility: "km"},
+	UnitsImperial: {Temperature: "°F", Speed: "mph", Precipitation: "in", Pressure: "inHg", Visibility: "mi"},
+	UnitsStandard: {Temperature: "K", Speed: "m/s", Precipitation: "mm", Pressure: "hPa", Visibility: "km"},
+}
+
+// UnitLabelsFor returns the labels of the given units. Unknown or empty units
+// (e.g. messages published before units were introduced) are metric.
+func UnitLabelsFor(units string) UnitLabels {
+	if labels, ok := unitLabels[units]; ok {
+		return labels
+	}
+	return unitLabels[UnitsMetric]
+}
@@ -1,22 +1,73 @@
 package domain
 
+import "time"
+
+// Weather holds metric values: temperature in °C, speeds in km/h, precipitation in mm,
+// pressure in hPa, visibility in km, humidity, probability and cloud cover in percent.
 type Weather struct {
 	Temperature float32 `json:"temperature"`
 	Humidity    float32 `json:"humidity"`
 	Description string  `json:"description"`
+
+	FeelsLike                float32 `json:"feels_like"`
+	WindSpeed                float32 `json:"wind_speed"`
+	WindDirection            float32 `json:"wind_direction"` // degrees, the direction the wind blows from
+	WindGust                 float32 `json:"wind_gust"`
+	Precipitation            float32 `json:"precipitation"`
+	PrecipitationProbability float32 `json:"precipitation_probability"`
+	UVIndex                  float32 `json:"uv_index"`
+	Pressure                 float32 `json:"pressure"`
+	Visibility               float32 `json:"visibility"`
+	CloudCover               float32 `json:"cloud_cover"`
+
+	// Stale is set when the weather service could not get fresh data and sent the last known weather,
+	// received from the provider at ObservedAt.
+	Stale      bool      `json:"stale"`
+	ObservedAt time.Time `json:"observed_at"`
 }
 
 type DayWeather struct {
-	SevenAM Weather `json:"seven_am"`
-	TenAM   Weather `json:"ten_am"`
-	OnePM   Weather `json:"one_pm"`
-	FourPM  Weather `json:"four_pm"`
-	SevenPM Weather `json:"seven_pm"`
-	TenPM   Weather `json:"ten_pm"`
+	// Hours are ordered by time of the day.
+	Hours []HourlyWeather `json:"hours"`
+
+	Stale      bool      `json:"stale"`
+	ObservedAt time.Time `json:"observed_at"`
+}
+
+type HourlyWeather struct {
+	Time string `json:"time"` // "15:04" in the city's timezone
+	Weather
+}
+
+// Every3HoursWeather is the current weather together with the forecast for the next 3 hours.
+type Every3HoursWeather struct {
+	Current Weather         `json:"current"`
+	Hours   []HourlyWeather `json:"hours"`
+}
+
+// Every6HoursWeather is the current weather together with the forecast for the next 6 hours.
+type Every6HoursWeather struct {
+	Current Weather         `json:"current"`
+	Hours   []HourlyWeather `json:"hours"`
+}
+
+// Forecast is a multi-day forecast, one entry per day.
+type Forecast struct {
+	Days []DayForecast `json:"days"`
+}
+
+type DayForecast struct {
+	Date                     string  `json:"date"` // "2006-01-02" in the city's timezone
+	MinTemperature           float32 `json:"min_temperature"`
+	MaxTemperature           float32 `json:"max_temperature"`
+	AvgTemperature           float32 `json:"avg_temperature"`
+	Humidity                 float32 `json:"humidity"`
+	PrecipitationProbability float32 `json:"precipitation_probability"`
+	Description              string  `json:"description"`
 }
 
 type WeatherType interface {
-	*Weather | *DayWeather
+	*Weather | *DayWeather | *Every3HoursWeather | *Every6HoursWeather | *Forecast
 }
 
 type WeatherForecastEmailInput[T WeatherType] struct {
@@ -24,4 +75,25 @@ type WeatherForecastEmailInput[T WeatherType] struct {
 	Weather         T
 	Date            string
 	UnsubscribeLink string
+	// AirQuality is nil unless the subscription opted into it.
+	AirQuality *AirQuality
+}
+
+// WeatherAlert is a severe weather warning, e.g. a storm or a heat wave.
+// Effective and Expires are zero if the provider doesn't report them.
+type WeatherAlert struct {
+	ID          string    `json:"id"`
+	Event       string    `json:"event"`
+	Headline    string    `json:"headline"`
+	Severity    string    `json:"severity"`
+	Description string    `json:"description"`
+	Instruction string    `json:"instruction"`
+	Effective   time.Time `json:"effective"`
+	Expires     time.Time `json:"expires"`
+}
+
+type WeatherAlertEmailInput struct {
+	Subscription    Subscription
+	Alert           WeatherAlert
+	UnsubscribeLink string
 }
@@ -16,14 +16,50 @@ type WeatherForecastDailyEmailTemplateInput struct {
 	UnsubscribeLink string
 	City            string
 	Weather         domain.DayWeather
+	Units           domain.UnitLabels
 	Date            string
+	AirQuality      *domain.AirQuality
 }
 
 type WeatherForecastHourlyEmailTemplateInput struct {
 	UnsubscribeLink string
 	City            string
 	Weather         domain.Weather
+	Units           domain.UnitLabels
 	Date            string
+	AirQuality      *domain.AirQuality
+}
+
+type WeatherForecastEvery3HoursEmailTemplateInput struct {
+	UnsubscribeLink string
+	City            string
+	Weather         domain.Every3HoursWeather
+	Units           domain.UnitLabels
+	Date            string
+	AirQuality      *domain.AirQuality
+}
+
+type WeatherForecastEvery6HoursEmailTemplateInput struct {
+	UnsubscribeLink string
+	City            string
+	Weather         domain.Every6HoursWeather
+	Units           domain.UnitLabels
+	Date            string
+	AirQuality      *domain.AirQuality
+}
+
+type WeatherForecastWeeklyEmailTemplateInput struct {
+	UnsubscribeLink string
+	City            string
+	Forecast        domain.Forecast
+	Units           domain.UnitLabels
+	Date            string
+}
+
+type WeatherAlertEmailTemplateInput struct {
+	UnsubscribeLink string
+	City            string
+	Alert           domain.WeatherAlert
 }
 
 type EmailService struct {
@@ -64,7 +100,7 @@ func (s *EmailService) SendConfirmationEmail(inp domain.ConfirmationEmailInput)
 	return s.sender.Send(sendInput)
 }
 
-func sendWeatherForecastEmail(
+func sendWeatherEmail(
 	sender email.Sender,
 	subscription domain.Subscription,
 	subject string,
@@ -92,12 +128,14 @@ func (s *EmailService) SendWeatherForecastDailyEmail(
 		UnsubscribeLink: inp.UnsubscribeLink,
 		City:            inp.Subscription.City,
 		Weather:         *inp.Weather,
+		Units:           domain.UnitLabelsFor(inp.Subscription.Units),
 		Date:            inp.Date,
+		AirQuality:      inp.AirQuality,
 	}
 
 	subject := fmt.Sprintf(s.emailConfig.Subjects.WeatherForecast, inp.Subscription.City)
 
-	return sendWeatherForecastEmail(
+	return sendWeatherEmail(
 		s.sender,
 		inp.Subscription,
 		subject,
@@ -113,12 +151,14 @@ func (s *EmailService) SendWeatherForecastHourlyEmail(
 		UnsubscribeLink: inp.UnsubscribeLink,
 		City:            inp.Subscription.City,
 		Weather:         *inp.Weather,
+		Units:           domain.UnitLabelsFor(inp.Subscription.Units),
 		Date:            inp.Date,
+		AirQuality:      inp.AirQuality,
 	}
 
 	subject := fmt.Sprintf(s.emailConfig.Subjects.WeatherForecast, inp.Subscription.City)
 
-	return sendWeatherForecastEmail(
+	return sendWeatherEmail(
 		s.sender,
 		inp.Subscription,
 		subject,
@@ -126,3 +166,89 @@ func (s *EmailService) SendWeatherForecastHourlyEmail(
 		templateInput,
 	)
 }
+
+func (s *EmailService) SendWeatherForecastEvery3HoursEmail(
+	inp domain.WeatherForecastEmailInput[*domain.Every3HoursWeather],
+) error {
+	templateInput := WeatherForecastEvery3HoursEmailTemplateInput{
+		UnsubscribeLink: inp.UnsubscribeLink,
+		City:            inp.Subscription.City,
+		Weather:         *inp.Weather,
+		Units:           domain.UnitLabelsFor(inp.Subscription.Units),
+		Date:            inp.Date,
+		AirQuality:      inp.AirQuality,
+	}
+
+	subject := fmt.Sprintf(s.emailConfig.Subjects.WeatherForecast, inp.Subscription.City)
+
+	return sendWeatherEmail(
+		s.sender,
+		inp.Subscription,
+		subject,
+		s.emailConfig.Templates.WeatherForecastEvery3Hours,
+		templateInput,
+	)
+}
+
+func (s *EmailService) SendWeatherForecastEvery6HoursEmail(
+	inp domain.WeatherForecastEmailInput[*domain.Every6HoursWeather],
+) error {
+	templateInput := WeatherForecastEvery6HoursEmailTemplateInput{
+		UnsubscribeLink: inp.UnsubscribeLink,
+		City:            inp.Subscription.City,
+		Weather:         *inp.Weather,
+		Units:           domain.UnitLabelsFor(inp.Subscription.Units),
+		Date:            inp.Date,
+		AirQuality:      inp.AirQuality,
+	}
+
+	subject := fmt.Sprintf(s.emailConfig.Subjects.WeatherForecast, inp.Subscription.City)
+
+	return sendWeatherEmail(
+		s.sender,
+		inp.Subscription,
+		subject,
+		s.emailConfig.Templates.WeatherForecastEvery6Hours,
+		templateInput,
+	)
+}
+
+func (s *EmailService) SendWeatherForecastWeeklyEmail(
+	inp domain.WeatherForecastEmailInput[*domain.Forecast],
+) error {
+	templateInput := WeatherForecastWeeklyEmailTemplateInput{
+		UnsubscribeLink: inp.UnsubscribeLink,
+		City:            inp.Subscription.City,
+		Forecast:        *inp.Weather,
+		Units:           domain.UnitLabelsFor(inp.Subscription.Units),
+		Date:            inp.Date,
+	}
+
+	subject := fmt.Sprintf(s.emailConfig.Subjects.WeatherForecastWeekly, inp.Subscription.City)
+
+	return sendWeatherEmail(
+		s.sender,
+		inp.Subscription,
+		subject,
+		s.emailConfig.Templates.WeatherForecastWeekly,
+		templateInput,
+	)
+}
+
+func (s *EmailService) SendWeatherAlertEmail(inp domain.WeatherAlertEmailInput) error {
+	templateInput := WeatherAlertEmailTemplateInput{
+		UnsubscribeLink: inp.UnsubscribeLink,
+		City:            inp.Subscription.City,
+		Alert:           inp.Alert,
+	}
+
+	subject := fmt.Sprintf(s.emailConfig.Subjects.WeatherAlert, inp.Subscription.City, inp.Alert.Event)
+
+	return sendWeatherEmail(
+		s.sender,
+		inp.Subscription,
+		subject,
+		s.emailConfig.Templates.WeatherAlert,
+		templateInput,
+	)
+}
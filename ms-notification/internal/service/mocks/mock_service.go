@@ -54,6 +54,20 @@ func (mr *MockEmailMockRecorder) SendConfirmationEmail(arg0 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendConfirmationEmail", reflect.TypeOf((*MockEmail)(nil).SendConfirmationEmail), arg0)
 }
 
+// SendWeatherAlertEmail mocks base method.
+func (m *MockEmail) SendWeatherAlertEmail(arg0 domain.WeatherAlertEmailInput) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendWeatherAlertEmail", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendWeatherAlertEmail indicates an expected call of SendWeatherAlertEmail.
+func (mr *MockEmailMockRecorder) SendWeatherAlertEmail(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendWeatherAlertEmail", reflect.TypeOf((*MockEmail)(nil).SendWeatherAlertEmail), arg0)
+}
+
 // SendWeatherForecastDailyEmail mocks base method.
 func (m *MockEmail) SendWeatherForecastDailyEmail(arg0 domain.WeatherForecastEmailInput[*domain.DayWeather]) error {
 	m.ctrl.T.Helper()
@@ -68,6 +82,34 @@ func (mr *MockEmailMockRecorder) SendWeatherForecastDailyEmail(arg0 any) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendWeatherForecastDailyEmail", reflect.TypeOf((*MockEmail)(nil).SendWeatherForecastDailyEmail), arg0)
 }
 
+// SendWeatherForecastEvery3HoursEmail mocks base method.
+func (m *MockEmail) SendWeatherForecastEvery3HoursEmail(arg0 domain.WeatherForecastEmailInput[*domain.Every3HoursWeather]) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendWeatherForecastEvery3HoursEmail", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendWeatherForecastEvery3HoursEmail indicates an expected call of SendWeatherForecastEvery3HoursEmail.
+func (mr *MockEmailMockRecorder) SendWeatherForecastEvery3HoursEmail(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendWeatherForecastEvery3HoursEmail", reflect.TypeOf((*MockEmail)(nil).SendWeatherForecastEvery3HoursEmail), arg0)
+}
+
+// SendWeatherForecastEvery6HoursEmail mocks base method.
+func (m *MockEmail) SendWeatherForecastEvery6HoursEmail(arg0 domain.WeatherForecastEmailInput[*domain.Every6HoursWeather]) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendWeatherForecastEvery6HoursEmail", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendWeatherForecastEvery6HoursEmail indicates an expected call of SendWeatherForecastEvery6HoursEmail.
+func (mr *MockEmailMockRecorder) SendWeatherForecastEvery6HoursEmail(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendWeatherForecastEvery6HoursEmail", reflect.TypeOf((*MockEmail)(nil).SendWeatherForecastEvery6HoursEmail), arg0)
+}
+
 // SendWeatherForecastHourlyEmail mocks base method.
 func (m *MockEmail) SendWeatherForecastHourlyEmail(arg0 domain.WeatherForecastEmailInput[*domain.Weather]) error {
 	m.ctrl.T.Helper()
@@ -81,3 +123,17 @@ func (mr *MockEmailMockRecorder) SendWeatherForecastHourlyEmail(arg0 any) *gomoc
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendWeatherForecastHourlyEmail", reflect.TypeOf((*MockEmail)(nil).SendWeatherForecastHourlyEmail), arg0)
 }
+
+// SendWeatherForecastWeeklyEmail mocks base method.
+func (m *MockEmail) SendWeatherForecastWeeklyEmail(arg0 domain.WeatherForecastEmailInput[*domain.Forecast]) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendWeatherForecastWeeklyEmail", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendWeatherForecastWeeklyEmail indicates an expected call of SendWeatherForecastWeeklyEmail.
+func (mr *MockEmailMockRecorder) SendWeatherForecastWeeklyEmail(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendWeatherForecastWeeklyEmail", reflect.TypeOf((*MockEmail)(nil).SendWeatherForecastWeeklyEmail), arg0)
+}
@@ -12,6 +12,10 @@ type Email interface {
 	SendConfirmationEmail(domain.ConfirmationEmailInput) error
 	SendWeatherForecastDailyEmail(domain.WeatherForecastEmailInput[*domain.DayWeather]) error
 	SendWeatherForecastHourlyEmail(domain.WeatherForecastEmailInput[*domain.Weather]) error
+	SendWeatherForecastEvery3HoursEmail(domain.WeatherForecastEmailInput[*domain.Every3HoursWeather]) error
+	SendWeatherForecastEvery6HoursEmail(domain.WeatherForecastEmailInput[*domain.Every6HoursWeather]) error
+	SendWeatherForecastWeeklyEmail(domain.WeatherForecastEmailInput[*domain.Forecast]) error
+	SendWeatherAlertEmail(domain.WeatherAlertEmailInput) error
 }
 
 type Deps struct {
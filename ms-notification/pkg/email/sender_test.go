@@ -7,7 +7,9 @@ import (
 	customErrors "ms-notification/pkg/errors"
 	"ms-notification/testutils"
 	"os"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -19,6 +21,14 @@ func TestGenerateBodyFromHTML(t *testing.T) {
 	)
 	t.Run("Generate HTML body for Weather hourly email", testGenerateBodyFromHTMLWeatherHourly)
 	t.Run("Generate HTML body for Weather daily email", testGenerateBodyFromHTMLWeatherDaily)
+	t.Run("Generate HTML body for Weather daily email in imperial units", testGenerateBodyFromHTMLWeatherDailyImperial)
+	t.Run("Generate HTML body for stale Weather hourly email", testGenerateBodyFromHTMLStaleWeatherHourly)
+	t.Run("Generate HTML body for Weather hourly email with air quality", testGenerateBodyFromHTMLWeatherHourlyAirQuality)
+	t.Run("Generate HTML body for Weather daily email with air quality", testGenerateBodyFromHTMLWeatherDailyAirQuality)
+	t.Run("Generate HTML body for Weather every 3 hours email", testGenerateBodyFromHTMLWeatherEvery3Hours)
+	t.Run("Generate HTML body for Weather every 6 hours email", testGenerateBodyFromHTMLWeatherEvery6Hours)
+	t.Run("Generate HTML body for Weather weekly email", testGenerateBodyFromHTMLWeatherWeekly)
+	t.Run("Generate HTML body for Weather alert email", testGenerateBodyFromHTMLWeatherAlert)
 	t.Run("Template file does not exist", testGenerateBodyFromHTMLInvalidTemplateFile)
 	t.Run("Template execution error", testGenerateBodyFromHTMLTemplateExecutionError)
 }
@@ -47,6 +57,203 @@ func testGenerateBodyFromHTMLWeatherHourly(t *testing.T) {
 
 	cfg := testutils.SetupTestConfig(t)
 
+	input := &email.SendEmailInput{
+		To:      "test@example.com",
+		Subject: "Test Email",
+	}
+	templateData := service.WeatherForecastHourlyEmailTemplateInput{
+		UnsubscribeLink: "https://example.com/api/unsubscribe",
+		City:            "London",
+		Weather: domain.Weather{
+			Temperature:              20.5,
+			Humidity:                 65,
+			Description:              "Sunny",
+			FeelsLike:                21.7,
+			WindSpeed:                12.6,
+			WindDirection:            270,
+			WindGust:                 25.2,
+			Precipitation:            0.4,
+			PrecipitationProbability: 35,
+			UVIndex:                  6,
+			Pressure:                 1013.2,
+			Visibility:               10,
+			CloudCover:               40,
+		},
+		Units: domain.UnitLabelsFor(domain.UnitsMetric),
+		Date:  "2025-01-01",
+	}
+
+	err := input.GenerateBodyFromHTML(cfg.Email.Templates.WeatherForecastHourly, templateData)
+
+	assert.Nil(t, err)
+	assert.Contains(t, input.Body, "https://example.com/api/unsubscribe")
+	assert.Contains(t, input.Body, "2025-01-01")
+	assert.Contains(t, input.Body, "21.7°C")
+	assert.Contains(t, input.Body, "12.6 km/h from 270°")
+	assert.Contains(t, input.Body, "1013.2 hPa")
+	assert.NotContains(t, input.Body, "temporarily unavailable")
+	assert.NotContains(t, input.Body, "Air quality")
+}
+
+func testGenerateBodyFromHTMLWeatherHourlyAirQuality(t *testing.T) {
+	t.Parallel()
+
+	cfg := testutils.SetupTestConfig(t)
+
+	input := &email.SendEmailInput{
+		To:      "test@example.com",
+		Subject: "Test Email",
+	}
+	templateData := service.WeatherForecastHourlyEmailTemplateInput{
+		UnsubscribeLink: "https://example.com/api/unsubscribe",
+		City:            "London",
+		Weather:         domain.Weather{Temperature: 20.5, Humidity: 65, Description: "Sunny"},
+		Units:           domain.UnitLabelsFor(domain.UnitsMetric),
+		Date:            "2025-01-01",
+		AirQuality:      &domain.AirQuality{AQI: 57, PM25: 12.3, PM10: 20, O3: 68.7, NO2: 13.5},
+	}
+
+	err := input.GenerateBodyFromHTML(cfg.Email.Templates.WeatherForecastHourly, templateData)
+
+	assert.Nil(t, err)
+	assert.Contains(t, input.Body, "Air quality")
+	assert.Contains(t, input.Body, "57 (Moderate)")
+	assert.Contains(t, input.Body, "12.3 μg/m³")
+	assert.Contains(t, input.Body, "68.7 μg/m³")
+}
+
+func testGenerateBodyFromHTMLWeatherDailyAirQuality(t *testing.T) {
+	t.Parallel()
+
+	cfg := testutils.SetupTestConfig(t)
+
+	input := &email.SendEmailInput{
+		To:      "test@example.com",
+		Subject: "Daily Email",
+	}
+	templateData := service.WeatherForecastDailyEmailTemplateInput{
+		UnsubscribeLink: "https://example.com/api/unsubscribe",
+		City:            "London",
+		Weather: domain.DayWeather{
+			Hours: []domain.HourlyWeather{
+				{Time: "07:00", Weather: domain.Weather{Temperature: 12.5, Humidity: 85, Description: "Clear"}},
+			},
+		},
+		Date:       "2025-01-01",
+		AirQuality: &domain.AirQuality{AQI: 162, PM25: 78.4, PM10: 120, O3: 40.2, NO2: 35.1},
+	}
+
+	err := input.GenerateBodyFromHTML(cfg.Email.Templates.WeatherForecastDaily, templateData)
+
+	assert.Nil(t, err)
+	assert.Contains(t, input.Body, "162 (Unhealthy)")
+	assert.Contains(t, input.Body, "35.1 μg/m³")
+}
+
+func testGenerateBodyFromHTMLWeatherEvery3Hours(t *testing.T) {
+	t.Parallel()
+
+	cfg := testutils.SetupTestConfig(t)
+
+	input := &email.SendEmailInput{
+		To:      "test@example.com",
+		Subject: "Test Email",
+	}
+	templateData := service.WeatherForecastEvery3HoursEmailTemplateInput{
+		UnsubscribeLink: "https://example.com/api/unsubscribe",
+		City:            "London",
+		Weather: domain.Every3HoursWeather{
+			Current: domain.Weather{Temperature: 20.5, FeelsLike: 21.7, Humidity: 65, Description: "Sunny"},
+			Hours: []domain.HourlyWeather{
+				{Time: "10:00", Weather: domain.Weather{Temperature: 22.4, Description: "Partly cloudy"}},
+				{Time: "13:00", Weather: domain.Weather{Temperature: 24.1, Description: "Light rain"}},
+			},
+		},
+		Units: domain.UnitLabelsFor(domain.UnitsMetric),
+		Date:  "2025-01-01 09:00:00",
+	}
+
+	err := input.GenerateBodyFromHTML(cfg.Email.Templates.WeatherForecastEvery3Hours, templateData)
+
+	assert.Nil(t, err)
+	assert.Contains(t, input.Body, "https://example.com/api/unsubscribe")
+	assert.Contains(t, input.Body, "the next 3 hours")
+	assert.Contains(t, input.Body, "21.7°C")
+	assert.Contains(t, input.Body, "13:00")
+	assert.Contains(t, input.Body, "Light rain")
+	assert.NotContains(t, input.Body, "Air quality")
+}
+
+func testGenerateBodyFromHTMLWeatherEvery6Hours(t *testing.T) {
+	t.Parallel()
+
+	cfg := testutils.SetupTestConfig(t)
+
+	input := &email.SendEmailInput{
+		To:      "test@example.com",
+		Subject: "Test Email",
+	}
+	templateData := service.WeatherForecastEvery6HoursEmailTemplateInput{
+		UnsubscribeLink: "https://example.com/api/unsubscribe",
+		City:            "London",
+		Weather: domain.Every6HoursWeather{
+			Current: domain.Weather{Temperature: 11.2, FeelsLike: 9.8, Humidity: 80, Description: "Fog"},
+			Hours: []domain.HourlyWeather{
+				{Time: "08:00", Weather: domain.Weather{Temperature: 12.3, Description: "Mist"}},
+				{Time: "13:00", Weather: domain.Weather{Temperature: 19.4, Description: "Sunny"}},
+			},
+		},
+		Units:      domain.UnitLabelsFor(domain.UnitsMetric),
+		Date:       "2025-01-01 07:00:00",
+		AirQuality: &domain.AirQuality{AQI: 42, PM25: 8.1, PM10: 15, O3: 60.3, NO2: 12.4},
+	}
+
+	err := input.GenerateBodyFromHTML(cfg.Email.Templates.WeatherForecastEvery6Hours, templateData)
+
+	assert.Nil(t, err)
+	assert.Contains(t, input.Body, "the next 6 hours")
+	assert.Contains(t, input.Body, "9.8°C")
+	assert.Contains(t, input.Body, "19.4")
+	assert.Contains(t, input.Body, "42 (Good)")
+}
+
+func testGenerateBodyFromHTMLWeatherWeekly(t *testing.T) {
+	t.Parallel()
+
+	cfg := testutils.SetupTestConfig(t)
+
+	input := &email.SendEmailInput{
+		To:      "test@example.com",
+		Subject: "Weekly Email",
+	}
+	templateData := service.WeatherForecastWeeklyEmailTemplateInput{
+		UnsubscribeLink: "https://example.com/api/unsubscribe",
+		City:            "London",
+		Forecast: domain.Forecast{
+			Days: []domain.DayForecast{
+				{Date: "2025-05-19", MinTemperature: 48.2, MaxTemperature: 66.2, Description: "Sunny"},
+				{Date: "2025-05-20", MinTemperature: 50, MaxTemperature: 59, Description: "Moderate rain"},
+			},
+		},
+		Units: domain.UnitLabelsFor(domain.UnitsImperial),
+		Date:  "2025-05-19",
+	}
+
+	err := input.GenerateBodyFromHTML(cfg.Email.Templates.WeatherForecastWeekly, templateData)
+
+	assert.Nil(t, err)
+	assert.Contains(t, input.Body, "https://example.com/api/unsubscribe")
+	assert.Contains(t, input.Body, "Max (°F)")
+	assert.Contains(t, input.Body, "2025-05-20")
+	assert.Contains(t, input.Body, "66.2")
+	assert.Contains(t, input.Body, "Moderate rain")
+}
+
+func testGenerateBodyFromHTMLStaleWeatherHourly(t *testing.T) {
+	t.Parallel()
+
+	cfg := testutils.SetupTestConfig(t)
+
 	input := &email.SendEmailInput{
 		To:      "test@example.com",
 		Subject: "Test Email",
@@ -58,6 +265,8 @@ func testGenerateBodyFromHTMLWeatherHourly(t *testing.T) {
 			Temperature: 20.5,
 			Humidity:    65,
 			Description: "Sunny",
+			Stale:       true,
+			ObservedAt:  time.Date(2025, 1, 1, 9, 30, 0, 0, time.UTC),
 		},
 		Date: "2025-01-01",
 	}
@@ -65,8 +274,8 @@ func testGenerateBodyFromHTMLWeatherHourly(t *testing.T) {
 	err := input.GenerateBodyFromHTML(cfg.Email.Templates.WeatherForecastHourly, templateData)
 
 	assert.Nil(t, err)
-	assert.Contains(t, input.Body, "https://example.com/api/unsubscribe")
-	assert.Contains(t, input.Body, "2025-01-01")
+	assert.Contains(t, input.Body, "temporarily unavailable")
+	assert.Contains(t, input.Body, "2025-01-01 09:30 UTC")
 }
 
 func testGenerateBodyFromHTMLWeatherDaily(t *testing.T) {
@@ -82,31 +291,11 @@ func testGenerateBodyFromHTMLWeatherDaily(t *testing.T) {
 		UnsubscribeLink: "https://example.com/api/unsubscribe",
 		City:            "London",
 		Weather: domain.DayWeather{
-			SevenAM: domain.Weather{
-				Temperature: 18.0,
-				Humidity:    70,
-				Description: "Cloudy",
+			Hours: []domain.HourlyWeather{
+				{Time: "00:00", Weather: domain.Weather{Temperature: 12.5, Humidity: 85, Description: "Clear"}},
+				{Time: "06:00", Weather: domain.Weather{Temperature: 14.0, Humidity: 80, Description: "Mist"}},
+				{Time: "13:00", Weather: domain.Weather{Temperature: 18.0, Humidity: 70, Description: "Cloudy", UVIndex: 4.5}},
 			},
-			TenAM: domain.Weather{
-				Temperature: 18.0,
-				Humidity:    70,
-				Description: "Cloudy"},
-			OnePM: domain.Weather{
-				Temperature: 18.0,
-				Humidity:    70,
-				Description: "Cloudy"},
-			FourPM: domain.Weather{
-				Temperature: 18.0,
-				Humidity:    70,
-				Description: "Cloudy"},
-			SevenPM: domain.Weather{
-				Temperature: 18.0,
-				Humidity:    70,
-				Description: "Cloudy"},
-			TenPM: domain.Weather{
-				Temperature: 18.0,
-				Humidity:    70,
-				Description: "Cloudy"},
 		},
 		Date: "2025-01-01",
 	}
@@ -116,6 +305,73 @@ func testGenerateBodyFromHTMLWeatherDaily(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Contains(t, input.Body, "https://example.com/api/unsubscribe")
 	assert.Contains(t, input.Body, "2025-01-01")
+	assert.Contains(t, input.Body, "00:00")
+	assert.Contains(t, input.Body, "Mist")
+	assert.Less(t, strings.Index(input.Body, "06:00"), strings.Index(input.Body, "13:00"))
+	assert.Contains(t, input.Body, "4.5")
+}
+
+func testGenerateBodyFromHTMLWeatherDailyImperial(t *testing.T) {
+	t.Parallel()
+
+	cfg := testutils.SetupTestConfig(t)
+
+	input := &email.SendEmailInput{
+		To:      "test@example.com",
+		Subject: "Daily Email",
+	}
+	templateData := service.WeatherForecastDailyEmailTemplateInput{
+		UnsubscribeLink: "https://example.com/api/unsubscribe",
+		City:            "London",
+		Weather: domain.DayWeather{
+			Hours: []domain.HourlyWeather{
+				{Time: "07:00", Weather: domain.Weather{Temperature: 64.4, WindSpeed: 10, Description: "Cloudy"}},
+			},
+		},
+		Units: domain.UnitLabelsFor(domain.UnitsImperial),
+		Date:  "2025-01-01",
+	}
+
+	err := input.GenerateBodyFromHTML(cfg.Email.Templates.WeatherForecastDaily, templateData)
+
+	assert.Nil(t, err)
+	assert.Contains(t, input.Body, "Temperature (°F)")
+	assert.Contains(t, input.Body, "Wind (mph)")
+	assert.NotContains(t, input.Body, "°C")
+}
+
+func testGenerateBodyFromHTMLWeatherAlert(t *testing.T) {
+	t.Parallel()
+
+	cfg := testutils.SetupTestConfig(t)
+
+	input := &email.SendEmailInput{
+		To:      "test@example.com",
+		Subject: "Alert Email",
+	}
+	templateData := service.WeatherAlertEmailTemplateInput{
+		UnsubscribeLink: "https://example.com/api/unsubscribe",
+		City:            "Kyiv",
+		Alert: domain.WeatherAlert{
+			Event:       "Storm Warning",
+			Headline:    "Storm warning issued for Kyiv",
+			Severity:    "Severe",
+			Description: "Wind gusts up to 90 km/h.",
+			Instruction: "Stay indoors.",
+			Expires:     time.Date(2025, 5, 18, 10, 0, 0, 0, time.UTC),
+		},
+	}
+
+	err := input.GenerateBodyFromHTML(cfg.Email.Templates.WeatherAlert, templateData)
+
+	assert.Nil(t, err)
+	assert.Contains(t, input.Body, "Kyiv: Storm Warning")
+	assert.Contains(t, input.Body, "Severity: Severe")
+	assert.Contains(t, input.Body, "Wind gusts up to 90 km/h.")
+	assert.Contains(t, input.Body, "Stay indoors.")
+	assert.Contains(t, input.Body, "2025-05-18 10:00 UTC")
+	assert.NotContains(t, input.Body, "Effective:", "unknown effective time is not shown")
+	assert.Contains(t, input.Body, "https://example.com/api/unsubscribe")
 }
 
 func testGenerateBodyFromHTMLInvalidTemplateFile(t *testing.T) {
@@ -4,6 +4,7 @@ import (
 	"log"
 	"ms-weather-subscription/internal/app"
 	"ms-weather-subscription/internal/config"
+	_ "time/tzdata" // the runtime image has no zoneinfo, city timezones are needed for forecast caching
 )
 
 func main() {
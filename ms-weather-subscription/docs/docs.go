@@ -15,6 +15,55 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/air-quality": {
+            "get": {
+                "description": "Returns the US EPA air quality index (0-500) and concentrations (μg/m³) of fine (PM2.5)\nand coarse (PM10) particulate matter, ozone and nitrogen dioxide.\nEither city or both lat and lon must be given.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "weather"
+                ],
+                "summary": "Get current air quality for a city or coordinates",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "City name for air quality",
+                        "name": "city",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Latitude in decimal degrees (-90 to 90)",
+                        "name": "lat",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Longitude in decimal degrees (-180 to 180)",
+                        "name": "lon",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.airQualityResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request"
+                    },
+                    "404": {
+                        "description": "City not found"
+                    }
+                }
+            }
+        },
         "/confirm/{token}": {
             "get": {
                 "description": "Confirms a subscription using the token sent in the confirmation email.",
@@ -52,7 +101,7 @@ const docTemplate = `{
         },
         "/subscribe": {
             "post": {
-                "description": "Subscribe an email to receive weather updates for a specific city with chosen frequency.",
+                "description": "Subscribe an email to receive weather updates for a specific city or coordinates\nwith chosen frequency. Either city or both lat and lon must be given.\nWith alerts frequency, an email is sent only when a new severe weather alert is issued.\nEvery 3 and 6 hours emails have the current weather and the forecast until the next email.\nWeekly emails are sent on Mondays with the outlook for 7 days.\nForecast emails, except for weekly ones, include the air quality if air_quality is set.\nDaily and weekly emails are sent at delivery_hour in the subscription's timezone,\nwhich is the timezone of the city unless timezone is given.\nHourly and every 3 and 6 hours emails are sent from active_from to active_to local time,\non weekdays only if weekdays_only is set.\nThe window wraps past midnight if active_from is after active_to.",
                 "consumes": [
                     "application/json",
                     "application/x-www-form-urlencoded"
@@ -76,19 +125,87 @@ const docTemplate = `{
                         "type": "string",
                         "description": "City for weather updates",
                         "name": "city",
-                        "in": "formData",
-                        "required": true
+                        "in": "formData"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Latitude in decimal degrees (-90 to 90)",
+                        "name": "lat",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Longitude in decimal degrees (-180 to 180)",
+                        "name": "lon",
+                        "in": "formData"
                     },
                     {
                         "enum": [
                             "hourly",
-                            "daily"
+                            "every_3h",
+                            "every_6h",
+                            "daily",
+                            "weekly",
+                            "alerts"
                         ],
                         "type": "string",
-                        "description": "Frequency of updates (hourly or daily)",
+                        "description": "Frequency of updates",
                         "name": "frequency",
                         "in": "formData",
                         "required": true
+                    },
+                    {
+                        "enum": [
+                            "metric",
+                            "imperial",
+                            "standard"
+                        ],
+                        "type": "string",
+                        "default": "metric",
+                        "description": "Units of the weather in emails",
+                        "name": "units",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "Include air quality in forecast emails",
+                        "name": "air_quality",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "IANA timezone of the subscription, e.g. Europe/Kyiv",
+                        "name": "timezone",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 7,
+                        "description": "Local hour daily and weekly emails are sent at (0-23)",
+                        "name": "delivery_hour",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "default": "00:00",
+                        "description": "First hour emails are sent at (HH:00)",
+                        "name": "active_from",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "default": "23:00",
+                        "description": "Last hour emails are sent at (HH:00)",
+                        "name": "active_to",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "Send emails from Monday to Friday only",
+                        "name": "weekdays_only",
+                        "in": "formData"
                     }
                 ],
                 "responses": {
@@ -98,6 +215,9 @@ const docTemplate = `{
                     "400": {
                         "description": "Invalid input"
                     },
+                    "404": {
+                        "description": "City not found"
+                    },
                     "409": {
                         "description": "Email already subscribed"
                     }
@@ -141,7 +261,68 @@ const docTemplate = `{
         },
         "/weather": {
             "get": {
-                "description": "Returns the current weather forecast for the specified city using WeatherAPI.com.",
+                "description": "Returns the current weather forecast for the specified city or coordinates using WeatherAPI.com.\nEither city or both lat and lon must be given.\nBesides temperature, humidity (%) and conditions it includes feels-like temperature,\nwind speed and gusts, wind direction (degrees), precipitation and its probability (%),\nUV index, sea level pressure, visibility and cloud cover (%).\nValues are in the requested units: metric (°C, km/h, mm, hPa, km),\nimperial (°F, mph, in, inHg, mi) or standard (K, m/s, mm, hPa, km).\nIf all weather providers are unavailable, the last known weather is returned with stale flag\nand the time it was observed at.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "weather"
+                ],
+                "summary": "Get current weather for a city or coordinates",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "City name for weather forecast",
+                        "name": "city",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Latitude in decimal degrees (-90 to 90)",
+                        "name": "lat",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Longitude in decimal degrees (-180 to 180)",
+                        "name": "lon",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "metric",
+                            "imperial",
+                            "standard"
+                        ],
+                        "type": "string",
+                        "default": "metric",
+                        "description": "Units of the values",
+                        "name": "units",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.weatherResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request"
+                    },
+                    "404": {
+                        "description": "City not found"
+                    }
+                }
+            }
+        },
+        "/weather/forecast": {
+            "get": {
+                "description": "Returns daily forecast for the specified city or coordinates starting from today:\nmin, max and average temperature, humidity, precipitation probability and conditions.\nEither city or both lat and lon must be given.\nTemperatures are in the requested units: metric (°C), imperial (°F) or standard (K).",
                 "consumes": [
                     "application/json"
                 ],
@@ -151,21 +332,126 @@ const docTemplate = `{
                 "tags": [
                     "weather"
                 ],
-                "summary": "Get current weather for a city",
+                "summary": "Get multi-day weather forecast for a city or coordinates",
                 "parameters": [
                     {
                         "type": "string",
                         "description": "City name for weather forecast",
                         "name": "city",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Latitude in decimal degrees (-90 to 90)",
+                        "name": "lat",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Longitude in decimal degrees (-180 to 180)",
+                        "name": "lon",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 14,
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Number of days to forecast (1-14)",
+                        "name": "days",
                         "in": "query",
                         "required": true
+                    },
+                    {
+                        "enum": [
+                            "metric",
+                            "imperial",
+                            "standard"
+                        ],
+                        "type": "string",
+                        "default": "metric",
+                        "description": "Units of the values",
+                        "name": "units",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/handlers.weatherResponse"
+                            "$ref": "#/definitions/handlers.forecastResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request"
+                    },
+                    "404": {
+                        "description": "City not found"
+                    }
+                }
+            }
+        },
+        "/weather/history": {
+            "get": {
+                "description": "Returns the current weather received from weather providers for the city or coordinates,\noldest first, together with the provider and the time it was received at. Either city\nor both lat and lon must be given. Different spellings of the city share the history\nof the location they resolve to, and nearby coordinates share the history of their grid cell.\nWeather is kept for the configured retention period. The period requested at once\ncan't be longer than 31 days. By default the last 24 hours are returned.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "weather"
+                ],
+                "summary": "Get weather history for a city or coordinates",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "City name",
+                        "name": "city",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Latitude in decimal degrees (-90 to 90)",
+                        "name": "lat",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Longitude in decimal degrees (-180 to 180)",
+                        "name": "lon",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start of the period (RFC 3339), 24 hours before to by default",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End of the period, exclusive (RFC 3339), now by default",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "metric",
+                            "imperial",
+                            "standard"
+                        ],
+                        "type": "string",
+                        "default": "metric",
+                        "description": "Units of the values",
+                        "name": "units",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.weatherHistoryResponse"
                         }
                     },
                     "400": {
@@ -179,17 +465,135 @@ const docTemplate = `{
         }
     },
     "definitions": {
+        "handlers.airQualityResponse": {
+            "type": "object",
+            "properties": {
+                "aqi": {
+                    "type": "integer"
+                },
+                "no2": {
+                    "type": "number"
+                },
+                "o3": {
+                    "type": "number"
+                },
+                "pm10": {
+                    "type": "number"
+                },
+                "pm2_5": {
+                    "type": "number"
+                }
+            }
+        },
+        "handlers.dayForecastResponse": {
+            "type": "object",
+            "properties": {
+                "avg_temperature": {
+                    "type": "number"
+                },
+                "date": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "humidity": {
+                    "type": "number"
+                },
+                "max_temperature": {
+                    "type": "number"
+                },
+                "min_temperature": {
+                    "type": "number"
+                },
+                "precipitation_probability": {
+                    "type": "number"
+                }
+            }
+        },
+        "handlers.forecastResponse": {
+            "type": "object",
+            "properties": {
+                "days": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.dayForecastResponse"
+                    }
+                }
+            }
+        },
+        "handlers.weatherHistoryResponse": {
+            "type": "object",
+            "properties": {
+                "snapshots": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.weatherSnapshotResponse"
+                    }
+                }
+            }
+        },
         "handlers.weatherResponse": {
             "type": "object",
             "properties": {
+                "cloud_cover": {
+                    "type": "number"
+                },
                 "description": {
                     "type": "string"
                 },
+                "feels_like": {
+                    "type": "number"
+                },
                 "humidity": {
                     "type": "number"
                 },
+                "observed_at": {
+                    "type": "string"
+                },
+                "precipitation": {
+                    "type": "number"
+                },
+                "precipitation_probability": {
+                    "type": "number"
+                },
+                "pressure": {
+                    "type": "number"
+                },
+                "stale": {
+                    "type": "boolean"
+                },
                 "temperature": {
                     "type": "number"
+                },
+                "uv_index": {
+                    "type": "number"
+                },
+                "visibility": {
+                    "type": "number"
+                },
+                "wind_direction": {
+                    "type": "number"
+                },
+                "wind_gust": {
+                    "type": "number"
+                },
+                "wind_speed": {
+                    "type": "number"
+                }
+            }
+        },
+        "handlers.weatherSnapshotResponse": {
+            "type": "object",
+            "properties": {
+                "observed_at": {
+                    "type": "string"
+                },
+                "provider": {
+                    "type": "string"
+                },
+                "weather": {
+                    "$ref": "#/definitions/handlers.weatherResponse"
                 }
             }
         }
@@ -14,6 +14,7 @@ import (
 	"ms-weather-subscription/pkg/cache"
 	"ms-weather-subscription/pkg/clients"
 	"ms-weather-subscription/pkg/hash"
+	"ms-weather-subscription/pkg/lock"
 	"ms-weather-subscription/pkg/publisher"
 	"net/http"
 	"os"
@@ -48,31 +49,78 @@ func (ab *ApplicationBuilder) setupDependencies(app *Application) {
 	hasher := &hash.SHA256Hasher{}
 
 	redisCache := cache.NewCache(app.redisConn)
+	var weatherCache cache.Cache = redisCache
+	if app.config.MemoryCache.MaxEntries > 0 {
+		weatherCache = cache.NewTieredCache(
+			cache.NewMemoryCache(app.config.MemoryCache.MaxEntries),
+			redisCache,
+			app.config.MemoryCache.PromotedTTL,
+		)
+	}
+
+	repositories := repository.NewRepositories(app.dbConn)
 
-	primaryWeatherClient := clients.NewWeatherAPIClient(app.config.ThirdParty.WeatherAPIKey)
-	fallbackWeatherClients := []clients.ChainWeatherProvider{
-		clients.NewVisualCrossingClient(app.config.ThirdParty.VisualCrossingAPIKey),
+	providerRegistry := clients.NewDefaultProviderRegistry(app.config.ThirdParty).
+		WithQuotaCounter(cache.NewRedisCounter(app.redisConn)).
+		WithSnapshotStore(repositories.WeatherSnapshot)
+	weatherProviders, err := providerRegistry.Build(app.config.WeatherProviders)
+	if err != nil {
+		log.Fatalf("failed to create weather providers: %v", err)
 	}
-	allWeatherClients := append(
-		[]clients.ChainWeatherProvider{primaryWeatherClient}, fallbackWeatherClients...,
-	)
-	chainWeatherClient, err := clients.NewChainWeatherClient(allWeatherClients)
+	locationSearchers, err := providerRegistry.BuildLocationSearchers(app.config.WeatherProviders)
+	if err != nil {
+		log.Fatalf("failed to create location searchers: %v", err)
+	}
+	locationSearcher, err := clients.NewChainLocationSearcher(locationSearchers)
+	if err != nil {
+		log.Fatalf("failed to create chain location searcher: %v", err)
+	}
+	alertsProviders, err := providerRegistry.BuildAlertsProviders(app.config.WeatherProviders)
+	if err != nil {
+		log.Fatalf("failed to create alerts providers: %v", err)
+	}
+	alertsClient, err := clients.NewChainAlertsClient(alertsProviders)
+	if err != nil {
+		log.Fatalf("failed to create chain alerts client: %v", err)
+	}
+	chainWeatherClient, err := clients.NewChainWeatherClient(weatherProviders)
 	if err != nil {
 		log.Fatalf("failed to create chain weather client: %v", err)
 	}
-	cachingWeatherClient := clients.NewCachingWeatherClient(chainWeatherClient, redisCache)
-
-	repositories := repository.NewRepositories(app.dbConn)
+	cachingWeatherClient := clients.NewCachingWeatherClient(chainWeatherClient, weatherCache)
+	// Only the weather requested via the API is hedged, the emails don't need the extra provider calls.
+	var apiWeatherClient clients.WeatherClient
+	if app.config.WeatherRequests.Mode == config.WeatherRequestModeHedged {
+		apiWeatherClient = clients.NewCachingWeatherClient(
+			clients.NewHedgedWeatherClient(chainWeatherClient, app.config.WeatherRequests.HedgeDelay),
+			weatherCache,
+		)
+	}
 
+	locker := lock.NewRedisLocker(app.redisConn, app.config.Cron.InstanceID)
 	services := service.NewServices(service.Deps{
 		WeatherClient:      cachingWeatherClient,
+		APIWeatherClient:   apiWeatherClient,
+		AlertsClient:       alertsClient,
+		LocationSearcher:   locationSearcher,
+		Cache:              cache.NewCache(app.redisConn),
 		Repos:              repositories,
 		SubscriptionHasher: hasher,
 		HTTPConfig:         app.config.HTTP,
+		DailyForecast:      app.config.DailyForecast,
+		WeatherHistory:     app.config.WeatherHistory,
+		Scheduler:          app.config.Scheduler,
+		SchedulerTokens:    locker,
 		EmailPublisher:     app.emailPublisher,
 	})
 
-	app.cronRunner = NewCronRunner(services.WeatherForecastSender)
+	app.cronRunner = NewCronRunner(
+		services.Scheduler,
+		services.WeatherAlertSender,
+		services.WeatherHistory,
+		locker,
+		app.config.Cron,
+	)
 
 	handler := handlers.NewHandler(services)
 
@@ -142,7 +190,6 @@ func (ab *ApplicationBuilder) Build(environment string) (*Application, error) {
 // @tag.description Subscription management operations
 func (a *Application) Run() {
 	a.cronRunner.Start()
-	defer a.cronRunner.Stop()
 
 	go func() {
 		if err := a.server.Run(); !errors.Is(err, http.ErrServerClosed) {
@@ -174,6 +221,10 @@ func (a *Application) shutdown() {
 		logger.Info("server stopped successfully")
 	}
 
+	// The running cron tasks release their locks in Redis, so it is stopped before Redis is closed.
+	a.cronRunner.Stop()
+	logger.Info("cron runner stopped successfully")
+
 	if err := a.dbConn.Close(); err != nil {
 		logger.Errorf("error occurred on db connection close: %s", err.Error())
 	} else {
@@ -1,8 +1,15 @@
 package app
 
 import (
+	"common/frequency"
 	"common/logger"
 	"context"
+	"errors"
+	"ms-weather-subscription/internal/config"
+	"ms-weather-subscription/internal/domain"
+	"ms-weather-subscription/pkg/lock"
+	"ms-weather-subscription/pkg/metrics"
+	"strings"
 	"time"
 
 	"github.com/robfig/cron/v3"
@@ -11,23 +18,63 @@ import (
 type Cron interface {
 	Start()
 	Stop()
-	AddTask(schedule string, taskFunc func(), taskName string)
+	AddTask(schedule string, taskFunc TaskFunc, taskName string)
 }
 
-type WeatherForecastSender interface {
-	SendHourlyWeatherForecast(ctx context.Context) error
-	SendDailyWeatherForecast(ctx context.Context) error
+// TaskFunc is a cron task. It should stop once ctx is canceled, e.g. on shutdown.
+type TaskFunc func(ctx context.Context)
+
+type SubscriptionScheduler interface {
+	RunDue(ctx context.Context) error
+}
+
+type WeatherAlertSender interface {
+	SendWeatherAlerts(ctx context.Context) error
+	DeleteExpiredSentAlerts(ctx context.Context) error
 }
 
+type WeatherHistoryCleaner interface {
+	DeleteExpiredSnapshots(ctx context.Context) error
+}
+
+const (
+	// stopTimeout bounds the wait for the running tasks on Stop after their contexts are canceled.
+	stopTimeout = 10 * time.Second
+	// lockReleaseTimeout bounds the release of a task lock, which is done after the task context is canceled.
+	lockReleaseTimeout = 5 * time.Second
+)
+
+// CronRunner runs each task on one instance at a time: a run is skipped if another instance holds the task lock.
+// The tasks which split the work between the instances themselves run on every instance.
 type CronRunner struct {
-	service WeatherForecastSender
-	cron    *cron.Cron
+	scheduler      SubscriptionScheduler
+	alertsSender   WeatherAlertSender
+	historyCleaner WeatherHistoryCleaner
+	locker         lock.Locker
+	config         config.CronConfig
+	cron           *cron.Cron
+	// ctx is the parent context of the tasks, it is canceled on Stop.
+	ctx    context.Context
+	cancel context.CancelFunc
 }
 
-func NewCronRunner(service WeatherForecastSender) *CronRunner {
+func NewCronRunner(
+	scheduler SubscriptionScheduler,
+	alertsSender WeatherAlertSender,
+	historyCleaner WeatherHistoryCleaner,
+	locker lock.Locker,
+	cronConfig config.CronConfig,
+) *CronRunner {
+	ctx, cancel := context.WithCancel(context.Background())
 	return &CronRunner{
-		service: service,
-		cron:    cron.New(cron.WithLocation(time.UTC)),
+		scheduler:      scheduler,
+		alertsSender:   alertsSender,
+		historyCleaner: historyCleaner,
+		locker:         locker,
+		config:         cronConfig,
+		cron:           cron.New(cron.WithLocation(time.UTC)),
+		ctx:            ctx,
+		cancel:         cancel,
 	}
 }
 
@@ -36,39 +83,168 @@ func (c *CronRunner) Start() {
 	c.cron.Start()
 }
 
+// Stop cancels the running tasks and waits for them up to stopTimeout,
+// so their locks are released before the connections are closed.
 func (c *CronRunner) Stop() {
-	c.cron.Stop().Done()
+	stopped := c.cron.Stop()
+	c.cancel()
+
+	select {
+	case <-stopped.Done():
+	case <-time.After(stopTimeout):
+		logger.Errorf("cron tasks on %s haven't stopped within %s", c.config.InstanceID, stopTimeout)
+	}
+}
+
+type emailTask struct {
+	schedule string
+	run      TaskFunc
+}
+
+// emailTasks returns the email sending task of each subscription frequency not sent by the scheduler.
+func (c *CronRunner) emailTasks() map[string]emailTask {
+	return map[string]emailTask{
+		// Every 10 minutes, so alerts are emailed shortly after they are issued
+		frequency.Alerts: {"*/10 * * * *", c.weatherAlertsEmailTask},
+	}
 }
 
 func (c *CronRunner) registerTasks() {
-	// Top of each hour (7:00, 8:00, 9:00, etc.)
-	c.AddTask("0 * * * *", c.hourlyWeatherEmailTask, "hourly weather email sending")
-	// Daily at 7AM
-	c.AddTask("0 7 * * *", c.dailyWeatherEmailTask, "daily weather email sending")
+	// Every minute, the forecast emails are sent at the next run of each subscription.
+	// Every instance runs it, the due subscriptions are claimed so each of them is sent by one instance.
+	c.AddConcurrentTask("* * * * *", c.scheduledWeatherEmailTask, "scheduled weather email sending")
+
+	tasks := c.emailTasks()
+	for _, f := range frequency.All {
+		if domain.IsScheduledFrequency(f) {
+			continue
+		}
+		task, ok := tasks[f]
+		if !ok {
+			logger.Errorf("no email task for %s subscriptions", f)
+			continue
+		}
+		c.AddTask(task.schedule, task.run, f+" weather email sending")
+	}
+
+	// Daily at 3:30AM, outside of the email sending hours
+	c.AddTask("30 3 * * *", c.cleanupTask, "expired data deletion")
+}
+
+// AddTask schedules the task to run on one instance at a time, see RunTask.
+func (c *CronRunner) AddTask(schedule string, taskFunc TaskFunc, taskName string) {
+	c.addFunc(schedule, func() { c.RunTask(taskFunc, taskName) }, taskName)
+}
+
+// AddConcurrentTask schedules the task to run on every instance.
+func (c *CronRunner) AddConcurrentTask(schedule string, taskFunc TaskFunc, taskName string) {
+	c.addFunc(schedule, func() {
+		logger.Debugf("start %s on %s", taskName, c.config.InstanceID)
+		metrics.CronTaskRunCount.WithLabelValues(taskName, c.config.InstanceID).Inc()
+		taskFunc(c.ctx)
+	}, taskName)
 }
 
-func (c *CronRunner) AddTask(schedule string, taskFunc func(), taskName string) {
-	_, err := c.cron.AddFunc(schedule, func() {
-		logger.Debugf("start %s", taskName)
-		taskFunc()
-	})
+func (c *CronRunner) addFunc(schedule string, run func(), taskName string) {
+	_, err := c.cron.AddFunc(schedule, run)
 	if err != nil {
 		logger.Errorf("failed to schedule %s: %v", taskName, err)
 	}
 }
 
-func (c *CronRunner) hourlyWeatherEmailTask() {
-	ctx := context.Background()
-	err := c.service.SendHourlyWeatherForecast(ctx)
+// RunTask runs the task unless another instance is running it. The task lock is extended while the task runs
+// and released once it's done, otherwise it expires after the lock TTL, e.g. if the instance crashes.
+// The task context is canceled if the lock is lost, so the task stops before another instance starts it.
+func (c *CronRunner) RunTask(taskFunc TaskFunc, taskName string) {
+	instance := c.config.InstanceID
+	key := "cron:" + strings.ReplaceAll(taskName, " ", "_")
+
+	taskLock, err := c.locker.TryAcquire(c.ctx, key, c.config.LockTTL)
+	if errors.Is(err, lock.ErrNotAcquired) {
+		logger.Debugf("skip %s on %s: %s", taskName, instance, err.Error())
+		metrics.CronTaskSkippedCount.WithLabelValues(taskName, instance).Inc()
+		return
+	}
+	if err != nil {
+		// The task isn't run without the lock, so it isn't run by several instances at once.
+		logger.Errorf("failed to acquire lock of %s on %s: %s", taskName, instance, err.Error())
+		metrics.CronTaskLockErrorCount.WithLabelValues(taskName, instance).Inc()
+		return
+	}
+
+	logger.Infof("start %s on %s", taskName, instance)
+	metrics.CronTaskRunCount.WithLabelValues(taskName, instance).Inc()
+
+	// The task passes the fencing token of its lock on to its writes, see lock.WithToken.
+	ctx, cancel := context.WithCancel(lock.WithToken(c.ctx, taskLock.Token()))
+	extended := make(chan struct{})
+	go func() {
+		defer close(extended)
+		c.extendLock(ctx, cancel, taskLock, taskName)
+	}()
+
+	taskFunc(ctx)
+
+	cancel()
+	<-extended
+
+	releaseCtx, cancelRelease := context.WithTimeout(context.Background(), lockReleaseTimeout)
+	defer cancelRelease()
+	if err := taskLock.Release(releaseCtx); err != nil {
+		logger.Errorf("failed to release lock of %s on %s: %s", taskName, instance, err.Error())
+		metrics.CronTaskLockErrorCount.WithLabelValues(taskName, instance).Inc()
+	}
+}
+
+// extendLock extends the task lock every third of the lock TTL until ctx is canceled.
+// The task is canceled once the lock is lost: it may be held by another instance already.
+func (c *CronRunner) extendLock(
+	ctx context.Context, cancelTask context.CancelFunc, taskLock lock.Lock, taskName string,
+) {
+	instance := c.config.InstanceID
+	ticker := time.NewTicker(c.config.LockTTL / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			err := taskLock.Extend(ctx, c.config.LockTTL)
+			if err == nil || ctx.Err() != nil {
+				continue
+			}
+			logger.Errorf("failed to extend lock of %s on %s: %s", taskName, instance, err.Error())
+			metrics.CronTaskLockErrorCount.WithLabelValues(taskName, instance).Inc()
+			if errors.Is(err, lock.ErrNotHeld) {
+				logger.Errorf("lock of %s lost on %s, the task is canceled", taskName, instance)
+				cancelTask()
+				return
+			}
+		}
+	}
+}
+
+func (c *CronRunner) scheduledWeatherEmailTask(ctx context.Context) {
+	err := c.scheduler.RunDue(ctx)
 	if err != nil {
-		logger.Errorf("hourly weather task error: %s", err.Error())
+		logger.Errorf("scheduled weather task error: %s", err.Error())
 	}
 }
 
-func (c *CronRunner) dailyWeatherEmailTask() {
-	ctx := context.Background()
-	err := c.service.SendDailyWeatherForecast(ctx)
+func (c *CronRunner) weatherAlertsEmailTask(ctx context.Context) {
+	err := c.alertsSender.SendWeatherAlerts(ctx)
 	if err != nil {
-		logger.Errorf("daily weather task error: %s", err.Error())
+		logger.Errorf("weather alerts task error: %s", err.Error())
+	}
+}
+
+// cleanupTask deletes the expired weather history and forgets the expired alerts sent to the subscriptions.
+func (c *CronRunner) cleanupTask(ctx context.Context) {
+	if err := c.historyCleaner.DeleteExpiredSnapshots(ctx); err != nil {
+		logger.Errorf("weather history cleanup task error: %s", err.Error())
+	}
+	if err := c.alertsSender.DeleteExpiredSentAlerts(ctx); err != nil {
+		logger.Errorf("sent alerts cleanup task error: %s", err.Error())
 	}
 }
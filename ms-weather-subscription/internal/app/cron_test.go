@@ -0,0 +1,206 @@
+package app_test
+
+import (
+	"context"
+	"errors"
+	"ms-weather-subscription/internal/app"
+	"ms-weather-subscription/internal/config"
+	"ms-weather-subscription/pkg/lock"
+	"ms-weather-subscription/pkg/metrics"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCronRunner(t *testing.T) {
+	t.Run("Run task under its lock", testRunTaskUnderLock)
+	t.Run("Skip task held by another instance", testRunTaskHeldByAnotherInstance)
+	t.Run("Skip task if lock can't be acquired", testRunTaskLockError)
+	t.Run("Cancel task once its lock is lost", testRunTaskLockLost)
+	t.Run("Cancel running tasks on stop", testStopCancelsRunningTasks)
+}
+
+const testInstance = "instance-1"
+
+// fakeLocker is a Locker backed by a map. Its locks fail to extend while lost is set.
+// All of its fencing tokens are issued from one sequence.
+type fakeLocker struct {
+	mu         sync.Mutex
+	held       map[string]bool
+	acquireErr error
+	lost       bool
+	lastToken  int64
+}
+
+func newFakeLocker() *fakeLocker {
+	return &fakeLocker{held: make(map[string]bool)}
+}
+
+func (l *fakeLocker) TryAcquire(_ context.Context, key string, _ time.Duration) (lock.Lock, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.acquireErr != nil {
+		return nil, l.acquireErr
+	}
+	if l.held[key] {
+		return nil, lock.ErrNotAcquired
+	}
+	l.held[key] = true
+	l.lastToken++
+	return &fakeLock{locker: l, key: key, token: lock.Token{Key: key, Value: l.lastToken}}, nil
+}
+
+func (l *fakeLocker) NextToken(_ context.Context, key string) (lock.Token, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.lastToken++
+	return lock.Token{Key: key, Value: l.lastToken}, nil
+}
+
+func (l *fakeLocker) isHeld(key string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.held[key]
+}
+
+type fakeLock struct {
+	locker *fakeLocker
+	key    string
+	token  lock.Token
+}
+
+func (l *fakeLock) Extend(context.Context, time.Duration) error {
+	l.locker.mu.Lock()
+	defer l.locker.mu.Unlock()
+
+	if l.locker.lost {
+		return lock.ErrNotHeld
+	}
+	return nil
+}
+
+func (l *fakeLock) Token() lock.Token {
+	return l.token
+}
+
+func (l *fakeLock) Release(context.Context) error {
+	l.locker.mu.Lock()
+	defer l.locker.mu.Unlock()
+
+	if !l.locker.held[l.key] {
+		return lock.ErrNotHeld
+	}
+	delete(l.locker.held, l.key)
+	return nil
+}
+
+func newTestCronRunner(locker lock.Locker) *app.CronRunner {
+	return app.NewCronRunner(nil, nil, nil, locker, config.CronConfig{
+		LockTTL:    30 * time.Millisecond,
+		InstanceID: testInstance,
+	})
+}
+
+func testRunTaskUnderLock(t *testing.T) {
+	// Setup
+	locker := newFakeLocker()
+	runner := newTestCronRunner(locker)
+	runs := testutil.ToFloat64(metrics.CronTaskRunCount.WithLabelValues("locked task", testInstance))
+
+	// Execute
+	var heldWhileRunning bool
+	var token lock.Token
+	runner.RunTask(func(ctx context.Context) {
+		heldWhileRunning = locker.isHeld("cron:locked_task")
+		token, _ = lock.TokenFrom(ctx)
+	}, "locked task")
+
+	// Verify
+	assert.True(t, heldWhileRunning)
+	assert.Equal(t, lock.Token{Key: "cron:locked_task", Value: 1}, token, "the task gets the token of its lock")
+	assert.False(t, locker.isHeld("cron:locked_task"), "the lock is released after the run")
+	assert.Equal(t, runs+1, testutil.ToFloat64(metrics.CronTaskRunCount.WithLabelValues("locked task", testInstance)))
+}
+
+func testRunTaskHeldByAnotherInstance(t *testing.T) {
+	// Setup
+	locker := newFakeLocker()
+	locker.held["cron:held_task"] = true
+	runner := newTestCronRunner(locker)
+	skipped := testutil.ToFloat64(metrics.CronTaskSkippedCount.WithLabelValues("held task", testInstance))
+
+	// Execute
+	ran := false
+	runner.RunTask(func(context.Context) { ran = true }, "held task")
+
+	// Verify
+	assert.False(t, ran)
+	assert.True(t, locker.isHeld("cron:held_task"), "the lock of another instance is kept")
+	assert.Equal(t, skipped+1, testutil.ToFloat64(metrics.CronTaskSkippedCount.WithLabelValues("held task", testInstance)))
+}
+
+func testRunTaskLockError(t *testing.T) {
+	// Setup
+	locker := newFakeLocker()
+	locker.acquireErr = errors.New("redis unavailable")
+	runner := newTestCronRunner(locker)
+	lockErrors := testutil.ToFloat64(metrics.CronTaskLockErrorCount.WithLabelValues("unlocked task", testInstance))
+
+	// Execute
+	ran := false
+	runner.RunTask(func(context.Context) { ran = true }, "unlocked task")
+
+	// Verify
+	assert.False(t, ran, "the task isn't run without the lock")
+	assert.Equal(t,
+		lockErrors+1, testutil.ToFloat64(metrics.CronTaskLockErrorCount.WithLabelValues("unlocked task", testInstance)))
+}
+
+func testRunTaskLockLost(t *testing.T) {
+	// Setup
+	locker := newFakeLocker()
+	locker.lost = true
+	runner := newTestCronRunner(locker)
+
+	// Execute: the task runs until it is canceled
+	var taskErr error
+	runner.RunTask(func(ctx context.Context) {
+		select {
+		case <-ctx.Done():
+			taskErr = ctx.Err()
+		case <-time.After(time.Second):
+		}
+	}, "lost task")
+
+	// Verify
+	assert.ErrorIs(t, taskErr, context.Canceled)
+}
+
+func testStopCancelsRunningTasks(t *testing.T) {
+	// Setup
+	runner := newTestCronRunner(newFakeLocker())
+
+	started := make(chan struct{})
+	done := make(chan error)
+	go runner.RunTask(func(ctx context.Context) {
+		close(started)
+		select {
+		case <-ctx.Done():
+			done <- ctx.Err()
+		case <-time.After(time.Second):
+			done <- nil
+		}
+	}, "long task")
+	<-started
+
+	// Execute
+	runner.Stop()
+
+	// Verify
+	assert.ErrorIs(t, <-done, context.Canceled)
+}
@@ -3,10 +3,14 @@ package app_test
 import (
 	"context"
 	"errors"
+	"ms-weather-subscription/internal/config"
 	"ms-weather-subscription/internal/domain"
+	"ms-weather-subscription/pkg/cache"
+	"ms-weather-subscription/pkg/lock"
 	"ms-weather-subscription/pkg/publisher"
 	"ms-weather-subscription/testutils"
 	"testing"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 
@@ -26,17 +30,45 @@ func TestSubscriptionCron(t *testing.T) {
 		"Send daily weather forecast success with partial failure",
 		testSendDailyWeatherForecastSuccessWithPartialFailure,
 	)
-	t.Run("Send daily weather forecast no subscriptions", testSendDailyWeatherForecastNoSubs)
-	t.Run("Send daily weather forecast repo error", testSendDailyWeatherForecastRepoError)
+	t.Run("Send daily weather forecast with configured hours only", testSendDailyWeatherForecastConfiguredHours)
+	t.Run("Send daily weather forecast at local delivery hour", testSendDailyWeatherForecastAtLocalDeliveryHour)
 	t.Run("Send hourly weather forecast success", testSendHourlyWeatherForecastSuccess)
-	t.Run("Send hourly weather forecast no subscriptions", testSendHourlyWeatherForecastNoSubs)
-	t.Run("Send hourly weather forecast repo error", testSendHourlyWeatherForecastRepoError)
+	t.Run("Send hourly weather forecast in subscription units", testSendHourlyWeatherForecastInUnits)
+	t.Run("Send hourly weather forecast once per location", testSendHourlyWeatherForecastOncePerLocation)
+	t.Run("Send hourly weather forecast with air quality", testSendHourlyWeatherForecastWithAirQuality)
+	t.Run(
+		"Send hourly weather forecast without unavailable air quality",
+		testSendHourlyWeatherForecastAirQualityUnavailable,
+	)
+	t.Run("Send hourly weather forecast from fake weather provider", testSendHourlyWeatherForecastFromFakeProvider)
+	t.Run("Send hourly weather forecast within active window", testSendHourlyWeatherForecastWithinActiveWindow)
+	t.Run("Send every 3 hours weather forecast", testSendEvery3HoursWeatherForecast)
+	t.Run("Send every 6 hours weather forecast", testSendEvery6HoursWeatherForecast)
+	t.Run("Send weekly weather forecast on Monday", testSendWeeklyWeatherForecastOnMonday)
+	t.Run("Send weekly weather forecast not on Monday", testSendWeeklyWeatherForecastNotOnMonday)
+	t.Run("Run due subscriptions no subscriptions", testRunDueNoSubscriptions)
+	t.Run("Run due subscriptions repo error", testRunDueRepoError)
+	t.Run("Run due subscriptions schedules next run", testRunDueSchedulesNextRun)
+	t.Run("Run due subscriptions in batches", testRunDueInBatches)
+	t.Run("Run due subscriptions retries failed emails", testRunDueRetriesFailedEmails)
+	t.Run("Run due subscriptions skips locked subscriptions", testRunDueSkipsLockedSubscriptions)
+	t.Run("Send weather alerts once per alert", testSendWeatherAlertsOncePerAlert)
+	t.Run("Send weather alerts again after failed publish", testSendWeatherAlertsAfterFailedPublish)
+	t.Run("Send weather alert once from any provider", testSendWeatherAlertsOnceFromAnyProvider)
+	t.Run("Stop sending weather alerts under a stale lock", testSendWeatherAlertsStaleLock)
+	t.Run("Delete expired sent alerts", testDeleteExpiredSentAlerts)
 }
 
+// cronTestNow is when the emails are sent in tests: the default delivery hour of subscriptions in UTC.
+var cronTestNow = time.Date(2025, 5, 17, domain.DefaultDeliveryHour, 0, 0, 0, time.UTC)
+
 type cronTestEnv struct {
 	TestDB                       *sqlx.DB
 	WeatherForecastSenderService *service.WeatherForecastSenderService
+	Scheduler                    *service.SubscriptionScheduler
+	WeatherAlertSenderService    *service.WeatherAlertSenderService
 	MockWeatherService           *mockService.MockWeather
+	MockAlertsService            *mockService.MockAlerts
 	MockEmailPublisher           *mockPublisher.MockEmailPublisher
 	CleanupFunc                  func()
 }
@@ -47,17 +79,27 @@ func setupCronTestEnvironment(t *testing.T, ctrl *gomock.Controller) cronTestEnv
 
 	subscriptionRepo := repository.NewSubscriptionRepo(testDB)
 	mockWeatherService := mockService.NewMockWeather(ctrl)
+	mockAlertsService := mockService.NewMockAlerts(ctrl)
 	mockEmailPublisher := mockPublisher.NewMockEmailPublisher(ctrl)
 
 	s := service.NewWeatherForecastSenderService(
 		cfg.HTTP,
+		cfg.DailyForecast,
 		mockWeatherService,
+		mockEmailPublisher,
+	)
+	scheduler := service.NewSubscriptionScheduler(subscriptionRepo, s, newFakeLocker(), cfg.Scheduler).
+		WithClock(func() time.Time { return cronTestNow })
+	alertSender := service.NewWeatherAlertSenderService(
+		cfg.HTTP,
+		mockAlertsService,
 		subscriptionRepo,
+		repository.NewSentAlertRepo(testDB),
 		mockEmailPublisher,
 	)
 
 	cleanupFunc := func() {
-		_, err := testDB.Exec(`DELETE FROM subscriptions;`)
+		_, err := testDB.Exec(`DELETE FROM subscriptions; DELETE FROM locations; DELETE FROM fencing_tokens;`)
 		if err != nil {
 			t.Fatalf("cleanup failed: could not delete subscriptions data: %v", err)
 		}
@@ -66,12 +108,25 @@ func setupCronTestEnvironment(t *testing.T, ctrl *gomock.Controller) cronTestEnv
 	return cronTestEnv{
 		TestDB:                       testDB,
 		WeatherForecastSenderService: s,
+		Scheduler:                    scheduler,
+		WeatherAlertSenderService:    alertSender,
 		MockWeatherService:           mockWeatherService,
+		MockAlertsService:            mockAlertsService,
 		MockEmailPublisher:           mockEmailPublisher,
 		CleanupFunc:                  cleanupFunc,
 	}
 }
 
+// scheduleSubscriptions sets the next run of all forecast subscriptions, which are inserted without one.
+func scheduleSubscriptions(t *testing.T, db *sqlx.DB, nextRunAt time.Time) {
+	t.Helper()
+
+	_, err := db.Exec(`UPDATE subscriptions SET next_run_at = $1 WHERE frequency <> 'alerts'`, nextRunAt)
+	if err != nil {
+		t.Fatalf("could not schedule subscriptions: %v", err)
+	}
+}
+
 func testSendDailyWeatherForecastSuccess(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -86,14 +141,17 @@ func testSendDailyWeatherForecastSuccess(t *testing.T) {
         VALUES ('daily@example.com', 'Kyiv', 'daily', 'token1', true, NOW())
     `)
 	assert.NoError(t, err)
+	scheduleSubscriptions(t, testSettings.TestDB, cronTestNow)
 
 	// Mock expectations
 	testSettings.MockWeatherService.EXPECT().
-		GetDayWeather(context.Background(), "Kyiv").
+		GetDayWeather(context.Background(), domain.CityQuery("Kyiv")).
 		Return(&domain.DayWeatherResponse{
-			SevenAM: domain.WeatherResponse{Temperature: 20, Humidity: 60, Description: "Sunny"},
-			TenAM:   domain.WeatherResponse{Temperature: 22, Humidity: 55, Description: "Sunny"},
-			// ... other times
+			Hours: []domain.HourlyWeather{
+				{Time: "07:00", WeatherResponse: domain.WeatherResponse{Temperature: 20, Humidity: 60, Description: "Sunny"}},
+				{Time: "10:00", WeatherResponse: domain.WeatherResponse{Temperature: 22, Humidity: 55, Description: "Sunny"}},
+				// ... other times
+			},
 		}, nil)
 
 	testSettings.MockEmailPublisher.
@@ -102,7 +160,7 @@ func testSendDailyWeatherForecastSuccess(t *testing.T) {
 		Return(nil)
 
 	// Execute
-	err = testSettings.WeatherForecastSenderService.SendDailyWeatherForecast(context.Background())
+	err = testSettings.Scheduler.RunDue(context.Background())
 
 	// Verify
 	assert.NoError(t, err)
@@ -125,13 +183,16 @@ func testSendDailyWeatherForecastSuccessWithPartialFailure(t *testing.T) {
             ('user3@example.com', 'Kyiv', 'daily', 'token3', true, NOW())
     `)
 	assert.NoError(t, err)
+	scheduleSubscriptions(t, testSettings.TestDB, cronTestNow)
 
 	// Expect weather service once (shared city — Kyiv)
 	testSettings.MockWeatherService.EXPECT().
-		GetDayWeather(gomock.Any(), "Kyiv").
+		GetDayWeather(gomock.Any(), domain.CityQuery("Kyiv")).
 		Return(&domain.DayWeatherResponse{
-			SevenAM: domain.WeatherResponse{Temperature: 20, Humidity: 60, Description: "Sunny"},
-			TenAM:   domain.WeatherResponse{Temperature: 22, Humidity: 55, Description: "Sunny"},
+			Hours: []domain.HourlyWeather{
+				{Time: "07:00", WeatherResponse: domain.WeatherResponse{Temperature: 20, Humidity: 60, Description: "Sunny"}},
+				{Time: "10:00", WeatherResponse: domain.WeatherResponse{Temperature: 22, Humidity: 55, Description: "Sunny"}},
+			},
 		}, nil)
 
 	// Expectations: 1st and 3rd succeed, 2nd fails
@@ -147,13 +208,110 @@ func testSendDailyWeatherForecastSuccessWithPartialFailure(t *testing.T) {
 		).Times(3)
 
 	// Execute
-	err = testSettings.WeatherForecastSenderService.SendDailyWeatherForecast(context.Background())
+	err = testSettings.Scheduler.RunDue(context.Background())
 
 	// Assert: the method still completes without global failure
 	assert.NoError(t, err)
 }
 
-func testSendDailyWeatherForecastNoSubs(t *testing.T) {
+func testSendDailyWeatherForecastConfiguredHours(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Setup
+	testSettings := setupCronTestEnvironment(t, ctrl)
+	defer testSettings.CleanupFunc()
+
+	_, err := testSettings.TestDB.Exec(`
+        INSERT INTO subscriptions (email, city, frequency, token, confirmed, created_at)
+        VALUES ('daily@example.com', 'Kyiv', 'daily', 'token1', true, NOW())
+    `)
+	assert.NoError(t, err)
+	scheduleSubscriptions(t, testSettings.TestDB, cronTestNow)
+
+	// 03:00 is not among the configured hours
+	testSettings.MockWeatherService.EXPECT().
+		GetDayWeather(gomock.Any(), domain.CityQuery("Kyiv")).
+		Return(&domain.DayWeatherResponse{
+			Hours: []domain.HourlyWeather{
+				{Time: "03:00", WeatherResponse: domain.WeatherResponse{Temperature: 14, Humidity: 90, Description: "Fog"}},
+				{Time: "07:00", WeatherResponse: domain.WeatherResponse{Temperature: 20, Humidity: 60, Description: "Sunny"}},
+			},
+		}, nil)
+
+	var published domain.WeatherForecastEmailInput[*domain.DayWeatherResponse]
+	testSettings.MockEmailPublisher.EXPECT().
+		Publish(publisher.EmailDailyForecastQueue, gomock.Any()).
+		DoAndReturn(
+			func(queueName string, cmd domain.WeatherForecastEmailInput[*domain.DayWeatherResponse]) error {
+				published = cmd
+				return nil
+			},
+		)
+
+	// Execute
+	err = testSettings.Scheduler.RunDue(context.Background())
+
+	// Verify
+	assert.NoError(t, err)
+	assert.Equal(t, []domain.HourlyWeather{
+		{Time: "07:00", WeatherResponse: domain.WeatherResponse{Temperature: 20, Humidity: 60, Description: "Sunny"}},
+	}, published.Weather.Hours)
+}
+
+func testSendDailyWeatherForecastAtLocalDeliveryHour(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Setup
+	testSettings := setupCronTestEnvironment(t, ctrl)
+	defer testSettings.CleanupFunc()
+
+	// 02:00 UTC is 19:00 of the previous day in Los Angeles and 05:00 in Kyiv.
+	now := time.Date(2025, 5, 17, 2, 0, 0, 0, time.UTC)
+	testSettings.Scheduler.WithClock(func() time.Time { return now })
+
+	_, err := testSettings.TestDB.Exec(`
+        INSERT INTO subscriptions (email, city, frequency, token, confirmed, created_at, timezone, delivery_hour)
+        VALUES 
+            ('la@example.com', 'Los Angeles', 'daily', 'token1', true, NOW(), 'America/Los_Angeles', 19),
+            ('kyiv@example.com', 'Kyiv', 'daily', 'token2', true, NOW(), 'Europe/Kyiv', 5),
+            ('late@example.com', 'Kyiv', 'daily', 'token3', true, NOW(), 'Europe/Kyiv', 7),
+            ('utc@example.com', 'London', 'daily', 'token4', true, NOW(), 'UTC', 7)
+    `)
+	assert.NoError(t, err)
+	scheduleSubscriptions(t, testSettings.TestDB, now)
+
+	// Weather is fetched only for the locations of the subscriptions due now.
+	testSettings.MockWeatherService.EXPECT().
+		GetDayWeather(gomock.Any(), domain.CityQuery("Los Angeles")).
+		Return(&domain.DayWeatherResponse{}, nil)
+	testSettings.MockWeatherService.EXPECT().
+		GetDayWeather(gomock.Any(), domain.CityQuery("Kyiv")).
+		Return(&domain.DayWeatherResponse{}, nil)
+
+	dates := make(map[string]string)
+	testSettings.MockEmailPublisher.EXPECT().
+		Publish(publisher.EmailDailyForecastQueue, gomock.Any()).
+		DoAndReturn(
+			func(queueName string, cmd domain.WeatherForecastEmailInput[*domain.DayWeatherResponse]) error {
+				dates[cmd.Subscription.Email] = cmd.Date
+				return nil
+			},
+		).Times(2)
+
+	// Execute
+	err = testSettings.Scheduler.RunDue(context.Background())
+
+	// Verify
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"la@example.com":   "2025-05-16",
+		"kyiv@example.com": "2025-05-17",
+	}, dates)
+}
+
+func testRunDueNoSubscriptions(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
@@ -162,32 +320,37 @@ func testSendDailyWeatherForecastNoSubs(t *testing.T) {
 	defer testSettings.CleanupFunc()
 
 	// Execute
-	err := testSettings.WeatherForecastSenderService.SendDailyWeatherForecast(context.Background())
+	err := testSettings.Scheduler.RunDue(context.Background())
 
 	// Verify
 	assert.NoError(t, err)
 }
 
-func testSendDailyWeatherForecastRepoError(t *testing.T) {
+func testRunDueRepoError(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	// Setup mock repo that returns error
 	mockRepo := mockRepository.NewMockSubscriptionRepository(ctrl)
-	mockRepo.EXPECT().GetConfirmedByFrequency(context.Background(), "daily").Return(
+	mockRepo.EXPECT().ClaimDue(context.Background(), cronTestNow, 100, time.Minute, int64(1)).Return(
 		nil, errors.New("database error"),
 	)
 
 	cfg := testutils.SetupTestConfig(t)
-	s := service.NewWeatherForecastSenderService(
-		cfg.HTTP,
-		mockService.NewMockWeather(ctrl),
+	s := service.NewSubscriptionScheduler(
 		mockRepo,
-		mockPublisher.NewMockEmailPublisher(ctrl),
-	)
+		service.NewWeatherForecastSenderService(
+			cfg.HTTP,
+			cfg.DailyForecast,
+			mockService.NewMockWeather(ctrl),
+			mockPublisher.NewMockEmailPublisher(ctrl),
+		),
+		newFakeLocker(),
+		config.SchedulerConfig{BatchSize: 100, ClaimTTL: time.Minute},
+	).WithClock(func() time.Time { return cronTestNow })
 
 	// Execute
-	err := s.SendDailyWeatherForecast(context.Background())
+	err := s.RunDue(context.Background())
 
 	// Verify
 	assert.Error(t, err)
@@ -208,10 +371,11 @@ func testSendHourlyWeatherForecastSuccess(t *testing.T) {
         VALUES ('hourly@example.com', 'Kyiv', 'hourly', 'token2', true, NOW())
     `)
 	assert.NoError(t, err)
+	scheduleSubscriptions(t, testSettings.TestDB, cronTestNow)
 
 	// Mock expectations
 	testSettings.MockWeatherService.EXPECT().
-		GetCurrentWeather(context.Background(), "Kyiv").
+		GetCurrentWeather(context.Background(), domain.CityQuery("Kyiv")).
 		Return(&domain.WeatherResponse{
 			Temperature: 21.5,
 			Humidity:    58,
@@ -223,13 +387,13 @@ func testSendHourlyWeatherForecastSuccess(t *testing.T) {
 		Return(nil)
 
 	// Execute
-	err = testSettings.WeatherForecastSenderService.SendHourlyWeatherForecast(context.Background())
+	err = testSettings.Scheduler.RunDue(context.Background())
 
 	// Verify
 	assert.NoError(t, err)
 }
 
-func testSendHourlyWeatherForecastNoSubs(t *testing.T) {
+func testSendHourlyWeatherForecastInUnits(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
@@ -237,35 +401,903 @@ func testSendHourlyWeatherForecastNoSubs(t *testing.T) {
 	testSettings := setupCronTestEnvironment(t, ctrl)
 	defer testSettings.CleanupFunc()
 
+	_, err := testSettings.TestDB.Exec(`
+        INSERT INTO subscriptions (email, city, frequency, token, confirmed, created_at, units)
+        VALUES 
+            ('metric@example.com', 'Kyiv', 'hourly', 'token1', true, NOW(), 'metric'),
+            ('imperial@example.com', 'Kyiv', 'hourly', 'token2', true, NOW(), 'imperial')
+    `)
+	assert.NoError(t, err)
+	scheduleSubscriptions(t, testSettings.TestDB, cronTestNow)
+
+	testSettings.MockWeatherService.EXPECT().
+		GetCurrentWeather(gomock.Any(), domain.CityQuery("Kyiv")).
+		Return(&domain.WeatherResponse{Temperature: 20, WindSpeed: 16.09344, Description: "Sunny"}, nil)
+
+	temperatures := make(map[string]float32)
+	windSpeeds := make(map[string]float32)
+	testSettings.MockEmailPublisher.EXPECT().
+		Publish(publisher.EmailHourlyForecastQueue, gomock.Any()).
+		DoAndReturn(
+			func(queueName string, cmd domain.WeatherForecastEmailInput[*domain.WeatherResponse]) error {
+				temperatures[cmd.Subscription.Email] = cmd.Weather.Temperature
+				windSpeeds[cmd.Subscription.Email] = cmd.Weather.WindSpeed
+				return nil
+			},
+		).Times(2)
+
 	// Execute
-	err := testSettings.WeatherForecastSenderService.SendHourlyWeatherForecast(context.Background())
+	err = testSettings.Scheduler.RunDue(context.Background())
 
 	// Verify
 	assert.NoError(t, err)
+	assert.Equal(t, float32(20), temperatures["metric@example.com"])
+	assert.Equal(t, float32(68), temperatures["imperial@example.com"])
+	assert.Equal(t, float32(10), windSpeeds["imperial@example.com"])
 }
 
-func testSendHourlyWeatherForecastRepoError(t *testing.T) {
+func testSendHourlyWeatherForecastOncePerLocation(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	// Setup mock repo that returns error
-	mockRepo := mockRepository.NewMockSubscriptionRepository(ctrl)
-	mockRepo.EXPECT().GetConfirmedByFrequency(context.Background(), "hourly").Return(
-		nil, errors.New("database error"),
-	)
+	// Setup
+	testSettings := setupCronTestEnvironment(t, ctrl)
+	defer testSettings.CleanupFunc()
+
+	// Subscriptions created before cities were resolved are grouped by normalized city.
+	_, err := testSettings.TestDB.Exec(`
+        INSERT INTO locations (id, name, country, latitude, longitude, timezone)
+        VALUES ('kyiv,ukraine@50,31', 'Kyiv', 'Ukraine', 50.45466, 30.5238, 'Europe/Kyiv');
+
+        INSERT INTO subscriptions (email, city, frequency, token, confirmed, created_at, location_id)
+        VALUES 
+            ('user1@example.com', 'Kyiv', 'hourly', 'token1', true, NOW(), 'kyiv,ukraine@50,31'),
+            ('user2@example.com', 'Kyiv', 'hourly', 'token2', true, NOW(), 'kyiv,ukraine@50,31'),
+            ('user3@example.com', 'Lviv', 'hourly', 'token3', true, NOW(), NULL),
+            ('user4@example.com', ' lviv', 'hourly', 'token4', true, NOW(), NULL)
+    `)
+	assert.NoError(t, err)
+	scheduleSubscriptions(t, testSettings.TestDB, cronTestNow)
+
+	// Resolved locations are looked up by their coordinates, the weather history is kept under the location.
+	kyiv := domain.CoordinatesQuery(domain.Coordinates{Latitude: 50.45466, Longitude: 30.5238})
+	kyiv.City = "Kyiv"
+	kyiv.LocationID = "kyiv,ukraine@50,31"
+	testSettings.MockWeatherService.EXPECT().
+		GetCurrentWeather(gomock.Any(), kyiv).
+		Return(&domain.WeatherResponse{Temperature: 20, Description: "Sunny"}, nil).
+		Times(1)
+	testSettings.MockWeatherService.EXPECT().
+		GetCurrentWeather(gomock.Any(), gomock.Any()).
+		Return(&domain.WeatherResponse{Temperature: 18, Description: "Cloudy"}, nil).
+		Times(1)
+
+	testSettings.MockEmailPublisher.EXPECT().
+		Publish(publisher.EmailHourlyForecastQueue, gomock.Any()).
+		Return(nil).
+		Times(4)
+
+	// Execute
+	err = testSettings.Scheduler.RunDue(context.Background())
+
+	// Verify
+	assert.NoError(t, err)
+}
+
+func testSendHourlyWeatherForecastWithAirQuality(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Setup
+	testSettings := setupCronTestEnvironment(t, ctrl)
+	defer testSettings.CleanupFunc()
+
+	_, err := testSettings.TestDB.Exec(`
+        INSERT INTO subscriptions (email, city, frequency, token, confirmed, created_at, air_quality)
+        VALUES 
+            ('aqi@example.com', 'Kyiv', 'hourly', 'token1', true, NOW(), true),
+            ('plain@example.com', 'Kyiv', 'hourly', 'token2', true, NOW(), false)
+    `)
+	assert.NoError(t, err)
+	scheduleSubscriptions(t, testSettings.TestDB, cronTestNow)
+
+	airQuality := &domain.AirQualityResponse{AQI: 57, PM25: 12.3, PM10: 20, O3: 68.7, NO2: 13.5}
+	testSettings.MockWeatherService.EXPECT().
+		GetCurrentWeather(gomock.Any(), domain.CityQuery("Kyiv")).
+		Return(&domain.WeatherResponse{Temperature: 20, Description: "Sunny"}, nil)
+	testSettings.MockWeatherService.EXPECT().
+		GetAirQuality(gomock.Any(), domain.CityQuery("Kyiv")).
+		Return(airQuality, nil).
+		Times(1)
+
+	sent := make(map[string]*domain.AirQualityResponse)
+	testSettings.MockEmailPublisher.EXPECT().
+		Publish(publisher.EmailHourlyForecastQueue, gomock.Any()).
+		DoAndReturn(
+			func(queueName string, cmd domain.WeatherForecastEmailInput[*domain.WeatherResponse]) error {
+				sent[cmd.Subscription.Email] = cmd.AirQuality
+				return nil
+			},
+		).Times(2)
+
+	// Execute
+	err = testSettings.Scheduler.RunDue(context.Background())
+
+	// Verify
+	assert.NoError(t, err)
+	assert.Equal(t, airQuality, sent["aqi@example.com"])
+	assert.Contains(t, sent, "plain@example.com")
+	assert.Nil(t, sent["plain@example.com"], "air quality is sent only to subscriptions which opted into it")
+}
+
+func testSendHourlyWeatherForecastAirQualityUnavailable(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Setup
+	testSettings := setupCronTestEnvironment(t, ctrl)
+	defer testSettings.CleanupFunc()
+
+	_, err := testSettings.TestDB.Exec(`
+        INSERT INTO subscriptions (email, city, frequency, token, confirmed, created_at, air_quality)
+        VALUES ('aqi@example.com', 'Kyiv', 'hourly', 'token1', true, NOW(), true)
+    `)
+	assert.NoError(t, err)
+	scheduleSubscriptions(t, testSettings.TestDB, cronTestNow)
+
+	testSettings.MockWeatherService.EXPECT().
+		GetCurrentWeather(gomock.Any(), domain.CityQuery("Kyiv")).
+		Return(&domain.WeatherResponse{Temperature: 20, Description: "Sunny"}, nil)
+	testSettings.MockWeatherService.EXPECT().
+		GetAirQuality(gomock.Any(), domain.CityQuery("Kyiv")).
+		Return(nil, errors.New("no air quality provider available"))
+
+	testSettings.MockEmailPublisher.EXPECT().
+		Publish(publisher.EmailHourlyForecastQueue, gomock.Any()).
+		DoAndReturn(
+			func(queueName string, cmd domain.WeatherForecastEmailInput[*domain.WeatherResponse]) error {
+				assert.Nil(t, cmd.AirQuality)
+				return nil
+			},
+		)
+
+	// Execute
+	err = testSettings.Scheduler.RunDue(context.Background())
+
+	// Verify
+	assert.NoError(t, err)
+}
+
+func testSendHourlyWeatherForecastFromFakeProvider(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Setup
+	testSettings := setupCronTestEnvironment(t, ctrl)
+	defer testSettings.CleanupFunc()
 
 	cfg := testutils.SetupTestConfig(t)
-	s := service.NewWeatherForecastSenderService(
-		cfg.HTTP,
-		mockService.NewMockWeather(ctrl),
-		mockRepo,
-		mockPublisher.NewMockEmailPublisher(ctrl),
+	locationService := service.NewLocationService(
+		repository.NewLocationRepo(testSettings.TestDB), testutils.SetupTestLocationSearcher(t), cache.NewMemoryCache(100),
 	)
+	weatherService := service.NewWeatherService(testutils.SetupTestWeatherClient(t), locationService)
+	s := service.NewSubscriptionScheduler(
+		repository.NewSubscriptionRepo(testSettings.TestDB),
+		service.NewWeatherForecastSenderService(cfg.HTTP, cfg.DailyForecast, weatherService, testSettings.MockEmailPublisher),
+		newFakeLocker(),
+		cfg.Scheduler,
+	).WithClock(func() time.Time { return cronTestNow })
+
+	// The fake provider is down for Kharkiv, so only the Kyiv forecast is sent.
+	_, err := testSettings.TestDB.Exec(`
+        INSERT INTO subscriptions (email, city, frequency, token, confirmed, created_at, air_quality)
+        VALUES 
+            ('kyiv@example.com', 'Kyiv', 'hourly', 'token1', true, NOW(), true),
+            ('kharkiv@example.com', 'Kharkiv', 'hourly', 'token2', true, NOW(), false)
+    `)
+	assert.NoError(t, err)
+	scheduleSubscriptions(t, testSettings.TestDB, cronTestNow)
+
+	var sent []domain.WeatherForecastEmailInput[*domain.WeatherResponse]
+	testSettings.MockEmailPublisher.EXPECT().
+		Publish(publisher.EmailHourlyForecastQueue, gomock.Any()).
+		DoAndReturn(
+			func(queueName string, cmd domain.WeatherForecastEmailInput[*domain.WeatherResponse]) error {
+				sent = append(sent, cmd)
+				return nil
+			},
+		).Times(1)
 
 	// Execute
-	err := s.SendHourlyWeatherForecast(context.Background())
+	err = s.RunDue(context.Background())
 
 	// Verify
-	assert.Error(t, err)
-	assert.Contains(t, err.Error(), "database error")
+	assert.NoError(t, err)
+	if assert.Len(t, sent, 1) {
+		assert.Equal(t, "kyiv@example.com", sent[0].Subscription.Email)
+		assert.Equal(t, float32(21.5), sent[0].Weather.Temperature)
+		assert.Equal(t, 42, sent[0].AirQuality.AQI)
+	}
+}
+
+func testSendHourlyWeatherForecastWithinActiveWindow(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Setup
+	testSettings := setupCronTestEnvironment(t, ctrl)
+	defer testSettings.CleanupFunc()
+
+	// cronTestNow is Saturday 07:00 UTC, 10:00 in Kyiv.
+	_, err := testSettings.TestDB.Exec(`
+        INSERT INTO subscriptions (
+            email, city, frequency, token, confirmed, created_at, timezone, active_from, active_to, weekdays_only
+        )
+        VALUES 
+            ('day@example.com', 'Kyiv', 'hourly', 'token1', true, NOW(), 'Europe/Kyiv', 7, 22, false),
+            ('night@example.com', 'Lviv', 'hourly', 'token2', true, NOW(), 'Europe/Kyiv', 22, 6, false),
+            ('weekdays@example.com', 'Odesa', 'hourly', 'token3', true, NOW(), 'Europe/Kyiv', 0, 23, true),
+            ('overnight@example.com', 'London', 'hourly', 'token4', true, NOW(), 'UTC', 20, 7, false)
+    `)
+	assert.NoError(t, err)
+	scheduleSubscriptions(t, testSettings.TestDB, cronTestNow)
+
+	// Weather isn't fetched for the locations without active subscriptions.
+	testSettings.MockWeatherService.EXPECT().
+		GetCurrentWeather(gomock.Any(), domain.CityQuery("Kyiv")).
+		Return(&domain.WeatherResponse{Temperature: 20}, nil)
+	testSettings.MockWeatherService.EXPECT().
+		GetCurrentWeather(gomock.Any(), domain.CityQuery("London")).
+		Return(&domain.WeatherResponse{Temperature: 12}, nil)
+
+	var emails []string
+	testSettings.MockEmailPublisher.EXPECT().
+		Publish(publisher.EmailHourlyForecastQueue, gomock.Any()).
+		DoAndReturn(
+			func(queueName string, cmd domain.WeatherForecastEmailInput[*domain.WeatherResponse]) error {
+				emails = append(emails, cmd.Subscription.Email)
+				return nil
+			},
+		).Times(2)
+
+	// Execute
+	err = testSettings.Scheduler.RunDue(context.Background())
+
+	// Verify
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"day@example.com", "overnight@example.com"}, emails)
+}
+
+func testSendEvery3HoursWeatherForecast(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Setup
+	testSettings := setupCronTestEnvironment(t, ctrl)
+	defer testSettings.CleanupFunc()
+
+	// 09:00 UTC is 10:00 in London, every 3 hours emails are due, every 6 hours ones aren't.
+	now := time.Date(2025, 5, 17, 9, 0, 0, 0, time.UTC)
+	testSettings.Scheduler.WithClock(func() time.Time { return now })
+
+	_, err := testSettings.TestDB.Exec(`
+        INSERT INTO subscriptions (email, city, frequency, token, confirmed, created_at)
+        VALUES 
+            ('every3h@example.com', 'London', 'every_3h', 'token1', true, NOW()),
+            ('every6h@example.com', 'London', 'every_6h', 'token2', true, NOW())
+    `)
+	assert.NoError(t, err)
+	scheduleSubscriptions(t, testSettings.TestDB, now)
+
+	testSettings.MockWeatherService.EXPECT().
+		GetCurrentWeather(gomock.Any(), domain.CityQuery("London")).
+		Return(&domain.WeatherResponse{Temperature: 14}, nil)
+	hour := func(time string, temperature float32) domain.HourlyWeather {
+		return domain.HourlyWeather{Time: time, WeatherResponse: domain.WeatherResponse{Temperature: temperature}}
+	}
+	testSettings.MockWeatherService.EXPECT().
+		GetDayWeather(gomock.Any(), domain.CityQuery("London")).
+		Return(&domain.DayWeatherResponse{
+			Hours: []domain.HourlyWeather{
+				hour("09:00", 16), hour("10:00", 18), hour("11:00", 19),
+				hour("12:00", 20), hour("13:00", 21), hour("14:00", 21),
+			},
+			Timezone: "Europe/London",
+		}, nil)
+
+	testSettings.MockEmailPublisher.EXPECT().
+		Publish(publisher.EmailEvery3HoursForecastQueue, gomock.Any()).
+		DoAndReturn(
+			func(queueName string, cmd domain.WeatherForecastEmailInput[*domain.Every3HoursWeatherResponse]) error {
+				assert.Equal(t, "every3h@example.com", cmd.Subscription.Email)
+				assert.Equal(t, float32(14), cmd.Weather.Current.Temperature)
+				assert.Equal(t, []domain.HourlyWeather{hour("11:00", 19), hour("12:00", 20), hour("13:00", 21)},
+					cmd.Weather.Hours)
+				return nil
+			},
+		)
+
+	// Execute
+	err = testSettings.Scheduler.RunDue(context.Background())
+
+	// Verify
+	assert.NoError(t, err)
+}
+
+func testSendEvery6HoursWeatherForecast(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Setup
+	testSettings := setupCronTestEnvironment(t, ctrl)
+	defer testSettings.CleanupFunc()
+
+	// 06:00 UTC is 07:00 in London.
+	now := time.Date(2025, 5, 17, 6, 0, 0, 0, time.UTC)
+	testSettings.Scheduler.WithClock(func() time.Time { return now })
+
+	_, err := testSettings.TestDB.Exec(`
+        INSERT INTO subscriptions (email, city, frequency, token, confirmed, created_at)
+        VALUES ('every6h@example.com', 'London', 'every_6h', 'token1', true, NOW())
+    `)
+	assert.NoError(t, err)
+	scheduleSubscriptions(t, testSettings.TestDB, now)
+
+	testSettings.MockWeatherService.EXPECT().
+		GetCurrentWeather(gomock.Any(), domain.CityQuery("London")).
+		Return(&domain.WeatherResponse{Temperature: 11}, nil)
+	hour := func(time string, temperature float32) domain.HourlyWeather {
+		return domain.HourlyWeather{Time: time, WeatherResponse: domain.WeatherResponse{Temperature: temperature}}
+	}
+	upcoming := []domain.HourlyWeather{
+		hour("08:00", 12), hour("09:00", 13), hour("10:00", 15),
+		hour("11:00", 17), hour("12:00", 18), hour("13:00", 19),
+	}
+	testSettings.MockWeatherService.EXPECT().
+		GetDayWeather(gomock.Any(), domain.CityQuery("London")).
+		Return(&domain.DayWeatherResponse{
+			Hours:    append([]domain.HourlyWeather{hour("07:00", 11)}, append(upcoming, hour("14:00", 20))...),
+			Timezone: "Europe/London",
+		}, nil)
+
+	testSettings.MockEmailPublisher.EXPECT().
+		Publish(publisher.EmailEvery6HoursForecastQueue, gomock.Any()).
+		DoAndReturn(
+			func(queueName string, cmd domain.WeatherForecastEmailInput[*domain.Every6HoursWeatherResponse]) error {
+				assert.Equal(t, "every6h@example.com", cmd.Subscription.Email)
+				assert.Equal(t, float32(11), cmd.Weather.Current.Temperature)
+				assert.Equal(t, upcoming, cmd.Weather.Hours)
+				return nil
+			},
+		)
+
+	// Execute
+	err = testSettings.Scheduler.RunDue(context.Background())
+
+	// Verify
+	assert.NoError(t, err)
+}
+
+func testSendWeeklyWeatherForecastOnMonday(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Setup
+	testSettings := setupCronTestEnvironment(t, ctrl)
+	defer testSettings.CleanupFunc()
+
+	monday := time.Date(2025, 5, 19, domain.DefaultDeliveryHour, 0, 0, 0, time.UTC)
+	testSettings.Scheduler.WithClock(func() time.Time { return monday })
+
+	// It is 10:00 in Kyiv, past the delivery hour of the Kyiv subscription.
+	_, err := testSettings.TestDB.Exec(`
+        INSERT INTO subscriptions (email, city, frequency, token, confirmed, created_at, units, timezone)
+        VALUES 
+            ('weekly@example.com', 'London', 'weekly', 'token1', true, NOW(), 'imperial', 'UTC'),
+            ('kyiv@example.com', 'Kyiv', 'weekly', 'token2', true, NOW(), 'metric', 'Europe/Kyiv'),
+            ('daily@example.com', 'London', 'daily', 'token3', true, NOW(), 'metric', 'Europe/Kyiv')
+    `)
+	assert.NoError(t, err)
+	scheduleSubscriptions(t, testSettings.TestDB, monday)
+
+	testSettings.MockWeatherService.EXPECT().
+		GetForecast(gomock.Any(), domain.CityQuery("London"), domain.WeeklyForecastDays).
+		Return(&domain.ForecastResponse{Days: []domain.DayForecast{
+			{Date: "2025-05-19", MinTemperature: 10, MaxTemperature: 20, Description: "Sunny"},
+			{Date: "2025-05-20", MinTemperature: 12, MaxTemperature: 18, Description: "Light rain"},
+		}}, nil)
+
+	testSettings.MockEmailPublisher.EXPECT().
+		Publish(publisher.EmailWeeklyForecastQueue, gomock.Any()).
+		DoAndReturn(
+			func(queueName string, cmd domain.WeatherForecastEmailInput[*domain.ForecastResponse]) error {
+				assert.Equal(t, "weekly@example.com", cmd.Subscription.Email)
+				assert.Equal(t, "2025-05-19", cmd.Date)
+				assert.Len(t, cmd.Weather.Days, 2)
+				assert.Equal(t, float32(50), cmd.Weather.Days[0].MinTemperature, "converted to °F")
+				assert.Equal(t, float32(68), cmd.Weather.Days[0].MaxTemperature, "converted to °F")
+				assert.Nil(t, cmd.AirQuality)
+				return nil
+			},
+		)
+
+	// Execute
+	err = testSettings.Scheduler.RunDue(context.Background())
+
+	// Verify
+	assert.NoError(t, err)
+}
+
+func testSendWeeklyWeatherForecastNotOnMonday(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Setup
+	testSettings := setupCronTestEnvironment(t, ctrl)
+	defer testSettings.CleanupFunc()
+
+	// cronTestNow is Saturday, at the delivery hour of the subscription.
+	_, err := testSettings.TestDB.Exec(`
+        INSERT INTO subscriptions (email, city, frequency, token, confirmed, created_at, timezone)
+        VALUES ('weekly@example.com', 'London', 'weekly', 'token1', true, NOW(), 'UTC')
+    `)
+	assert.NoError(t, err)
+	scheduleSubscriptions(t, testSettings.TestDB, cronTestNow)
+
+	testSettings.MockWeatherService.EXPECT().GetForecast(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+	testSettings.MockEmailPublisher.EXPECT().Publish(gomock.Any(), gomock.Any()).Times(0)
+
+	// Execute
+	err = testSettings.Scheduler.RunDue(context.Background())
+
+	// Verify
+	assert.NoError(t, err)
+}
+
+func testRunDueSchedulesNextRun(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Setup
+	testSettings := setupCronTestEnvironment(t, ctrl)
+	defer testSettings.CleanupFunc()
+
+	_, err := testSettings.TestDB.Exec(`
+        INSERT INTO subscriptions (email, city, frequency, token, confirmed, created_at, timezone, next_run_at)
+        VALUES 
+            ('hourly@example.com', 'Kyiv', 'hourly', 'token1', true, NOW(), 'UTC', $1),
+            ('daily@example.com', 'Kyiv', 'daily', 'token2', true, NOW(), 'UTC', $1),
+            ('weekly@example.com', 'Kyiv', 'weekly', 'token3', true, NOW(), 'UTC', $1),
+            ('later@example.com', 'Kyiv', 'hourly', 'token4', true, NOW(), 'UTC', $2),
+            ('unscheduled@example.com', 'Kyiv', 'hourly', 'token5', true, NOW(), 'UTC', NULL),
+            ('unconfirmed@example.com', 'Kyiv', 'hourly', 'token6', false, NOW(), 'UTC', $1)
+    `, cronTestNow, cronTestNow.Add(time.Hour))
+	assert.NoError(t, err)
+
+	// The weekly subscription is claimed on Saturday, but it isn't emailed.
+	testSettings.MockWeatherService.EXPECT().
+		GetCurrentWeather(gomock.Any(), domain.CityQuery("Kyiv")).
+		Return(&domain.WeatherResponse{Temperature: 20}, nil)
+	testSettings.MockWeatherService.EXPECT().
+		GetDayWeather(gomock.Any(), domain.CityQuery("Kyiv")).
+		Return(&domain.DayWeatherResponse{}, nil)
+
+	var emails []string
+	testSettings.MockEmailPublisher.EXPECT().
+		Publish(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(queueName string, cmd any) error {
+			switch cmd := cmd.(type) {
+			case domain.WeatherForecastEmailInput[*domain.WeatherResponse]:
+				emails = append(emails, cmd.Subscription.Email)
+			case domain.WeatherForecastEmailInput[*domain.DayWeatherResponse]:
+				emails = append(emails, cmd.Subscription.Email)
+			}
+			return nil
+		}).
+		Times(2)
+
+	// Execute
+	err = testSettings.Scheduler.RunDue(context.Background())
+
+	// Verify
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"hourly@example.com", "daily@example.com"}, emails)
+
+	var nextRuns []struct {
+		Email     string     `db:"email"`
+		NextRunAt *time.Time `db:"next_run_at"`
+	}
+	err = testSettings.TestDB.Select(&nextRuns, `SELECT email, next_run_at FROM subscriptions`)
+	assert.NoError(t, err)
+
+	expected := map[string]*time.Time{
+		"hourly@example.com":      ptr(cronTestNow.Add(time.Hour)),
+		"daily@example.com":       ptr(cronTestNow.AddDate(0, 0, 1)),
+		"weekly@example.com":      ptr(time.Date(2025, 5, 19, domain.DefaultDeliveryHour, 0, 0, 0, time.UTC)),
+		"later@example.com":       ptr(cronTestNow.Add(time.Hour)),
+		"unscheduled@example.com": nil,
+		"unconfirmed@example.com": ptr(cronTestNow),
+	}
+	for _, nextRun := range nextRuns {
+		if expected[nextRun.Email] == nil {
+			assert.Nil(t, nextRun.NextRunAt, nextRun.Email)
+			continue
+		}
+		if assert.NotNil(t, nextRun.NextRunAt, nextRun.Email) {
+			assert.True(t, expected[nextRun.Email].Equal(*nextRun.NextRunAt), "%s: %s", nextRun.Email, nextRun.NextRunAt)
+		}
+	}
+}
+
+func testRunDueInBatches(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Setup
+	testSettings := setupCronTestEnvironment(t, ctrl)
+	defer testSettings.CleanupFunc()
+
+	s := service.NewSubscriptionScheduler(
+		repository.NewSubscriptionRepo(testSettings.TestDB),
+		testSettings.WeatherForecastSenderService,
+		newFakeLocker(),
+		config.SchedulerConfig{BatchSize: 2, ClaimTTL: time.Minute},
+	).WithClock(func() time.Time { return cronTestNow })
+
+	_, err := testSettings.TestDB.Exec(`
+        INSERT INTO subscriptions (email, city, frequency, token, confirmed, created_at)
+        VALUES 
+            ('user1@example.com', 'Kyiv', 'hourly', 'token1', true, NOW()),
+            ('user2@example.com', 'Kyiv', 'hourly', 'token2', true, NOW()),
+            ('user3@example.com', 'Kyiv', 'hourly', 'token3', true, NOW()),
+            ('user4@example.com', 'Kyiv', 'hourly', 'token4', true, NOW()),
+            ('user5@example.com', 'Kyiv', 'hourly', 'token5', true, NOW())
+    `)
+	assert.NoError(t, err)
+	scheduleSubscriptions(t, testSettings.TestDB, cronTestNow)
+
+	// Weather is fetched once per batch.
+	testSettings.MockWeatherService.EXPECT().
+		GetCurrentWeather(gomock.Any(), domain.CityQuery("Kyiv")).
+		Return(&domain.WeatherResponse{Temperature: 20}, nil).
+		Times(3)
+	testSettings.MockEmailPublisher.EXPECT().
+		Publish(publisher.EmailHourlyForecastQueue, gomock.Any()).
+		Return(nil).
+		Times(5)
+
+	// Execute
+	err = s.RunDue(context.Background())
+
+	// Verify
+	assert.NoError(t, err)
+}
+
+func testRunDueRetriesFailedEmails(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Setup
+	testSettings := setupCronTestEnvironment(t, ctrl)
+	defer testSettings.CleanupFunc()
+
+	_, err := testSettings.TestDB.Exec(`
+        INSERT INTO subscriptions (email, city, frequency, token, confirmed, created_at)
+        VALUES 
+            ('sent@example.com', 'Kyiv', 'hourly', 'token1', true, NOW()),
+            ('failed@example.com', 'Lviv', 'hourly', 'token2', true, NOW())
+    `)
+	assert.NoError(t, err)
+	scheduleSubscriptions(t, testSettings.TestDB, cronTestNow)
+
+	testSettings.MockWeatherService.EXPECT().
+		GetCurrentWeather(gomock.Any(), domain.CityQuery("Kyiv")).
+		Return(&domain.WeatherResponse{Temperature: 20}, nil)
+	gomock.InOrder(
+		testSettings.MockWeatherService.EXPECT().
+			GetCurrentWeather(gomock.Any(), domain.CityQuery("Lviv")).
+			Return(nil, errors.New("all weather providers failed")),
+		testSettings.MockWeatherService.EXPECT().
+			GetCurrentWeather(gomock.Any(), domain.CityQuery("Lviv")).
+			Return(&domain.WeatherResponse{Temperature: 18}, nil),
+	)
+
+	var emails []string
+	testSettings.MockEmailPublisher.EXPECT().
+		Publish(publisher.EmailHourlyForecastQueue, gomock.Any()).
+		DoAndReturn(
+			func(queueName string, cmd domain.WeatherForecastEmailInput[*domain.WeatherResponse]) error {
+				emails = append(emails, cmd.Subscription.Email)
+				return nil
+			},
+		).
+		Times(2)
+
+	// Execute: the failed email is claimed again once its claim expires
+	err = testSettings.Scheduler.RunDue(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"sent@example.com"}, emails)
+
+	err = testSettings.Scheduler.RunDue(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"sent@example.com"}, emails, "the claimed subscription isn't sent before the claim expires")
+
+	cfg := testutils.SetupTestConfig(t)
+	testSettings.Scheduler.WithClock(func() time.Time { return cronTestNow.Add(cfg.Scheduler.ClaimTTL) })
+	err = testSettings.Scheduler.RunDue(context.Background())
+
+	// Verify
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"sent@example.com", "failed@example.com"}, emails)
+}
+
+func testRunDueSkipsLockedSubscriptions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Setup
+	testSettings := setupCronTestEnvironment(t, ctrl)
+	defer testSettings.CleanupFunc()
+
+	_, err := testSettings.TestDB.Exec(`
+        INSERT INTO subscriptions (email, city, frequency, token, confirmed, created_at)
+        VALUES 
+            ('free@example.com', 'Kyiv', 'hourly', 'token1', true, NOW()),
+            ('locked@example.com', 'Lviv', 'hourly', 'token2', true, NOW())
+    `)
+	assert.NoError(t, err)
+	scheduleSubscriptions(t, testSettings.TestDB, cronTestNow)
+
+	// Another instance is sending the locked subscription.
+	tx, err := testSettings.TestDB.Beginx()
+	assert.NoError(t, err)
+	defer func() { _ = tx.Rollback() }()
+	_, err = tx.Exec(`SELECT id FROM subscriptions WHERE email = 'locked@example.com' FOR UPDATE`)
+	assert.NoError(t, err)
+
+	testSettings.MockWeatherService.EXPECT().
+		GetCurrentWeather(gomock.Any(), domain.CityQuery("Kyiv")).
+		Return(&domain.WeatherResponse{Temperature: 20}, nil)
+	testSettings.MockEmailPublisher.EXPECT().
+		Publish(publisher.EmailHourlyForecastQueue, gomock.Any()).
+		DoAndReturn(
+			func(queueName string, cmd domain.WeatherForecastEmailInput[*domain.WeatherResponse]) error {
+				assert.Equal(t, "free@example.com", cmd.Subscription.Email)
+				return nil
+			},
+		)
+
+	// Execute
+	err = testSettings.Scheduler.RunDue(context.Background())
+
+	// Verify
+	assert.NoError(t, err)
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+var stormWarning = domain.WeatherAlert{
+	ID:       "weatherapi:2f1b7c9e04d3a8b6",
+	Event:    "Storm Warning",
+	Headline: "Storm warning issued for Kyiv",
+	Severity: "Severe",
+}
+
+// underAlertsLock returns a context carrying the fencing token of a run of the alerts task.
+func underAlertsLock(token int64) context.Context {
+	return lock.WithToken(context.Background(), lock.Token{Key: "cron:alerts_weather_email_sending", Value: token})
+}
+
+func testSendWeatherAlertsOncePerAlert(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Setup
+	testSettings := setupCronTestEnvironment(t, ctrl)
+	defer testSettings.CleanupFunc()
+
+	_, err := testSettings.TestDB.Exec(`
+        INSERT INTO subscriptions (email, city, frequency, token, confirmed, created_at)
+        VALUES
+            ('alerts@example.com', 'Kyiv', 'alerts', 'token1', true, NOW()),
+            ('daily@example.com', 'Kyiv', 'daily', 'token2', true, NOW())
+    `)
+	assert.NoError(t, err)
+
+	activeAlert := stormWarning
+	activeAlert.Expires = time.Now().Add(time.Hour)
+	expiredAlert := domain.WeatherAlert{ID: "weatherapi:0c5a61e2b7d49f38", Expires: time.Now().Add(-time.Hour)}
+
+	testSettings.MockAlertsService.EXPECT().
+		GetAlerts(gomock.Any(), domain.CityQuery("Kyiv")).
+		Return([]domain.WeatherAlert{activeAlert, expiredAlert}, nil).
+		Times(2)
+
+	var sent []domain.WeatherAlertEmailInput
+	testSettings.MockEmailPublisher.EXPECT().
+		Publish(publisher.EmailWeatherAlertQueue, gomock.Any()).
+		DoAndReturn(func(queueName string, cmd domain.WeatherAlertEmailInput) error {
+			sent = append(sent, cmd)
+			return nil
+		}).
+		Times(1)
+
+	// Execute: the second poll finds the same alert
+	err = testSettings.WeatherAlertSenderService.SendWeatherAlerts(underAlertsLock(1))
+	assert.NoError(t, err)
+	err = testSettings.WeatherAlertSenderService.SendWeatherAlerts(underAlertsLock(2))
+	assert.NoError(t, err)
+
+	// Verify
+	if assert.Len(t, sent, 1) {
+		assert.Equal(t, "alerts@example.com", sent[0].Subscription.Email)
+		assert.Equal(t, stormWarning.ID, sent[0].Alert.ID)
+	}
+}
+
+func testSendWeatherAlertsAfterFailedPublish(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Setup
+	testSettings := setupCronTestEnvironment(t, ctrl)
+	defer testSettings.CleanupFunc()
+
+	_, err := testSettings.TestDB.Exec(`
+        INSERT INTO subscriptions (email, city, frequency, token, confirmed, created_at)
+        VALUES ('alerts@example.com', 'Kyiv', 'alerts', 'token1', true, NOW())
+    `)
+	assert.NoError(t, err)
+
+	testSettings.MockAlertsService.EXPECT().
+		GetAlerts(gomock.Any(), domain.CityQuery("Kyiv")).
+		Return([]domain.WeatherAlert{stormWarning}, nil).
+		Times(2)
+
+	gomock.InOrder(
+		testSettings.MockEmailPublisher.EXPECT().
+			Publish(publisher.EmailWeatherAlertQueue, gomock.Any()).
+			Return(errors.New("broker unavailable")),
+		testSettings.MockEmailPublisher.EXPECT().
+			Publish(publisher.EmailWeatherAlertQueue, gomock.Any()).
+			Return(nil),
+	)
+
+	// Execute
+	err = testSettings.WeatherAlertSenderService.SendWeatherAlerts(underAlertsLock(1))
+	assert.NoError(t, err)
+	err = testSettings.WeatherAlertSenderService.SendWeatherAlerts(underAlertsLock(2))
+
+	// Verify
+	assert.NoError(t, err)
+}
+
+func testSendWeatherAlertsOnceFromAnyProvider(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Setup
+	testSettings := setupCronTestEnvironment(t, ctrl)
+	defer testSettings.CleanupFunc()
+
+	_, err := testSettings.TestDB.Exec(`
+        INSERT INTO subscriptions (email, city, frequency, token, confirmed, created_at)
+        VALUES ('alerts@example.com', 'Kyiv', 'alerts', 'token1', true, NOW())
+    `)
+	assert.NoError(t, err)
+
+	// The second poll falls back to VisualCrossing, which returns the same warning under its own id.
+	fromWeatherAPI := stormWarning
+	fromWeatherAPI.Effective = time.Now().Truncate(time.Hour)
+	fromVisualCrossing := fromWeatherAPI
+	fromVisualCrossing.ID = "visualcrossing:ua-storm-1"
+	fromVisualCrossing.Event = "storm warning"
+
+	gomock.InOrder(
+		testSettings.MockAlertsService.EXPECT().
+			GetAlerts(gomock.Any(), domain.CityQuery("Kyiv")).
+			Return([]domain.WeatherAlert{fromWeatherAPI}, nil),
+		testSettings.MockAlertsService.EXPECT().
+			GetAlerts(gomock.Any(), domain.CityQuery("Kyiv")).
+			Return([]domain.WeatherAlert{fromVisualCrossing}, nil),
+	)
+	testSettings.MockEmailPublisher.EXPECT().
+		Publish(publisher.EmailWeatherAlertQueue, gomock.Any()).
+		Return(nil).
+		Times(1)
+
+	// Execute
+	err = testSettings.WeatherAlertSenderService.SendWeatherAlerts(underAlertsLock(1))
+	assert.NoError(t, err)
+	err = testSettings.WeatherAlertSenderService.SendWeatherAlerts(underAlertsLock(2))
+
+	// Verify
+	assert.NoError(t, err)
+}
+
+func testSendWeatherAlertsStaleLock(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Setup
+	testSettings := setupCronTestEnvironment(t, ctrl)
+	defer testSettings.CleanupFunc()
+
+	_, err := testSettings.TestDB.Exec(`
+        INSERT INTO subscriptions (email, city, frequency, token, confirmed, created_at)
+        VALUES ('alerts@example.com', 'Kyiv', 'alerts', 'token1', true, NOW())
+    `)
+	assert.NoError(t, err)
+
+	floodWarning := domain.WeatherAlert{ID: "weatherapi:7d3e9a0c51b28f46", Event: "Flood Warning"}
+	gomock.InOrder(
+		testSettings.MockAlertsService.EXPECT().
+			GetAlerts(gomock.Any(), domain.CityQuery("Kyiv")).
+			Return([]domain.WeatherAlert{stormWarning}, nil),
+		testSettings.MockAlertsService.EXPECT().
+			GetAlerts(gomock.Any(), domain.CityQuery("Kyiv")).
+			Return([]domain.WeatherAlert{floodWarning}, nil),
+	)
+	testSettings.MockEmailPublisher.EXPECT().
+		Publish(publisher.EmailWeatherAlertQueue, gomock.Any()).
+		Return(nil).
+		Times(1)
+
+	// Execute: the run whose lock expired polls after the run which took the lock over
+	err = testSettings.WeatherAlertSenderService.SendWeatherAlerts(underAlertsLock(2))
+	assert.NoError(t, err)
+	err = testSettings.WeatherAlertSenderService.SendWeatherAlerts(underAlertsLock(1))
+
+	// Verify
+	assert.ErrorIs(t, err, lock.ErrStaleToken)
+}
+
+func testDeleteExpiredSentAlerts(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Setup
+	testSettings := setupCronTestEnvironment(t, ctrl)
+	defer testSettings.CleanupFunc()
+
+	_, err := testSettings.TestDB.Exec(`
+        INSERT INTO subscriptions (email, city, frequency, token, confirmed, created_at)
+        VALUES ('alerts@example.com', 'Kyiv', 'alerts', 'token1', true, NOW())
+    `)
+	assert.NoError(t, err)
+
+	activeAlert := stormWarning
+	activeAlert.Expires = time.Now().Add(time.Hour).Truncate(time.Second)
+	testSettings.MockAlertsService.EXPECT().
+		GetAlerts(gomock.Any(), domain.CityQuery("Kyiv")).
+		Return([]domain.WeatherAlert{activeAlert}, nil)
+	testSettings.MockEmailPublisher.EXPECT().
+		Publish(publisher.EmailWeatherAlertQueue, gomock.Any()).
+		Return(nil)
+
+	err = testSettings.WeatherAlertSenderService.SendWeatherAlerts(underAlertsLock(1))
+	assert.NoError(t, err)
+	_, err = testSettings.TestDB.Exec(`
+        INSERT INTO sent_alerts (subscription_id, alert_id, expires_at)
+        SELECT id, 'weatherapi:0c5a61e2b7d49f38', NOW() - INTERVAL '1 hour' FROM subscriptions
+    `)
+	assert.NoError(t, err)
+
+	// Execute
+	err = testSettings.WeatherAlertSenderService.DeleteExpiredSentAlerts(context.Background())
+
+	// Verify: the alert still in effect is remembered until it expires
+	assert.NoError(t, err)
+	var remaining []struct {
+		AlertID   string    `db:"alert_id"`
+		ExpiresAt time.Time `db:"expires_at"`
+	}
+	err = testSettings.TestDB.Select(&remaining, "SELECT alert_id, expires_at FROM sent_alerts")
+	assert.NoError(t, err)
+	if assert.Len(t, remaining, 1) {
+		assert.Equal(t, activeAlert.DedupKey(), remaining[0].AlertID)
+		assert.True(t, activeAlert.Expires.Equal(remaining[0].ExpiresAt))
+	}
 }
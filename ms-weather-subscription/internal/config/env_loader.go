@@ -39,8 +39,6 @@ func (e *GodotenvLoader) GetRequiredEnvVars(environment string) map[string]strin
 			allVars,
 			"LOGG_ENV",
 			"HTTP_HOST",
-			"WEATHER_API_KEY",
-			"VISUAL_CROSSING_API_KEY",
 			"RABBITMQ_URL",
 		)
 	}
@@ -57,6 +55,16 @@ func (e *GodotenvLoader) GetRequiredEnvVars(environment string) map[string]strin
 	return result
 }
 
+// GetOptionalEnvVars returns the variables which may be unset, e.g. API keys
+// of weather providers which aren't enabled. Unset variables are empty.
+func (e *GodotenvLoader) GetOptionalEnvVars() map[string]string {
+	result := make(map[string]string)
+	for _, key := range []string{"WEATHER_API_KEY", "VISUAL_CROSSING_API_KEY"} {
+		result[key] = os.Getenv(key)
+	}
+	return result
+}
+
 func GetEnvironmentOrDefault(defaultEnvironment string) string {
 	environment := os.Getenv("ENV")
 	if environment == "" {
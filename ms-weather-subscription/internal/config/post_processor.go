@@ -4,6 +4,9 @@ import (
 	commonCfg "common/config"
 
 	"fmt"
+	"os"
+	"path/filepath"
+	"time"
 )
 
 type DefaultConfigPostProcessor struct{}
@@ -29,7 +32,89 @@ func (p *DefaultConfigPostProcessor) processDatabaseConfig(cfg *Config) {
 	cfg.DB.MigrationsPath = migrationsPath
 }
 
-func (p *DefaultConfigPostProcessor) ProcessConfig(cfg *Config) {
+func (p *DefaultConfigPostProcessor) processWeatherProvidersConfig(cfg *Config) error {
+	for i, providerCfg := range cfg.WeatherProviders {
+		// The fake provider serves made-up weather, it is meant for local development and tests only.
+		if providerCfg.Enabled && providerCfg.Name == FakeWeatherProviderName && cfg.Environment == ProdEnvironment {
+			return fmt.Errorf("weather provider %q can't be used in %s", FakeWeatherProviderName, cfg.Environment)
+		}
+		if providerCfg.Fixture != "" && !filepath.IsAbs(providerCfg.Fixture) {
+			cfg.WeatherProviders[i].Fixture = commonCfg.GetOriginalPath(providerCfg.Fixture)
+		}
+	}
+	return nil
+}
+
+func (p *DefaultConfigPostProcessor) processWeatherRequestsConfig(cfg *Config) error {
+	switch cfg.WeatherRequests.Mode {
+	case WeatherRequestModeSequential, WeatherRequestModeHedged:
+		return nil
+	default:
+		return fmt.Errorf("unknown weather request mode %q", cfg.WeatherRequests.Mode)
+	}
+}
+
+// processDailyForecastConfig checks the forecast hours, which must be formatted as "15:04"
+// the same way providers return the day forecast hour by hour.
+func (p *DefaultConfigPostProcessor) processDailyForecastConfig(cfg *Config) error {
+	for _, hour := range cfg.DailyForecast.Hours {
+		parsed, err := time.Parse("15:04", hour)
+		if err != nil || parsed.Minute() != 0 || parsed.Format("15:04") != hour {
+			return fmt.Errorf("invalid daily forecast hour %q, expected HH:00", hour)
+		}
+	}
+	return nil
+}
+
+func (p *DefaultConfigPostProcessor) processWeatherHistoryConfig(cfg *Config) error {
+	if cfg.WeatherHistory.Retention <= 0 {
+		return fmt.Errorf("invalid weather history retention %s, expected a positive duration",
+			cfg.WeatherHistory.Retention)
+	}
+	return nil
+}
+
+func (p *DefaultConfigPostProcessor) processSchedulerConfig(cfg *Config) error {
+	if cfg.Scheduler.BatchSize <= 0 {
+		return fmt.Errorf("invalid scheduler batch size %d, expected a positive number", cfg.Scheduler.BatchSize)
+	}
+	if cfg.Scheduler.ClaimTTL <= 0 {
+		return fmt.Errorf("invalid scheduler claim ttl %s, expected a positive duration", cfg.Scheduler.ClaimTTL)
+	}
+	return nil
+}
+
+func (p *DefaultConfigPostProcessor) processCronConfig(cfg *Config) error {
+	if cfg.Cron.LockTTL <= 0 {
+		return fmt.Errorf("invalid cron lock ttl %s, expected a positive duration", cfg.Cron.LockTTL)
+	}
+	if cfg.Cron.InstanceID != "" {
+		return nil
+	}
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	cfg.Cron.InstanceID = fmt.Sprintf("%s-%d", hostname, os.Getpid())
+	return nil
+}
+
+// ProcessConfig sets the derived values and returns an error if any value is invalid.
+func (p *DefaultConfigPostProcessor) ProcessConfig(cfg *Config) error {
 	p.processHTTPConfig(cfg)
 	p.processDatabaseConfig(cfg)
+
+	for _, process := range []func(cfg *Config) error{
+		p.processWeatherProvidersConfig,
+		p.processWeatherRequestsConfig,
+		p.processDailyForecastConfig,
+		p.processWeatherHistoryConfig,
+		p.processSchedulerConfig,
+		p.processCronConfig,
+	} {
+		if err := process(cfg); err != nil {
+			return err
+		}
+	}
+	return nil
 }
@@ -0,0 +1,110 @@
+package config_test
+
+import (
+	"ms-weather-subscription/internal/config"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultConfigPostProcessor(t *testing.T) {
+	t.Run("Valid config", testPostProcessorValidConfig)
+	t.Run("Invalid config", testPostProcessorInvalidConfig)
+	t.Run("Config files are valid", testConfigFilesAreValid)
+}
+
+func validConfig() *config.Config {
+	return &config.Config{
+		Environment:      config.DevEnvironment,
+		WeatherProviders: []config.WeatherProviderConfig{{Name: config.FakeWeatherProviderName, Enabled: true}},
+		WeatherRequests:  config.WeatherRequestsConfig{Mode: config.WeatherRequestModeHedged},
+		DailyForecast:    config.DailyForecastConfig{Hours: []string{"07:00", "22:00"}},
+		WeatherHistory:   config.WeatherHistoryConfig{Retention: 30 * 24 * time.Hour},
+		Scheduler:        config.SchedulerConfig{BatchSize: 100, ClaimTTL: 10 * time.Minute},
+		Cron:             config.CronConfig{LockTTL: time.Minute},
+	}
+}
+
+func testPostProcessorValidConfig(t *testing.T) {
+	// Setup
+	cfg := validConfig()
+
+	// Execute
+	err := (&config.DefaultConfigPostProcessor{}).ProcessConfig(cfg)
+
+	// Verify
+	assert.NoError(t, err)
+	assert.NotEmpty(t, cfg.Cron.InstanceID)
+}
+
+func testPostProcessorInvalidConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(cfg *config.Config)
+		message string
+	}{
+		{
+			name:    "fake provider in production",
+			modify:  func(cfg *config.Config) { cfg.Environment = config.ProdEnvironment },
+			message: `weather provider "fake" can't be used in prod`,
+		},
+		{
+			name:    "unknown request mode",
+			modify:  func(cfg *config.Config) { cfg.WeatherRequests.Mode = "racing" },
+			message: `unknown weather request mode "racing"`,
+		},
+		{
+			name:    "daily forecast hour with minutes",
+			modify:  func(cfg *config.Config) { cfg.DailyForecast.Hours = []string{"07:00", "07:30"} },
+			message: `invalid daily forecast hour "07:30"`,
+		},
+		{
+			name:    "daily forecast hour without leading zero",
+			modify:  func(cfg *config.Config) { cfg.DailyForecast.Hours = []string{"7:00"} },
+			message: `invalid daily forecast hour "7:00"`,
+		},
+		{
+			name:    "no weather history retention",
+			modify:  func(cfg *config.Config) { cfg.WeatherHistory.Retention = 0 },
+			message: "invalid weather history retention",
+		},
+		{
+			name:    "no scheduler batch size",
+			modify:  func(cfg *config.Config) { cfg.Scheduler.BatchSize = 0 },
+			message: "invalid scheduler batch size",
+		},
+		{
+			name:    "negative scheduler claim ttl",
+			modify:  func(cfg *config.Config) { cfg.Scheduler.ClaimTTL = -time.Minute },
+			message: "invalid scheduler claim ttl",
+		},
+		{
+			name:    "no cron lock ttl",
+			modify:  func(cfg *config.Config) { cfg.Cron.LockTTL = 0 },
+			message: "invalid cron lock ttl",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			cfg := validConfig()
+			tt.modify(cfg)
+
+			// Execute
+			err := (&config.DefaultConfigPostProcessor{}).ProcessConfig(cfg)
+
+			// Verify
+			assert.ErrorContains(t, err, tt.message)
+		})
+	}
+}
+
+func testConfigFilesAreValid(t *testing.T) {
+	// Execute
+	_, err := config.Init(config.ConfigsDir, config.TestEnvironment)
+
+	// Verify
+	assert.NoError(t, err)
+}
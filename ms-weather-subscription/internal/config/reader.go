@@ -1,19 +1,27 @@
 package config
 
 import (
+	"errors"
+
 	"github.com/spf13/viper"
 )
 
 const (
 	defaultHTTPPort       = "8080"
 	defaultMigrationsPath = "file://migrations"
+	defaultHedgeDelay     = "500ms"
 )
 
+var defaultDailyForecastHours = []string{"07:00", "10:00", "13:00", "16:00", "19:00", "22:00"}
+
 type ViperConfigReader struct{}
 
 func (r *ViperConfigReader) SetDefaults() {
 	viper.SetDefault("http_server.port", defaultHTTPPort)
 	viper.SetDefault("db.migrationsPath", defaultMigrationsPath)
+	viper.SetDefault("weather_requests.mode", WeatherRequestModeSequential)
+	viper.SetDefault("weather_requests.hedge_delay", defaultHedgeDelay)
+	viper.SetDefault("daily_forecast.hours", defaultDailyForecastHours)
 }
 
 func (r *ViperConfigReader) ReadConfigFile(configDirPath, configName string) error {
@@ -23,6 +31,21 @@ func (r *ViperConfigReader) ReadConfigFile(configDirPath, configName string) err
 	return viper.ReadInConfig()
 }
 
+// MergeConfigFile overrides values read so far with the ones of the config file, if it exists.
+// Lists are replaced as a whole.
+func (r *ViperConfigReader) MergeConfigFile(configDirPath, configName string) error {
+	viper.SetConfigName(configName)
+	viper.SetConfigType("yaml")
+	viper.AddConfigPath(configDirPath)
+
+	err := viper.MergeInConfig()
+	var notFoundErr viper.ConfigFileNotFoundError
+	if errors.As(err, &notFoundErr) {
+		return nil
+	}
+	return err
+}
+
 func (r *ViperConfigReader) Unmarshal(cfg interface{}) error {
 	return viper.Unmarshal(cfg)
 }
@@ -10,16 +10,18 @@ import (
 type ConfigReader interface {
 	SetDefaults()
 	ReadConfigFile(configDirPath, configName string) error
+	MergeConfigFile(configDirPath, configName string) error
 	Unmarshal(cfg interface{}) error
 }
 
 type EnvLoader interface {
 	LoadEnvFile(filePath string) error
 	GetRequiredEnvVars(environment string) map[string]string
+	GetOptionalEnvVars() map[string]string
 }
 
 type ConfigPostProcessor interface {
-	ProcessConfig(cfg *Config)
+	ProcessConfig(cfg *Config) error
 }
 
 type ConfigService struct {
@@ -54,6 +56,11 @@ func (s *ConfigService) LoadConfig(configDir, environment string) (*Config, erro
 		return nil, fmt.Errorf("failed to read config file: %w", err)
 	}
 
+	// Environment config file, e.g. dev.yaml, overrides main.yaml
+	if err := s.reader.MergeConfigFile(configDirPath, environment); err != nil {
+		return nil, fmt.Errorf("failed to read %s config file: %w", environment, err)
+	}
+
 	// Create and unmarshal config
 	cfg := &Config{Environment: environment}
 	if err := s.reader.Unmarshal(cfg); err != nil {
@@ -65,8 +72,10 @@ func (s *ConfigService) LoadConfig(configDir, environment string) (*Config, erro
 		return nil, fmt.Errorf("failed to set environment variables: %w", err)
 	}
 
-	// Post-process derived values
-	s.postProcessor.ProcessConfig(cfg)
+	// Post-process derived values and validate the config
+	if err := s.postProcessor.ProcessConfig(cfg); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
 
 	return cfg, nil
 }
@@ -88,6 +97,7 @@ func (s *ConfigService) loadEnvironmentFile(environment string) error {
 
 func (s *ConfigService) setEnvironmentVariables(cfg *Config, environment string) error {
 	envVars := s.envLoader.GetRequiredEnvVars(environment)
+	optionalEnvVars := s.envLoader.GetOptionalEnvVars()
 
 	// Map environment variables to config fields
 	cfg.DB.Host = envVars["DB_HOST"]
@@ -108,10 +118,12 @@ func (s *ConfigService) setEnvironmentVariables(cfg *Config, environment string)
 	if environment != TestEnvironment {
 		cfg.Logger.LoggerEnv = envVars["LOGG_ENV"]
 		cfg.HTTP.Host = envVars["HTTP_HOST"]
-		cfg.ThirdParty.WeatherAPIKey = envVars["WEATHER_API_KEY"]
-		cfg.ThirdParty.VisualCrossingAPIKey = envVars["VISUAL_CROSSING_API_KEY"]
 		cfg.RabbitMQ.URL = envVars["RABBITMQ_URL"]
 	}
 
+	// API keys are needed only by the providers which are enabled
+	cfg.ThirdParty.WeatherAPIKey = optionalEnvVars["WEATHER_API_KEY"]
+	cfg.ThirdParty.VisualCrossingAPIKey = optionalEnvVars["VISUAL_CROSSING_API_KEY"]
+
 	return nil
 }
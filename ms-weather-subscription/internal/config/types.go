@@ -15,8 +15,16 @@ type Config struct {
 	Logger      LoggerConfig   `mapstructure:"logger"`
 	DB          DatabaseConfig `mapstructure:"db"`
 	Redis       RedisConfig
+	MemoryCache MemoryCacheConfig `mapstructure:"memory_cache"`
 	RabbitMQ    RabbitMQConfig
 	ThirdParty  ThirdPartyConfig
+
+	WeatherProviders []WeatherProviderConfig `mapstructure:"weather_providers"`
+	WeatherRequests  WeatherRequestsConfig   `mapstructure:"weather_requests"`
+	DailyForecast    DailyForecastConfig     `mapstructure:"daily_forecast"`
+	WeatherHistory   WeatherHistoryConfig    `mapstructure:"weather_history"`
+	Scheduler        SchedulerConfig         `mapstructure:"scheduler"`
+	Cron             CronConfig              `mapstructure:"cron"`
 }
 
 type HTTPConfig struct {
@@ -36,6 +44,82 @@ type ThirdPartyConfig struct {
 	VisualCrossingAPIKey string
 }
 
+// FakeWeatherProviderName is the name of the weather provider serving made-up weather,
+// which can't be enabled in production.
+const FakeWeatherProviderName = "fake"
+
+// WeatherProviderConfig describes a single provider of the weather client chain.
+// Providers are queried in the order they are listed in the config file.
+type WeatherProviderConfig struct {
+	Name              string        `mapstructure:"name"`
+	Enabled           bool          `mapstructure:"enabled"`
+	Timeout           time.Duration `mapstructure:"timeout"`
+	BaseURL           string        `mapstructure:"base_url"`
+	GeocodingBaseURL  string        `mapstructure:"geocoding_base_url"`
+	AirQualityBaseURL string        `mapstructure:"air_quality_base_url"`
+	// Fixture is the file the fake provider serves the weather from, relative to the project root.
+	Fixture string `mapstructure:"fixture"`
+
+	CircuitBreaker CircuitBreakerConfig `mapstructure:"circuit_breaker"`
+	Quota          QuotaConfig          `mapstructure:"quota"`
+}
+
+// CircuitBreakerConfig configures when a weather provider is skipped by the chain.
+// Zero FailureThreshold disables the circuit breaker.
+type CircuitBreakerConfig struct {
+	FailureThreshold int           `mapstructure:"failure_threshold"`
+	CoolDown         time.Duration `mapstructure:"cool_down"`
+}
+
+// QuotaConfig limits calls to a weather provider: at most DailyLimit calls per UTC day
+// and at most RateLimit calls per RatePeriod. Zero limit disables it.
+type QuotaConfig struct {
+	DailyLimit int           `mapstructure:"daily_limit"`
+	RateLimit  int           `mapstructure:"rate_limit"`
+	RatePeriod time.Duration `mapstructure:"rate_period"`
+}
+
+const (
+	WeatherRequestModeSequential = "sequential"
+	WeatherRequestModeHedged     = "hedged"
+)
+
+// WeatherRequestsConfig configures how current weather requests are spread over the providers.
+// In hedged mode the next provider is queried in parallel if the previous one
+// hasn't answered within HedgeDelay. Only the weather requested via the API is hedged.
+type WeatherRequestsConfig struct {
+	Mode       string        `mapstructure:"mode"`
+	HedgeDelay time.Duration `mapstructure:"hedge_delay"`
+}
+
+// DailyForecastConfig configures the daily forecast email.
+// Hours are the times of the day ("15:04", city's local time) included in the forecast.
+type DailyForecastConfig struct {
+	Hours []string `mapstructure:"hours"`
+}
+
+// WeatherHistoryConfig configures the stored weather history.
+// Weather snapshots are deleted once they are older than Retention.
+type WeatherHistoryConfig struct {
+	Retention time.Duration `mapstructure:"retention"`
+}
+
+// SchedulerConfig configures the scheduler sending the forecast emails of due subscriptions.
+// Due subscriptions are claimed BatchSize at a time for ClaimTTL: the emails which failed
+// to be sent are retried once the claim expires.
+type SchedulerConfig struct {
+	BatchSize int           `mapstructure:"batch_size"`
+	ClaimTTL  time.Duration `mapstructure:"claim_ttl"`
+}
+
+// CronConfig configures the cron tasks, which run on one instance at a time.
+// A task lock expires LockTTL after its holder stops extending it, e.g. crashes.
+// InstanceID identifies the instance in logs and metrics, the host name and the process ID by default.
+type CronConfig struct {
+	LockTTL    time.Duration `mapstructure:"lock_ttl"`
+	InstanceID string        `mapstructure:"instance_id"`
+}
+
 type LoggerConfig struct {
 	LoggerEnv string
 	FilePath  string `mapstructure:"file_path"`
@@ -58,6 +142,13 @@ type RedisConfig struct {
 	Password string
 }
 
+// MemoryCacheConfig configures the in-process cache in front of Redis.
+// Zero MaxEntries disables it. Values read from Redis are kept in memory for PromotedTTL.
+type MemoryCacheConfig struct {
+	MaxEntries  int           `mapstructure:"max_entries"`
+	PromotedTTL time.Duration `mapstructure:"promoted_ttl"`
+}
+
 type RabbitMQConfig struct {
 	URL string
 }
@@ -0,0 +1,60 @@
+package domain
+
+import "math"
+
+// AirQualityResponse holds the US EPA air quality index (0-500) and pollutant
+// concentrations in μg/m³: fine and coarse particulate matter, ozone and nitrogen dioxide.
+type AirQualityResponse struct {
+	AQI  int     `json:"aqi"`
+	PM25 float32 `json:"pm2_5"`
+	PM10 float32 `json:"pm10"`
+	O3   float32 `json:"o3"`
+	NO2  float32 `json:"no2"`
+}
+
+// aqiBreakpoint maps the concentration range [low, high] to the index range [indexLow, indexHigh].
+type aqiBreakpoint struct {
+	low, high           float64
+	indexLow, indexHigh int
+}
+
+// US EPA breakpoints of PM2.5 (μg/m³, truncated to 0.1) and PM10 (μg/m³, truncated to 1).
+var (
+	pm25AQIBreakpoints = []aqiBreakpoint{
+		{0, 9, 0, 50},
+		{9.1, 35.4, 51, 100},
+		{35.5, 55.4, 101, 150},
+		{55.5, 125.4, 151, 200},
+		{125.5, 225.4, 201, 300},
+		{225.5, 325.4, 301, 500},
+	}
+	pm10AQIBreakpoints = []aqiBreakpoint{
+		{0, 54, 0, 50},
+		{55, 154, 51, 100},
+		{155, 254, 101, 150},
+		{255, 354, 151, 200},
+		{355, 424, 201, 300},
+		{425, 604, 301, 500},
+	}
+)
+
+const maxAQI = 500
+
+// USAQIFromParticulates returns the US EPA air quality index of the particulate matter concentrations,
+// for providers which return concentrations only. It is the highest of the PM2.5 and PM10 indices.
+func USAQIFromParticulates(pm25, pm10 float32) int {
+	pm25Index := aqiIndex(math.Floor(float64(pm25)*10)/10, pm25AQIBreakpoints)
+	pm10Index := aqiIndex(math.Floor(float64(pm10)), pm10AQIBreakpoints)
+	return max(pm25Index, pm10Index)
+}
+
+func aqiIndex(concentration float64, breakpoints []aqiBreakpoint) int {
+	for _, bp := range breakpoints {
+		if concentration <= bp.high {
+			concentration = max(concentration, bp.low)
+			index := float64(bp.indexHigh-bp.indexLow)/(bp.high-bp.low)*(concentration-bp.low) + float64(bp.indexLow)
+			return int(math.Round(index))
+		}
+	}
+	return maxAQI
+}
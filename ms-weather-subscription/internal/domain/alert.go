@@ -0,0 +1,59 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+)
+
+// SentAlertRetention is how long an alert without an expiry time is remembered as sent to a subscription.
+// Such an alert is sent again if it is still reported after that.
+const SentAlertRetention = 30 * 24 * time.Hour
+
+// WeatherAlert is a severe weather warning issued for a location, e.g. a storm or a heat wave.
+type WeatherAlert struct {
+	// ID is the provider-qualified id of the alert. Subscribers are emailed once per DedupKey.
+	ID          string    `json:"id"`
+	Event       string    `json:"event"`
+	Headline    string    `json:"headline"`
+	Severity    string    `json:"severity"`
+	Description string    `json:"description"`
+	Instruction string    `json:"instruction"`
+	Effective   time.Time `json:"effective,omitzero"`
+	Expires     time.Time `json:"expires,omitzero"`
+}
+
+// DedupKey identifies the alert whichever provider returned it, so the same warning isn't emailed
+// again once the alerts are polled from a fallback provider: it is derived from the event and the time
+// the alert takes effect at. Alerts without the effective time are identified by their provider id.
+// Providers naming the same event differently still give it different keys.
+func (a *WeatherAlert) DedupKey() string {
+	if a.Effective.IsZero() {
+		return a.ID
+	}
+	event := strings.ToLower(strings.TrimSpace(a.Event))
+	key := sha256.Sum256([]byte(event + "|" + a.Effective.UTC().Format(time.RFC3339)))
+	return "alert:" + hex.EncodeToString(key[:8])
+}
+
+// IsExpired reports whether the alert is no longer in effect at the given time.
+// Alerts without an expiry time never expire.
+func (a *WeatherAlert) IsExpired(now time.Time) bool {
+	return !a.Expires.IsZero() && !a.Expires.After(now)
+}
+
+// SentUntil returns how long the alert sent at sentAt is remembered as sent:
+// until it expires, or for SentAlertRetention if it has no expiry time.
+func (a *WeatherAlert) SentUntil(sentAt time.Time) time.Time {
+	if a.Expires.IsZero() {
+		return sentAt.Add(SentAlertRetention)
+	}
+	return a.Expires
+}
+
+type WeatherAlertEmailInput struct {
+	Subscription    Subscription `json:"subscription"`
+	Alert           WeatherAlert `json:"alert"`
+	UnsubscribeLink string       `json:"unsubscribe_link"`
+}
@@ -0,0 +1,74 @@
+package domain_test
+
+import (
+	"ms-weather-subscription/internal/domain"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWeatherAlertDedupKey(t *testing.T) {
+	effective := time.Date(2025, 5, 17, 6, 0, 0, 0, time.UTC)
+	weatherAPI := domain.WeatherAlert{ID: "weatherapi:2f1b7c9e04d3a8b6", Event: "Storm Warning", Effective: effective}
+
+	tests := []struct {
+		name  string
+		alert domain.WeatherAlert
+		same  bool
+	}{
+		{
+			name: "same warning from another provider",
+			alert: domain.WeatherAlert{
+				ID: "visualcrossing:ua-1", Event: " storm warning", Effective: effective.In(time.FixedZone("EEST", 3*3600)),
+			},
+			same: true,
+		},
+		{
+			name: "same event taking effect later",
+			alert: domain.WeatherAlert{
+				ID: "weatherapi:0c5a61e2b7d49f38", Event: "Storm Warning", Effective: effective.Add(time.Hour),
+			},
+			same: false,
+		},
+		{
+			name:  "other event",
+			alert: domain.WeatherAlert{ID: "weatherapi:9d04e7a1c2b35f68", Event: "Heat Warning", Effective: effective},
+			same:  false,
+		},
+		{
+			name:  "without effective time",
+			alert: domain.WeatherAlert{ID: "visualcrossing:ua-1", Event: "Storm Warning"},
+			same:  false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.same, weatherAPI.DedupKey() == tt.alert.DedupKey())
+		})
+	}
+
+	withoutEffective := domain.WeatherAlert{ID: "visualcrossing:ua-1", Event: "Storm Warning"}
+	assert.Equal(t, "visualcrossing:ua-1", withoutEffective.DedupKey())
+}
+
+func TestWeatherAlertSentUntil(t *testing.T) {
+	sentAt := time.Date(2025, 5, 17, 6, 0, 0, 0, time.UTC)
+	expires := sentAt.Add(12 * time.Hour)
+
+	tests := []struct {
+		name  string
+		alert domain.WeatherAlert
+		want  time.Time
+	}{
+		{name: "until it expires", alert: domain.WeatherAlert{Expires: expires}, want: expires},
+		{name: "without expiry time", alert: domain.WeatherAlert{}, want: sentAt.Add(domain.SentAlertRetention)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.alert.SentUntil(sentAt))
+		})
+	}
+}
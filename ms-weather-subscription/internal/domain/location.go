@@ -0,0 +1,58 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Location is a city resolved by a provider's search API. Different spellings
+// of the same city ("Kyiv", "kyiv ", "Kiev", "Київ") resolve to one location.
+type Location struct {
+	// ID identifies the location whichever provider resolved it, see NewLocation.
+	ID        string  `json:"id" db:"id"`
+	Name      string  `json:"name" db:"name"`
+	Country   string  `json:"country" db:"country"`
+	Latitude  float64 `json:"latitude" db:"latitude"`
+	Longitude float64 `json:"longitude" db:"longitude"`
+	Timezone  string  `json:"timezone" db:"timezone"`
+}
+
+// NewLocation returns the location of a city found by a provider. Its ID is derived from the city,
+// not from the provider's own id, so a fallback provider resolves it to the same location,
+// e.g. "kyiv,ukraine@50,31". The coordinates are rounded to whole degrees: they only tell apart
+// the cities of the same name in a country, which the providers place a few kilometers apart at most.
+func NewLocation(name, country string, latitude, longitude float64, timezone string) Location {
+	return Location{
+		ID: fmt.Sprintf("%s,%s@%d,%d",
+			NormalizeLocationQuery(name), NormalizeLocationQuery(country),
+			int(math.Round(latitude)), int(math.Round(longitude)),
+		),
+		Name:      name,
+		Country:   country,
+		Latitude:  latitude,
+		Longitude: longitude,
+		Timezone:  timezone,
+	}
+}
+
+// coordinatesLocationPrefix is the ID prefix of locations created from coordinates.
+const coordinatesLocationPrefix = "coordinates:"
+
+// NewCoordinatesLocation returns the location of the grid cell the coordinates are on, in the timezone.
+func NewCoordinatesLocation(coordinates Coordinates, timezone string) Location {
+	onGrid := coordinates.OnGrid()
+	return Location{
+		ID:        coordinatesLocationPrefix + coordinates.GridKey(),
+		Name:      coordinates.GridKey(),
+		Latitude:  onGrid.Latitude,
+		Longitude: onGrid.Longitude,
+		Timezone:  timezone,
+	}
+}
+
+// NormalizeLocationQuery turns user input into the alias a resolved location is stored under:
+// lower-cased, with surrounding spaces trimmed and inner spaces collapsed.
+func NormalizeLocationQuery(query string) string {
+	return strings.Join(strings.Fields(strings.ToLower(query)), " ")
+}
@@ -0,0 +1,33 @@
+package domain_test
+
+import (
+	"ms-weather-subscription/internal/domain"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewLocation(t *testing.T) {
+	t.Run("Same city from different providers", testNewLocationSameCity)
+	t.Run("Cities of the same name", testNewLocationSameName)
+}
+
+func testNewLocationSameCity(t *testing.T) {
+	// Execute: WeatherAPI and Open-Meteo place Kyiv a couple of kilometers apart
+	weatherAPI := domain.NewLocation("Kyiv", "Ukraine", 50.43, 30.52, "Europe/Kiev")
+	openMeteo := domain.NewLocation("Kyiv ", "ukraine", 50.45466, 30.5238, "Europe/Kyiv")
+
+	// Verify
+	assert.Equal(t, "kyiv,ukraine@50,31", weatherAPI.ID)
+	assert.Equal(t, weatherAPI.ID, openMeteo.ID)
+}
+
+func testNewLocationSameName(t *testing.T) {
+	// Execute
+	illinois := domain.NewLocation("Springfield", "United States of America", 39.8, -89.64, "America/Chicago")
+	missouri := domain.NewLocation("Springfield", "United States of America", 37.22, -93.3, "America/Chicago")
+
+	// Verify
+	assert.NotEqual(t, illinois.ID, missouri.ID)
+	assert.Equal(t, "springfield,united states of america@40,-90", illinois.ID)
+}
@@ -0,0 +1,62 @@
+package domain
+
+import (
+	"slices"
+	"time"
+)
+
+// ScheduledFrequencies are sent by the subscription scheduler at the next run of each subscription.
+// Alerts subscriptions are emailed as soon as an alert is issued instead.
+var ScheduledFrequencies = []string{
+	HourlyWeatherEmailFrequency,
+	Every3HoursWeatherEmailFrequency,
+	Every6HoursWeatherEmailFrequency,
+	DailyWeatherEmailFrequency,
+	WeeklyWeatherEmailFrequency,
+}
+
+// maxRunInterval is the longest time between two runs of a scheduled subscription: a week,
+// plus a day for the daylight saving time changes and the weekdays only subscriptions.
+const maxRunInterval = 8 * 24 * time.Hour
+
+func IsScheduledFrequency(frequency string) bool {
+	return slices.Contains(ScheduledFrequencies, frequency)
+}
+
+// IsDue reports whether the email of the subscription is due at the hour of t.
+// Every 3 and 6 hours emails are due at the UTC hours divisible by 3 and 6.
+func (s *Subscription) IsDue(t time.Time) bool {
+	switch s.Frequency {
+	case HourlyWeatherEmailFrequency:
+		return s.IsActive(t)
+	case Every3HoursWeatherEmailFrequency:
+		return t.UTC().Hour()%3 == 0 && s.IsActive(t)
+	case Every6HoursWeatherEmailFrequency:
+		return t.UTC().Hour()%6 == 0 && s.IsActive(t)
+	case DailyWeatherEmailFrequency:
+		return s.IsDeliveryHour(t)
+	case WeeklyWeatherEmailFrequency:
+		return s.IsWeeklyDeliveryTime(t)
+	default:
+		return false
+	}
+}
+
+// NextRun returns the first top of the hour after t the email of the subscription is due at.
+// ok is false if the subscription isn't scheduled, e.g. for alerts subscriptions,
+// or its email is never due, e.g. every 3 hours within a window between the hours it is sent at.
+func (s *Subscription) NextRun(t time.Time) (next time.Time, ok bool) {
+	if !IsScheduledFrequency(s.Frequency) {
+		return time.Time{}, false
+	}
+
+	// Emails are sent at the top of the UTC hours.
+	next = t.UTC().Truncate(time.Hour)
+	for next.Sub(t) <= maxRunInterval {
+		next = next.Add(time.Hour)
+		if s.IsDue(next) {
+			return next, true
+		}
+	}
+	return time.Time{}, false
+}
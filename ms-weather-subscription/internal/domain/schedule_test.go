@@ -0,0 +1,187 @@
+package domain_test
+
+import (
+	"ms-weather-subscription/internal/domain"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubscriptionIsDue(t *testing.T) {
+	newYork := kyivSubscription(domain.WeeklyWeatherEmailFrequency)
+	newYork.Timezone = "America/New_York"
+	newYork.DeliveryHour = 22
+
+	// 2025-05-19 is a Monday.
+	tests := []struct {
+		name         string
+		subscription domain.Subscription
+		at           time.Time
+		want         bool
+	}{
+		{
+			name:         "hourly within window",
+			subscription: withActiveWindow(kyivSubscription(domain.HourlyWeatherEmailFrequency), 9, 17, false),
+			at:           utc(5, 14, 10),
+			want:         true,
+		},
+		{
+			name:         "hourly outside window",
+			subscription: withActiveWindow(kyivSubscription(domain.HourlyWeatherEmailFrequency), 9, 17, false),
+			at:           utc(5, 14, 16),
+			want:         false,
+		},
+		{
+			name:         "every 3 hours at UTC hour divisible by 3",
+			subscription: kyivSubscription(domain.Every3HoursWeatherEmailFrequency),
+			at:           utc(5, 14, 9),
+			want:         true,
+		},
+		{
+			name:         "every 3 hours between runs",
+			subscription: kyivSubscription(domain.Every3HoursWeatherEmailFrequency),
+			at:           utc(5, 14, 10),
+			want:         false,
+		},
+		{
+			name:         "every 3 hours outside window",
+			subscription: withActiveWindow(kyivSubscription(domain.Every3HoursWeatherEmailFrequency), 9, 17, false),
+			at:           utc(5, 14, 15),
+			want:         false,
+		},
+		{
+			name:         "every 6 hours at UTC hour divisible by 6",
+			subscription: kyivSubscription(domain.Every6HoursWeatherEmailFrequency),
+			at:           utc(5, 14, 6),
+			want:         true,
+		},
+		{
+			name:         "every 6 hours between runs",
+			subscription: kyivSubscription(domain.Every6HoursWeatherEmailFrequency),
+			at:           utc(5, 14, 9),
+			want:         false,
+		},
+		{
+			name:         "daily at delivery hour in summer",
+			subscription: kyivSubscription(domain.DailyWeatherEmailFrequency),
+			at:           utc(5, 14, 4),
+			want:         true,
+		},
+		{
+			name:         "daily at summer delivery hour in winter",
+			subscription: kyivSubscription(domain.DailyWeatherEmailFrequency),
+			at:           utc(1, 15, 4),
+			want:         false,
+		},
+		{
+			name:         "daily at delivery hour in winter",
+			subscription: kyivSubscription(domain.DailyWeatherEmailFrequency),
+			at:           utc(1, 15, 5),
+			want:         true,
+		},
+		{
+			name:         "daily ignores active window",
+			subscription: withActiveWindow(kyivSubscription(domain.DailyWeatherEmailFrequency), 9, 17, true),
+			at:           utc(5, 17, 4),
+			want:         true,
+		},
+		{
+			name:         "weekly on Monday",
+			subscription: kyivSubscription(domain.WeeklyWeatherEmailFrequency),
+			at:           utc(5, 19, 4),
+			want:         true,
+		},
+		{
+			name:         "weekly on Tuesday",
+			subscription: kyivSubscription(domain.WeeklyWeatherEmailFrequency),
+			at:           utc(5, 20, 4),
+			want:         false,
+		},
+		{name: "weekly on local Monday", subscription: newYork, at: utc(5, 20, 2), want: true},
+		{name: "weekly on UTC Monday", subscription: newYork, at: utc(5, 19, 2), want: false},
+		{
+			name:         "alerts",
+			subscription: kyivSubscription(domain.WeatherAlertsFrequency),
+			at:           utc(5, 14, 6),
+			want:         false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.subscription.IsDue(tt.at))
+		})
+	}
+}
+
+func TestSubscriptionNextRun(t *testing.T) {
+	daily := kyivSubscription(domain.DailyWeatherEmailFrequency)
+
+	tests := []struct {
+		name         string
+		subscription domain.Subscription
+		after        time.Time
+		want         time.Time
+		ok           bool
+	}{
+		{
+			name:         "hourly",
+			subscription: kyivSubscription(domain.HourlyWeatherEmailFrequency),
+			after:        utc(5, 14, 10).Add(20 * time.Minute),
+			want:         utc(5, 14, 11),
+			ok:           true,
+		},
+		{
+			name:         "every 6 hours",
+			subscription: kyivSubscription(domain.Every6HoursWeatherEmailFrequency),
+			after:        utc(5, 14, 1).Add(30 * time.Minute),
+			want:         utc(5, 14, 6),
+			ok:           true,
+		},
+		{name: "daily after delivery hour", subscription: daily, after: utc(5, 14, 4), want: utc(5, 15, 4), ok: true},
+		{name: "daily over spring DST change", subscription: daily, after: utc(3, 29, 5), want: utc(3, 30, 4), ok: true},
+		{name: "daily over autumn DST change", subscription: daily, after: utc(10, 25, 4), want: utc(10, 26, 5), ok: true},
+		{
+			name:         "weekly after delivery time",
+			subscription: kyivSubscription(domain.WeeklyWeatherEmailFrequency),
+			after:        utc(5, 19, 4),
+			want:         utc(5, 26, 4),
+			ok:           true,
+		},
+		{
+			name:         "weekdays only over weekend",
+			subscription: withActiveWindow(kyivSubscription(domain.HourlyWeatherEmailFrequency), 9, 17, true),
+			after:        utc(5, 16, 14),
+			want:         utc(5, 19, 6),
+			ok:           true,
+		},
+		{
+			name:         "every 3 hours within wrap-around window",
+			subscription: withActiveWindow(kyivSubscription(domain.Every3HoursWeatherEmailFrequency), 22, 6, false),
+			after:        utc(5, 14, 12),
+			want:         utc(5, 14, 21),
+			ok:           true,
+		},
+		{
+			name:         "every 3 hours never within window",
+			subscription: withActiveWindow(kyivSubscription(domain.Every3HoursWeatherEmailFrequency), 7, 8, false),
+			after:        utc(5, 14, 12),
+			ok:           false,
+		},
+		{
+			name:         "alerts aren't scheduled",
+			subscription: kyivSubscription(domain.WeatherAlertsFrequency),
+			after:        utc(5, 14, 12),
+			ok:           false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next, ok := tt.subscription.NextRun(tt.after)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.want, next)
+		})
+	}
+}
@@ -1,13 +1,40 @@
 package domain
 
 import (
+	"common/frequency"
 	"fmt"
+	"slices"
 	"time"
 )
 
+// Subscription frequencies, see common/frequency.
 const (
-	DailyWeatherEmailFrequency  = "daily"
-	HourlyWeatherEmailFrequency = "hourly"
+	HourlyWeatherEmailFrequency      = frequency.Hourly
+	Every3HoursWeatherEmailFrequency = frequency.Every3Hours
+	Every6HoursWeatherEmailFrequency = frequency.Every6Hours
+	DailyWeatherEmailFrequency       = frequency.Daily
+	WeeklyWeatherEmailFrequency      = frequency.Weekly
+	WeatherAlertsFrequency           = frequency.Alerts
+)
+
+// activeWindowFrequencies are sent several times a day, within the active window of the subscription.
+var activeWindowFrequencies = []string{
+	HourlyWeatherEmailFrequency, Every3HoursWeatherEmailFrequency, Every6HoursWeatherEmailFrequency,
+}
+
+// HasActiveWindow reports whether emails of the frequency are limited to the active window of the subscription.
+func HasActiveWindow(frequency string) bool {
+	return slices.Contains(activeWindowFrequencies, frequency)
+}
+
+const (
+	// DefaultTimezone is used for subscriptions whose city has no known timezone.
+	DefaultTimezone = "UTC"
+	// DefaultDeliveryHour is the local hour daily emails are sent at, unless the subscriber chose another one.
+	DefaultDeliveryHour = 7
+	// DefaultActiveFrom and DefaultActiveTo make hourly and every-N-hours emails sent all day long.
+	DefaultActiveFrom = 0
+	DefaultActiveTo   = 23
 )
 
 type Subscription struct {
@@ -18,17 +45,120 @@ type Subscription struct {
 	Token     string    `json:"token" db:"token"`
 	Frequency string    `json:"frequency" db:"frequency"`
 	Confirmed bool      `json:"confirmed" db:"confirmed"`
+	Units     Units     `json:"units" db:"units"`
+	// AirQuality subscriptions get the air quality section in all forecast emails but weekly ones.
+	AirQuality bool `json:"air_quality" db:"air_quality"`
+	// Timezone is the IANA timezone of the subscription, e.g. "Europe/Kyiv". Daily and weekly
+	// emails are sent at DeliveryHour (0-23) in it, and email dates are local to it.
+	Timezone     string `json:"timezone" db:"timezone"`
+	DeliveryHour int    `json:"delivery_hour" db:"delivery_hour"`
+	// ActiveFrom and ActiveTo are the first and the last local hour (0-23) hourly and every-N-hours
+	// emails are sent at. The window wraps past midnight if ActiveFrom is after ActiveTo, e.g. 22-06.
+	// WeekdaysOnly subscriptions get these emails from Monday to Friday only.
+	ActiveFrom   int  `json:"active_from" db:"active_from"`
+	ActiveTo     int  `json:"active_to" db:"active_to"`
+	WeekdaysOnly bool `json:"weekdays_only" db:"weekdays_only"`
+	// NextRunAt is when the scheduler sends the next email of the subscription, see NextRun.
+	// It is nil for unconfirmed and not scheduled subscriptions.
+	NextRunAt *time.Time `json:"-" db:"next_run_at"`
+	// LocationID is the resolved location of City. It is nil for subscriptions
+	// created before cities were resolved to locations.
+	LocationID *string `json:"location_id" db:"location_id"`
+	// Latitude and Longitude of the resolved location, loaded together with subscriptions to send.
+	Latitude  *float64 `json:"-" db:"latitude"`
+	Longitude *float64 `json:"-" db:"longitude"`
 }
 
-func NewSubscription(email, city, frequency, token string) Subscription {
+// NewSubscription creates an unconfirmed subscription to the weather of the location.
+// Unless the subscriber chose a timezone, the timezone of the location is used.
+// The active window is kept only for the frequencies which have one, see HasActiveWindow.
+func NewSubscription(inp CreateSubscriptionInput, location Location, token string) Subscription {
+	timezone := inp.Timezone
+	if timezone == "" {
+		timezone = location.Timezone
+	}
+	if timezone == "" {
+		timezone = DefaultTimezone
+	}
+
+	activeFrom, activeTo, weekdaysOnly := DefaultActiveFrom, DefaultActiveTo, false
+	if HasActiveWindow(inp.Frequency) {
+		activeFrom, activeTo, weekdaysOnly = inp.ActiveFrom, inp.ActiveTo, inp.WeekdaysOnly
+	}
+
 	return Subscription{
-		CreatedAt: time.Now(),
-		Email:     email,
-		City:      city,
-		Frequency: frequency,
-		Token:     token,
-		Confirmed: false,
+		CreatedAt:    time.Now(),
+		Email:        inp.Email,
+		City:         location.Name,
+		Frequency:    inp.Frequency,
+		Token:        token,
+		Confirmed:    false,
+		Units:        inp.Units,
+		AirQuality:   inp.AirQuality,
+		Timezone:     timezone,
+		DeliveryHour: inp.DeliveryHour,
+		ActiveFrom:   activeFrom,
+		ActiveTo:     activeTo,
+		WeekdaysOnly: weekdaysOnly,
+		LocationID:   &location.ID,
+	}
+}
+
+// LocationKey identifies the place the subscription's weather is requested for.
+// Subscriptions without a resolved location are identified by their normalized city.
+func (s *Subscription) LocationKey() string {
+	if s.LocationID != nil {
+		return *s.LocationID
+	}
+	return NormalizeLocationQuery(s.City)
+}
+
+// WeatherQuery returns the query for the weather of the subscription: the coordinates
+// of its location, together with its city, if they are loaded, and the city otherwise.
+func (s *Subscription) WeatherQuery() WeatherQuery {
+	query := CityQuery(s.City)
+	if s.Latitude != nil && s.Longitude != nil {
+		query = CoordinatesQuery(Coordinates{Latitude: *s.Latitude, Longitude: *s.Longitude})
+		query.City = s.City
+	}
+	if s.LocationID != nil {
+		query.LocationID = *s.LocationID
+	}
+	return query
+}
+
+// LocalTime returns t in the subscription's timezone. Subscriptions with an unknown timezone get UTC.
+func (s *Subscription) LocalTime(t time.Time) time.Time {
+	timezone, err := LoadTimezone(s.Timezone)
+	if err != nil {
+		return t.UTC()
+	}
+	return t.In(timezone)
+}
+
+// IsDeliveryHour reports whether t falls within the delivery hour of the subscription in its timezone.
+func (s *Subscription) IsDeliveryHour(t time.Time) bool {
+	return s.LocalTime(t).Hour() == s.DeliveryHour
+}
+
+// IsWeeklyDeliveryTime reports whether t falls within the delivery hour of the subscription on a Monday,
+// both in its timezone.
+func (s *Subscription) IsWeeklyDeliveryTime(t time.Time) bool {
+	return s.LocalTime(t).Weekday() == time.Monday && s.IsDeliveryHour(t)
+}
+
+// IsActive reports whether t falls within the active window of the subscription in its timezone.
+func (s *Subscription) IsActive(t time.Time) bool {
+	local := s.LocalTime(t)
+	if s.WeekdaysOnly && (local.Weekday() == time.Saturday || local.Weekday() == time.Sunday) {
+		return false
+	}
+
+	hour := local.Hour()
+	if s.ActiveFrom <= s.ActiveTo {
+		return s.ActiveFrom <= hour && hour <= s.ActiveTo
 	}
+	return hour >= s.ActiveFrom || hour <= s.ActiveTo
 }
 
 func (s *Subscription) CreateConfirmationLink(baseURL string) string {
@@ -40,9 +170,19 @@ func (s *Subscription) CreateUnsubscribeLink(baseURL string) string {
 }
 
 type CreateSubscriptionInput struct {
-	Email     string
-	City      string
-	Frequency string
+	Email string
+	// Place is the city or the coordinates to subscribe to the weather of.
+	Place      WeatherQuery
+	Frequency  string
+	Units      Units
+	AirQuality bool
+	// Timezone is the IANA timezone chosen by the subscriber. Empty means the timezone of the city.
+	Timezone     string
+	DeliveryHour int
+	// ActiveFrom, ActiveTo and WeekdaysOnly limit when hourly and every-N-hours emails are sent.
+	ActiveFrom   int
+	ActiveTo     int
+	WeekdaysOnly bool
 }
 
 type ConfirmationEmailInput struct {
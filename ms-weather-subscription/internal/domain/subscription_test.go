@@ -0,0 +1,67 @@
+package domain_test
+
+import (
+	"ms-weather-subscription/internal/domain"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// kyivSubscription is an all day long subscription in Europe/Kyiv: UTC+3 in summer and UTC+2 in winter.
+func kyivSubscription(frequency string) domain.Subscription {
+	return domain.Subscription{
+		Frequency:    frequency,
+		Timezone:     "Europe/Kyiv",
+		DeliveryHour: domain.DefaultDeliveryHour,
+		ActiveFrom:   domain.DefaultActiveFrom,
+		ActiveTo:     domain.DefaultActiveTo,
+	}
+}
+
+func withActiveWindow(subscription domain.Subscription, from, to int, weekdaysOnly bool) domain.Subscription {
+	subscription.ActiveFrom, subscription.ActiveTo, subscription.WeekdaysOnly = from, to, weekdaysOnly
+	return subscription
+}
+
+func utc(month time.Month, day, hour int) time.Time {
+	return time.Date(2025, month, day, hour, 0, 0, 0, time.UTC)
+}
+
+func TestSubscriptionIsActive(t *testing.T) {
+	hourly := kyivSubscription(domain.HourlyWeatherEmailFrequency)
+	office := withActiveWindow(hourly, 9, 17, false)
+	night := withActiveWindow(hourly, 22, 6, false)
+	weekdays := withActiveWindow(hourly, domain.DefaultActiveFrom, domain.DefaultActiveTo, true)
+	unknownTimezone := office
+	unknownTimezone.Timezone = "Mars/Olympus"
+
+	// 2025-05-14 is a Wednesday, 2025-05-17 a Saturday.
+	tests := []struct {
+		name         string
+		subscription domain.Subscription
+		at           time.Time
+		want         bool
+	}{
+		{name: "all day long", subscription: hourly, at: utc(5, 14, 0), want: true},
+		{name: "first hour of window", subscription: office, at: utc(5, 14, 6), want: true},
+		{name: "before window", subscription: office, at: utc(5, 14, 5), want: false},
+		{name: "last hour of window", subscription: office, at: utc(5, 14, 14), want: true},
+		{name: "after window", subscription: office, at: utc(5, 14, 15), want: false},
+		{name: "wrap-around window before midnight", subscription: night, at: utc(5, 14, 20), want: true},
+		{name: "wrap-around window after midnight", subscription: night, at: utc(5, 14, 3), want: true},
+		{name: "after wrap-around window", subscription: night, at: utc(5, 14, 4), want: false},
+		{name: "before wrap-around window", subscription: night, at: utc(5, 14, 18), want: false},
+		{name: "weekdays only on Friday", subscription: weekdays, at: utc(5, 16, 10), want: true},
+		{name: "weekdays only on Saturday", subscription: weekdays, at: utc(5, 17, 10), want: false},
+		{name: "weekdays only on local Saturday", subscription: weekdays, at: utc(5, 16, 22), want: false},
+		{name: "weekdays only on local Monday", subscription: weekdays, at: utc(5, 18, 22), want: true},
+		{name: "unknown timezone is UTC", subscription: unknownTimezone, at: utc(5, 14, 9), want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.subscription.IsActive(tt.at))
+		})
+	}
+}
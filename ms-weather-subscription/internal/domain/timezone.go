@@ -0,0 +1,29 @@
+package domain
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// timezones caches loaded IANA timezones, since subscriptions are checked against their timezone every hour.
+var timezones sync.Map
+
+// LoadTimezone returns the IANA timezone by its name, e.g. "Europe/Kyiv".
+func LoadTimezone(name string) (*time.Location, error) {
+	if cached, ok := timezones.Load(name); ok {
+		return cached.(*time.Location), nil
+	}
+
+	// Empty name and "Local" are valid for time.LoadLocation, but aren't IANA timezones.
+	if name == "" || name == "Local" {
+		return nil, fmt.Errorf("unknown timezone %q", name)
+	}
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("unknown timezone %q: %w", name, err)
+	}
+
+	timezones.Store(name, location)
+	return location, nil
+}
@@ -0,0 +1,116 @@
+package domain
+
+import "math"
+
+// Units is the unit system weather is presented in. Weather providers are always queried
+// in metric units and the weather is cached that way; it is converted only when returned to the user.
+//
+//	metric   - °C, km/h, mm, hPa, km
+//	imperial - °F, mph, in, inHg, mi
+//	standard - K, m/s, mm, hPa, km
+type Units string
+
+const (
+	UnitsMetric   Units = "metric"
+	UnitsImperial Units = "imperial"
+	UnitsStandard Units = "standard"
+)
+
+const (
+	kmPerMile        = 1.609344
+	mmPerInch        = 25.4
+	hPaPerInHg       = 33.8639
+	kmhPerMps        = 3.6
+	zeroCelsiusInK   = 273.15
+	convertPrecision = 100 // converted values are rounded to 2 decimal places
+)
+
+func (u Units) IsValid() bool {
+	switch u {
+	case UnitsMetric, UnitsImperial, UnitsStandard:
+		return true
+	default:
+		return false
+	}
+}
+
+// InUnits returns a copy of the weather converted from metric to the given units.
+func (w *WeatherResponse) InUnits(units Units) *WeatherResponse {
+	converted := *w
+	switch units {
+	case UnitsImperial:
+		converted.Temperature = round(w.Temperature*9/5 + 32)
+		converted.FeelsLike = round(w.FeelsLike*9/5 + 32)
+		converted.WindSpeed = round(w.WindSpeed / kmPerMile)
+		converted.WindGust = round(w.WindGust / kmPerMile)
+		converted.Precipitation = round(w.Precipitation / mmPerInch)
+		converted.Pressure = round(w.Pressure / hPaPerInHg)
+		converted.Visibility = round(w.Visibility / kmPerMile)
+	case UnitsStandard:
+		converted.Temperature = round(w.Temperature + zeroCelsiusInK)
+		converted.FeelsLike = round(w.FeelsLike + zeroCelsiusInK)
+		converted.WindSpeed = round(w.WindSpeed / kmhPerMps)
+		converted.WindGust = round(w.WindGust / kmhPerMps)
+	case UnitsMetric:
+	}
+	return &converted
+}
+
+// InUnits returns a copy of the forecast with every hour converted from metric to the given units.
+func (d *DayWeatherResponse) InUnits(units Units) *DayWeatherResponse {
+	converted := *d
+	converted.Hours = hoursInUnits(d.Hours, units)
+	return &converted
+}
+
+// InUnits returns a copy of the weather with the current weather and every hour converted
+// from metric to the given units.
+func (w *Every3HoursWeatherResponse) InUnits(units Units) *Every3HoursWeatherResponse {
+	return &Every3HoursWeatherResponse{Current: *w.Current.InUnits(units), Hours: hoursInUnits(w.Hours, units)}
+}
+
+// InUnits returns a copy of the weather with the current weather and every hour converted
+// from metric to the given units.
+func (w *Every6HoursWeatherResponse) InUnits(units Units) *Every6HoursWeatherResponse {
+	return &Every6HoursWeatherResponse{Current: *w.Current.InUnits(units), Hours: hoursInUnits(w.Hours, units)}
+}
+
+func hoursInUnits(hours []HourlyWeather, units Units) []HourlyWeather {
+	converted := make([]HourlyWeather, len(hours))
+	for i, hour := range hours {
+		converted[i] = HourlyWeather{
+			Time:            hour.Time,
+			WeatherResponse: *hour.WeatherResponse.InUnits(units),
+		}
+	}
+	return converted
+}
+
+// InUnits returns a copy of the forecast with the temperatures of every day converted
+// from metric to the given units. The rest of the values are unitless.
+func (f *ForecastResponse) InUnits(units Units) *ForecastResponse {
+	converted := *f
+	converted.Days = make([]DayForecast, len(f.Days))
+	for i, day := range f.Days {
+		day.MinTemperature = temperatureInUnits(day.MinTemperature, units)
+		day.MaxTemperature = temperatureInUnits(day.MaxTemperature, units)
+		day.AvgTemperature = temperatureInUnits(day.AvgTemperature, units)
+		converted.Days[i] = day
+	}
+	return &converted
+}
+
+func temperatureInUnits(celsius float32, units Units) float32 {
+	switch units {
+	case UnitsImperial:
+		return round(celsius*9/5 + 32)
+	case UnitsStandard:
+		return round(celsius + zeroCelsiusInK)
+	default:
+		return celsius
+	}
+}
+
+func round(value float32) float32 {
+	return float32(math.Round(float64(value)*convertPrecision) / convertPrecision)
+}
@@ -1,22 +1,129 @@
 package domain
 
+import (
+	"slices"
+	"time"
+)
+
+// WeatherResponse holds metric values: temperature in °C, speeds in km/h, precipitation in mm,
+// pressure in hPa (sea level), visibility in km, humidity, probability and cloud cover in percent.
+// Values the provider doesn't return are zero.
 type WeatherResponse struct {
 	Temperature float32 `json:"temperature"`
 	Humidity    float32 `json:"humidity"`
 	Description string  `json:"description"`
+
+	FeelsLike                float32 `json:"feels_like"`
+	WindSpeed                float32 `json:"wind_speed"`
+	WindDirection            float32 `json:"wind_direction"` // degrees, the direction the wind blows from
+	WindGust                 float32 `json:"wind_gust"`
+	Precipitation            float32 `json:"precipitation"`
+	PrecipitationProbability float32 `json:"precipitation_probability"`
+	UVIndex                  float32 `json:"uv_index"`
+	Pressure                 float32 `json:"pressure"`
+	Visibility               float32 `json:"visibility"`
+	CloudCover               float32 `json:"cloud_cover"`
+
+	// Stale is set when no weather provider could answer and the last known weather is returned.
+	// ObservedAt is when that weather was received from the provider.
+	Stale      bool      `json:"stale,omitempty"`
+	ObservedAt time.Time `json:"observed_at,omitzero"`
 }
 
+// DayWeatherResponse is the forecast for the current day of the city, hour by hour.
 type DayWeatherResponse struct {
-	SevenAM WeatherResponse `json:"seven_am"`
-	TenAM   WeatherResponse `json:"ten_am"`
-	OnePM   WeatherResponse `json:"one_pm"`
-	FourPM  WeatherResponse `json:"four_pm"`
-	SevenPM WeatherResponse `json:"seven_pm"`
-	TenPM   WeatherResponse `json:"ten_pm"`
+	// Hours are ordered by time of the day.
+	Hours []HourlyWeather `json:"hours"`
+
+	// Timezone is the IANA timezone of the city, e.g. "Europe/Kyiv". Empty if the provider didn't return it.
+	Timezone string `json:"timezone,omitempty"`
+
+	Stale      bool      `json:"stale,omitempty"`
+	ObservedAt time.Time `json:"observed_at,omitzero"`
+}
+
+type HourlyWeather struct {
+	Time string `json:"time"` // "15:04" in the city's timezone
+	WeatherResponse
+}
+
+// SelectHours returns a copy of the forecast with only the given hours ("15:04") kept.
+// Hours the provider hasn't returned are skipped.
+func (d *DayWeatherResponse) SelectHours(hours []string) *DayWeatherResponse {
+	selected := *d
+	selected.Hours = make([]HourlyWeather, 0, len(hours))
+	for _, hour := range d.Hours {
+		if slices.Contains(hours, hour.Time) {
+			selected.Hours = append(selected.Hours, hour)
+		}
+	}
+	return &selected
+}
+
+// UpcomingHours returns the hours of the day forecast within the given number of hours after now,
+// which is converted to the timezone of the forecast. Hours after midnight aren't in the forecast of the day.
+func (d *DayWeatherResponse) UpcomingHours(now time.Time, hours int) []HourlyWeather {
+	if timezone, err := LoadTimezone(d.Timezone); err == nil {
+		now = now.In(timezone)
+	} else {
+		now = now.UTC()
+	}
+	from := now.Hour()*60 + now.Minute()
+	to := from + hours*60
+
+	upcoming := make([]HourlyWeather, 0, hours)
+	for _, hour := range d.Hours {
+		parsed, err := time.Parse("15:04", hour.Time)
+		if err != nil {
+			continue
+		}
+		if minute := parsed.Hour()*60 + parsed.Minute(); from < minute && minute <= to {
+			upcoming = append(upcoming, hour)
+		}
+	}
+	return upcoming
+}
+
+// Every3HoursWeatherResponse is the current weather together with the forecast for the next 3 hours,
+// as sent in every 3 hours emails.
+type Every3HoursWeatherResponse struct {
+	Current WeatherResponse `json:"current"`
+	// Hours are ordered by time of the day, "15:04" in the city's timezone.
+	Hours []HourlyWeather `json:"hours"`
+}
+
+// Every6HoursWeatherResponse is the current weather together with the forecast for the next 6 hours,
+// as sent in every 6 hours emails.
+type Every6HoursWeatherResponse struct {
+	Current WeatherResponse `json:"current"`
+	// Hours are ordered by time of the day, "15:04" in the city's timezone.
+	Hours []HourlyWeather `json:"hours"`
+}
+
+// WeeklyForecastDays is the number of days in the weekly outlook email.
+const WeeklyForecastDays = 7
+
+// MaxForecastDays is the maximum number of days a multi-day forecast can be requested for.
+const MaxForecastDays = 14
+
+// ForecastResponse is a multi-day forecast, one entry per day starting from today.
+type ForecastResponse struct {
+	Days []DayForecast `json:"days"`
+}
+
+type DayForecast struct {
+	Date                     string  `json:"date"` // "2006-01-02" in the city's timezone
+	MinTemperature           float32 `json:"min_temperature"`
+	MaxTemperature           float32 `json:"max_temperature"`
+	AvgTemperature           float32 `json:"avg_temperature"`
+	Humidity                 float32 `json:"humidity"`
+	PrecipitationProbability float32 `json:"precipitation_probability"`
+	Description              string  `json:"description"`
 }
 
 type WeatherResponseType interface {
-	*WeatherResponse | *DayWeatherResponse
+	*WeatherResponse | *DayWeatherResponse | *Every3HoursWeatherResponse | *Every6HoursWeatherResponse |
+		*ForecastResponse
 }
 
 type WeatherForecastEmailInput[T WeatherResponseType] struct {
@@ -24,4 +131,6 @@ type WeatherForecastEmailInput[T WeatherResponseType] struct {
 	Weather         T            `json:"weather"`
 	Date            string       `json:"date"`
 	UnsubscribeLink string       `json:"unsubscribe_link"`
+	// AirQuality is set only for subscriptions which opted into it.
+	AirQuality *AirQualityResponse `json:"air_quality,omitempty"`
 }
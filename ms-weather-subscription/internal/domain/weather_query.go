@@ -0,0 +1,63 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+)
+
+// coordinatesGridPrecision rounds coordinates to 0.01° (about 1 km).
+const coordinatesGridPrecision = 100
+
+// Coordinates of a place in decimal degrees.
+type Coordinates struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+func (c Coordinates) IsValid() bool {
+	return c.Latitude >= -90 && c.Latitude <= 90 && c.Longitude >= -180 && c.Longitude <= 180
+}
+
+// OnGrid returns the coordinates rounded to the grid. Nearby coordinates on the same grid cell
+// share one cached weather and one location.
+func (c Coordinates) OnGrid() Coordinates {
+	return Coordinates{
+		Latitude:  math.Round(c.Latitude*coordinatesGridPrecision) / coordinatesGridPrecision,
+		Longitude: math.Round(c.Longitude*coordinatesGridPrecision) / coordinatesGridPrecision,
+	}
+}
+
+// GridKey identifies the grid cell of the coordinates, e.g. "50.45,30.52".
+func (c Coordinates) GridKey() string {
+	onGrid := c.OnGrid()
+	return fmt.Sprintf("%.2f,%.2f", onGrid.Latitude, onGrid.Longitude)
+}
+
+func (c Coordinates) String() string {
+	return fmt.Sprintf("%.4f,%.4f", c.Latitude, c.Longitude)
+}
+
+// WeatherQuery is the place weather is requested for: either a city name or coordinates.
+// A query by coordinates may also carry the city they were resolved from, providers are asked
+// for the coordinates then. LocationID is the resolved location of the query, if it is known:
+// the weather history is recorded under it.
+type WeatherQuery struct {
+	City        string
+	Coordinates *Coordinates
+	LocationID  string
+}
+
+func CityQuery(city string) WeatherQuery {
+	return WeatherQuery{City: city}
+}
+
+func CoordinatesQuery(coordinates Coordinates) WeatherQuery {
+	return WeatherQuery{Coordinates: &coordinates}
+}
+
+func (q WeatherQuery) String() string {
+	if q.Coordinates != nil {
+		return q.Coordinates.String()
+	}
+	return q.City
+}
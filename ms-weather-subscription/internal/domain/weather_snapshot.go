@@ -0,0 +1,16 @@
+package domain
+
+import "time"
+
+// MaxWeatherHistoryRange is the longest period the weather history can be requested for at once.
+const MaxWeatherHistoryRange = 31 * 24 * time.Hour
+
+// WeatherSnapshot is the current weather a provider returned for a location at ObservedAt.
+// Coordinates are the ones the weather was requested for, if any.
+type WeatherSnapshot struct {
+	LocationID  string
+	Coordinates *Coordinates
+	Provider    string
+	ObservedAt  time.Time
+	Weather     WeatherResponse
+}
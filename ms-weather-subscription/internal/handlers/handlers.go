@@ -23,7 +23,7 @@ type Handler struct {
 func NewHandler(services *service.Services) *Handler {
 	return &Handler{
 		SubscriptionHandler: NewSubscriptionHandler(services.Subscriptions),
-		WeatherHandler:      NewWeatherHandler(services.Weather),
+		WeatherHandler:      NewWeatherHandler(services.Weather, services.WeatherHistory),
 	}
 }
 
@@ -71,8 +71,12 @@ func (h *Handler) initAPI(router *gin.Engine) {
 		weather := api.Group("/weather")
 		{
 			weather.GET("/", h.WeatherHandler.GetWeather)
+			weather.GET("/forecast", h.WeatherHandler.GetForecast)
+			weather.GET("/history", h.WeatherHandler.GetWeatherHistory)
 		}
 
+		api.GET("/air-quality", h.WeatherHandler.GetAirQuality)
+
 		subscription := api.Group("")
 		{
 			subscription.POST("/subscribe", h.SubscriptionHandler.SubscribeEmail)
@@ -4,7 +4,9 @@ import (
 	"context"
 	"errors"
 	"net/http"
+	"time"
 
+	"common/frequency"
 	"ms-weather-subscription/internal/domain"
 	customErrors "ms-weather-subscription/pkg/errors"
 	"ms-weather-subscription/pkg/hash"
@@ -29,27 +31,107 @@ func NewSubscriptionHandler(subscriptionService Subscription) *SubscriptionHandl
 }
 
 type subscribeEmailInput struct {
+	placeInput
 	Email     string `form:"email" json:"email" binding:"required,email,max=255"`
-	City      string `form:"city" json:"city" binding:"required,max=255"`
-	Frequency string `form:"frequency" json:"frequency" binding:"oneof=hourly daily"`
+	Frequency string `form:"frequency" json:"frequency" binding:"required"` // one of frequency.All
+	Units     string `form:"units" json:"units" binding:"omitempty,oneof=metric imperial standard"`
+	// AirQuality opts into the air quality section in forecast emails, except for weekly ones.
+	AirQuality bool `form:"air_quality" json:"air_quality"`
+	// Timezone is the IANA timezone of the subscription. The city's timezone is used if it's empty.
+	Timezone string `form:"timezone" json:"timezone" binding:"omitempty,max=64"`
+	// DeliveryHour is the local hour daily and weekly emails are sent at.
+	DeliveryHour *int `form:"delivery_hour" json:"delivery_hour" binding:"omitempty,min=0,max=23"`
+	// ActiveFrom and ActiveTo ("HH:00", local time) limit the hours hourly and every-N-hours emails are sent at.
+	ActiveFrom   string `form:"active_from" json:"active_from"`
+	ActiveTo     string `form:"active_to" json:"active_to"`
+	WeekdaysOnly bool   `form:"weekdays_only" json:"weekdays_only"`
+}
+
+// parseWholeHour parses the time of the day in "HH:00" format into the hour.
+// Empty value means defaultHour.
+func parseWholeHour(value string, defaultHour int) (int, bool) {
+	if value == "" {
+		return defaultHour, true
+	}
+
+	parsed, err := time.Parse("15:04", value)
+	if err != nil || parsed.Minute() != 0 || parsed.Format("15:04") != value {
+		return 0, false
+	}
+	return parsed.Hour(), true
+}
+
+type frequencyOption struct {
+	Value string
+	Label string
+}
+
+var frequencyLabels = map[string]string{
+	frequency.Hourly:      "Hourly",
+	frequency.Every3Hours: "Every 3 hours",
+	frequency.Every6Hours: "Every 6 hours",
+	frequency.Daily:       "Daily",
+	frequency.Weekly:      "Weekly (Monday outlook for 7 days)",
+	frequency.Alerts:      "Severe weather alerts only",
 }
 
 func (h *SubscriptionHandler) ShowSubscribePage(c *gin.Context) {
-	c.HTML(http.StatusOK, "subscribe.html", gin.H{})
+	hours := make([]int, 24)
+	for hour := range hours {
+		hours[hour] = hour
+	}
+
+	frequencies := make([]frequencyOption, 0, len(frequency.All))
+	for _, f := range frequency.All {
+		label, ok := frequencyLabels[f]
+		if !ok {
+			label = f
+		}
+		frequencies = append(frequencies, frequencyOption{Value: f, Label: label})
+	}
+
+	c.HTML(http.StatusOK, "subscribe.html", gin.H{
+		"Frequencies":         frequencies,
+		"DefaultFrequency":    frequency.Daily,
+		"Hours":               hours,
+		"DefaultDeliveryHour": domain.DefaultDeliveryHour,
+		"DefaultActiveFrom":   domain.DefaultActiveFrom,
+		"DefaultActiveTo":     domain.DefaultActiveTo,
+	})
 }
 
 // SubscribeEmail godoc
 // @Summary Subscribe to weather updates
-// @Description Subscribe an email to receive weather updates for a specific city with chosen frequency.
+// @Description Subscribe an email to receive weather updates for a specific city or coordinates
+// @Description with chosen frequency. Either city or both lat and lon must be given.
+// @Description With alerts frequency, an email is sent only when a new severe weather alert is issued.
+// @Description Every 3 and 6 hours emails have the current weather and the forecast until the next email.
+// @Description Weekly emails are sent on Mondays with the outlook for 7 days.
+// @Description Forecast emails, except for weekly ones, include the air quality if air_quality is set.
+// @Description Daily and weekly emails are sent at delivery_hour in the subscription's timezone,
+// @Description which is the timezone of the city unless timezone is given.
+// @Description Hourly and every 3 and 6 hours emails are sent from active_from to active_to local time,
+// @Description on weekdays only if weekdays_only is set.
+// @Description The window wraps past midnight if active_from is after active_to.
 // @Tags subscription
 // @Accept  json
 // @Accept  x-www-form-urlencoded
 // @Produce json
 // @Param email formData string true "Email address to subscribe"
-// @Param city formData string true "City for weather updates"
-// @Param frequency formData string true "Frequency of updates (hourly or daily)" Enums(hourly, daily)
+// @Param city formData string false "City for weather updates"
+// @Param lat formData number false "Latitude in decimal degrees (-90 to 90)"
+// @Param lon formData number false "Longitude in decimal degrees (-180 to 180)"
+// @Param frequency formData string true "Frequency of updates" Enums(hourly, every_3h, every_6h, daily, weekly, alerts)
+// @Param units formData string false "Units of the weather in emails" Enums(metric, imperial, standard) default(metric)
+// @Param air_quality formData boolean false "Include air quality in forecast emails" default(false)
+// @Param timezone formData string false "IANA timezone of the subscription, e.g. Europe/Kyiv"
+// @Param delivery_hour formData integer false "Local hour daily and weekly emails are sent at (0-23)" default(7)
+// @Param active_from formData string false "First hour emails are sent at (HH:00)" default(00:00)
+// @Param active_to formData string false "Last hour emails are sent at (HH:00)" default(23:00)
+// @Param weekdays_only formData boolean false "Send emails from Monday to Friday only" default(false)
 // @Success 200 "Subscription successful. Confirmation email sent."
 // @Failure 400 "Invalid input"
+// @Failure 404 "City not found"
 // @Failure 409 "Email already subscribed"
 // @Router /subscribe [post]
 func (h *SubscriptionHandler) SubscribeEmail(c *gin.Context) {
@@ -60,18 +142,68 @@ func (h *SubscriptionHandler) SubscribeEmail(c *gin.Context) {
 		return
 	}
 
+	if !frequency.IsValid(inp.Frequency) {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	place, ok := inp.weatherQuery()
+	if !ok {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	units := domain.UnitsMetric
+	if inp.Units != "" {
+		units = domain.Units(inp.Units)
+	}
+
+	if inp.Timezone != "" {
+		if _, err := domain.LoadTimezone(inp.Timezone); err != nil {
+			c.Status(http.StatusBadRequest)
+			return
+		}
+	}
+
+	deliveryHour := domain.DefaultDeliveryHour
+	if inp.DeliveryHour != nil {
+		deliveryHour = *inp.DeliveryHour
+	}
+
+	activeFrom, ok := parseWholeHour(inp.ActiveFrom, domain.DefaultActiveFrom)
+	if !ok {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+	activeTo, ok := parseWholeHour(inp.ActiveTo, domain.DefaultActiveTo)
+	if !ok {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
 	err := h.subscriptionService.Create(
 		c,
 		domain.CreateSubscriptionInput{
-			Email:     inp.Email,
-			City:      inp.City,
-			Frequency: inp.Frequency,
+			Email:        inp.Email,
+			Place:        place,
+			Frequency:    inp.Frequency,
+			Units:        units,
+			AirQuality:   inp.AirQuality,
+			Timezone:     inp.Timezone,
+			DeliveryHour: deliveryHour,
+			ActiveFrom:   activeFrom,
+			ActiveTo:     activeTo,
+			WeekdaysOnly: inp.WeekdaysOnly,
 		},
 	)
 	if err != nil {
 		switch {
 		case errors.Is(err, customErrors.ErrSubscriptionAlreadyExists):
 			c.Status(http.StatusConflict)
+		case errors.Is(err, customErrors.ErrCityNotFound):
+			c.Status(http.StatusNotFound)
+		case errors.Is(err, customErrors.ErrSubscriptionNeverDue):
+			c.Status(http.StatusBadRequest)
 		default:
 			c.Status(http.StatusInternalServerError)
 		}
@@ -3,18 +3,24 @@ package handlers_test
 import (
 	"bytes"
 	commonCfg "common/config"
+	"common/frequency"
+	"context"
 	"errors"
 	"ms-weather-subscription/internal/domain"
 	"ms-weather-subscription/internal/handlers"
 	"ms-weather-subscription/internal/repository"
 	"ms-weather-subscription/internal/service"
+	"ms-weather-subscription/pkg/cache"
+	customErrors "ms-weather-subscription/pkg/errors"
 	"ms-weather-subscription/pkg/hash"
 	"ms-weather-subscription/pkg/publisher"
 	mockPublisher "ms-weather-subscription/pkg/publisher/mocks"
 	"ms-weather-subscription/testutils"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 
@@ -27,7 +33,21 @@ func TestSubscription(t *testing.T) {
 	t.Run("Show subscribe page", testShowSubscribePageMocked)
 	t.Run("Successful subscription", testSuccessfulSubscribe)
 	t.Run("Successful subscription with failed email", testSuccessfulSubscribeWithFailedEmail)
+	t.Run("Successful subscription with units", testSuccessfulSubscribeWithUnits)
+	t.Run("Successful subscription with every frequency", testSuccessfulSubscribeEveryFrequency)
 	t.Run("Invalid request body", testInvalidSubscribeRequestBody)
+	t.Run("Invalid units", testInvalidSubscribeUnits)
+	t.Run("Subscription with timezone and delivery hour", testSubscribeWithDeliveryTime)
+	t.Run("Invalid timezone or delivery hour", testInvalidSubscribeDeliveryTime)
+	t.Run("Hourly subscription with active window", testSubscribeWithActiveWindow)
+	t.Run("Active window of daily subscription", testSubscribeDailyIgnoresActiveWindow)
+	t.Run("Invalid active window", testInvalidSubscribeActiveWindow)
+	t.Run("Active window without delivery hours", testSubscribeActiveWindowNeverDue)
+	t.Run("City resolved to location", testSubscribeResolvesCity)
+	t.Run("Duplicate subscription with other city spelling", testDuplicateSubscribeOtherSpelling)
+	t.Run("Unknown city", testSubscribeUnknownCity)
+	t.Run("Subscription by coordinates", testSubscribeByCoordinates)
+	t.Run("Invalid coordinates", testInvalidSubscribeCoordinates)
 	t.Run("Duplicate subscription", testDuplicateSubscribe)
 	t.Run("Duplicate email subscription with different frequency", testDuplicateEmailSubscribe)
 	t.Run("Unsubscribe success", testUnsubscribeSuccess)
@@ -38,6 +58,37 @@ func TestSubscription(t *testing.T) {
 	t.Run("Confirm invalid token", testConfirmInvalidToken)
 }
 
+var kyiv = domain.Location{
+	ID:        "kyiv,ukraine@50,31",
+	Name:      "Kyiv",
+	Country:   "Ukraine",
+	Latitude:  50.45466,
+	Longitude: 30.5238,
+	Timezone:  "Europe/Kyiv",
+}
+
+// staticLocationSearcher resolves the cities it is given without querying providers.
+type staticLocationSearcher map[string]domain.Location
+
+func (s staticLocationSearcher) SearchLocation(_ context.Context, query string) (*domain.Location, error) {
+	location, ok := s[query]
+	if !ok {
+		return nil, customErrors.ErrCityNotFound
+	}
+	return &location, nil
+}
+
+// LookupTimezone returns the timezone of the city on the grid cell of the coordinates.
+func (s staticLocationSearcher) LookupTimezone(_ context.Context, coordinates domain.Coordinates) (string, error) {
+	for _, location := range s {
+		if (domain.Coordinates{Latitude: location.Latitude, Longitude: location.Longitude}).GridKey() ==
+			coordinates.GridKey() {
+			return location.Timezone, nil
+		}
+	}
+	return "", customErrors.ErrCityNotFound
+}
+
 type subscriptionTestEnv struct {
 	TestDB             *sqlx.DB
 	Router             *gin.Engine
@@ -54,9 +105,16 @@ func setupTestEnvironment(t *testing.T, ctrl *gomock.Controller) subscriptionTes
 
 	mockEmailPublisher := mockPublisher.NewMockEmailPublisher(ctrl)
 
+	locationService := service.NewLocationService(
+		repository.NewLocationRepo(testDB),
+		staticLocationSearcher{"kyiv": kyiv, "kiev": kyiv, "київ": kyiv},
+		cache.NewMemoryCache(100),
+	)
+
 	subService := service.NewSubscriptionService(
 		cfg.HTTP,
 		repo,
+		locationService,
 		hasher,
 		mockEmailPublisher,
 	)
@@ -75,7 +133,7 @@ func setupTestEnvironment(t *testing.T, ctrl *gomock.Controller) subscriptionTes
 	router.LoadHTMLGlob(commonCfg.GetOriginalPath("ms-weather-subscription/templates/**/*.html"))
 
 	cleanup := func() {
-		_, err := testDB.Exec(`DELETE FROM subscriptions;`)
+		_, err := testDB.Exec(`DELETE FROM subscriptions; DELETE FROM locations;`)
 		if err != nil {
 			t.Fatalf("cleanup failed: could not delete subscriptions data: %v", err)
 		}
@@ -104,6 +162,9 @@ func testShowSubscribePageMocked(t *testing.T) {
 
 	assert.Equal(t, http.StatusOK, w.Code)
 	assert.Contains(t, w.Body.String(), "Subscribe to Weather updates")
+	for _, f := range frequency.All {
+		assert.Contains(t, w.Body.String(), `value="`+f+`"`)
+	}
 }
 
 func testSuccessfulSubscribe(t *testing.T) {
@@ -143,9 +204,244 @@ func testSuccessfulSubscribe(t *testing.T) {
 	assert.Equal(t, "test@example.com", sub.Email)
 	assert.Equal(t, "Kyiv", sub.City)
 	assert.Equal(t, "daily", sub.Frequency)
+	assert.Equal(t, domain.UnitsMetric, sub.Units, "metric units should be used by default")
 	assert.False(t, sub.Confirmed, "subscription should not be confirmed yet")
 }
 
+func testSuccessfulSubscribeWithUnits(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Setup
+	testSettings := setupTestEnvironment(t, ctrl)
+	defer testSettings.CleanupFunc()
+
+	testSettings.MockEmailPublisher.
+		EXPECT().
+		Publish(publisher.EmailConfirmationQueue, gomock.Any()).
+		Return(nil)
+
+	// Execute
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/api/subscribe", bytes.NewBufferString(
+		`{"email": "test@example.com", "city": "Kyiv", "frequency": "daily", "units": "imperial"}`,
+	))
+	req.Header.Set("Content-Type", "application/json")
+
+	testSettings.Router.ServeHTTP(w, req)
+
+	// Verify
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var units domain.Units
+	err := testSettings.TestDB.QueryRowx(
+		`SELECT units FROM subscriptions WHERE email = $1`, "test@example.com",
+	).Scan(&units)
+	assert.NoError(t, err)
+	assert.Equal(t, domain.UnitsImperial, units)
+}
+
+// testSuccessfulSubscribeEveryFrequency makes sure the frequencies check constraint of the database
+// allows all the frequencies.
+func testSuccessfulSubscribeEveryFrequency(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Setup
+	testSettings := setupTestEnvironment(t, ctrl)
+	defer testSettings.CleanupFunc()
+
+	testSettings.MockEmailPublisher.
+		EXPECT().
+		Publish(publisher.EmailConfirmationQueue, gomock.Any()).
+		Return(nil).
+		Times(len(frequency.All))
+
+	for _, f := range frequency.All {
+		// Execute
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/api/subscribe", bytes.NewBufferString(
+			`{"email": "test@example.com", "city": "Kyiv", "frequency": "`+f+`"}`,
+		))
+		req.Header.Set("Content-Type", "application/json")
+
+		testSettings.Router.ServeHTTP(w, req)
+
+		// Verify
+		assert.Equal(t, http.StatusOK, w.Code, f)
+	}
+
+	var frequencies []string
+	err := testSettings.TestDB.Select(&frequencies, `SELECT frequency FROM subscriptions WHERE email = $1`,
+		"test@example.com")
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, frequency.All, frequencies)
+}
+
+func testSubscribeResolvesCity(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Setup
+	testSettings := setupTestEnvironment(t, ctrl)
+	defer testSettings.CleanupFunc()
+
+	testSettings.MockEmailPublisher.
+		EXPECT().
+		Publish(publisher.EmailConfirmationQueue, gomock.Any()).
+		Return(nil)
+
+	// Execute
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/api/subscribe", bytes.NewBufferString(
+		`{"email": "test@example.com", "city": "  Kiev ", "frequency": "daily"}`,
+	))
+	req.Header.Set("Content-Type", "application/json")
+
+	testSettings.Router.ServeHTTP(w, req)
+
+	// Verify
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var sub domain.Subscription
+	err := testSettings.TestDB.QueryRowx(
+		`SELECT * FROM subscriptions WHERE email = $1`, "test@example.com",
+	).StructScan(&sub)
+	assert.NoError(t, err)
+	assert.Equal(t, "Kyiv", sub.City, "canonical name of the location should be stored")
+	assert.Equal(t, kyiv.Timezone, sub.Timezone, "timezone of the location should be used by default")
+	assert.Equal(t, domain.DefaultDeliveryHour, sub.DeliveryHour)
+	if assert.NotNil(t, sub.LocationID) {
+		assert.Equal(t, kyiv.ID, *sub.LocationID)
+	}
+
+	var locationID string
+	err = testSettings.TestDB.QueryRowx(
+		`SELECT location_id FROM location_aliases WHERE alias = $1`, "kiev",
+	).Scan(&locationID)
+	assert.NoError(t, err)
+	assert.Equal(t, kyiv.ID, locationID)
+}
+
+func testSubscribeByCoordinates(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Setup
+	testSettings := setupTestEnvironment(t, ctrl)
+	defer testSettings.CleanupFunc()
+
+	testSettings.MockEmailPublisher.
+		EXPECT().
+		Publish(publisher.EmailConfirmationQueue, gomock.Any()).
+		Return(nil)
+
+	// Execute
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/api/subscribe", bytes.NewBufferString(
+		`{"email": "test@example.com", "lat": 50.4547, "lon": 30.5238, "frequency": "daily"}`,
+	))
+	req.Header.Set("Content-Type", "application/json")
+
+	testSettings.Router.ServeHTTP(w, req)
+
+	// Verify
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var sub domain.Subscription
+	err := testSettings.TestDB.QueryRowx(
+		`SELECT * FROM subscriptions WHERE email = $1`, "test@example.com",
+	).StructScan(&sub)
+	assert.NoError(t, err)
+	assert.Equal(t, "50.45,30.52", sub.City)
+	assert.Equal(t, "Europe/Kyiv", sub.Timezone, "the timezone of the coordinates is looked up")
+	if assert.NotNil(t, sub.LocationID) {
+		assert.Equal(t, "coordinates:50.45,30.52", *sub.LocationID)
+	}
+}
+
+func testInvalidSubscribeCoordinates(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Setup
+	testSettings := setupTestEnvironment(t, ctrl)
+	defer testSettings.CleanupFunc()
+
+	for _, body := range []string{
+		`{"email": "test@example.com", "frequency": "daily"}`,
+		`{"email": "test@example.com", "lat": 50.45, "frequency": "daily"}`,
+		`{"email": "test@example.com", "lat": 95, "lon": 30.52, "frequency": "daily"}`,
+		`{"email": "test@example.com", "city": "Kyiv", "lat": 50.45, "lon": 30.52, "frequency": "daily"}`,
+	} {
+		// Execute
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/api/subscribe", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+
+		testSettings.Router.ServeHTTP(w, req)
+
+		// Verify
+		assert.Equal(t, http.StatusBadRequest, w.Code, body)
+	}
+}
+
+func testDuplicateSubscribeOtherSpelling(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Setup
+	testSettings := setupTestEnvironment(t, ctrl)
+	defer testSettings.CleanupFunc()
+
+	testSettings.MockEmailPublisher.
+		EXPECT().
+		Publish(publisher.EmailConfirmationQueue, gomock.Any()).
+		Return(nil)
+
+	// Execute
+	codes := make([]int, 0, 2)
+	for _, city := range []string{"Kyiv", "Київ"} {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/api/subscribe", bytes.NewBufferString(
+			`{"email": "test@example.com", "city": "`+city+`", "frequency": "daily"}`,
+		))
+		req.Header.Set("Content-Type", "application/json")
+
+		testSettings.Router.ServeHTTP(w, req)
+		codes = append(codes, w.Code)
+	}
+
+	// Verify
+	assert.Equal(t, []int{http.StatusOK, http.StatusConflict}, codes)
+}
+
+func testSubscribeUnknownCity(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Setup
+	testSettings := setupTestEnvironment(t, ctrl)
+	defer testSettings.CleanupFunc()
+
+	// Execute
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/api/subscribe", bytes.NewBufferString(
+		`{"email": "test@example.com", "city": "Atlantis", "frequency": "daily"}`,
+	))
+	req.Header.Set("Content-Type", "application/json")
+
+	testSettings.Router.ServeHTTP(w, req)
+
+	// Verify
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	var count int
+	err := testSettings.TestDB.QueryRowx(`SELECT COUNT(*) FROM subscriptions`).Scan(&count)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, count, "no record should be created for unknown city")
+}
+
 func testSuccessfulSubscribeWithFailedEmail(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -214,6 +510,232 @@ func testInvalidSubscribeRequestBody(t *testing.T) {
 	assert.Equal(t, 0, count, "no record should be created for invalid request")
 }
 
+func testInvalidSubscribeUnits(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Setup
+	testSettings := setupTestEnvironment(t, ctrl)
+	defer testSettings.CleanupFunc()
+
+	// Execute
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/api/subscribe", bytes.NewBufferString(
+		`{"email": "test@example.com", "city": "Kyiv", "frequency": "daily", "units": "kelvin"}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	testSettings.Router.ServeHTTP(w, req)
+
+	// Verify
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func testSubscribeWithDeliveryTime(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Setup
+	testSettings := setupTestEnvironment(t, ctrl)
+	defer testSettings.CleanupFunc()
+
+	testSettings.MockEmailPublisher.
+		EXPECT().
+		Publish(publisher.EmailConfirmationQueue, gomock.Any()).
+		Return(nil)
+
+	// Execute
+	form := url.Values{
+		"email":         {"test@example.com"},
+		"city":          {"Kyiv"},
+		"frequency":     {"daily"},
+		"timezone":      {"America/New_York"},
+		"delivery_hour": {"0"},
+	}
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/api/subscribe", bytes.NewBufferString(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	testSettings.Router.ServeHTTP(w, req)
+
+	// Verify
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var sub domain.Subscription
+	err := testSettings.TestDB.QueryRowx(
+		`SELECT * FROM subscriptions WHERE email = $1`, "test@example.com",
+	).StructScan(&sub)
+	assert.NoError(t, err)
+	assert.Equal(t, "America/New_York", sub.Timezone)
+	assert.Equal(t, 0, sub.DeliveryHour)
+}
+
+func testInvalidSubscribeDeliveryTime(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Setup
+	testSettings := setupTestEnvironment(t, ctrl)
+	defer testSettings.CleanupFunc()
+
+	for _, body := range []string{
+		`{"email": "test@example.com", "city": "Kyiv", "frequency": "daily", "timezone": "Mars/Olympus_Mons"}`,
+		`{"email": "test@example.com", "city": "Kyiv", "frequency": "daily", "timezone": "Local"}`,
+		`{"email": "test@example.com", "city": "Kyiv", "frequency": "daily", "delivery_hour": 24}`,
+		`{"email": "test@example.com", "city": "Kyiv", "frequency": "daily", "delivery_hour": -1}`,
+	} {
+		// Execute
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/api/subscribe", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+
+		testSettings.Router.ServeHTTP(w, req)
+
+		// Verify
+		assert.Equal(t, http.StatusBadRequest, w.Code, body)
+	}
+}
+
+func subscribeAndGet(t *testing.T, testSettings subscriptionTestEnv, form url.Values) domain.Subscription {
+	t.Helper()
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/api/subscribe", bytes.NewBufferString(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	testSettings.Router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var sub domain.Subscription
+	err := testSettings.TestDB.QueryRowx(
+		`SELECT * FROM subscriptions WHERE email = $1`, form.Get("email"),
+	).StructScan(&sub)
+	assert.NoError(t, err)
+	return sub
+}
+
+func testSubscribeWithActiveWindow(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Setup
+	testSettings := setupTestEnvironment(t, ctrl)
+	defer testSettings.CleanupFunc()
+
+	testSettings.MockEmailPublisher.
+		EXPECT().
+		Publish(publisher.EmailConfirmationQueue, gomock.Any()).
+		Return(nil)
+
+	// Execute
+	sub := subscribeAndGet(t, testSettings, url.Values{
+		"email":         {"test@example.com"},
+		"city":          {"Kyiv"},
+		"frequency":     {"hourly"},
+		"active_from":   {"22:00"},
+		"active_to":     {"06:00"},
+		"weekdays_only": {"true"},
+	})
+
+	// Verify
+	assert.Equal(t, 22, sub.ActiveFrom)
+	assert.Equal(t, 6, sub.ActiveTo)
+	assert.True(t, sub.WeekdaysOnly)
+}
+
+func testSubscribeDailyIgnoresActiveWindow(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Setup
+	testSettings := setupTestEnvironment(t, ctrl)
+	defer testSettings.CleanupFunc()
+
+	testSettings.MockEmailPublisher.
+		EXPECT().
+		Publish(publisher.EmailConfirmationQueue, gomock.Any()).
+		Return(nil)
+
+	// Execute: the subscribe form always sends the window
+	sub := subscribeAndGet(t, testSettings, url.Values{
+		"email":         {"test@example.com"},
+		"city":          {"Kyiv"},
+		"frequency":     {"daily"},
+		"active_from":   {"07:00"},
+		"active_to":     {"22:00"},
+		"weekdays_only": {"true"},
+	})
+
+	// Verify
+	assert.Equal(t, domain.DefaultActiveFrom, sub.ActiveFrom)
+	assert.Equal(t, domain.DefaultActiveTo, sub.ActiveTo)
+	assert.False(t, sub.WeekdaysOnly)
+}
+
+func testInvalidSubscribeActiveWindow(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Setup
+	testSettings := setupTestEnvironment(t, ctrl)
+	defer testSettings.CleanupFunc()
+
+	for _, window := range [][2]string{
+		{"07:30", "22:00"},
+		{"7:00", "22:00"},
+		{"07:00", "24:00"},
+		{"morning", "22:00"},
+	} {
+		// Execute
+		form := url.Values{
+			"email":       {"test@example.com"},
+			"city":        {"Kyiv"},
+			"frequency":   {"hourly"},
+			"active_from": {window[0]},
+			"active_to":   {window[1]},
+		}
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/api/subscribe", bytes.NewBufferString(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		testSettings.Router.ServeHTTP(w, req)
+
+		// Verify
+		assert.Equal(t, http.StatusBadRequest, w.Code, window)
+	}
+}
+
+func testSubscribeActiveWindowNeverDue(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Setup
+	testSettings := setupTestEnvironment(t, ctrl)
+	defer testSettings.CleanupFunc()
+
+	// Execute: every 3 hours emails are sent at 06:00 and 09:00 UTC, not within the window
+	form := url.Values{
+		"email":       {"test@example.com"},
+		"city":        {"Kyiv"},
+		"frequency":   {"every_3h"},
+		"timezone":    {"UTC"},
+		"active_from": {"07:00"},
+		"active_to":   {"08:00"},
+	}
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/api/subscribe", bytes.NewBufferString(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	testSettings.Router.ServeHTTP(w, req)
+
+	// Verify
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	var count int
+	err := testSettings.TestDB.Get(&count, `SELECT COUNT(*) FROM subscriptions`)
+	assert.NoError(t, err)
+	assert.Zero(t, count)
+}
+
 func testDuplicateSubscribe(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -440,12 +962,17 @@ func testConfirmSuccess(t *testing.T) {
 	// Verify response
 	assert.Equal(t, http.StatusOK, w.Code)
 
-	// Verify it's confirmed in DB
+	// Verify it's confirmed in DB and scheduled at the next delivery hour
+	var nextRunAt *time.Time
 	err = testSettings.TestDB.QueryRowx(`
-        SELECT confirmed FROM subscriptions WHERE token = $1
-    `, token).Scan(&confirmed)
+        SELECT confirmed, next_run_at FROM subscriptions WHERE token = $1
+    `, token).Scan(&confirmed, &nextRunAt)
 	assert.NoError(t, err)
 	assert.True(t, confirmed, "subscription should be confirmed after request")
+	if assert.NotNil(t, nextRunAt, "subscription should be scheduled after request") {
+		assert.Equal(t, domain.DefaultDeliveryHour, nextRunAt.UTC().Hour())
+		assert.True(t, nextRunAt.After(time.Now()))
+	}
 }
 
 func testConfirmNotFound(t *testing.T) {
@@ -6,50 +6,178 @@ import (
 	"ms-weather-subscription/internal/domain"
 	customErrors "ms-weather-subscription/pkg/errors"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
 type Weather interface {
-	GetCurrentWeather(ctx context.Context, city string) (*domain.WeatherResponse, error)
-	GetDayWeather(ctx context.Context, city string) (*domain.DayWeatherResponse, error)
+	GetCurrentWeather(ctx context.Context, query domain.WeatherQuery) (*domain.WeatherResponse, error)
+	GetDayWeather(ctx context.Context, query domain.WeatherQuery) (*domain.DayWeatherResponse, error)
+	GetForecast(ctx context.Context, query domain.WeatherQuery, days int) (*domain.ForecastResponse, error)
+	GetAirQuality(ctx context.Context, query domain.WeatherQuery) (*domain.AirQualityResponse, error)
+}
+
+type WeatherHistory interface {
+	GetHistory(ctx context.Context, query domain.WeatherQuery, from, to time.Time) ([]domain.WeatherSnapshot, error)
 }
 
 type WeatherHandler struct {
 	weatherService Weather
+	historyService WeatherHistory
 }
 
-func NewWeatherHandler(weatherService Weather) *WeatherHandler {
+func NewWeatherHandler(weatherService Weather, historyService WeatherHistory) *WeatherHandler {
 	return &WeatherHandler{
 		weatherService: weatherService,
+		historyService: historyService,
+	}
+}
+
+// placeInput is the place weather is requested for: either a city or coordinates (both lat and lon).
+type placeInput struct {
+	City string   `form:"city" json:"city" binding:"max=255"`
+	Lat  *float64 `form:"lat" json:"lat"`
+	Lon  *float64 `form:"lon" json:"lon"`
+}
+
+// weatherQuery reports false if neither or both of city and coordinates are given,
+// or the coordinates are out of range.
+func (p placeInput) weatherQuery() (domain.WeatherQuery, bool) {
+	if p.Lat == nil && p.Lon == nil {
+		return domain.CityQuery(p.City), p.City != ""
 	}
+
+	if p.City != "" || p.Lat == nil || p.Lon == nil {
+		return domain.WeatherQuery{}, false
+	}
+
+	coordinates := domain.Coordinates{Latitude: *p.Lat, Longitude: *p.Lon}
+	return domain.CoordinatesQuery(coordinates), coordinates.IsValid()
 }
 
 type weatherResponse struct {
 	Temperature float32 `json:"temperature"`
 	Humidity    float32 `json:"humidity"`
 	Description string  `json:"description"`
+
+	FeelsLike                float32 `json:"feels_like"`
+	WindSpeed                float32 `json:"wind_speed"`
+	WindDirection            float32 `json:"wind_direction"`
+	WindGust                 float32 `json:"wind_gust"`
+	Precipitation            float32 `json:"precipitation"`
+	PrecipitationProbability float32 `json:"precipitation_probability"`
+	UVIndex                  float32 `json:"uv_index"`
+	Pressure                 float32 `json:"pressure"`
+	Visibility               float32 `json:"visibility"`
+	CloudCover               float32 `json:"cloud_cover"`
+
+	Stale      bool      `json:"stale,omitempty"`
+	ObservedAt time.Time `json:"observed_at,omitzero"`
 }
 
 // GetWeather godoc
-// @Summary Get current weather for a city
-// @Description Returns the current weather forecast for the specified city using WeatherAPI.com.
+// @Summary Get current weather for a city or coordinates
+// @Description Returns the current weather forecast for the specified city or coordinates using WeatherAPI.com.
+// @Description Either city or both lat and lon must be given.
+// @Description Besides temperature, humidity (%) and conditions it includes feels-like temperature,
+// @Description wind speed and gusts, wind direction (degrees), precipitation and its probability (%),
+// @Description UV index, sea level pressure, visibility and cloud cover (%).
+// @Description Values are in the requested units: metric (°C, km/h, mm, hPa, km),
+// @Description imperial (°F, mph, in, inHg, mi) or standard (K, m/s, mm, hPa, km).
+// @Description If all weather providers are unavailable, the last known weather is returned with stale flag
+// @Description and the time it was observed at.
 // @Tags weather
 // @Accept json
 // @Produce json
-// @Param city query string true "City name for weather forecast"
+// @Param city query string false "City name for weather forecast"
+// @Param lat query number false "Latitude in decimal degrees (-90 to 90)"
+// @Param lon query number false "Longitude in decimal degrees (-180 to 180)"
+// @Param units query string false "Units of the values" Enums(metric, imperial, standard) default(metric)
 // @Success 200 {object} weatherResponse
 // @Failure 400 "Invalid request"
 // @Failure 404 "City not found"
 // @Router /weather [get]
 func (h *WeatherHandler) GetWeather(c *gin.Context) {
-	city := c.Query("city")
-	if city == "" {
+	var inp placeInput
+	if err := c.ShouldBindQuery(&inp); err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	query, ok := inp.weatherQuery()
+	units := domain.Units(c.DefaultQuery("units", string(domain.UnitsMetric)))
+	if !ok || !units.IsValid() {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	weather, err := h.weatherService.GetCurrentWeather(c, query)
+	if err != nil {
+		switch {
+		case errors.Is(err, customErrors.ErrCityNotFound):
+			c.Status(http.StatusNotFound)
+		default:
+			c.Status(http.StatusInternalServerError)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, weatherResponse(*weather.InUnits(units)))
+}
+
+type forecastInput struct {
+	placeInput
+	Days int `form:"days" binding:"required"`
+}
+
+type dayForecastResponse struct {
+	Date                     string  `json:"date"`
+	MinTemperature           float32 `json:"min_temperature"`
+	MaxTemperature           float32 `json:"max_temperature"`
+	AvgTemperature           float32 `json:"avg_temperature"`
+	Humidity                 float32 `json:"humidity"`
+	PrecipitationProbability float32 `json:"precipitation_probability"`
+	Description              string  `json:"description"`
+}
+
+type forecastResponse struct {
+	Days []dayForecastResponse `json:"days"`
+}
+
+// GetForecast godoc
+// @Summary Get multi-day weather forecast for a city or coordinates
+// @Description Returns daily forecast for the specified city or coordinates starting from today:
+// @Description min, max and average temperature, humidity, precipitation probability and conditions.
+// @Description Either city or both lat and lon must be given.
+// @Description Temperatures are in the requested units: metric (°C), imperial (°F) or standard (K).
+// @Tags weather
+// @Accept json
+// @Produce json
+// @Param city query string false "City name for weather forecast"
+// @Param lat query number false "Latitude in decimal degrees (-90 to 90)"
+// @Param lon query number false "Longitude in decimal degrees (-180 to 180)"
+// @Param days query int true "Number of days to forecast (1-14)" minimum(1) maximum(14)
+// @Param units query string false "Units of the values" Enums(metric, imperial, standard) default(metric)
+// @Success 200 {object} forecastResponse
+// @Failure 400 "Invalid request"
+// @Failure 404 "City not found"
+// @Router /weather/forecast [get]
+func (h *WeatherHandler) GetForecast(c *gin.Context) {
+	var inp forecastInput
+	if err := c.ShouldBindQuery(&inp); err != nil || inp.Days < 1 || inp.Days > domain.MaxForecastDays {
 		c.Status(http.StatusBadRequest)
 		return
 	}
 
-	weather, err := h.weatherService.GetCurrentWeather(c, city)
+	query, ok := inp.weatherQuery()
+	units := domain.Units(c.DefaultQuery("units", string(domain.UnitsMetric)))
+	if !ok || !units.IsValid() {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	forecast, err := h.weatherService.GetForecast(c, query, inp.Days)
 	if err != nil {
 		switch {
 		case errors.Is(err, customErrors.ErrCityNotFound):
@@ -60,5 +188,142 @@ func (h *WeatherHandler) GetWeather(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, weatherResponse(*weather))
+	resp := forecastResponse{Days: make([]dayForecastResponse, 0, len(forecast.Days))}
+	for _, day := range forecast.InUnits(units).Days {
+		resp.Days = append(resp.Days, dayForecastResponse(day))
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+type airQualityResponse struct {
+	AQI  int     `json:"aqi"`
+	PM25 float32 `json:"pm2_5"`
+	PM10 float32 `json:"pm10"`
+	O3   float32 `json:"o3"`
+	NO2  float32 `json:"no2"`
+}
+
+// GetAirQuality godoc
+// @Summary Get current air quality for a city or coordinates
+// @Description Returns the US EPA air quality index (0-500) and concentrations (μg/m³) of fine (PM2.5)
+// @Description and coarse (PM10) particulate matter, ozone and nitrogen dioxide.
+// @Description Either city or both lat and lon must be given.
+// @Tags weather
+// @Accept json
+// @Produce json
+// @Param city query string false "City name for air quality"
+// @Param lat query number false "Latitude in decimal degrees (-90 to 90)"
+// @Param lon query number false "Longitude in decimal degrees (-180 to 180)"
+// @Success 200 {object} airQualityResponse
+// @Failure 400 "Invalid request"
+// @Failure 404 "City not found"
+// @Router /air-quality [get]
+func (h *WeatherHandler) GetAirQuality(c *gin.Context) {
+	var inp placeInput
+	if err := c.ShouldBindQuery(&inp); err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	query, ok := inp.weatherQuery()
+	if !ok {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	airQuality, err := h.weatherService.GetAirQuality(c, query)
+	if err != nil {
+		switch {
+		case errors.Is(err, customErrors.ErrCityNotFound):
+			c.Status(http.StatusNotFound)
+		default:
+			c.Status(http.StatusInternalServerError)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, airQualityResponse(*airQuality))
+}
+
+// weatherHistoryDefaultRange is the period the history is returned for if from isn't given.
+const weatherHistoryDefaultRange = 24 * time.Hour
+
+type weatherHistoryInput struct {
+	placeInput
+	From time.Time `form:"from"`
+	To   time.Time `form:"to"`
+}
+
+type weatherSnapshotResponse struct {
+	Provider   string          `json:"provider"`
+	ObservedAt time.Time       `json:"observed_at"`
+	Weather    weatherResponse `json:"weather"`
+}
+
+type weatherHistoryResponse struct {
+	Snapshots []weatherSnapshotResponse `json:"snapshots"`
+}
+
+// GetWeatherHistory godoc
+// @Summary Get weather history for a city or coordinates
+// @Description Returns the current weather received from weather providers for the city or coordinates,
+// @Description oldest first, together with the provider and the time it was received at. Either city
+// @Description or both lat and lon must be given. Different spellings of the city share the history
+// @Description of the location they resolve to, and nearby coordinates share the history of their grid cell.
+// @Description Weather is kept for the configured retention period. The period requested at once
+// @Description can't be longer than 31 days. By default the last 24 hours are returned.
+// @Tags weather
+// @Accept json
+// @Produce json
+// @Param city query string false "City name"
+// @Param lat query number false "Latitude in decimal degrees (-90 to 90)"
+// @Param lon query number false "Longitude in decimal degrees (-180 to 180)"
+// @Param from query string false "Start of the period (RFC 3339), 24 hours before to by default"
+// @Param to query string false "End of the period, exclusive (RFC 3339), now by default"
+// @Param units query string false "Units of the values" Enums(metric, imperial, standard) default(metric)
+// @Success 200 {object} weatherHistoryResponse
+// @Failure 400 "Invalid request"
+// @Failure 404 "City not found"
+// @Router /weather/history [get]
+func (h *WeatherHandler) GetWeatherHistory(c *gin.Context) {
+	var inp weatherHistoryInput
+	if err := c.ShouldBindQuery(&inp); err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	if inp.To.IsZero() {
+		inp.To = time.Now()
+	}
+	if inp.From.IsZero() {
+		inp.From = inp.To.Add(-weatherHistoryDefaultRange)
+	}
+
+	query, ok := inp.weatherQuery()
+	units := domain.Units(c.DefaultQuery("units", string(domain.UnitsMetric)))
+	if !ok || !inp.From.Before(inp.To) || inp.To.Sub(inp.From) > domain.MaxWeatherHistoryRange || !units.IsValid() {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	snapshots, err := h.historyService.GetHistory(c, query, inp.From, inp.To)
+	if err != nil {
+		switch {
+		case errors.Is(err, customErrors.ErrCityNotFound):
+			c.Status(http.StatusNotFound)
+		default:
+			c.Status(http.StatusInternalServerError)
+		}
+		return
+	}
+
+	resp := weatherHistoryResponse{Snapshots: make([]weatherSnapshotResponse, 0, len(snapshots))}
+	for _, snapshot := range snapshots {
+		resp.Snapshots = append(resp.Snapshots, weatherSnapshotResponse{
+			Provider:   snapshot.Provider,
+			ObservedAt: snapshot.ObservedAt.UTC(),
+			Weather:    weatherResponse(*snapshot.Weather.InUnits(units)),
+		})
+	}
+	c.JSON(http.StatusOK, resp)
 }
@@ -1,14 +1,20 @@
 package handlers_test
 
 import (
+	"context"
+	"ms-weather-subscription/internal/config"
+	"ms-weather-subscription/internal/domain"
 	"ms-weather-subscription/internal/handlers"
+	"ms-weather-subscription/internal/repository"
 	"ms-weather-subscription/internal/service"
+	"ms-weather-subscription/pkg/cache"
 	"ms-weather-subscription/pkg/clients"
 	"ms-weather-subscription/testutils"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
@@ -20,14 +26,39 @@ func TestWeather(t *testing.T) {
 		"Successful weather request with fallback to second client",
 		testSuccessfulWeatherRequestFallbackToSecondClient,
 	)
+	t.Run("Weather request in imperial units", testWeatherRequestInImperialUnits)
 	t.Run("Empty city parameter", testEmptyCityParameter)
+	t.Run("Weather request by coordinates", testWeatherRequestByCoordinates)
+	t.Run("Invalid coordinates", testInvalidCoordinates)
+	t.Run("Invalid units parameter", testInvalidUnitsParameter)
 	t.Run("City not found", testCityNotFound)
+	t.Run("Weather provider unavailable", testWeatherProviderUnavailable)
+}
+
+func TestForecast(t *testing.T) {
+	t.Run("Successful forecast request", testSuccessfulForecastRequest)
+	t.Run("Forecast request in imperial units", testForecastRequestInImperialUnits)
+	t.Run("Invalid days parameter", testForecastInvalidDays)
+}
+
+func TestWeatherHistory(t *testing.T) {
+	t.Run("Successful weather history request", testSuccessfulWeatherHistoryRequest)
+	t.Run("Weather history request by coordinates", testWeatherHistoryRequestByCoordinates)
+	t.Run("Invalid weather history parameters", testWeatherHistoryInvalidParameters)
+}
+
+func TestAirQuality(t *testing.T) {
+	t.Run("Successful air quality request", testSuccessfulAirQualityRequest)
+	t.Run("Missing place", testAirQualityMissingPlace)
 }
 
 func setupTestRouter(h *handlers.Handler) *gin.Engine {
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
 	router.GET("/api/weather", h.WeatherHandler.GetWeather)
+	router.GET("/api/weather/forecast", h.WeatherHandler.GetForecast)
+	router.GET("/api/weather/history", h.WeatherHandler.GetWeatherHistory)
+	router.GET("/api/air-quality", h.WeatherHandler.GetAirQuality)
 	return router
 }
 
@@ -38,6 +69,17 @@ func performRequest(router *gin.Engine, url string) *httptest.ResponseRecorder {
 	return w
 }
 
+// cityLocations resolves every city to a location of its own without querying providers.
+type cityLocations struct{}
+
+func (cityLocations) Resolve(_ context.Context, city string) (domain.Location, error) {
+	return domain.Location{ID: domain.NormalizeLocationQuery(city), Name: city}, nil
+}
+
+func (cityLocations) ResolveCoordinates(_ context.Context, coordinates domain.Coordinates) (domain.Location, error) {
+	return domain.NewCoordinatesLocation(coordinates, "UTC"), nil
+}
+
 func fakeNewWeatherAPIClient(fakePrimaryServer *httptest.Server) *clients.WeatherAPIClient {
 	return clients.NewWeatherAPIClient(
 		"dummy-key",
@@ -72,7 +114,16 @@ func testSuccessfulWeatherRequest(t *testing.T) {
 				"humidity": 70,
 				"condition": {
 					"text": "Sunny"
-				}
+				},
+				"feelslike_c": 26.1,
+				"wind_kph": 14.4,
+				"wind_degree": 90,
+				"gust_kph": 20.2,
+				"precip_mm": 0,
+				"pressure_mb": 1018,
+				"vis_km": 10,
+				"cloud": 25,
+				"uv": 6
 			}
 		}`))
 		if err != nil {
@@ -96,7 +147,7 @@ func testSuccessfulWeatherRequest(t *testing.T) {
 	redisCache := testutils.SetupTestCache(t)
 	cachingWeatherClient := clients.NewCachingWeatherClient(chainClient, redisCache)
 
-	weatherService := service.NewWeatherService(cachingWeatherClient)
+	weatherService := service.NewWeatherService(cachingWeatherClient, cityLocations{})
 	h := handlers.NewHandler(&service.Services{Weather: weatherService})
 
 	router := setupTestRouter(h)
@@ -106,7 +157,9 @@ func testSuccessfulWeatherRequest(t *testing.T) {
 	assert.Equal(t, http.StatusOK, w.Code)
 	assert.JSONEq(
 		t,
-		`{"temperature":25.4,"humidity":70,"description":"Sunny"}`,
+		`{"temperature":25.4,"humidity":70,"description":"Sunny","feels_like":26.1,"wind_speed":14.4,`+
+			`"wind_direction":90,"wind_gust":20.2,"precipitation":0,"precipitation_probability":0,"uv_index":6,`+
+			`"pressure":1018,"visibility":10,"cloud_cover":25}`,
 		strings.TrimSpace(w.Body.String()),
 	)
 }
@@ -148,7 +201,7 @@ func testSuccessfulWeatherRequestFallbackToSecondClient(t *testing.T) {
 	redisCache := testutils.SetupTestCache(t)
 	cachingWeatherClient := clients.NewCachingWeatherClient(chainClient, redisCache)
 
-	weatherService := service.NewWeatherService(cachingWeatherClient)
+	weatherService := service.NewWeatherService(cachingWeatherClient, cityLocations{})
 	h := handlers.NewHandler(&service.Services{Weather: weatherService})
 
 	router := setupTestRouter(h)
@@ -158,11 +211,74 @@ func testSuccessfulWeatherRequestFallbackToSecondClient(t *testing.T) {
 	// Assert fallback (VisualCrossing) result
 	assert.Equal(t, http.StatusOK, w.Code)
 	assert.JSONEq(t,
-		`{"temperature":18.5,"humidity":60,"description":"Partly cloudy"}`,
+		`{"temperature":18.5,"humidity":60,"description":"Partly cloudy","feels_like":0,"wind_speed":0,`+
+			`"wind_direction":0,"wind_gust":0,"precipitation":0,"precipitation_probability":0,"uv_index":0,`+
+			`"pressure":0,"visibility":0,"cloud_cover":0}`,
 		strings.TrimSpace(w.Body.String()),
 	)
 }
 
+func testWeatherRequestInImperialUnits(t *testing.T) {
+	primaryServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, err := w.Write([]byte(`{
+			"current": {
+				"temp_c": 25,
+				"feelslike_c": -40,
+				"humidity": 70,
+				"condition": {"text": "Sunny"},
+				"wind_kph": 16.09344,
+				"precip_mm": 25.4,
+				"pressure_mb": 1016,
+				"vis_km": 8.04672
+			}
+		}`))
+		if err != nil {
+			t.Fatalf("failed to write response: %v", err)
+		}
+	}))
+	defer primaryServer.Close()
+
+	client := fakeNewWeatherAPIClient(primaryServer)
+
+	redisCache := testutils.SetupTestCache(t)
+	cachingWeatherClient := clients.NewCachingWeatherClient(client, redisCache)
+
+	weatherService := service.NewWeatherService(cachingWeatherClient, cityLocations{})
+	h := handlers.NewHandler(&service.Services{Weather: weatherService})
+
+	router := setupTestRouter(h)
+
+	w := performRequest(router, "/api/weather?city=Kyiv&units=imperial")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t,
+		`{"temperature":77,"humidity":70,"description":"Sunny","feels_like":-40,"wind_speed":10,`+
+			`"wind_direction":0,"wind_gust":0,"precipitation":1,"precipitation_probability":0,"uv_index":0,`+
+			`"pressure":30,"visibility":5,"cloud_cover":0}`,
+		strings.TrimSpace(w.Body.String()),
+	)
+}
+
+func testInvalidUnitsParameter(t *testing.T) {
+	dummyServer := httptest.NewServer(http.NotFoundHandler())
+	defer dummyServer.Close()
+
+	client := fakeNewWeatherAPIClient(dummyServer)
+
+	redisCache := testutils.SetupTestCache(t)
+	cachingWeatherClient := clients.NewCachingWeatherClient(client, redisCache)
+
+	weatherService := service.NewWeatherService(cachingWeatherClient, cityLocations{})
+	h := handlers.NewHandler(&service.Services{Weather: weatherService})
+
+	router := setupTestRouter(h)
+
+	w := performRequest(router, "/api/weather?city=Kyiv&units=kelvin")
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
 func testEmptyCityParameter(t *testing.T) {
 	// Use dummy client that will never be called
 	dummyServer := httptest.NewServer(http.NotFoundHandler())
@@ -173,7 +289,7 @@ func testEmptyCityParameter(t *testing.T) {
 	redisCache := testutils.SetupTestCache(t)
 	cachingWeatherClient := clients.NewCachingWeatherClient(client, redisCache)
 
-	weatherService := service.NewWeatherService(cachingWeatherClient)
+	weatherService := service.NewWeatherService(cachingWeatherClient, cityLocations{})
 	h := handlers.NewHandler(&service.Services{Weather: weatherService})
 
 	router := setupTestRouter(h)
@@ -184,6 +300,59 @@ func testEmptyCityParameter(t *testing.T) {
 	assert.Empty(t, strings.TrimSpace(w.Body.String()))
 }
 
+func testWeatherRequestByCoordinates(t *testing.T) {
+	// Nearby coordinates are rounded to the same grid cell before they reach the provider
+	primaryServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "50.450000,30.520000", r.URL.Query().Get("q"))
+		w.WriteHeader(http.StatusOK)
+		_, err := w.Write([]byte(`{"current": {"temp_c": 18, "humidity": 40, "condition": {"text": "Sunny"}}}`))
+		if err != nil {
+			t.Fatalf("failed to write response: %v", err)
+		}
+	}))
+	defer primaryServer.Close()
+
+	redisCache := testutils.SetupTestCache(t)
+	cachingWeatherClient := clients.NewCachingWeatherClient(fakeNewWeatherAPIClient(primaryServer), redisCache)
+
+	weatherService := service.NewWeatherService(cachingWeatherClient, cityLocations{})
+	h := handlers.NewHandler(&service.Services{Weather: weatherService})
+
+	router := setupTestRouter(h)
+
+	w := performRequest(router, "/api/weather?lat=50.4547&lon=30.5238")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"temperature":18`)
+}
+
+func testInvalidCoordinates(t *testing.T) {
+	// Use dummy client that will never be called
+	dummyServer := httptest.NewServer(http.NotFoundHandler())
+	defer dummyServer.Close()
+
+	redisCache := testutils.SetupTestCache(t)
+	cachingWeatherClient := clients.NewCachingWeatherClient(fakeNewWeatherAPIClient(dummyServer), redisCache)
+
+	weatherService := service.NewWeatherService(cachingWeatherClient, cityLocations{})
+	h := handlers.NewHandler(&service.Services{Weather: weatherService})
+
+	router := setupTestRouter(h)
+
+	for _, url := range []string{
+		"/api/weather?lat=50.45",
+		"/api/weather?lon=30.52",
+		"/api/weather?lat=91&lon=30.52",
+		"/api/weather?lat=50.45&lon=-181",
+		"/api/weather?lat=north&lon=30.52",
+		"/api/weather?city=Kyiv&lat=50.45&lon=30.52",
+		"/api/weather/forecast?lat=50.45&days=3",
+	} {
+		w := performRequest(router, url)
+		assert.Equal(t, http.StatusBadRequest, w.Code, url)
+	}
+}
+
 func testCityNotFound(t *testing.T) {
 	// Fake WeatherAPI response (400 + code 1006)
 	primaryServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -218,7 +387,7 @@ func testCityNotFound(t *testing.T) {
 	redisCache := testutils.SetupTestCache(t)
 	cachingWeatherClient := clients.NewCachingWeatherClient(chainClient, redisCache)
 
-	weatherService := service.NewWeatherService(cachingWeatherClient)
+	weatherService := service.NewWeatherService(cachingWeatherClient, cityLocations{})
 	h := handlers.NewHandler(&service.Services{Weather: weatherService})
 
 	router := setupTestRouter(h)
@@ -228,3 +397,232 @@ func testCityNotFound(t *testing.T) {
 	assert.Equal(t, http.StatusNotFound, w.Code)
 	assert.Empty(t, strings.TrimSpace(w.Body.String()))
 }
+
+func testWeatherProviderUnavailable(t *testing.T) {
+	weatherService := service.NewWeatherService(testutils.SetupTestWeatherClient(t), cityLocations{})
+	h := handlers.NewHandler(&service.Services{Weather: weatherService})
+
+	router := setupTestRouter(h)
+
+	// The fake provider is down for Kharkiv
+	w := performRequest(router, "/api/weather?city=Kharkiv")
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func testSuccessfulForecastRequest(t *testing.T) {
+	weatherService := service.NewWeatherService(testutils.SetupTestWeatherClient(t), cityLocations{})
+	h := handlers.NewHandler(&service.Services{Weather: weatherService})
+
+	router := setupTestRouter(h)
+
+	w := performRequest(router, "/api/weather/forecast?city=Kyiv&days=1")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t,
+		`{"days":[{"date":"2025-05-17","min_temperature":12.4,"max_temperature":22.3,"avg_temperature":17.3,`+
+			`"humidity":63,"precipitation_probability":5,"description":"Sunny"}]}`,
+		strings.TrimSpace(w.Body.String()),
+	)
+}
+
+func testForecastRequestInImperialUnits(t *testing.T) {
+	weatherService := service.NewWeatherService(testutils.SetupTestWeatherClient(t), cityLocations{})
+	h := handlers.NewHandler(&service.Services{Weather: weatherService})
+
+	router := setupTestRouter(h)
+
+	w := performRequest(router, "/api/weather/forecast?city=Kyiv&days=1&units=imperial")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t,
+		`{"days":[{"date":"2025-05-17","min_temperature":54.32,"max_temperature":72.14,"avg_temperature":63.14,`+
+			`"humidity":63,"precipitation_probability":5,"description":"Sunny"}]}`,
+		strings.TrimSpace(w.Body.String()),
+	)
+}
+
+func testForecastInvalidDays(t *testing.T) {
+	// Use dummy client that will never be called
+	dummyServer := httptest.NewServer(http.NotFoundHandler())
+	defer dummyServer.Close()
+
+	client := fakeNewWeatherAPIClient(dummyServer)
+
+	redisCache := testutils.SetupTestCache(t)
+	cachingWeatherClient := clients.NewCachingWeatherClient(client, redisCache)
+
+	weatherService := service.NewWeatherService(cachingWeatherClient, cityLocations{})
+	h := handlers.NewHandler(&service.Services{Weather: weatherService})
+
+	router := setupTestRouter(h)
+
+	for _, url := range []string{
+		"/api/weather/forecast?city=Kyiv",
+		"/api/weather/forecast?city=Kyiv&days=0",
+		"/api/weather/forecast?city=Kyiv&days=15",
+		"/api/weather/forecast?city=Kyiv&days=week",
+		"/api/weather/forecast?days=3",
+		"/api/weather/forecast?city=Kyiv&days=3&units=kelvin",
+	} {
+		w := performRequest(router, url)
+		assert.Equal(t, http.StatusBadRequest, w.Code, url)
+	}
+}
+
+func testSuccessfulAirQualityRequest(t *testing.T) {
+	weatherService := service.NewWeatherService(testutils.SetupTestWeatherClient(t), cityLocations{})
+	h := handlers.NewHandler(&service.Services{Weather: weatherService})
+
+	router := setupTestRouter(h)
+
+	w := performRequest(router, "/api/air-quality?city=Kyiv")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t,
+		`{"aqi":42,"pm2_5":9.8,"pm10":17.2,"o3":55.1,"no2":11.4}`,
+		strings.TrimSpace(w.Body.String()),
+	)
+}
+
+func testAirQualityMissingPlace(t *testing.T) {
+	// Use dummy client that will never be called
+	dummyServer := httptest.NewServer(http.NotFoundHandler())
+	defer dummyServer.Close()
+
+	client := fakeNewWeatherAPIClient(dummyServer)
+
+	redisCache := testutils.SetupTestCache(t)
+	cachingWeatherClient := clients.NewCachingWeatherClient(client, redisCache)
+
+	weatherService := service.NewWeatherService(cachingWeatherClient, cityLocations{})
+	h := handlers.NewHandler(&service.Services{Weather: weatherService})
+
+	router := setupTestRouter(h)
+
+	for _, url := range []string{
+		"/api/air-quality",
+		"/api/air-quality?lat=50.45",
+		"/api/air-quality?city=Kyiv&lat=50.45&lon=30.52",
+	} {
+		w := performRequest(router, url)
+		assert.Equal(t, http.StatusBadRequest, w.Code, url)
+	}
+}
+
+func testSuccessfulWeatherHistoryRequest(t *testing.T) {
+	testDB := testutils.SetupTestDB(t)
+	defer func() {
+		if _, err := testDB.Exec(`DELETE FROM weather_snapshots; DELETE FROM locations;`); err != nil {
+			t.Fatalf("cleanup failed: could not delete weather snapshots: %v", err)
+		}
+	}()
+
+	repo := repository.NewWeatherSnapshotRepo(testDB)
+	morning := time.Date(2025, 5, 17, 7, 0, 0, 0, time.UTC)
+	for _, snapshot := range []domain.WeatherSnapshot{
+		{LocationID: kyiv.ID, Provider: "visualcrossing", ObservedAt: morning.Add(time.Hour),
+			Weather: domain.WeatherResponse{Temperature: 15, Description: "Cloudy"}},
+		{LocationID: kyiv.ID, Provider: "weatherapi", ObservedAt: morning,
+			Weather: domain.WeatherResponse{Temperature: 12, Description: "Sunny"}},
+		{LocationID: kyiv.ID, Provider: "weatherapi", ObservedAt: morning.Add(-24 * time.Hour),
+			Weather: domain.WeatherResponse{Temperature: 10, Description: "Rain"}},
+		{LocationID: "lviv,ukraine@50,24", Provider: "weatherapi", ObservedAt: morning,
+			Weather: domain.WeatherResponse{Temperature: 9, Description: "Fog"}},
+	} {
+		if err := repo.Create(context.Background(), snapshot); err != nil {
+			t.Fatalf("failed to create weather snapshot: %v", err)
+		}
+	}
+
+	locationService := service.NewLocationService(
+		repository.NewLocationRepo(testDB),
+		staticLocationSearcher{"kyiv": kyiv, "kiev": kyiv},
+		cache.NewMemoryCache(100),
+	)
+	historyService := service.NewWeatherHistoryService(
+		config.WeatherHistoryConfig{Retention: time.Hour}, repo, locationService,
+	)
+	h := handlers.NewHandler(&service.Services{WeatherHistory: historyService})
+
+	router := setupTestRouter(h)
+
+	// The snapshots of Kyiv are found by any spelling of the city
+	w := performRequest(router, "/api/weather/history?city=KIEV&from=2025-05-17T00:00:00Z&to=2025-05-18T00:00:00Z")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t,
+		`{"snapshots":[`+
+			`{"provider":"weatherapi","observed_at":"2025-05-17T07:00:00Z","weather":{"temperature":12,`+
+			`"humidity":0,"description":"Sunny","feels_like":0,"wind_speed":0,"wind_direction":0,"wind_gust":0,`+
+			`"precipitation":0,"precipitation_probability":0,"uv_index":0,"pressure":0,"visibility":0,`+
+			`"cloud_cover":0}},`+
+			`{"provider":"visualcrossing","observed_at":"2025-05-17T08:00:00Z","weather":{"temperature":15,`+
+			`"humidity":0,"description":"Cloudy","feels_like":0,"wind_speed":0,"wind_direction":0,"wind_gust":0,`+
+			`"precipitation":0,"precipitation_probability":0,"uv_index":0,"pressure":0,"visibility":0,`+
+			`"cloud_cover":0}}]}`,
+		strings.TrimSpace(w.Body.String()),
+	)
+}
+
+func testWeatherHistoryRequestByCoordinates(t *testing.T) {
+	testDB := testutils.SetupTestDB(t)
+	defer func() {
+		if _, err := testDB.Exec(`DELETE FROM weather_snapshots; DELETE FROM locations;`); err != nil {
+			t.Fatalf("cleanup failed: could not delete weather snapshots: %v", err)
+		}
+	}()
+
+	repo := repository.NewWeatherSnapshotRepo(testDB)
+	coordinates := domain.Coordinates{Latitude: 50.4501, Longitude: 30.5234}
+	err := repo.Create(context.Background(), domain.WeatherSnapshot{
+		LocationID:  domain.NewCoordinatesLocation(coordinates, "Europe/Kyiv").ID,
+		Coordinates: &coordinates,
+		Provider:    "openmeteo",
+		ObservedAt:  time.Date(2025, 5, 17, 7, 0, 0, 0, time.UTC),
+		Weather:     domain.WeatherResponse{Temperature: 12, Description: "Sunny"},
+	})
+	if err != nil {
+		t.Fatalf("failed to create weather snapshot: %v", err)
+	}
+
+	locationService := service.NewLocationService(
+		repository.NewLocationRepo(testDB), staticLocationSearcher{"kyiv": kyiv}, cache.NewMemoryCache(100),
+	)
+	historyService := service.NewWeatherHistoryService(
+		config.WeatherHistoryConfig{Retention: time.Hour}, repo, locationService,
+	)
+	h := handlers.NewHandler(&service.Services{WeatherHistory: historyService})
+
+	router := setupTestRouter(h)
+
+	// Nearby coordinates on the same grid cell share the history
+	w := performRequest(router,
+		"/api/weather/history?lat=50.4489&lon=30.5241&from=2025-05-17T00:00:00Z&to=2025-05-18T00:00:00Z")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"provider":"openmeteo","observed_at":"2025-05-17T07:00:00Z"`)
+}
+
+func testWeatherHistoryInvalidParameters(t *testing.T) {
+	// The history is never queried for invalid parameters.
+	historyService := service.NewWeatherHistoryService(config.WeatherHistoryConfig{}, nil, nil)
+	h := handlers.NewHandler(&service.Services{WeatherHistory: historyService})
+
+	router := setupTestRouter(h)
+
+	for _, url := range []string{
+		"/api/weather/history",
+		"/api/weather/history?from=2025-05-17T00:00:00Z",
+		"/api/weather/history?city=Kyiv&from=yesterday",
+		"/api/weather/history?city=Kyiv&from=2025-05-18T00:00:00Z&to=2025-05-17T00:00:00Z",
+		"/api/weather/history?city=Kyiv&from=2025-03-01T00:00:00Z&to=2025-05-17T00:00:00Z",
+		"/api/weather/history?city=Kyiv&units=kelvin",
+		"/api/weather/history?lat=50.45",
+		"/api/weather/history?city=Kyiv&lat=50.45&lon=30.52",
+		"/api/weather/history?lat=95&lon=30.52",
+	} {
+		w := performRequest(router, url)
+		assert.Equal(t, http.StatusBadRequest, w.Code, url)
+	}
+}
@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"ms-weather-subscription/internal/domain"
+	customErrors "ms-weather-subscription/pkg/errors"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type LocationRepo struct {
+	db *sqlx.DB
+}
+
+func NewLocationRepo(db *sqlx.DB) *LocationRepo {
+	return &LocationRepo{db: db}
+}
+
+func (r *LocationRepo) GetByAlias(ctx context.Context, alias string) (domain.Location, error) {
+	var location domain.Location
+
+	query := `
+		SELECT
+		l.id,
+		l.name,
+		l.country,
+		l.latitude,
+		l.longitude,
+		l.timezone
+		FROM locations l
+		JOIN location_aliases a ON a.location_id = l.id
+		WHERE a.alias = $1;`
+
+	err := r.db.QueryRowxContext(ctx, query, alias).StructScan(&location)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.Location{}, customErrors.ErrLocationNotFound
+		}
+
+		return domain.Location{}, err
+	}
+
+	return location, nil
+}
+
+// Save stores the location, refreshing it if it is already known, and the alias it was resolved from.
+func (r *LocationRepo) Save(ctx context.Context, location domain.Location, alias string) (err error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			err = errors.Join(err, tx.Rollback())
+		}
+	}()
+
+	query := `
+		INSERT INTO locations (id, name, country, latitude, longitude, timezone)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (id) DO UPDATE SET
+		name = EXCLUDED.name,
+		country = EXCLUDED.country,
+		latitude = EXCLUDED.latitude,
+		longitude = EXCLUDED.longitude,
+		timezone = EXCLUDED.timezone;`
+	_, err = tx.ExecContext(
+		ctx,
+		query,
+		location.ID,
+		location.Name,
+		location.Country,
+		location.Latitude,
+		location.Longitude,
+		location.Timezone,
+	)
+	if err != nil {
+		return err
+	}
+
+	query = `
+		INSERT INTO location_aliases (alias, location_id)
+		VALUES ($1, $2)
+		ON CONFLICT (alias) DO NOTHING;`
+	if _, err = tx.ExecContext(ctx, query, alias, location.ID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,139 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	customErrors "ms-weather-subscription/pkg/errors"
+	"ms-weather-subscription/testutils"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+
+	"ms-weather-subscription/internal/domain"
+	"ms-weather-subscription/internal/repository"
+)
+
+func TestLocationRepo(t *testing.T) {
+	t.Run("GetByAlias", testLocationRepoGetByAlias)
+	t.Run("GetByAlias Not Found", testLocationRepoGetByAliasNotFound)
+	t.Run("Save", testLocationRepoSave)
+	t.Run("Save Error", testLocationRepoSaveError)
+}
+
+var kyiv = domain.Location{
+	ID:        "kyiv,ukraine@50,31",
+	Name:      "Kyiv",
+	Country:   "Ukraine",
+	Latitude:  50.45466,
+	Longitude: 30.5238,
+	Timezone:  "Europe/Kyiv",
+}
+
+func testLocationRepoGetByAlias(t *testing.T) {
+	t.Parallel()
+
+	db, mock := testutils.SetupMockDB(t)
+	defer func() {
+		mock.ExpectClose()
+		err := db.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	repo := repository.NewLocationRepo(db)
+
+	rows := sqlmock.NewRows([]string{
+		"id", "name", "country", "latitude", "longitude", "timezone",
+	}).AddRow(kyiv.ID, kyiv.Name, kyiv.Country, kyiv.Latitude, kyiv.Longitude, kyiv.Timezone)
+
+	mock.ExpectQuery("SELECT .* FROM locations .* WHERE a.alias =").
+		WithArgs("kiev").
+		WillReturnRows(rows)
+
+	got, err := repo.GetByAlias(context.Background(), "kiev")
+	assert.NoError(t, err)
+	assert.Equal(t, kyiv, got)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func testLocationRepoGetByAliasNotFound(t *testing.T) {
+	t.Parallel()
+
+	db, mock := testutils.SetupMockDB(t)
+	defer func() {
+		mock.ExpectClose()
+		err := db.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	repo := repository.NewLocationRepo(db)
+
+	mock.ExpectQuery("SELECT .* FROM locations .* WHERE a.alias =").
+		WithArgs("atlantis").
+		WillReturnError(sql.ErrNoRows)
+
+	_, err := repo.GetByAlias(context.Background(), "atlantis")
+	assert.ErrorIs(t, err, customErrors.ErrLocationNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func testLocationRepoSave(t *testing.T) {
+	t.Parallel()
+
+	db, mock := testutils.SetupMockDB(t)
+	defer func() {
+		mock.ExpectClose()
+		err := db.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	repo := repository.NewLocationRepo(db)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO locations").
+		WithArgs(kyiv.ID, kyiv.Name, kyiv.Country, kyiv.Latitude, kyiv.Longitude, kyiv.Timezone).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO location_aliases").
+		WithArgs("kiev", kyiv.ID).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	err := repo.Save(context.Background(), kyiv, "kiev")
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func testLocationRepoSaveError(t *testing.T) {
+	t.Parallel()
+
+	db, mock := testutils.SetupMockDB(t)
+	defer func() {
+		mock.ExpectClose()
+		err := db.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	repo := repository.NewLocationRepo(db)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO locations").
+		WithArgs(kyiv.ID, kyiv.Name, kyiv.Country, kyiv.Latitude, kyiv.Longitude, kyiv.Timezone).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO location_aliases").
+		WithArgs("kiev", kyiv.ID).
+		WillReturnError(errors.New("some db error"))
+	mock.ExpectRollback()
+
+	err := repo.Save(context.Background(), kyiv, "kiev")
+	assert.ErrorContains(t, err, "some db error")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
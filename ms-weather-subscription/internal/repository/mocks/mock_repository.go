@@ -12,7 +12,9 @@ package mock_repository
 import (
 	context "context"
 	domain "ms-weather-subscription/internal/domain"
+	lock "ms-weather-subscription/pkg/lock"
 	reflect "reflect"
+	time "time"
 
 	gomock "go.uber.org/mock/gomock"
 )
@@ -41,18 +43,33 @@ func (m *MockSubscriptionRepository) EXPECT() *MockSubscriptionRepositoryMockRec
 	return m.recorder
 }
 
+// ClaimDue mocks base method.
+func (m *MockSubscriptionRepository) ClaimDue(ctx context.Context, now time.Time, limit int, claimTTL time.Duration, claimToken int64) ([]domain.Subscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimDue", ctx, now, limit, claimTTL, claimToken)
+	ret0, _ := ret[0].([]domain.Subscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimDue indicates an expected call of ClaimDue.
+func (mr *MockSubscriptionRepositoryMockRecorder) ClaimDue(ctx, now, limit, claimTTL, claimToken any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimDue", reflect.TypeOf((*MockSubscriptionRepository)(nil).ClaimDue), ctx, now, limit, claimTTL, claimToken)
+}
+
 // Confirm mocks base method.
-func (m *MockSubscriptionRepository) Confirm(ctx context.Context, token string) error {
+func (m *MockSubscriptionRepository) Confirm(ctx context.Context, token string, nextRunAt *time.Time) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Confirm", ctx, token)
+	ret := m.ctrl.Call(m, "Confirm", ctx, token, nextRunAt)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Confirm indicates an expected call of Confirm.
-func (mr *MockSubscriptionRepositoryMockRecorder) Confirm(ctx, token any) *gomock.Call {
+func (mr *MockSubscriptionRepositoryMockRecorder) Confirm(ctx, token, nextRunAt any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Confirm", reflect.TypeOf((*MockSubscriptionRepository)(nil).Confirm), ctx, token)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Confirm", reflect.TypeOf((*MockSubscriptionRepository)(nil).Confirm), ctx, token, nextRunAt)
 }
 
 // Create mocks base method.
@@ -112,3 +129,206 @@ func (mr *MockSubscriptionRepositoryMockRecorder) GetConfirmedByFrequency(ctx, f
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetConfirmedByFrequency", reflect.TypeOf((*MockSubscriptionRepository)(nil).GetConfirmedByFrequency), ctx, frequency)
 }
+
+// Reschedule mocks base method.
+func (m *MockSubscriptionRepository) Reschedule(ctx context.Context, id string, nextRunAt *time.Time, claimToken int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reschedule", ctx, id, nextRunAt, claimToken)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Reschedule indicates an expected call of Reschedule.
+func (mr *MockSubscriptionRepositoryMockRecorder) Reschedule(ctx, id, nextRunAt, claimToken any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reschedule", reflect.TypeOf((*MockSubscriptionRepository)(nil).Reschedule), ctx, id, nextRunAt, claimToken)
+}
+
+// MockLocationRepository is a mock of LocationRepository interface.
+type MockLocationRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockLocationRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockLocationRepositoryMockRecorder is the mock recorder for MockLocationRepository.
+type MockLocationRepositoryMockRecorder struct {
+	mock *MockLocationRepository
+}
+
+// NewMockLocationRepository creates a new mock instance.
+func NewMockLocationRepository(ctrl *gomock.Controller) *MockLocationRepository {
+	mock := &MockLocationRepository{ctrl: ctrl}
+	mock.recorder = &MockLocationRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLocationRepository) EXPECT() *MockLocationRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetByAlias mocks base method.
+func (m *MockLocationRepository) GetByAlias(ctx context.Context, alias string) (domain.Location, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByAlias", ctx, alias)
+	ret0, _ := ret[0].(domain.Location)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByAlias indicates an expected call of GetByAlias.
+func (mr *MockLocationRepositoryMockRecorder) GetByAlias(ctx, alias any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByAlias", reflect.TypeOf((*MockLocationRepository)(nil).GetByAlias), ctx, alias)
+}
+
+// Save mocks base method.
+func (m *MockLocationRepository) Save(ctx context.Context, location domain.Location, alias string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Save", ctx, location, alias)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Save indicates an expected call of Save.
+func (mr *MockLocationRepositoryMockRecorder) Save(ctx, location, alias any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockLocationRepository)(nil).Save), ctx, location, alias)
+}
+
+// MockSentAlertRepository is a mock of SentAlertRepository interface.
+type MockSentAlertRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockSentAlertRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockSentAlertRepositoryMockRecorder is the mock recorder for MockSentAlertRepository.
+type MockSentAlertRepositoryMockRecorder struct {
+	mock *MockSentAlertRepository
+}
+
+// NewMockSentAlertRepository creates a new mock instance.
+func NewMockSentAlertRepository(ctrl *gomock.Controller) *MockSentAlertRepository {
+	mock := &MockSentAlertRepository{ctrl: ctrl}
+	mock.recorder = &MockSentAlertRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSentAlertRepository) EXPECT() *MockSentAlertRepositoryMockRecorder {
+	return m.recorder
+}
+
+// DeleteExpired mocks base method.
+func (m *MockSentAlertRepository) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpired", ctx, before)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteExpired indicates an expected call of DeleteExpired.
+func (mr *MockSentAlertRepositoryMockRecorder) DeleteExpired(ctx, before any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpired", reflect.TypeOf((*MockSentAlertRepository)(nil).DeleteExpired), ctx, before)
+}
+
+// MarkSent mocks base method.
+func (m *MockSentAlertRepository) MarkSent(ctx context.Context, subscriptionID, alertID string, expiresAt time.Time, token lock.Token) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkSent", ctx, subscriptionID, alertID, expiresAt, token)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarkSent indicates an expected call of MarkSent.
+func (mr *MockSentAlertRepositoryMockRecorder) MarkSent(ctx, subscriptionID, alertID, expiresAt, token any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkSent", reflect.TypeOf((*MockSentAlertRepository)(nil).MarkSent), ctx, subscriptionID, alertID, expiresAt, token)
+}
+
+// UnmarkSent mocks base method.
+func (m *MockSentAlertRepository) UnmarkSent(ctx context.Context, subscriptionID, alertID string, token lock.Token) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnmarkSent", ctx, subscriptionID, alertID, token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnmarkSent indicates an expected call of UnmarkSent.
+func (mr *MockSentAlertRepositoryMockRecorder) UnmarkSent(ctx, subscriptionID, alertID, token any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnmarkSent", reflect.TypeOf((*MockSentAlertRepository)(nil).UnmarkSent), ctx, subscriptionID, alertID, token)
+}
+
+// MockWeatherSnapshotRepository is a mock of WeatherSnapshotRepository interface.
+type MockWeatherSnapshotRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockWeatherSnapshotRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockWeatherSnapshotRepositoryMockRecorder is the mock recorder for MockWeatherSnapshotRepository.
+type MockWeatherSnapshotRepositoryMockRecorder struct {
+	mock *MockWeatherSnapshotRepository
+}
+
+// NewMockWeatherSnapshotRepository creates a new mock instance.
+func NewMockWeatherSnapshotRepository(ctrl *gomock.Controller) *MockWeatherSnapshotRepository {
+	mock := &MockWeatherSnapshotRepository{ctrl: ctrl}
+	mock.recorder = &MockWeatherSnapshotRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWeatherSnapshotRepository) EXPECT() *MockWeatherSnapshotRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockWeatherSnapshotRepository) Create(ctx context.Context, snapshot domain.WeatherSnapshot) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, snapshot)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockWeatherSnapshotRepositoryMockRecorder) Create(ctx, snapshot any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockWeatherSnapshotRepository)(nil).Create), ctx, snapshot)
+}
+
+// DeleteObservedBefore mocks base method.
+func (m *MockWeatherSnapshotRepository) DeleteObservedBefore(ctx context.Context, before time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteObservedBefore", ctx, before)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteObservedBefore indicates an expected call of DeleteObservedBefore.
+func (mr *MockWeatherSnapshotRepositoryMockRecorder) DeleteObservedBefore(ctx, before any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteObservedBefore", reflect.TypeOf((*MockWeatherSnapshotRepository)(nil).DeleteObservedBefore), ctx, before)
+}
+
+// GetByLocation mocks base method.
+func (m *MockWeatherSnapshotRepository) GetByLocation(ctx context.Context, locationID string, from, to time.Time) ([]domain.WeatherSnapshot, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByLocation", ctx, locationID, from, to)
+	ret0, _ := ret[0].([]domain.WeatherSnapshot)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByLocation indicates an expected call of GetByLocation.
+func (mr *MockWeatherSnapshotRepositoryMockRecorder) GetByLocation(ctx, locationID, from, to any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByLocation", reflect.TypeOf((*MockWeatherSnapshotRepository)(nil).GetByLocation), ctx, locationID, from, to)
+}
@@ -3,6 +3,8 @@ package repository
 import (
 	"context"
 	"ms-weather-subscription/internal/domain"
+	"ms-weather-subscription/pkg/lock"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 )
@@ -12,17 +14,46 @@ import (
 type SubscriptionRepository interface {
 	Create(ctx context.Context, subscription domain.Subscription) error
 	GetByToken(ctx context.Context, token string) (domain.Subscription, error)
-	Confirm(ctx context.Context, token string) error
+	Confirm(ctx context.Context, token string, nextRunAt *time.Time) error
 	Delete(ctx context.Context, token string) error
 	GetConfirmedByFrequency(ctx context.Context, frequency string) ([]domain.Subscription, error)
+	ClaimDue(
+		ctx context.Context, now time.Time, limit int, claimTTL time.Duration, claimToken int64,
+	) ([]domain.Subscription, error)
+	Reschedule(ctx context.Context, id string, nextRunAt *time.Time, claimToken int64) error
+}
+
+type LocationRepository interface {
+	GetByAlias(ctx context.Context, alias string) (domain.Location, error)
+	Save(ctx context.Context, location domain.Location, alias string) error
+}
+
+type SentAlertRepository interface {
+	MarkSent(
+		ctx context.Context, subscriptionID, alertID string, expiresAt time.Time, token lock.Token,
+	) (bool, error)
+	UnmarkSent(ctx context.Context, subscriptionID, alertID string, token lock.Token) error
+	DeleteExpired(ctx context.Context, before time.Time) (int64, error)
+}
+
+type WeatherSnapshotRepository interface {
+	Create(ctx context.Context, snapshot domain.WeatherSnapshot) error
+	GetByLocation(ctx context.Context, locationID string, from, to time.Time) ([]domain.WeatherSnapshot, error)
+	DeleteObservedBefore(ctx context.Context, before time.Time) (int64, error)
 }
 
 type Repositories struct {
-	Subscription SubscriptionRepository
+	Subscription    SubscriptionRepository
+	Location        LocationRepository
+	SentAlert       SentAlertRepository
+	WeatherSnapshot WeatherSnapshotRepository
 }
 
 func NewRepositories(db *sqlx.DB) *Repositories {
 	return &Repositories{
-		Subscription: NewSubscriptionRepo(db),
+		Subscription:    NewSubscriptionRepo(db),
+		Location:        NewLocationRepo(db),
+		SentAlert:       NewSentAlertRepo(db),
+		WeatherSnapshot: NewWeatherSnapshotRepo(db),
 	}
 }
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"ms-weather-subscription/pkg/lock"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type SentAlertRepo struct {
+	db *sqlx.DB
+}
+
+func NewSentAlertRepo(db *sqlx.DB) *SentAlertRepo {
+	return &SentAlertRepo{db: db}
+}
+
+// fenceQuery records the fencing token $4 of the lock $3 unless a greater token was recorded,
+// it returns a row only if the token isn't stale.
+const fenceQuery = `
+	fence AS (
+		INSERT INTO fencing_tokens (key, token)
+		VALUES ($3, $4)
+		ON CONFLICT (key) DO UPDATE SET token = EXCLUDED.token
+		WHERE fencing_tokens.token <= EXCLUDED.token
+		RETURNING token
+	)`
+
+// MarkSent records that the alert is sent to the subscription under the lock of token,
+// the mark is kept until expiresAt. It reports false if the alert was already marked as sent
+// and lock.ErrStaleToken if the token is older than the latest one written with.
+func (r *SentAlertRepo) MarkSent(
+	ctx context.Context, subscriptionID, alertID string, expiresAt time.Time, token lock.Token,
+) (bool, error) {
+	query := `
+		WITH` + fenceQuery + `,
+		inserted AS (
+			INSERT INTO sent_alerts (subscription_id, alert_id, expires_at)
+			SELECT $1, $2, $5 FROM fence
+			ON CONFLICT (subscription_id, alert_id) DO NOTHING
+			RETURNING alert_id
+		)
+		SELECT EXISTS (SELECT 1 FROM fence), EXISTS (SELECT 1 FROM inserted);`
+
+	var fenced, inserted bool
+	err := r.db.QueryRowxContext(ctx, query, subscriptionID, alertID, token.Key, token.Value, expiresAt).
+		Scan(&fenced, &inserted)
+	if err != nil {
+		return false, err
+	}
+	if !fenced {
+		return false, lock.ErrStaleToken
+	}
+	return inserted, nil
+}
+
+// UnmarkSent forgets the alert was sent to the subscription, so it is sent on the next poll.
+// Like MarkSent, it returns lock.ErrStaleToken if the token is older than the latest one.
+func (r *SentAlertRepo) UnmarkSent(ctx context.Context, subscriptionID, alertID string, token lock.Token) error {
+	query := `
+		WITH` + fenceQuery + `,
+		deleted AS (
+			DELETE FROM sent_alerts
+			WHERE subscription_id = $1 AND alert_id = $2 AND EXISTS (SELECT 1 FROM fence)
+		)
+		SELECT EXISTS (SELECT 1 FROM fence);`
+
+	var fenced bool
+	err := r.db.QueryRowxContext(ctx, query, subscriptionID, alertID, token.Key, token.Value).Scan(&fenced)
+	if err != nil {
+		return err
+	}
+	if !fenced {
+		return lock.ErrStaleToken
+	}
+	return nil
+}
+
+// DeleteExpired deletes the marks of the alerts expired before the given time and returns how many were deleted.
+func (r *SentAlertRepo) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	query := "DELETE FROM sent_alerts WHERE expires_at < $1;"
+	result, err := r.db.ExecContext(ctx, query, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
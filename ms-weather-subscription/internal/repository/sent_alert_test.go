@@ -0,0 +1,177 @@
+package repository_test
+
+import (
+	"context"
+	"ms-weather-subscription/internal/repository"
+	"ms-weather-subscription/pkg/lock"
+	"ms-weather-subscription/testutils"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSentAlertRepo(t *testing.T) {
+	t.Run("MarkSent", testSentAlertRepoMarkSent)
+	t.Run("MarkSent already sent", testSentAlertRepoMarkSentAlreadySent)
+	t.Run("MarkSent stale token", testSentAlertRepoMarkSentStaleToken)
+	t.Run("UnmarkSent", testSentAlertRepoUnmarkSent)
+	t.Run("UnmarkSent stale token", testSentAlertRepoUnmarkSentStaleToken)
+	t.Run("DeleteExpired", testSentAlertRepoDeleteExpired)
+}
+
+const (
+	alertSubscriptionID = "68501cb6-0bf0-800e-81ba-bae3763ecdd2"
+	alertID             = "weatherapi:2f1b7c9e04d3a8b6"
+)
+
+var (
+	alertToken   = lock.Token{Key: "cron:alerts_weather_email_sending", Value: 7}
+	alertExpires = time.Date(2025, 5, 17, 18, 0, 0, 0, time.UTC)
+)
+
+func testSentAlertRepoMarkSent(t *testing.T) {
+	t.Parallel()
+
+	db, mock := testutils.SetupMockDB(t)
+	defer func() {
+		mock.ExpectClose()
+		err := db.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	repo := repository.NewSentAlertRepo(db)
+
+	mock.ExpectQuery("INSERT INTO fencing_tokens .* WHERE fencing_tokens.token <= EXCLUDED.token .* "+
+		"INSERT INTO sent_alerts .* SELECT \\$1, \\$2, \\$5 FROM fence ON CONFLICT .* DO NOTHING").
+		WithArgs(alertSubscriptionID, alertID, alertToken.Key, alertToken.Value, alertExpires).
+		WillReturnRows(sqlmock.NewRows([]string{"fenced", "inserted"}).AddRow(true, true))
+
+	marked, err := repo.MarkSent(context.Background(), alertSubscriptionID, alertID, alertExpires, alertToken)
+	assert.NoError(t, err)
+	assert.True(t, marked)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func testSentAlertRepoMarkSentAlreadySent(t *testing.T) {
+	t.Parallel()
+
+	db, mock := testutils.SetupMockDB(t)
+	defer func() {
+		mock.ExpectClose()
+		err := db.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	repo := repository.NewSentAlertRepo(db)
+
+	mock.ExpectQuery("INSERT INTO sent_alerts").
+		WithArgs(alertSubscriptionID, alertID, alertToken.Key, alertToken.Value, alertExpires).
+		WillReturnRows(sqlmock.NewRows([]string{"fenced", "inserted"}).AddRow(true, false))
+
+	marked, err := repo.MarkSent(context.Background(), alertSubscriptionID, alertID, alertExpires, alertToken)
+	assert.NoError(t, err)
+	assert.False(t, marked, "alert sent before must not be marked again")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func testSentAlertRepoMarkSentStaleToken(t *testing.T) {
+	t.Parallel()
+
+	db, mock := testutils.SetupMockDB(t)
+	defer func() {
+		mock.ExpectClose()
+		err := db.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	repo := repository.NewSentAlertRepo(db)
+
+	mock.ExpectQuery("INSERT INTO sent_alerts").
+		WithArgs(alertSubscriptionID, alertID, alertToken.Key, alertToken.Value, alertExpires).
+		WillReturnRows(sqlmock.NewRows([]string{"fenced", "inserted"}).AddRow(false, false))
+
+	marked, err := repo.MarkSent(context.Background(), alertSubscriptionID, alertID, alertExpires, alertToken)
+	assert.ErrorIs(t, err, lock.ErrStaleToken)
+	assert.False(t, marked)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func testSentAlertRepoUnmarkSent(t *testing.T) {
+	t.Parallel()
+
+	db, mock := testutils.SetupMockDB(t)
+	defer func() {
+		mock.ExpectClose()
+		err := db.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	repo := repository.NewSentAlertRepo(db)
+
+	mock.ExpectQuery("INSERT INTO fencing_tokens .* DELETE FROM sent_alerts "+
+		"WHERE subscription_id = \\$1 AND alert_id = \\$2 AND EXISTS \\(SELECT 1 FROM fence\\)").
+		WithArgs(alertSubscriptionID, alertID, alertToken.Key, alertToken.Value).
+		WillReturnRows(sqlmock.NewRows([]string{"fenced"}).AddRow(true))
+
+	err := repo.UnmarkSent(context.Background(), alertSubscriptionID, alertID, alertToken)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func testSentAlertRepoUnmarkSentStaleToken(t *testing.T) {
+	t.Parallel()
+
+	db, mock := testutils.SetupMockDB(t)
+	defer func() {
+		mock.ExpectClose()
+		err := db.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	repo := repository.NewSentAlertRepo(db)
+
+	mock.ExpectQuery("DELETE FROM sent_alerts").
+		WithArgs(alertSubscriptionID, alertID, alertToken.Key, alertToken.Value).
+		WillReturnRows(sqlmock.NewRows([]string{"fenced"}).AddRow(false))
+
+	err := repo.UnmarkSent(context.Background(), alertSubscriptionID, alertID, alertToken)
+	assert.ErrorIs(t, err, lock.ErrStaleToken)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func testSentAlertRepoDeleteExpired(t *testing.T) {
+	t.Parallel()
+
+	db, mock := testutils.SetupMockDB(t)
+	defer func() {
+		mock.ExpectClose()
+		err := db.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	repo := repository.NewSentAlertRepo(db)
+
+	now := time.Date(2025, 5, 17, 3, 30, 0, 0, time.UTC)
+	mock.ExpectExec("DELETE FROM sent_alerts WHERE expires_at < \\$1").
+		WithArgs(now).
+		WillReturnResult(sqlmock.NewResult(0, 4))
+
+	deleted, err := repo.DeleteExpired(context.Background(), now)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), deleted)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -6,8 +6,11 @@ import (
 	"errors"
 	"ms-weather-subscription/internal/domain"
 	customErrors "ms-weather-subscription/pkg/errors"
+	"ms-weather-subscription/pkg/lock"
+	"time"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
 type SubscriptionRepo struct {
@@ -20,8 +23,11 @@ func NewSubscriptionRepo(db *sqlx.DB) *SubscriptionRepo {
 
 func (r *SubscriptionRepo) Create(ctx context.Context, subscription domain.Subscription) error {
 	query := `
-		INSERT INTO subscriptions (created_at, email, city, token, frequency, confirmed) 
-		values ($1, $2, $3, $4, $5, $6);`
+		INSERT INTO subscriptions (
+			created_at, email, city, token, frequency, confirmed, units, air_quality, timezone, delivery_hour,
+			active_from, active_to, weekdays_only, location_id
+		)
+		values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14);`
 	_, err := r.db.ExecContext(
 		ctx,
 		query,
@@ -31,6 +37,14 @@ func (r *SubscriptionRepo) Create(ctx context.Context, subscription domain.Subsc
 		subscription.Token,
 		subscription.Frequency,
 		subscription.Confirmed,
+		subscription.Units,
+		subscription.AirQuality,
+		subscription.Timezone,
+		subscription.DeliveryHour,
+		subscription.ActiveFrom,
+		subscription.ActiveTo,
+		subscription.WeekdaysOnly,
+		subscription.LocationID,
 	)
 	if err != nil {
 		if customErrors.IsDuplicateDBError(err) {
@@ -51,7 +65,15 @@ func (r *SubscriptionRepo) GetByToken(ctx context.Context, token string) (domain
 		city,
 		token,
 		frequency,
-		confirmed
+		confirmed,
+		units,
+		air_quality,
+		timezone,
+		delivery_hour,
+		active_from,
+		active_to,
+		weekdays_only,
+		location_id
 		FROM subscriptions
 		WHERE token = $1;`
 
@@ -67,9 +89,10 @@ func (r *SubscriptionRepo) GetByToken(ctx context.Context, token string) (domain
 	return subscription, nil
 }
 
-func (r *SubscriptionRepo) Confirm(ctx context.Context, token string) error {
-	query := "UPDATE subscriptions SET confirmed = true WHERE token = $1;"
-	_, err := r.db.ExecContext(ctx, query, token)
+// Confirm confirms the subscription and schedules its first email at nextRunAt, nil means it isn't scheduled.
+func (r *SubscriptionRepo) Confirm(ctx context.Context, token string, nextRunAt *time.Time) error {
+	query := "UPDATE subscriptions SET confirmed = true, next_run_at = $2 WHERE token = $1;"
+	_, err := r.db.ExecContext(ctx, query, token, nextRunAt)
 	return err
 }
 
@@ -86,17 +109,117 @@ func (r *SubscriptionRepo) GetConfirmedByFrequency(
 
 	query := `
 		SELECT     
-		id,
-		created_at,
-		email,
-		city,
-		token,
-		frequency,
-		confirmed
-		FROM subscriptions
-		WHERE confirmed = true AND frequency = $1;`
+		s.id,
+		s.created_at,
+		s.email,
+		s.city,
+		s.token,
+		s.frequency,
+		s.confirmed,
+		s.units,
+		s.air_quality,
+		s.timezone,
+		s.delivery_hour,
+		s.active_from,
+		s.active_to,
+		s.weekdays_only,
+		s.location_id,
+		l.latitude,
+		l.longitude
+		FROM subscriptions s
+		LEFT JOIN locations l ON l.id = s.location_id
+		WHERE s.confirmed = true AND s.frequency = $1;`
 
 	err := r.db.SelectContext(ctx, &subscriptions, query, frequency)
 
 	return subscriptions, err
 }
+
+// ClaimDue claims up to limit confirmed subscriptions whose next run is at or before now, the earliest first,
+// until now plus claimTTL. Subscriptions claimed by a concurrent call are skipped, so each claimed subscription
+// is sent by one caller. The next run is kept until the caller reschedules the subscription once it is sent,
+// so a subscription which failed to be sent is claimed again after claimTTL: the emails are sent at least once.
+// The claim is made with claimToken, which has to be unique to the caller, see Reschedule.
+func (r *SubscriptionRepo) ClaimDue(
+	ctx context.Context, now time.Time, limit int, claimTTL time.Duration, claimToken int64,
+) (subscriptions []domain.Subscription, err error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			err = errors.Join(err, tx.Rollback())
+		}
+	}()
+
+	query := `
+		SELECT     
+		s.id,
+		s.created_at,
+		s.email,
+		s.city,
+		s.token,
+		s.frequency,
+		s.confirmed,
+		s.units,
+		s.air_quality,
+		s.timezone,
+		s.delivery_hour,
+		s.active_from,
+		s.active_to,
+		s.weekdays_only,
+		s.next_run_at,
+		s.location_id,
+		l.latitude,
+		l.longitude
+		FROM subscriptions s
+		LEFT JOIN locations l ON l.id = s.location_id
+		WHERE s.confirmed = true AND s.next_run_at <= $1 AND (s.claimed_until IS NULL OR s.claimed_until <= $1)
+		ORDER BY s.next_run_at
+		LIMIT $2
+		FOR UPDATE OF s SKIP LOCKED;`
+	if err = tx.SelectContext(ctx, &subscriptions, query, now, limit); err != nil {
+		return nil, err
+	}
+
+	if len(subscriptions) == 0 {
+		return nil, tx.Commit()
+	}
+
+	ids := make([]string, 0, len(subscriptions))
+	for _, subscription := range subscriptions {
+		ids = append(ids, subscription.ID)
+	}
+	query = "UPDATE subscriptions SET claimed_until = $2, claim_token = $3 WHERE id = ANY($1::uuid[]);"
+	if _, err = tx.ExecContext(ctx, query, pq.Array(ids), now.Add(claimTTL), claimToken); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+	return subscriptions, nil
+}
+
+// Reschedule releases the claim of the subscription and moves its next run to nextRunAt,
+// nil means it isn't scheduled. It returns lock.ErrStaleToken unless the subscription is still claimed
+// with claimToken, e.g. its claim expired and it was claimed again by another caller.
+func (r *SubscriptionRepo) Reschedule(ctx context.Context, id string, nextRunAt *time.Time, claimToken int64) error {
+	query := `
+		UPDATE subscriptions SET next_run_at = $2, claimed_until = NULL, claim_token = NULL
+		WHERE id = $1 AND claim_token = $3;`
+	result, err := r.db.ExecContext(ctx, query, id, nextRunAt, claimToken)
+	if err != nil {
+		return err
+	}
+
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if updated == 0 {
+		return lock.ErrStaleToken
+	}
+	return nil
+}
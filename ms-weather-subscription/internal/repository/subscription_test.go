@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"errors"
 	customErrors "ms-weather-subscription/pkg/errors"
+	"ms-weather-subscription/pkg/lock"
 	"ms-weather-subscription/testutils"
 	"testing"
 	"time"
@@ -31,8 +32,14 @@ func TestSubscriptionRepo(t *testing.T) {
 	t.Run("Delete Error", testSubscriptionRepoDeleteError)
 	t.Run("GetConfirmedByFrequency", testSubscriptionRepoGetConfirmedByFrequency)
 	t.Run("GetConfirmedByFrequency Error", testSubscriptionRepoGetConfirmedByFrequencyError)
+	t.Run("ClaimDue", testSubscriptionRepoClaimDue)
+	t.Run("ClaimDue Error", testSubscriptionRepoClaimDueError)
+	t.Run("Reschedule", testSubscriptionRepoReschedule)
+	t.Run("Reschedule stale claim", testSubscriptionRepoRescheduleStaleClaim)
 }
 
+var kyivLocationID = "kyiv,ukraine@50,31"
+
 func testSubscriptionRepoCreate(t *testing.T) {
 	t.Parallel()
 
@@ -48,16 +55,23 @@ func testSubscriptionRepoCreate(t *testing.T) {
 	repo := repository.NewSubscriptionRepo(db)
 
 	sub := domain.Subscription{
-		CreatedAt: time.Now(),
-		Email:     "test@example.com",
-		City:      "Kyiv",
-		Token:     "token123",
-		Frequency: "daily",
-		Confirmed: false,
+		CreatedAt:    time.Now(),
+		Email:        "test@example.com",
+		City:         "Kyiv",
+		Token:        "token123",
+		Frequency:    "daily",
+		Confirmed:    false,
+		Units:        domain.UnitsMetric,
+		Timezone:     "Europe/Kyiv",
+		DeliveryHour: domain.DefaultDeliveryHour,
+		LocationID:   &kyivLocationID,
 	}
 
 	mock.ExpectExec("INSERT INTO subscriptions").
-		WithArgs(sub.CreatedAt, sub.Email, sub.City, sub.Token, sub.Frequency, sub.Confirmed).
+		WithArgs(
+			sub.CreatedAt, sub.Email, sub.City, sub.Token, sub.Frequency, sub.Confirmed, sub.Units, sub.AirQuality,
+			sub.Timezone, sub.DeliveryHour, sub.ActiveFrom, sub.ActiveTo, sub.WeekdaysOnly, sub.LocationID,
+		).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
 	err := repo.Create(context.Background(), sub)
@@ -87,10 +101,14 @@ func testSubscriptionRepoCreateError(t *testing.T) {
 		Token:     "errorToken",
 		Frequency: "daily",
 		Confirmed: false,
+		Units:     domain.UnitsMetric,
 	}
 
 	mock.ExpectExec("INSERT INTO subscriptions").
-		WithArgs(sub.CreatedAt, sub.Email, sub.City, sub.Token, sub.Frequency, sub.Confirmed).
+		WithArgs(
+			sub.CreatedAt, sub.Email, sub.City, sub.Token, sub.Frequency, sub.Confirmed, sub.Units, sub.AirQuality,
+			sub.Timezone, sub.DeliveryHour, sub.ActiveFrom, sub.ActiveTo, sub.WeekdaysOnly, sub.LocationID,
+		).
 		WillReturnError(errors.New("some db error"))
 
 	err := repo.Create(context.Background(), sub)
@@ -120,11 +138,15 @@ func testSubscriptionRepoCreateDuplicationError(t *testing.T) {
 		Token:     "errorToken",
 		Frequency: "daily",
 		Confirmed: false,
+		Units:     domain.UnitsMetric,
 	}
 
 	duplicateError := pq.Error{Code: customErrors.PgUniqueViolationCode}
 	mock.ExpectExec("INSERT INTO subscriptions").
-		WithArgs(sub.CreatedAt, sub.Email, sub.City, sub.Token, sub.Frequency, sub.Confirmed).
+		WithArgs(
+			sub.CreatedAt, sub.Email, sub.City, sub.Token, sub.Frequency, sub.Confirmed, sub.Units, sub.AirQuality,
+			sub.Timezone, sub.DeliveryHour, sub.ActiveFrom, sub.ActiveTo, sub.WeekdaysOnly, sub.LocationID,
+		).
 		WillReturnError(&duplicateError)
 
 	err := repo.Create(context.Background(), sub)
@@ -149,20 +171,30 @@ func testSubscriptionRepoGetByToken(t *testing.T) {
 
 	token := "test-token"
 	expected := domain.Subscription{
-		ID:        "68501cb6-0bf0-800e-81ba-bae3763ecdd2",
-		CreatedAt: time.Now(),
-		Email:     "user@example.com",
-		City:      "Kyiv",
-		Token:     token,
-		Frequency: "daily",
-		Confirmed: true,
+		ID:           "68501cb6-0bf0-800e-81ba-bae3763ecdd2",
+		CreatedAt:    time.Now(),
+		Email:        "user@example.com",
+		City:         "Kyiv",
+		Token:        token,
+		Frequency:    "daily",
+		Confirmed:    true,
+		Units:        domain.UnitsImperial,
+		Timezone:     "Europe/Kyiv",
+		DeliveryHour: 8,
+		ActiveFrom:   7,
+		ActiveTo:     22,
+		WeekdaysOnly: true,
+		LocationID:   &kyivLocationID,
 	}
 
 	rows := sqlmock.NewRows([]string{
-		"id", "created_at", "email", "city", "token", "frequency", "confirmed",
+		"id", "created_at", "email", "city", "token", "frequency", "confirmed", "units", "air_quality",
+		"timezone", "delivery_hour", "active_from", "active_to", "weekdays_only", "location_id", "latitude", "longitude",
 	}).AddRow(
 		expected.ID, expected.CreatedAt, expected.Email, expected.City, expected.Token,
-		expected.Frequency, expected.Confirmed,
+		expected.Frequency, expected.Confirmed, expected.Units, expected.AirQuality,
+		expected.Timezone, expected.DeliveryHour, expected.ActiveFrom, expected.ActiveTo, expected.WeekdaysOnly,
+		expected.LocationID, nil, nil,
 	)
 
 	mock.ExpectQuery("SELECT .* FROM subscriptions WHERE token =").
@@ -172,6 +204,13 @@ func testSubscriptionRepoGetByToken(t *testing.T) {
 	got, err := repo.GetByToken(context.Background(), token)
 	assert.NoError(t, err)
 	assert.Equal(t, expected.Email, got.Email)
+	assert.Equal(t, expected.Units, got.Units)
+	assert.Equal(t, expected.LocationID, got.LocationID)
+	assert.Equal(t, expected.Timezone, got.Timezone)
+	assert.Equal(t, expected.DeliveryHour, got.DeliveryHour)
+	assert.Equal(t, expected.ActiveFrom, got.ActiveFrom)
+	assert.Equal(t, expected.ActiveTo, got.ActiveTo)
+	assert.Equal(t, expected.WeekdaysOnly, got.WeekdaysOnly)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
@@ -239,11 +278,12 @@ func testSubscriptionRepoConfirm(t *testing.T) {
 
 	repo := repository.NewSubscriptionRepo(db)
 
-	mock.ExpectExec("UPDATE subscriptions SET confirmed = true").
-		WithArgs("token123").
+	nextRunAt := time.Date(2025, 5, 17, 8, 0, 0, 0, time.UTC)
+	mock.ExpectExec("UPDATE subscriptions SET confirmed = true, next_run_at = \\$2").
+		WithArgs("token123", &nextRunAt).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
-	err := repo.Confirm(context.Background(), "token123")
+	err := repo.Confirm(context.Background(), "token123", &nextRunAt)
 	assert.NoError(t, err)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
@@ -263,10 +303,10 @@ func testSubscriptionRepoConfirmError(t *testing.T) {
 	repo := repository.NewSubscriptionRepo(db)
 
 	mock.ExpectExec("UPDATE subscriptions SET confirmed = true").
-		WithArgs("token123").
+		WithArgs("token123", nil).
 		WillReturnError(errors.New("update error"))
 
-	err := repo.Confirm(context.Background(), "token123")
+	err := repo.Confirm(context.Background(), "token123", nil)
 	assert.Error(t, err)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
@@ -339,16 +379,20 @@ func testSubscriptionRepoGetConfirmedByFrequency(t *testing.T) {
 		Token:     "token321",
 		Frequency: "weekly",
 		Confirmed: true,
+		Units:     domain.UnitsMetric,
 	}
 
 	rows := sqlmock.NewRows([]string{
-		"id", "created_at", "email", "city", "token", "frequency", "confirmed",
+		"id", "created_at", "email", "city", "token", "frequency", "confirmed", "units", "air_quality",
+		"timezone", "delivery_hour", "active_from", "active_to", "weekdays_only", "location_id", "latitude", "longitude",
 	}).AddRow(
 		expected.ID, expected.CreatedAt, expected.Email, expected.City, expected.Token,
-		expected.Frequency, expected.Confirmed,
+		expected.Frequency, expected.Confirmed, expected.Units, expected.AirQuality,
+		expected.Timezone, expected.DeliveryHour, expected.ActiveFrom, expected.ActiveTo, expected.WeekdaysOnly,
+		expected.LocationID, nil, nil,
 	)
 
-	mock.ExpectQuery("SELECT .* FROM subscriptions WHERE confirmed = true").
+	mock.ExpectQuery("SELECT .* FROM subscriptions s LEFT JOIN locations l .* WHERE s.confirmed = true").
 		WithArgs("weekly").
 		WillReturnRows(rows)
 
@@ -356,6 +400,8 @@ func testSubscriptionRepoGetConfirmedByFrequency(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Len(t, subs, 1)
 	assert.Equal(t, expected.Email, subs[0].Email)
+	assert.Nil(t, subs[0].LocationID, "subscription created before locations has no location")
+	assert.Equal(t, domain.CityQuery("Lviv"), subs[0].WeatherQuery())
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
@@ -373,7 +419,7 @@ func testSubscriptionRepoGetConfirmedByFrequencyError(t *testing.T) {
 
 	repo := repository.NewSubscriptionRepo(db)
 
-	mock.ExpectQuery("SELECT .* FROM subscriptions WHERE confirmed = true").
+	mock.ExpectQuery("SELECT .* FROM subscriptions s LEFT JOIN locations l .* WHERE s.confirmed = true").
 		WithArgs("weekly").
 		WillReturnError(errors.New("query error"))
 
@@ -381,3 +427,124 @@ func testSubscriptionRepoGetConfirmedByFrequencyError(t *testing.T) {
 	assert.Error(t, err)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
+
+func testSubscriptionRepoClaimDue(t *testing.T) {
+	t.Parallel()
+
+	db, mock := testutils.SetupMockDB(t)
+	defer func() {
+		mock.ExpectClose()
+		err := db.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	repo := repository.NewSubscriptionRepo(db)
+
+	now := time.Date(2025, 5, 17, 7, 0, 0, 0, time.UTC)
+	rows := sqlmock.NewRows([]string{
+		"id", "created_at", "email", "city", "token", "frequency", "confirmed", "units", "air_quality",
+		"timezone", "delivery_hour", "active_from", "active_to", "weekdays_only", "next_run_at",
+		"location_id", "latitude", "longitude",
+	}).AddRow(
+		"68501cb6-0bf0-800e-81ba-bae3763ecdd2", now, "test@example.com", "Lviv", "token321",
+		"hourly", true, domain.UnitsMetric, false,
+		"UTC", domain.DefaultDeliveryHour, 0, 23, false, now,
+		nil, nil, nil,
+	)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .* WHERE s.confirmed = true AND s.next_run_at <= \\$1 AND "+
+		"\\(s.claimed_until IS NULL OR s.claimed_until <= \\$1\\) .* FOR UPDATE OF s SKIP LOCKED").
+		WithArgs(now, 10).
+		WillReturnRows(rows)
+	mock.ExpectExec("UPDATE subscriptions SET claimed_until = \\$2, claim_token = \\$3 WHERE id = ANY").
+		WithArgs(pq.Array([]string{"68501cb6-0bf0-800e-81ba-bae3763ecdd2"}), now.Add(5*time.Minute), int64(3)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	subs, err := repo.ClaimDue(context.Background(), now, 10, 5*time.Minute, 3)
+	assert.NoError(t, err)
+	if assert.Len(t, subs, 1) {
+		assert.Equal(t, "test@example.com", subs[0].Email)
+		assert.Equal(t, &now, subs[0].NextRunAt, "the next run is kept until the subscription is rescheduled")
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func testSubscriptionRepoClaimDueError(t *testing.T) {
+	t.Parallel()
+
+	db, mock := testutils.SetupMockDB(t)
+	defer func() {
+		mock.ExpectClose()
+		err := db.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	repo := repository.NewSubscriptionRepo(db)
+
+	now := time.Date(2025, 5, 17, 7, 0, 0, 0, time.UTC)
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .* FOR UPDATE OF s SKIP LOCKED").
+		WithArgs(now, 10).
+		WillReturnError(errors.New("query error"))
+	mock.ExpectRollback()
+
+	_, err := repo.ClaimDue(context.Background(), now, 10, 5*time.Minute, 3)
+	assert.Error(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func testSubscriptionRepoReschedule(t *testing.T) {
+	t.Parallel()
+
+	db, mock := testutils.SetupMockDB(t)
+	defer func() {
+		mock.ExpectClose()
+		err := db.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	repo := repository.NewSubscriptionRepo(db)
+
+	next := time.Date(2025, 5, 17, 8, 0, 0, 0, time.UTC)
+	mock.ExpectExec("UPDATE subscriptions SET next_run_at = \\$2, claimed_until = NULL, claim_token = NULL "+
+		"WHERE id = \\$1 AND claim_token = \\$3").
+		WithArgs("68501cb6-0bf0-800e-81ba-bae3763ecdd2", &next, int64(3)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := repo.Reschedule(context.Background(), "68501cb6-0bf0-800e-81ba-bae3763ecdd2", &next, 3)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func testSubscriptionRepoRescheduleStaleClaim(t *testing.T) {
+	t.Parallel()
+
+	db, mock := testutils.SetupMockDB(t)
+	defer func() {
+		mock.ExpectClose()
+		err := db.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	repo := repository.NewSubscriptionRepo(db)
+
+	// The subscription was claimed again with a later token after the claim expired
+	next := time.Date(2025, 5, 17, 8, 0, 0, 0, time.UTC)
+	mock.ExpectExec("UPDATE subscriptions SET next_run_at = \\$2, .* WHERE id = \\$1 AND claim_token = \\$3").
+		WithArgs("68501cb6-0bf0-800e-81ba-bae3763ecdd2", &next, int64(3)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err := repo.Reschedule(context.Background(), "68501cb6-0bf0-800e-81ba-bae3763ecdd2", &next, 3)
+	assert.ErrorIs(t, err, lock.ErrStaleToken)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
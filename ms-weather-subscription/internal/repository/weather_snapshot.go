@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"ms-weather-subscription/internal/domain"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type WeatherSnapshotRepo struct {
+	db *sqlx.DB
+}
+
+func NewWeatherSnapshotRepo(db *sqlx.DB) *WeatherSnapshotRepo {
+	return &WeatherSnapshotRepo{db: db}
+}
+
+type weatherSnapshotRow struct {
+	LocationID string    `db:"location_id"`
+	Latitude   *float64  `db:"latitude"`
+	Longitude  *float64  `db:"longitude"`
+	Provider   string    `db:"provider"`
+	ObservedAt time.Time `db:"observed_at"`
+	Metrics    []byte    `db:"metrics"`
+}
+
+func (r *WeatherSnapshotRepo) Create(ctx context.Context, snapshot domain.WeatherSnapshot) error {
+	metrics, err := json.Marshal(snapshot.Weather)
+	if err != nil {
+		return err
+	}
+
+	var latitude, longitude *float64
+	if snapshot.Coordinates != nil {
+		latitude, longitude = &snapshot.Coordinates.Latitude, &snapshot.Coordinates.Longitude
+	}
+
+	query := `
+		INSERT INTO weather_snapshots (location_id, latitude, longitude, provider, observed_at, metrics)
+		VALUES ($1, $2, $3, $4, $5, $6);`
+	_, err = r.db.ExecContext(
+		ctx,
+		query,
+		snapshot.LocationID,
+		latitude,
+		longitude,
+		snapshot.Provider,
+		snapshot.ObservedAt,
+		metrics,
+	)
+	return err
+}
+
+// GetByLocation returns the snapshots of the location observed in [from, to), oldest first.
+func (r *WeatherSnapshotRepo) GetByLocation(
+	ctx context.Context, locationID string, from, to time.Time,
+) ([]domain.WeatherSnapshot, error) {
+	var rows []weatherSnapshotRow
+
+	query := `
+		SELECT
+		location_id,
+		latitude,
+		longitude,
+		provider,
+		observed_at,
+		metrics
+		FROM weather_snapshots
+		WHERE location_id = $1 AND observed_at >= $2 AND observed_at < $3
+		ORDER BY observed_at;`
+
+	if err := r.db.SelectContext(ctx, &rows, query, locationID, from, to); err != nil {
+		return nil, err
+	}
+
+	snapshots := make([]domain.WeatherSnapshot, 0, len(rows))
+	for _, row := range rows {
+		snapshot := domain.WeatherSnapshot{
+			LocationID: row.LocationID,
+			Provider:   row.Provider,
+			ObservedAt: row.ObservedAt,
+		}
+		if row.Latitude != nil && row.Longitude != nil {
+			snapshot.Coordinates = &domain.Coordinates{Latitude: *row.Latitude, Longitude: *row.Longitude}
+		}
+		if err := json.Unmarshal(row.Metrics, &snapshot.Weather); err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+
+	return snapshots, nil
+}
+
+// DeleteObservedBefore deletes the snapshots observed before the given time and returns how many were deleted.
+func (r *WeatherSnapshotRepo) DeleteObservedBefore(ctx context.Context, before time.Time) (int64, error) {
+	query := "DELETE FROM weather_snapshots WHERE observed_at < $1;"
+	result, err := r.db.ExecContext(ctx, query, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,130 @@
+package repository_test
+
+import (
+	"context"
+	"ms-weather-subscription/internal/domain"
+	"ms-weather-subscription/internal/repository"
+	"ms-weather-subscription/testutils"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWeatherSnapshotRepo(t *testing.T) {
+	t.Run("Create", testWeatherSnapshotRepoCreate)
+	t.Run("GetByLocation", testWeatherSnapshotRepoGetByLocation)
+	t.Run("DeleteObservedBefore", testWeatherSnapshotRepoDeleteObservedBefore)
+}
+
+const sunnyMetrics = `{"temperature":21.5,"humidity":48,"description":"Sunny","feels_like":0,"wind_speed":12.6,` +
+	`"wind_direction":0,"wind_gust":0,"precipitation":0,"precipitation_probability":0,"uv_index":0,` +
+	`"pressure":0,"visibility":0,"cloud_cover":0}`
+
+var sunnyWeather = domain.WeatherResponse{Temperature: 21.5, Humidity: 48, Description: "Sunny", WindSpeed: 12.6}
+
+func testWeatherSnapshotRepoCreate(t *testing.T) {
+	t.Parallel()
+
+	db, mock := testutils.SetupMockDB(t)
+	defer func() {
+		mock.ExpectClose()
+		err := db.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	repo := repository.NewWeatherSnapshotRepo(db)
+
+	latitude, longitude := 50.45, 30.52
+	snapshot := domain.WeatherSnapshot{
+		LocationID:  kyivLocationID,
+		Coordinates: &domain.Coordinates{Latitude: latitude, Longitude: longitude},
+		Provider:    "weatherapi",
+		ObservedAt:  time.Date(2025, 5, 17, 12, 0, 0, 0, time.UTC),
+		Weather:     sunnyWeather,
+	}
+
+	mock.ExpectExec("INSERT INTO weather_snapshots").
+		WithArgs(kyivLocationID, &latitude, &longitude, "weatherapi", snapshot.ObservedAt, []byte(sunnyMetrics)).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	err := repo.Create(context.Background(), snapshot)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func testWeatherSnapshotRepoGetByLocation(t *testing.T) {
+	t.Parallel()
+
+	db, mock := testutils.SetupMockDB(t)
+	defer func() {
+		mock.ExpectClose()
+		err := db.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	repo := repository.NewWeatherSnapshotRepo(db)
+
+	from := time.Date(2025, 5, 17, 0, 0, 0, 0, time.UTC)
+	to := from.Add(24 * time.Hour)
+	observedAt := from.Add(12 * time.Hour)
+
+	rows := sqlmock.NewRows([]string{"location_id", "latitude", "longitude", "provider", "observed_at", "metrics"}).
+		AddRow(kyivLocationID, 50.45, 30.52, "weatherapi", observedAt, []byte(sunnyMetrics)).
+		AddRow(kyivLocationID, nil, nil, "visualcrossing", observedAt.Add(time.Hour), []byte(`{"temperature":23}`))
+
+	mock.ExpectQuery(
+		"SELECT .* FROM weather_snapshots WHERE location_id = \\$1 AND observed_at >= \\$2 AND observed_at < \\$3",
+	).
+		WithArgs(kyivLocationID, from, to).
+		WillReturnRows(rows)
+
+	snapshots, err := repo.GetByLocation(context.Background(), kyivLocationID, from, to)
+	assert.NoError(t, err)
+	assert.Equal(t, []domain.WeatherSnapshot{
+		{
+			LocationID:  kyivLocationID,
+			Coordinates: &domain.Coordinates{Latitude: 50.45, Longitude: 30.52},
+			Provider:    "weatherapi",
+			ObservedAt:  observedAt,
+			Weather:     sunnyWeather,
+		},
+		{
+			LocationID: kyivLocationID,
+			Provider:   "visualcrossing",
+			ObservedAt: observedAt.Add(time.Hour),
+			Weather:    domain.WeatherResponse{Temperature: 23},
+		},
+	}, snapshots)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func testWeatherSnapshotRepoDeleteObservedBefore(t *testing.T) {
+	t.Parallel()
+
+	db, mock := testutils.SetupMockDB(t)
+	defer func() {
+		mock.ExpectClose()
+		err := db.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	repo := repository.NewWeatherSnapshotRepo(db)
+
+	before := time.Date(2025, 4, 17, 0, 0, 0, 0, time.UTC)
+	mock.ExpectExec("DELETE FROM weather_snapshots WHERE observed_at < \\$1").
+		WithArgs(before).
+		WillReturnResult(sqlmock.NewResult(0, 42))
+
+	deleted, err := repo.DeleteObservedBefore(context.Background(), before)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(42), deleted)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,144 @@
+package service
+
+import (
+	"common/logger"
+	"context"
+	"errors"
+	"ms-weather-subscription/internal/config"
+	"ms-weather-subscription/internal/domain"
+	"ms-weather-subscription/internal/repository"
+	"ms-weather-subscription/pkg/clients"
+	"ms-weather-subscription/pkg/lock"
+	"ms-weather-subscription/pkg/publisher"
+	"time"
+)
+
+type AlertsService struct {
+	client clients.AlertsClient
+}
+
+func NewAlertsService(client clients.AlertsClient) *AlertsService {
+	return &AlertsService{client: client}
+}
+
+func (s *AlertsService) GetAlerts(ctx context.Context, query domain.WeatherQuery) ([]domain.WeatherAlert, error) {
+	return s.client.GetAPIAlerts(ctx, query)
+}
+
+type SubscriptionSenderRepository interface {
+	GetConfirmedByFrequency(ctx context.Context, frequency string) ([]domain.Subscription, error)
+}
+
+type WeatherAlertSenderService struct {
+	httpConfig             config.HTTPConfig
+	alertsService          Alerts
+	subscriptionSenderRepo SubscriptionSenderRepository
+	sentAlertRepo          repository.SentAlertRepository
+	emailPublisher         publisher.EmailPublisher
+}
+
+func NewWeatherAlertSenderService(
+	httpConfig config.HTTPConfig,
+	alertsService Alerts,
+	subscriptionSenderRepo SubscriptionSenderRepository,
+	sentAlertRepo repository.SentAlertRepository,
+	emailPublisher publisher.EmailPublisher,
+) *WeatherAlertSenderService {
+	return &WeatherAlertSenderService{
+		httpConfig:             httpConfig,
+		alertsService:          alertsService,
+		subscriptionSenderRepo: subscriptionSenderRepo,
+		sentAlertRepo:          sentAlertRepo,
+		emailPublisher:         emailPublisher,
+	}
+}
+
+// SendWeatherAlerts polls alerts for the locations of alerts subscriptions and emails
+// the subscribers about the alerts they haven't been sent yet. Expired alerts are skipped.
+// It must run under a lock whose fencing token ctx carries, see lock.WithToken: the run stops
+// once the alerts are marked as sent under a later lock, e.g. after its own lock expired.
+func (s *WeatherAlertSenderService) SendWeatherAlerts(ctx context.Context) error {
+	token, ok := lock.TokenFrom(ctx)
+	if !ok {
+		return errors.New("weather alerts must be sent under a lock")
+	}
+
+	subs, err := s.subscriptionSenderRepo.GetConfirmedByFrequency(ctx, domain.WeatherAlertsFrequency)
+	if err != nil {
+		logger.Errorf("failed to get subscriptions (%s): %s", domain.WeatherAlertsFrequency, err.Error())
+		return err
+	}
+
+	for _, subscriptions := range groupByLocation(subs) {
+		query := subscriptions[0].WeatherQuery()
+		alerts, err := s.alertsService.GetAlerts(ctx, query)
+		if err != nil {
+			logger.Errorf("failed to get weather alerts for %s: %s", query, err.Error())
+			continue
+		}
+
+		now := time.Now()
+		for _, alert := range alerts {
+			if alert.IsExpired(now) {
+				continue
+			}
+			for _, subscription := range subscriptions {
+				if err := s.sendAlert(ctx, subscription, alert, token); err != nil {
+					logger.Warnf("weather alerts are sent under a later lock, stopping: %s", err.Error())
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// sendAlert marks the alert as sent before publishing it, so concurrent polls don't send it twice.
+// The mark is removed if publishing fails, so the alert is sent on the next poll.
+// Alerts are marked by their DedupKey, so an alert from another provider isn't sent again.
+// It returns lock.ErrStaleToken if token is stale, the other errors are logged.
+func (s *WeatherAlertSenderService) sendAlert(
+	ctx context.Context, subscription domain.Subscription, alert domain.WeatherAlert, token lock.Token,
+) error {
+	isNew, err := s.sentAlertRepo.MarkSent(ctx, subscription.ID, alert.DedupKey(), alert.SentUntil(time.Now()), token)
+	if errors.Is(err, lock.ErrStaleToken) {
+		return err
+	}
+	if err != nil {
+		logger.Errorf("failed to mark weather alert %s as sent to %s: %s", alert.ID, subscription.Email, err.Error())
+		return nil
+	}
+	if !isNew {
+		return nil
+	}
+
+	emailInput := domain.WeatherAlertEmailInput{
+		Subscription:    subscription,
+		Alert:           alert,
+		UnsubscribeLink: subscription.CreateUnsubscribeLink(s.httpConfig.BaseURL),
+	}
+	if err := s.emailPublisher.Publish(publisher.EmailWeatherAlertQueue, emailInput); err != nil {
+		logger.Errorf("failed to send weather alert %s to %s: %s", alert.ID, subscription.Email, err.Error())
+		err := s.sentAlertRepo.UnmarkSent(ctx, subscription.ID, alert.DedupKey(), token)
+		if errors.Is(err, lock.ErrStaleToken) {
+			return err
+		}
+		if err != nil {
+			logger.Errorf("failed to unmark weather alert %s as sent: %s", alert.ID, err.Error())
+		}
+	}
+	return nil
+}
+
+// DeleteExpiredSentAlerts forgets the alerts sent to the subscriptions once they have expired.
+func (s *WeatherAlertSenderService) DeleteExpiredSentAlerts(ctx context.Context) error {
+	deleted, err := s.sentAlertRepo.DeleteExpired(ctx, time.Now())
+	if err != nil {
+		logger.Errorf("failed to delete expired sent alerts: %s", err.Error())
+		return err
+	}
+
+	logger.Infof("deleted %d expired sent alerts", deleted)
+	return nil
+}
@@ -0,0 +1,144 @@
+package service
+
+import (
+	"common/logger"
+	"context"
+	"encoding/json"
+	"errors"
+	"ms-weather-subscription/internal/domain"
+	"ms-weather-subscription/pkg/cache"
+	"ms-weather-subscription/pkg/clients"
+	customErrors "ms-weather-subscription/pkg/errors"
+	"time"
+)
+
+// locationCacheTTL is how long a resolved alias is kept in Redis in front of Postgres.
+const locationCacheTTL = 24 * time.Hour
+
+type LocationRepository interface {
+	GetByAlias(ctx context.Context, alias string) (domain.Location, error)
+	Save(ctx context.Context, location domain.Location, alias string) error
+}
+
+// LocationService resolves user input to a canonical location. Resolved aliases are stored
+// in Postgres and cached in Redis, so providers are searched once per spelling of a city.
+type LocationService struct {
+	repo     LocationRepository
+	searcher clients.LocationSearcher
+	cache    cache.Cache
+}
+
+func NewLocationService(
+	repo LocationRepository, searcher clients.LocationSearcher, cache cache.Cache,
+) *LocationService {
+	return &LocationService{
+		repo:     repo,
+		searcher: searcher,
+		cache:    cache,
+	}
+}
+
+func (s *LocationService) Resolve(ctx context.Context, query string) (domain.Location, error) {
+	alias := domain.NormalizeLocationQuery(query)
+	if alias == "" {
+		return domain.Location{}, customErrors.ErrCityNotFound
+	}
+
+	if location, ok := s.getCached(ctx, alias); ok {
+		return location, nil
+	}
+
+	location, err := s.repo.GetByAlias(ctx, alias)
+	if err == nil {
+		s.setCached(ctx, alias, location)
+		return location, nil
+	}
+	if !errors.Is(err, customErrors.ErrLocationNotFound) {
+		return domain.Location{}, err
+	}
+
+	found, err := s.searcher.SearchLocation(ctx, alias)
+	if err != nil {
+		return domain.Location{}, err
+	}
+
+	if err := s.repo.Save(ctx, *found, alias); err != nil {
+		return domain.Location{}, err
+	}
+	s.setCached(ctx, alias, *found)
+
+	return *found, nil
+}
+
+// ResolveCoordinates returns the location of the grid cell the coordinates are on.
+// Such locations aren't searched for, they are named after their coordinates,
+// and only their timezone is looked up. Locations stored without a timezone get it looked up again.
+func (s *LocationService) ResolveCoordinates(
+	ctx context.Context, coordinates domain.Coordinates,
+) (domain.Location, error) {
+	alias := coordinates.GridKey()
+	if location, ok := s.getCached(ctx, alias); ok && location.Timezone != "" {
+		return location, nil
+	}
+
+	location, err := s.repo.GetByAlias(ctx, alias)
+	if err == nil && location.Timezone != "" {
+		s.setCached(ctx, alias, location)
+		return location, nil
+	}
+	if err != nil && !errors.Is(err, customErrors.ErrLocationNotFound) {
+		return domain.Location{}, err
+	}
+
+	timezone, err := s.searcher.LookupTimezone(ctx, coordinates)
+	if err != nil {
+		return domain.Location{}, err
+	}
+
+	location = domain.NewCoordinatesLocation(coordinates, timezone)
+	if err := s.repo.Save(ctx, location, alias); err != nil {
+		return domain.Location{}, err
+	}
+	s.setCached(ctx, alias, location)
+
+	return location, nil
+}
+
+// resolvePlace resolves the coordinates of the place if they are given, and its city otherwise.
+func resolvePlace(ctx context.Context, locations Location, place domain.WeatherQuery) (domain.Location, error) {
+	if place.Coordinates != nil {
+		return locations.ResolveCoordinates(ctx, *place.Coordinates)
+	}
+	return locations.Resolve(ctx, place.City)
+}
+
+func (s *LocationService) getCached(ctx context.Context, alias string) (domain.Location, bool) {
+	cached, err := s.cache.Get(ctx, locationKey(alias))
+	if err != nil {
+		clients.HandleRedisError(err)
+		return domain.Location{}, false
+	}
+
+	var location domain.Location
+	if err := json.Unmarshal([]byte(cached), &location); err != nil {
+		logger.Warnf("cache unmarshal error (location): %v", err)
+		return domain.Location{}, false
+	}
+	return location, true
+}
+
+func (s *LocationService) setCached(ctx context.Context, alias string, location domain.Location) {
+	data, err := json.Marshal(location)
+	if err != nil {
+		logger.Errorf("cache marshal error (location): %s", err)
+		return
+	}
+
+	if err := s.cache.Set(ctx, locationKey(alias), string(data), locationCacheTTL); err != nil {
+		clients.HandleRedisError(err)
+	}
+}
+
+func locationKey(alias string) string {
+	return "location:" + alias
+}
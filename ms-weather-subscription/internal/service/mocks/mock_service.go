@@ -13,6 +13,7 @@ import (
 	context "context"
 	domain "ms-weather-subscription/internal/domain"
 	reflect "reflect"
+	time "time"
 
 	gomock "go.uber.org/mock/gomock"
 )
@@ -83,6 +84,60 @@ func (mr *MockSubscriptionMockRecorder) Delete(ctx, token any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockSubscription)(nil).Delete), ctx, token)
 }
 
+// MockLocation is a mock of Location interface.
+type MockLocation struct {
+	ctrl     *gomock.Controller
+	recorder *MockLocationMockRecorder
+	isgomock struct{}
+}
+
+// MockLocationMockRecorder is the mock recorder for MockLocation.
+type MockLocationMockRecorder struct {
+	mock *MockLocation
+}
+
+// NewMockLocation creates a new mock instance.
+func NewMockLocation(ctrl *gomock.Controller) *MockLocation {
+	mock := &MockLocation{ctrl: ctrl}
+	mock.recorder = &MockLocationMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLocation) EXPECT() *MockLocationMockRecorder {
+	return m.recorder
+}
+
+// Resolve mocks base method.
+func (m *MockLocation) Resolve(ctx context.Context, query string) (domain.Location, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Resolve", ctx, query)
+	ret0, _ := ret[0].(domain.Location)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Resolve indicates an expected call of Resolve.
+func (mr *MockLocationMockRecorder) Resolve(ctx, query any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Resolve", reflect.TypeOf((*MockLocation)(nil).Resolve), ctx, query)
+}
+
+// ResolveCoordinates mocks base method.
+func (m *MockLocation) ResolveCoordinates(ctx context.Context, coordinates domain.Coordinates) (domain.Location, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResolveCoordinates", ctx, coordinates)
+	ret0, _ := ret[0].(domain.Location)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResolveCoordinates indicates an expected call of ResolveCoordinates.
+func (mr *MockLocationMockRecorder) ResolveCoordinates(ctx, coordinates any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResolveCoordinates", reflect.TypeOf((*MockLocation)(nil).ResolveCoordinates), ctx, coordinates)
+}
+
 // MockWeatherForecastSender is a mock of WeatherForecastSender interface.
 type MockWeatherForecastSender struct {
 	ctrl     *gomock.Controller
@@ -107,32 +162,200 @@ func (m *MockWeatherForecastSender) EXPECT() *MockWeatherForecastSenderMockRecor
 	return m.recorder
 }
 
-// SendDailyWeatherForecast mocks base method.
-func (m *MockWeatherForecastSender) SendDailyWeatherForecast(ctx context.Context) error {
+// SendWeatherForecasts mocks base method.
+func (m *MockWeatherForecastSender) SendWeatherForecasts(ctx context.Context, subscriptions []domain.Subscription, now time.Time) []domain.Subscription {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendWeatherForecasts", ctx, subscriptions, now)
+	ret0, _ := ret[0].([]domain.Subscription)
+	return ret0
+}
+
+// SendWeatherForecasts indicates an expected call of SendWeatherForecasts.
+func (mr *MockWeatherForecastSenderMockRecorder) SendWeatherForecasts(ctx, subscriptions, now any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendWeatherForecasts", reflect.TypeOf((*MockWeatherForecastSender)(nil).SendWeatherForecasts), ctx, subscriptions, now)
+}
+
+// MockScheduler is a mock of Scheduler interface.
+type MockScheduler struct {
+	ctrl     *gomock.Controller
+	recorder *MockSchedulerMockRecorder
+	isgomock struct{}
+}
+
+// MockSchedulerMockRecorder is the mock recorder for MockScheduler.
+type MockSchedulerMockRecorder struct {
+	mock *MockScheduler
+}
+
+// NewMockScheduler creates a new mock instance.
+func NewMockScheduler(ctrl *gomock.Controller) *MockScheduler {
+	mock := &MockScheduler{ctrl: ctrl}
+	mock.recorder = &MockSchedulerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockScheduler) EXPECT() *MockSchedulerMockRecorder {
+	return m.recorder
+}
+
+// RunDue mocks base method.
+func (m *MockScheduler) RunDue(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RunDue", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RunDue indicates an expected call of RunDue.
+func (mr *MockSchedulerMockRecorder) RunDue(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RunDue", reflect.TypeOf((*MockScheduler)(nil).RunDue), ctx)
+}
+
+// MockWeatherAlertSender is a mock of WeatherAlertSender interface.
+type MockWeatherAlertSender struct {
+	ctrl     *gomock.Controller
+	recorder *MockWeatherAlertSenderMockRecorder
+	isgomock struct{}
+}
+
+// MockWeatherAlertSenderMockRecorder is the mock recorder for MockWeatherAlertSender.
+type MockWeatherAlertSenderMockRecorder struct {
+	mock *MockWeatherAlertSender
+}
+
+// NewMockWeatherAlertSender creates a new mock instance.
+func NewMockWeatherAlertSender(ctrl *gomock.Controller) *MockWeatherAlertSender {
+	mock := &MockWeatherAlertSender{ctrl: ctrl}
+	mock.recorder = &MockWeatherAlertSenderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWeatherAlertSender) EXPECT() *MockWeatherAlertSenderMockRecorder {
+	return m.recorder
+}
+
+// DeleteExpiredSentAlerts mocks base method.
+func (m *MockWeatherAlertSender) DeleteExpiredSentAlerts(ctx context.Context) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SendDailyWeatherForecast", ctx)
+	ret := m.ctrl.Call(m, "DeleteExpiredSentAlerts", ctx)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// SendDailyWeatherForecast indicates an expected call of SendDailyWeatherForecast.
-func (mr *MockWeatherForecastSenderMockRecorder) SendDailyWeatherForecast(ctx any) *gomock.Call {
+// DeleteExpiredSentAlerts indicates an expected call of DeleteExpiredSentAlerts.
+func (mr *MockWeatherAlertSenderMockRecorder) DeleteExpiredSentAlerts(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiredSentAlerts", reflect.TypeOf((*MockWeatherAlertSender)(nil).DeleteExpiredSentAlerts), ctx)
+}
+
+// SendWeatherAlerts mocks base method.
+func (m *MockWeatherAlertSender) SendWeatherAlerts(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendWeatherAlerts", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendWeatherAlerts indicates an expected call of SendWeatherAlerts.
+func (mr *MockWeatherAlertSenderMockRecorder) SendWeatherAlerts(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendWeatherAlerts", reflect.TypeOf((*MockWeatherAlertSender)(nil).SendWeatherAlerts), ctx)
+}
+
+// MockAlerts is a mock of Alerts interface.
+type MockAlerts struct {
+	ctrl     *gomock.Controller
+	recorder *MockAlertsMockRecorder
+	isgomock struct{}
+}
+
+// MockAlertsMockRecorder is the mock recorder for MockAlerts.
+type MockAlertsMockRecorder struct {
+	mock *MockAlerts
+}
+
+// NewMockAlerts creates a new mock instance.
+func NewMockAlerts(ctrl *gomock.Controller) *MockAlerts {
+	mock := &MockAlerts{ctrl: ctrl}
+	mock.recorder = &MockAlertsMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAlerts) EXPECT() *MockAlertsMockRecorder {
+	return m.recorder
+}
+
+// GetAlerts mocks base method.
+func (m *MockAlerts) GetAlerts(ctx context.Context, query domain.WeatherQuery) ([]domain.WeatherAlert, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAlerts", ctx, query)
+	ret0, _ := ret[0].([]domain.WeatherAlert)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAlerts indicates an expected call of GetAlerts.
+func (mr *MockAlertsMockRecorder) GetAlerts(ctx, query any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendDailyWeatherForecast", reflect.TypeOf((*MockWeatherForecastSender)(nil).SendDailyWeatherForecast), ctx)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAlerts", reflect.TypeOf((*MockAlerts)(nil).GetAlerts), ctx, query)
+}
+
+// MockWeatherHistory is a mock of WeatherHistory interface.
+type MockWeatherHistory struct {
+	ctrl     *gomock.Controller
+	recorder *MockWeatherHistoryMockRecorder
+	isgomock struct{}
+}
+
+// MockWeatherHistoryMockRecorder is the mock recorder for MockWeatherHistory.
+type MockWeatherHistoryMockRecorder struct {
+	mock *MockWeatherHistory
 }
 
-// SendHourlyWeatherForecast mocks base method.
-func (m *MockWeatherForecastSender) SendHourlyWeatherForecast(ctx context.Context) error {
+// NewMockWeatherHistory creates a new mock instance.
+func NewMockWeatherHistory(ctrl *gomock.Controller) *MockWeatherHistory {
+	mock := &MockWeatherHistory{ctrl: ctrl}
+	mock.recorder = &MockWeatherHistoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWeatherHistory) EXPECT() *MockWeatherHistoryMockRecorder {
+	return m.recorder
+}
+
+// DeleteExpiredSnapshots mocks base method.
+func (m *MockWeatherHistory) DeleteExpiredSnapshots(ctx context.Context) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SendHourlyWeatherForecast", ctx)
+	ret := m.ctrl.Call(m, "DeleteExpiredSnapshots", ctx)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// SendHourlyWeatherForecast indicates an expected call of SendHourlyWeatherForecast.
-func (mr *MockWeatherForecastSenderMockRecorder) SendHourlyWeatherForecast(ctx any) *gomock.Call {
+// DeleteExpiredSnapshots indicates an expected call of DeleteExpiredSnapshots.
+func (mr *MockWeatherHistoryMockRecorder) DeleteExpiredSnapshots(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiredSnapshots", reflect.TypeOf((*MockWeatherHistory)(nil).DeleteExpiredSnapshots), ctx)
+}
+
+// GetHistory mocks base method.
+func (m *MockWeatherHistory) GetHistory(ctx context.Context, query domain.WeatherQuery, from, to time.Time) ([]domain.WeatherSnapshot, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHistory", ctx, query, from, to)
+	ret0, _ := ret[0].([]domain.WeatherSnapshot)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHistory indicates an expected call of GetHistory.
+func (mr *MockWeatherHistoryMockRecorder) GetHistory(ctx, query, from, to any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendHourlyWeatherForecast", reflect.TypeOf((*MockWeatherForecastSender)(nil).SendHourlyWeatherForecast), ctx)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHistory", reflect.TypeOf((*MockWeatherHistory)(nil).GetHistory), ctx, query, from, to)
 }
 
 // MockWeather is a mock of Weather interface.
@@ -159,32 +382,62 @@ func (m *MockWeather) EXPECT() *MockWeatherMockRecorder {
 	return m.recorder
 }
 
+// GetAirQuality mocks base method.
+func (m *MockWeather) GetAirQuality(ctx context.Context, query domain.WeatherQuery) (*domain.AirQualityResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAirQuality", ctx, query)
+	ret0, _ := ret[0].(*domain.AirQualityResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAirQuality indicates an expected call of GetAirQuality.
+func (mr *MockWeatherMockRecorder) GetAirQuality(ctx, query any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAirQuality", reflect.TypeOf((*MockWeather)(nil).GetAirQuality), ctx, query)
+}
+
 // GetCurrentWeather mocks base method.
-func (m *MockWeather) GetCurrentWeather(ctx context.Context, city string) (*domain.WeatherResponse, error) {
+func (m *MockWeather) GetCurrentWeather(ctx context.Context, query domain.WeatherQuery) (*domain.WeatherResponse, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetCurrentWeather", ctx, city)
+	ret := m.ctrl.Call(m, "GetCurrentWeather", ctx, query)
 	ret0, _ := ret[0].(*domain.WeatherResponse)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetCurrentWeather indicates an expected call of GetCurrentWeather.
-func (mr *MockWeatherMockRecorder) GetCurrentWeather(ctx, city any) *gomock.Call {
+func (mr *MockWeatherMockRecorder) GetCurrentWeather(ctx, query any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCurrentWeather", reflect.TypeOf((*MockWeather)(nil).GetCurrentWeather), ctx, city)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCurrentWeather", reflect.TypeOf((*MockWeather)(nil).GetCurrentWeather), ctx, query)
 }
 
 // GetDayWeather mocks base method.
-func (m *MockWeather) GetDayWeather(ctx context.Context, city string) (*domain.DayWeatherResponse, error) {
+func (m *MockWeather) GetDayWeather(ctx context.Context, query domain.WeatherQuery) (*domain.DayWeatherResponse, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetDayWeather", ctx, city)
+	ret := m.ctrl.Call(m, "GetDayWeather", ctx, query)
 	ret0, _ := ret[0].(*domain.DayWeatherResponse)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetDayWeather indicates an expected call of GetDayWeather.
-func (mr *MockWeatherMockRecorder) GetDayWeather(ctx, city any) *gomock.Call {
+func (mr *MockWeatherMockRecorder) GetDayWeather(ctx, query any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDayWeather", reflect.TypeOf((*MockWeather)(nil).GetDayWeather), ctx, query)
+}
+
+// GetForecast mocks base method.
+func (m *MockWeather) GetForecast(ctx context.Context, query domain.WeatherQuery, days int) (*domain.ForecastResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetForecast", ctx, query, days)
+	ret0, _ := ret[0].(*domain.ForecastResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetForecast indicates an expected call of GetForecast.
+func (mr *MockWeatherMockRecorder) GetForecast(ctx, query, days any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDayWeather", reflect.TypeOf((*MockWeather)(nil).GetDayWeather), ctx, city)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetForecast", reflect.TypeOf((*MockWeather)(nil).GetForecast), ctx, query, days)
 }
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"common/logger"
+	"context"
+	"errors"
+	"ms-weather-subscription/internal/config"
+	"ms-weather-subscription/internal/domain"
+	"ms-weather-subscription/pkg/lock"
+	"ms-weather-subscription/pkg/metrics"
+	"time"
+)
+
+type SubscriptionSchedulerRepository interface {
+	ClaimDue(
+		ctx context.Context, now time.Time, limit int, claimTTL time.Duration, claimToken int64,
+	) ([]domain.Subscription, error)
+	Reschedule(ctx context.Context, id string, nextRunAt *time.Time, claimToken int64) error
+}
+
+type ScheduledEmailSender interface {
+	SendWeatherForecasts(ctx context.Context, subscriptions []domain.Subscription, now time.Time) []domain.Subscription
+}
+
+// schedulerTokenKey is the key of the fencing tokens the scheduler runs claim the subscriptions with.
+const schedulerTokenKey = "subscription_scheduler"
+
+// SubscriptionScheduler sends the forecast emails of the subscriptions at their next run.
+// Each run claims the due subscriptions, so several instances can run it at the same time.
+// The emails are sent at least once: a subscription whose email failed isn't rescheduled
+// and is sent again once its claim expires after the claim TTL.
+// Each run claims the subscriptions with its own fencing token, so a run which outlived its claims
+// doesn't reschedule the subscriptions claimed again by another run.
+type SubscriptionScheduler struct {
+	repo   SubscriptionSchedulerRepository
+	sender ScheduledEmailSender
+	tokens lock.TokenIssuer
+	config config.SchedulerConfig
+	now    func() time.Time
+}
+
+func NewSubscriptionScheduler(
+	repo SubscriptionSchedulerRepository,
+	sender ScheduledEmailSender,
+	tokens lock.TokenIssuer,
+	schedulerConfig config.SchedulerConfig,
+) *SubscriptionScheduler {
+	return &SubscriptionScheduler{
+		repo:   repo,
+		sender: sender,
+		tokens: tokens,
+		config: schedulerConfig,
+		now:    time.Now,
+	}
+}
+
+// WithClock replaces the clock the due subscriptions are determined by.
+func (s *SubscriptionScheduler) WithClock(now func() time.Time) *SubscriptionScheduler {
+	s.now = now
+	return s
+}
+
+// RunDue sends the emails of all subscriptions due by now and schedules their next run.
+// Subscriptions which are never due again, e.g. within an active window without the hours
+// their emails are sent at, are left without a next run.
+func (s *SubscriptionScheduler) RunDue(ctx context.Context) error {
+	now := s.now()
+	token, err := s.tokens.NextToken(ctx, schedulerTokenKey)
+	if err != nil {
+		logger.Errorf("failed to issue the claim token: %s", err.Error())
+		return err
+	}
+
+	for {
+		subscriptions, err := s.repo.ClaimDue(ctx, now, s.config.BatchSize, s.config.ClaimTTL, token.Value)
+		if err != nil {
+			logger.Errorf("failed to claim due subscriptions: %s", err.Error())
+			return err
+		}
+
+		// The next run of the subscriptions confirmed before they had one is the next hour, due or not.
+		due := make([]domain.Subscription, 0, len(subscriptions))
+		for _, subscription := range subscriptions {
+			if subscription.NextRunAt != nil && subscription.IsDue(*subscription.NextRunAt) {
+				due = append(due, subscription)
+			}
+		}
+
+		failed := make(map[string]bool)
+		for _, subscription := range s.sender.SendWeatherForecasts(ctx, due, now) {
+			failed[subscription.ID] = true
+			metrics.ScheduledEmailFailedCount.WithLabelValues(subscription.Frequency).Inc()
+		}
+
+		// The sent emails are recorded even if the run is canceled, so they aren't sent again.
+		rescheduleCtx := context.WithoutCancel(ctx)
+		for _, subscription := range subscriptions {
+			if failed[subscription.ID] {
+				continue
+			}
+			err := s.repo.Reschedule(rescheduleCtx, subscription.ID, s.nextRun(subscription, now), token.Value)
+			if errors.Is(err, lock.ErrStaleToken) {
+				logger.Warnf("claim of subscription %s expired before it was rescheduled", subscription.ID)
+			} else if err != nil {
+				logger.Errorf("failed to reschedule subscription %s: %s", subscription.ID, err.Error())
+			}
+		}
+
+		if len(subscriptions) < s.config.BatchSize || ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+}
+
+func (s *SubscriptionScheduler) nextRun(subscription domain.Subscription, now time.Time) *time.Time {
+	next, ok := subscription.NextRun(now)
+	if !ok {
+		logger.Warnf("subscription %s (%s) is never due, it won't be sent", subscription.ID, subscription.Frequency)
+		return nil
+	}
+	return &next
+}
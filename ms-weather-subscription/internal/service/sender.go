@@ -6,102 +6,250 @@ import (
 	"ms-weather-subscription/internal/config"
 	"ms-weather-subscription/internal/domain"
 	"ms-weather-subscription/pkg/publisher"
+	"slices"
 	"time"
 )
 
 type (
-	WeatherFetcherFunc[T domain.WeatherResponseType] func(ctx context.Context, city string) (T, error)
+	WeatherFetcherFunc[T domain.WeatherResponseType] func(ctx context.Context, query domain.WeatherQuery) (T, error)
 	EmailSenderFunc[T domain.WeatherResponseType]    func(inp domain.WeatherForecastEmailInput[T]) error
+	AirQualityFetcherFunc                            func(
+		ctx context.Context, query domain.WeatherQuery,
+	) (*domain.AirQualityResponse, error)
 )
 
 type sendWeatherForecastInput[T domain.WeatherResponseType] struct {
 	ctx            context.Context
-	repo           SubscriptionSenderRepository
+	subscriptions  []domain.Subscription
 	emailPublisher publisher.EmailPublisher
 	frequency      string
+	now            time.Time
 	dateFormat     string
 	queue          string
 	getWeather     WeatherFetcherFunc[T]
+	getAirQuality  AirQualityFetcherFunc // nil means emails without the air quality section
+	inUnits        func(weather T, units domain.Units) T
 	baseURL        string
 }
 
-type SubscriptionSenderRepository interface {
-	GetConfirmedByFrequency(ctx context.Context, frequency string) ([]domain.Subscription, error)
-}
-
 type WeatherForecastSenderService struct {
-	httpConfig             config.HTTPConfig
-	emailPublisher         publisher.EmailPublisher
-	weatherService         Weather
-	subscriptionSenderRepo SubscriptionSenderRepository
+	httpConfig          config.HTTPConfig
+	dailyForecastConfig config.DailyForecastConfig
+	emailPublisher      publisher.EmailPublisher
+	weatherService      Weather
 }
 
 func NewWeatherForecastSenderService(
 	httpConfig config.HTTPConfig,
+	dailyForecastConfig config.DailyForecastConfig,
 	weatherService Weather,
-	subscriptionSenderRepo SubscriptionSenderRepository,
 	emailPublisher publisher.EmailPublisher,
 ) *WeatherForecastSenderService {
 	return &WeatherForecastSenderService{
-		httpConfig:             httpConfig,
-		emailPublisher:         emailPublisher,
-		weatherService:         weatherService,
-		subscriptionSenderRepo: subscriptionSenderRepo,
+		httpConfig:          httpConfig,
+		dailyForecastConfig: dailyForecastConfig,
+		emailPublisher:      emailPublisher,
+		weatherService:      weatherService,
+	}
+}
+
+// SendWeatherForecasts emails the weather as of now to the subscriptions, each one the email of its frequency,
+// and returns the subscriptions whose emails failed to be sent.
+// Subscriptions of the frequencies which aren't scheduled, e.g. alerts, are skipped.
+func (s *WeatherForecastSenderService) SendWeatherForecasts(
+	ctx context.Context, subscriptions []domain.Subscription, now time.Time,
+) (failed []domain.Subscription) {
+	senders := map[string]func(
+		ctx context.Context, subscriptions []domain.Subscription, now time.Time,
+	) []domain.Subscription{
+		domain.HourlyWeatherEmailFrequency:      s.sendHourlyWeatherForecast,
+		domain.Every3HoursWeatherEmailFrequency: s.sendEvery3HoursWeatherForecast,
+		domain.Every6HoursWeatherEmailFrequency: s.sendEvery6HoursWeatherForecast,
+		domain.DailyWeatherEmailFrequency:       s.sendDailyWeatherForecast,
+		domain.WeeklyWeatherEmailFrequency:      s.sendWeeklyWeatherForecast,
+	}
+
+	byFrequency := make(map[string][]domain.Subscription)
+	for _, subscription := range subscriptions {
+		byFrequency[subscription.Frequency] = append(byFrequency[subscription.Frequency], subscription)
 	}
+
+	for frequency, subscriptions := range byFrequency {
+		send, ok := senders[frequency]
+		if !ok {
+			logger.Errorf("no weather forecast email for %d %s subscriptions", len(subscriptions), frequency)
+			continue
+		}
+		failed = append(failed, send(ctx, subscriptions, now)...)
+	}
+	return failed
 }
 
-func (s *WeatherForecastSenderService) SendDailyWeatherForecast(ctx context.Context) error {
+// sendDailyWeatherForecast emails the day forecast.
+func (s *WeatherForecastSenderService) sendDailyWeatherForecast(
+	ctx context.Context, subscriptions []domain.Subscription, now time.Time,
+) []domain.Subscription {
 	return sendWeatherForecast(sendWeatherForecastInput[*domain.DayWeatherResponse]{
 		ctx:            ctx,
-		repo:           s.subscriptionSenderRepo,
+		subscriptions:  subscriptions,
 		emailPublisher: s.emailPublisher,
 		frequency:      domain.DailyWeatherEmailFrequency,
+		now:            now,
 		dateFormat:     time.DateOnly,
 		queue:          publisher.EmailDailyForecastQueue,
-		getWeather:     s.weatherService.GetDayWeather,
+		getWeather:     s.getDayWeather,
+		getAirQuality:  s.weatherService.GetAirQuality,
+		inUnits:        (*domain.DayWeatherResponse).InUnits,
 		baseURL:        s.httpConfig.BaseURL,
 	})
 }
 
-func (s *WeatherForecastSenderService) SendHourlyWeatherForecast(ctx context.Context) error {
+// getDayWeather returns the day forecast with only the hours configured for the daily email.
+func (s *WeatherForecastSenderService) getDayWeather(ctx context.Context, query domain.WeatherQuery) (
+	*domain.DayWeatherResponse, error,
+) {
+	weather, err := s.weatherService.GetDayWeather(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return weather.SelectHours(s.dailyForecastConfig.Hours), nil
+}
+
+// sendHourlyWeatherForecast emails the current weather.
+func (s *WeatherForecastSenderService) sendHourlyWeatherForecast(
+	ctx context.Context, subscriptions []domain.Subscription, now time.Time,
+) []domain.Subscription {
 	return sendWeatherForecast(sendWeatherForecastInput[*domain.WeatherResponse]{
 		ctx:            ctx,
-		repo:           s.subscriptionSenderRepo,
+		subscriptions:  subscriptions,
 		emailPublisher: s.emailPublisher,
 		frequency:      domain.HourlyWeatherEmailFrequency,
+		now:            now,
 		dateFormat:     time.DateTime,
 		queue:          publisher.EmailHourlyForecastQueue,
 		getWeather:     s.weatherService.GetCurrentWeather,
+		getAirQuality:  s.weatherService.GetAirQuality,
+		inUnits:        (*domain.WeatherResponse).InUnits,
 		baseURL:        s.httpConfig.BaseURL,
 	})
 }
 
-func sendWeatherForecast[T domain.WeatherResponseType](inp sendWeatherForecastInput[T]) error {
-	subs, err := inp.repo.GetConfirmedByFrequency(inp.ctx, inp.frequency)
+// sendEvery3HoursWeatherForecast emails the current weather and the forecast for the next 3 hours.
+func (s *WeatherForecastSenderService) sendEvery3HoursWeatherForecast(
+	ctx context.Context, subscriptions []domain.Subscription, now time.Time,
+) []domain.Subscription {
+	return sendWeatherForecast(sendWeatherForecastInput[*domain.Every3HoursWeatherResponse]{
+		ctx:            ctx,
+		subscriptions:  subscriptions,
+		emailPublisher: s.emailPublisher,
+		frequency:      domain.Every3HoursWeatherEmailFrequency,
+		now:            now,
+		dateFormat:     time.DateTime,
+		queue:          publisher.EmailEvery3HoursForecastQueue,
+		getWeather: func(ctx context.Context, query domain.WeatherQuery) (*domain.Every3HoursWeatherResponse, error) {
+			current, hours, err := s.getUpcomingWeather(ctx, query, now, 3)
+			if err != nil {
+				return nil, err
+			}
+			return &domain.Every3HoursWeatherResponse{Current: *current, Hours: hours}, nil
+		},
+		getAirQuality: s.weatherService.GetAirQuality,
+		inUnits:       (*domain.Every3HoursWeatherResponse).InUnits,
+		baseURL:       s.httpConfig.BaseURL,
+	})
+}
+
+// sendEvery6HoursWeatherForecast emails the current weather and the forecast for the next 6 hours.
+func (s *WeatherForecastSenderService) sendEvery6HoursWeatherForecast(
+	ctx context.Context, subscriptions []domain.Subscription, now time.Time,
+) []domain.Subscription {
+	return sendWeatherForecast(sendWeatherForecastInput[*domain.Every6HoursWeatherResponse]{
+		ctx:            ctx,
+		subscriptions:  subscriptions,
+		emailPublisher: s.emailPublisher,
+		frequency:      domain.Every6HoursWeatherEmailFrequency,
+		now:            now,
+		dateFormat:     time.DateTime,
+		queue:          publisher.EmailEvery6HoursForecastQueue,
+		getWeather: func(ctx context.Context, query domain.WeatherQuery) (*domain.Every6HoursWeatherResponse, error) {
+			current, hours, err := s.getUpcomingWeather(ctx, query, now, 6)
+			if err != nil {
+				return nil, err
+			}
+			return &domain.Every6HoursWeatherResponse{Current: *current, Hours: hours}, nil
+		},
+		getAirQuality: s.weatherService.GetAirQuality,
+		inUnits:       (*domain.Every6HoursWeatherResponse).InUnits,
+		baseURL:       s.httpConfig.BaseURL,
+	})
+}
+
+// getUpcomingWeather returns the current weather together with the forecast for the given number of hours.
+func (s *WeatherForecastSenderService) getUpcomingWeather(
+	ctx context.Context, query domain.WeatherQuery, now time.Time, hours int,
+) (*domain.WeatherResponse, []domain.HourlyWeather, error) {
+	current, err := s.weatherService.GetCurrentWeather(ctx, query)
 	if err != nil {
-		logger.Errorf("failed to get subscriptions (%s): %s", inp.frequency, err.Error())
-		return err
+		return nil, nil, err
 	}
-
-	cityToSubscriptions := make(map[string][]domain.Subscription)
-	for _, sub := range subs {
-		cityToSubscriptions[sub.City] = append(cityToSubscriptions[sub.City], sub)
+	day, err := s.weatherService.GetDayWeather(ctx, query)
+	if err != nil {
+		return nil, nil, err
 	}
+	return current, day.UpcomingHours(now, hours), nil
+}
 
-	for city, subscriptions := range cityToSubscriptions {
-		weatherData, err := inp.getWeather(inp.ctx, city)
+// sendWeeklyWeatherForecast emails the 7-day outlook.
+func (s *WeatherForecastSenderService) sendWeeklyWeatherForecast(
+	ctx context.Context, subscriptions []domain.Subscription, now time.Time,
+) []domain.Subscription {
+	return sendWeatherForecast(sendWeatherForecastInput[*domain.ForecastResponse]{
+		ctx:            ctx,
+		subscriptions:  subscriptions,
+		emailPublisher: s.emailPublisher,
+		frequency:      domain.WeeklyWeatherEmailFrequency,
+		now:            now,
+		dateFormat:     time.DateOnly,
+		queue:          publisher.EmailWeeklyForecastQueue,
+		getWeather: func(ctx context.Context, query domain.WeatherQuery) (*domain.ForecastResponse, error) {
+			return s.weatherService.GetForecast(ctx, query, domain.WeeklyForecastDays)
+		},
+		inUnits: (*domain.ForecastResponse).InUnits,
+		baseURL: s.httpConfig.BaseURL,
+	})
+}
+
+// sendWeatherForecast emails the weather to the subscriptions and returns the ones whose emails failed to be sent.
+func sendWeatherForecast[T domain.WeatherResponseType](inp sendWeatherForecastInput[T]) (failed []domain.Subscription) {
+	for _, subscriptions := range groupByLocation(inp.subscriptions) {
+		query := subscriptions[0].WeatherQuery()
+		weatherData, err := inp.getWeather(inp.ctx, query)
 		if err != nil {
-			logger.Errorf("failed to get weather (%s) for city %s: %s", inp.frequency, city, err.Error())
+			logger.Errorf("failed to get weather (%s) for %s: %s", inp.frequency, query, err.Error())
+			failed = append(failed, subscriptions...)
 			continue
 		}
 
+		airQuality := getAirQuality(inp, subscriptions)
+
+		// The weather is fetched in metric units and converted once per unit system.
+		weatherInUnits := make(map[domain.Units]T)
 		for _, subscription := range subscriptions {
+			weather, ok := weatherInUnits[subscription.Units]
+			if !ok {
+				weather = inp.inUnits(weatherData, subscription.Units)
+				weatherInUnits[subscription.Units] = weather
+			}
+
 			emailInput := domain.WeatherForecastEmailInput[T]{
 				Subscription:    subscription,
-				Weather:         weatherData,
-				Date:            time.Now().Format(inp.dateFormat),
+				Weather:         weather,
+				Date:            subscription.LocalTime(inp.now).Format(inp.dateFormat),
 				UnsubscribeLink: subscription.CreateUnsubscribeLink(inp.baseURL),
 			}
+			if subscription.AirQuality {
+				emailInput.AirQuality = airQuality
+			}
 
 			if err := inp.emailPublisher.Publish(inp.queue, emailInput); err != nil {
 				logger.Errorf(
@@ -110,10 +258,41 @@ func sendWeatherForecast[T domain.WeatherResponseType](inp sendWeatherForecastIn
 					subscription.Email,
 					err.Error(),
 				)
+				failed = append(failed, subscription)
 				continue
 			}
 		}
 	}
+	return failed
+}
+
+// getAirQuality returns the air quality of the subscriptions' location if any of them opted into it.
+// The emails are sent without air quality if it can't be fetched.
+func getAirQuality[T domain.WeatherResponseType](
+	inp sendWeatherForecastInput[T], subscriptions []domain.Subscription,
+) *domain.AirQualityResponse {
+	if inp.getAirQuality == nil {
+		return nil
+	}
+	if !slices.ContainsFunc(subscriptions, func(s domain.Subscription) bool { return s.AirQuality }) {
+		return nil
+	}
 
-	return nil
+	query := subscriptions[0].WeatherQuery()
+	airQuality, err := inp.getAirQuality(inp.ctx, query)
+	if err != nil {
+		logger.Errorf("failed to get air quality (%s) for %s: %s", inp.frequency, query, err.Error())
+		return nil
+	}
+	return airQuality
+}
+
+// groupByLocation groups subscriptions by their location, so weather is requested once per location.
+func groupByLocation(subs []domain.Subscription) map[string][]domain.Subscription {
+	locationToSubscriptions := make(map[string][]domain.Subscription)
+	for _, sub := range subs {
+		key := sub.LocationKey()
+		locationToSubscriptions[key] = append(locationToSubscriptions[key], sub)
+	}
+	return locationToSubscriptions
 }
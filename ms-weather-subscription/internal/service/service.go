@@ -5,9 +5,12 @@ import (
 	"ms-weather-subscription/internal/config"
 	"ms-weather-subscription/internal/domain"
 	"ms-weather-subscription/internal/repository"
+	"ms-weather-subscription/pkg/cache"
 	"ms-weather-subscription/pkg/clients"
 	"ms-weather-subscription/pkg/hash"
+	"ms-weather-subscription/pkg/lock"
 	"ms-weather-subscription/pkg/publisher"
+	"time"
 )
 
 //go:generate mockgen -source=service.go -destination=mocks/mock_service.go
@@ -18,45 +21,106 @@ type Subscription interface {
 	Delete(ctx context.Context, token string) error
 }
 
+type Location interface {
+	Resolve(ctx context.Context, query string) (domain.Location, error)
+	ResolveCoordinates(ctx context.Context, coordinates domain.Coordinates) (domain.Location, error)
+}
+
 type WeatherForecastSender interface {
-	SendHourlyWeatherForecast(ctx context.Context) error
-	SendDailyWeatherForecast(ctx context.Context) error
+	SendWeatherForecasts(ctx context.Context, subscriptions []domain.Subscription, now time.Time) []domain.Subscription
+}
+
+type Scheduler interface {
+	RunDue(ctx context.Context) error
+}
+
+type WeatherAlertSender interface {
+	SendWeatherAlerts(ctx context.Context) error
+	DeleteExpiredSentAlerts(ctx context.Context) error
+}
+
+type Alerts interface {
+	GetAlerts(ctx context.Context, query domain.WeatherQuery) ([]domain.WeatherAlert, error)
+}
+
+type WeatherHistory interface {
+	GetHistory(ctx context.Context, query domain.WeatherQuery, from, to time.Time) ([]domain.WeatherSnapshot, error)
+	DeleteExpiredSnapshots(ctx context.Context) error
 }
 
 type Weather interface {
-	GetCurrentWeather(ctx context.Context, city string) (*domain.WeatherResponse, error)
-	GetDayWeather(ctx context.Context, city string) (*domain.DayWeatherResponse, error)
+	GetCurrentWeather(ctx context.Context, query domain.WeatherQuery) (*domain.WeatherResponse, error)
+	GetDayWeather(ctx context.Context, query domain.WeatherQuery) (*domain.DayWeatherResponse, error)
+	GetForecast(ctx context.Context, query domain.WeatherQuery, days int) (*domain.ForecastResponse, error)
+	GetAirQuality(ctx context.Context, query domain.WeatherQuery) (*domain.AirQualityResponse, error)
 }
 
 type Deps struct {
-	Repos              *repository.Repositories
-	WeatherClient      clients.WeatherClient
+	Repos         *repository.Repositories
+	WeatherClient clients.WeatherClient
+	// APIWeatherClient serves the weather requested via the API, e.g. hedged for a lower latency,
+	// while the emails are sent with WeatherClient. WeatherClient serves both if it is nil.
+	APIWeatherClient   clients.WeatherClient
+	AlertsClient       clients.AlertsClient
+	LocationSearcher   clients.LocationSearcher
+	Cache              cache.Cache
 	SubscriptionHasher hash.SubscriptionHasher
 	HTTPConfig         config.HTTPConfig
-	EmailPublisher     publisher.EmailPublisher
+	DailyForecast      config.DailyForecastConfig
+	WeatherHistory     config.WeatherHistoryConfig
+	Scheduler          config.SchedulerConfig
+	// SchedulerTokens issues the fencing tokens the scheduler claims the due subscriptions with.
+	SchedulerTokens lock.TokenIssuer
+	EmailPublisher  publisher.EmailPublisher
 }
 
 type Services struct {
 	Subscriptions         Subscription
+	Locations             Location
 	Weather               Weather
 	WeatherForecastSender WeatherForecastSender
+	Scheduler             Scheduler
+	WeatherAlertSender    WeatherAlertSender
+	WeatherHistory        WeatherHistory
 }
 
 func NewServices(deps Deps) *Services {
-	weatherService := NewWeatherService(deps.WeatherClient)
+	locationService := NewLocationService(deps.Repos.Location, deps.LocationSearcher, deps.Cache)
+	weatherService := NewWeatherService(deps.WeatherClient, locationService)
+	apiWeatherService := weatherService
+	if deps.APIWeatherClient != nil {
+		apiWeatherService = NewWeatherService(deps.APIWeatherClient, locationService)
+	}
+	forecastSender := NewWeatherForecastSenderService(
+		deps.HTTPConfig,
+		deps.DailyForecast,
+		weatherService,
+		deps.EmailPublisher,
+	)
 	return &Services{
 		Subscriptions: NewSubscriptionService(
 			deps.HTTPConfig,
 			deps.Repos.Subscription,
+			locationService,
 			deps.SubscriptionHasher,
 			deps.EmailPublisher,
 		),
-		Weather: weatherService,
-		WeatherForecastSender: NewWeatherForecastSenderService(
+		Locations:             locationService,
+		Weather:               apiWeatherService,
+		WeatherForecastSender: forecastSender,
+		Scheduler: NewSubscriptionScheduler(
+			deps.Repos.Subscription,
+			forecastSender,
+			deps.SchedulerTokens,
+			deps.Scheduler,
+		),
+		WeatherAlertSender: NewWeatherAlertSenderService(
 			deps.HTTPConfig,
-			weatherService,
+			NewAlertsService(deps.AlertsClient),
 			deps.Repos.Subscription,
+			deps.Repos.SentAlert,
 			deps.EmailPublisher,
 		),
+		WeatherHistory: NewWeatherHistoryService(deps.WeatherHistory, deps.Repos.WeatherSnapshot, locationService),
 	}
 }
@@ -4,19 +4,22 @@ import (
 	"context"
 	"ms-weather-subscription/internal/config"
 	"ms-weather-subscription/internal/domain"
+	customErrors "ms-weather-subscription/pkg/errors"
 	"ms-weather-subscription/pkg/hash"
 	"ms-weather-subscription/pkg/publisher"
+	"time"
 )
 
 type SubscriptionRepository interface {
 	Create(ctx context.Context, subscription domain.Subscription) error
 	GetByToken(ctx context.Context, token string) (domain.Subscription, error)
-	Confirm(ctx context.Context, token string) error
+	Confirm(ctx context.Context, token string, nextRunAt *time.Time) error
 	Delete(ctx context.Context, token string) error
 }
 
 type SubscriptionService struct {
 	repo           SubscriptionRepository
+	locations      Location
 	hasher         hash.SubscriptionHasher
 	httpConfig     config.HTTPConfig
 	emailPublisher publisher.EmailPublisher
@@ -25,22 +28,37 @@ type SubscriptionService struct {
 func NewSubscriptionService(
 	httpConfig config.HTTPConfig,
 	repo SubscriptionRepository,
+	locations Location,
 	hasher hash.SubscriptionHasher,
 	emailPublisher publisher.EmailPublisher,
 ) *SubscriptionService {
 	return &SubscriptionService{
 		httpConfig:     httpConfig,
 		repo:           repo,
+		locations:      locations,
 		hasher:         hasher,
 		emailPublisher: emailPublisher,
 	}
 }
 
+// Create subscribes to the weather of the location the city or the coordinates resolve to.
+// It fails with ErrCityNotFound if the city can't be resolved.
 func (s *SubscriptionService) Create(ctx context.Context, inp domain.CreateSubscriptionInput) error {
-	token := s.hasher.GenerateSubscriptionHash(inp.Email, inp.City, inp.Frequency)
+	location, err := resolvePlace(ctx, s.locations, inp.Place)
+	if err != nil {
+		return err
+	}
+
+	token := s.hasher.GenerateSubscriptionHash(inp.Email, location.ID, inp.Frequency)
 
-	subscription := domain.NewSubscription(inp.Email, inp.City, inp.Frequency, token)
-	err := s.repo.Create(ctx, subscription)
+	subscription := domain.NewSubscription(inp, location, token)
+	// Confirmed subscriptions without a next run are never emailed, e.g. every 3 hours
+	// within a window between the hours it is sent at, so they aren't created.
+	if _, ok := subscription.NextRun(time.Now()); domain.IsScheduledFrequency(inp.Frequency) && !ok {
+		return customErrors.ErrSubscriptionNeverDue
+	}
+
+	err = s.repo.Create(ctx, subscription)
 
 	if err != nil {
 		return err
@@ -64,13 +82,18 @@ func (s *SubscriptionService) Create(ctx context.Context, inp domain.CreateSubsc
 	return nil
 }
 
+// Confirm confirms the subscription and schedules its first email.
 func (s *SubscriptionService) Confirm(ctx context.Context, token string) error {
-	_, err := s.repo.GetByToken(ctx, token)
+	subscription, err := s.repo.GetByToken(ctx, token)
 	if err != nil {
 		return err
 	}
 
-	return s.repo.Confirm(ctx, token)
+	var nextRunAt *time.Time
+	if next, ok := subscription.NextRun(time.Now()); ok {
+		nextRunAt = &next
+	}
+	return s.repo.Confirm(ctx, token, nextRunAt)
 }
 
 func (s *SubscriptionService) Delete(ctx context.Context, token string) error {
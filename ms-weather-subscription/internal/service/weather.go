@@ -1,27 +1,73 @@
 package service
 
 import (
+	"common/logger"
 	"context"
+	"errors"
 	"ms-weather-subscription/internal/domain"
 	"ms-weather-subscription/pkg/clients"
+	customErrors "ms-weather-subscription/pkg/errors"
 )
 
 type WeatherService struct {
-	client clients.WeatherClient
+	client    clients.WeatherClient
+	locations Location
 }
 
-func NewWeatherService(client clients.WeatherClient) *WeatherService {
-	return &WeatherService{client: client}
+func NewWeatherService(client clients.WeatherClient, locations Location) *WeatherService {
+	return &WeatherService{
+		client:    client,
+		locations: locations,
+	}
 }
 
-func (s *WeatherService) GetCurrentWeather(ctx context.Context, city string) (
+// GetCurrentWeather resolves the location of the query first, so the weather is recorded in the history
+// of the location whichever spelling of the city it was requested by.
+func (s *WeatherService) GetCurrentWeather(ctx context.Context, query domain.WeatherQuery) (
 	*domain.WeatherResponse, error,
 ) {
-	return s.client.GetAPICurrentWeather(ctx, city)
+	query, err := s.withLocation(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return s.client.GetAPICurrentWeather(ctx, query)
 }
 
-func (s *WeatherService) GetDayWeather(ctx context.Context, city string) (
+func (s *WeatherService) GetDayWeather(ctx context.Context, query domain.WeatherQuery) (
 	*domain.DayWeatherResponse, error,
 ) {
-	return s.client.GetAPIDayWeather(ctx, city)
+	return s.client.GetAPIDayWeather(ctx, query)
+}
+
+func (s *WeatherService) GetForecast(ctx context.Context, query domain.WeatherQuery, days int) (
+	*domain.ForecastResponse, error,
+) {
+	return s.client.GetAPIForecast(ctx, query, days)
+}
+
+func (s *WeatherService) GetAirQuality(ctx context.Context, query domain.WeatherQuery) (
+	*domain.AirQualityResponse, error,
+) {
+	return s.client.GetAPIAirQuality(ctx, query)
+}
+
+// withLocation sets the resolved location of the query unless it is known already. If the location
+// can't be resolved for another reason than an unknown city, the weather is still requested,
+// it just isn't recorded in the history.
+func (s *WeatherService) withLocation(ctx context.Context, query domain.WeatherQuery) (domain.WeatherQuery, error) {
+	if query.LocationID != "" {
+		return query, nil
+	}
+
+	location, err := resolvePlace(ctx, s.locations, query)
+	switch {
+	case errors.Is(err, customErrors.ErrCityNotFound):
+		return query, err
+	case err != nil:
+		logger.Warnf("failed to resolve location of %s: %v", query, err)
+		return query, nil
+	}
+
+	query.LocationID = location.ID
+	return query, nil
 }
@@ -0,0 +1,52 @@
+package service
+
+import (
+	"common/logger"
+	"context"
+	"ms-weather-subscription/internal/config"
+	"ms-weather-subscription/internal/domain"
+	"ms-weather-subscription/internal/repository"
+	"time"
+)
+
+type WeatherHistoryService struct {
+	config    config.WeatherHistoryConfig
+	repo      repository.WeatherSnapshotRepository
+	locations Location
+}
+
+func NewWeatherHistoryService(
+	config config.WeatherHistoryConfig,
+	repo repository.WeatherSnapshotRepository,
+	locations Location,
+) *WeatherHistoryService {
+	return &WeatherHistoryService{
+		config:    config,
+		repo:      repo,
+		locations: locations,
+	}
+}
+
+// GetHistory returns the weather received for the location of the city or the coordinates
+// in [from, to), oldest first.
+func (s *WeatherHistoryService) GetHistory(
+	ctx context.Context, query domain.WeatherQuery, from, to time.Time,
+) ([]domain.WeatherSnapshot, error) {
+	location, err := resolvePlace(ctx, s.locations, query)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.GetByLocation(ctx, location.ID, from, to)
+}
+
+// DeleteExpiredSnapshots deletes the weather snapshots older than the configured retention.
+func (s *WeatherHistoryService) DeleteExpiredSnapshots(ctx context.Context) error {
+	deleted, err := s.repo.DeleteObservedBefore(ctx, time.Now().Add(-s.config.Retention))
+	if err != nil {
+		logger.Errorf("failed to delete expired weather snapshots: %s", err.Error())
+		return err
+	}
+
+	logger.Infof("deleted %d expired weather snapshots", deleted)
+	return nil
+}
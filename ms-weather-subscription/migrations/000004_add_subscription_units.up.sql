@@ -0,0 +1,3 @@
+ALTER TABLE subscriptions
+    ADD COLUMN IF NOT EXISTS units VARCHAR(16) NOT NULL DEFAULT 'metric'
+    CONSTRAINT subscriptions_units_check CHECK (units IN ('metric', 'imperial', 'standard'));
@@ -0,0 +1,4 @@
+ALTER TABLE subscriptions DROP COLUMN IF EXISTS location_id;
+
+DROP TABLE IF EXISTS location_aliases;
+DROP TABLE IF EXISTS locations;
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS locations (
+    id VARCHAR(255) PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    country VARCHAR(255) NOT NULL DEFAULT '',
+    latitude DOUBLE PRECISION NOT NULL,
+    longitude DOUBLE PRECISION NOT NULL,
+    timezone VARCHAR(64) NOT NULL DEFAULT ''
+);
+
+-- Normalized user input each location was resolved from, e.g. "kyiv", "kiev", "київ".
+CREATE TABLE IF NOT EXISTS location_aliases (
+    alias VARCHAR(255) PRIMARY KEY,
+    location_id VARCHAR(255) NOT NULL REFERENCES locations (id) ON DELETE CASCADE
+);
+
+-- Subscriptions created before locations were introduced keep a NULL location_id.
+ALTER TABLE subscriptions
+    ADD COLUMN IF NOT EXISTS location_id VARCHAR(255) REFERENCES locations (id);
@@ -0,0 +1,8 @@
+-- Weather alerts already emailed to each subscription, so the same alert is never sent twice.
+CREATE TABLE IF NOT EXISTS sent_alerts (
+    subscription_id UUID NOT NULL REFERENCES subscriptions (id) ON DELETE CASCADE,
+    alert_id TEXT NOT NULL,
+    sent_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+    PRIMARY KEY (subscription_id, alert_id)
+);
@@ -0,0 +1,2 @@
+ALTER TABLE subscriptions
+    ADD COLUMN IF NOT EXISTS air_quality BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,14 @@
+-- Current weather received from providers, kept for the configured retention period.
+-- city is the normalized city the weather was requested for, NULL for requests by coordinates only.
+CREATE TABLE IF NOT EXISTS weather_snapshots (
+    id BIGSERIAL PRIMARY KEY,
+    city VARCHAR(255),
+    latitude DOUBLE PRECISION,
+    longitude DOUBLE PRECISION,
+    provider VARCHAR(64) NOT NULL,
+    observed_at TIMESTAMPTZ NOT NULL,
+    metrics JSONB NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS weather_snapshots_city_observed_at_idx ON weather_snapshots (city, observed_at);
+CREATE INDEX IF NOT EXISTS weather_snapshots_observed_at_idx ON weather_snapshots (observed_at);
@@ -0,0 +1,11 @@
+-- Daily emails are sent at delivery_hour in the subscription's timezone.
+ALTER TABLE subscriptions
+    ADD COLUMN IF NOT EXISTS timezone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+    ADD COLUMN IF NOT EXISTS delivery_hour SMALLINT NOT NULL DEFAULT 7
+        CHECK (delivery_hour BETWEEN 0 AND 23);
+
+-- Existing subscriptions get the timezone of their resolved city.
+UPDATE subscriptions s
+SET timezone = l.timezone
+FROM locations l
+WHERE l.id = s.location_id AND l.timezone <> '';
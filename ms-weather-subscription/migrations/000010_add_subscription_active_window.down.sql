@@ -0,0 +1,4 @@
+ALTER TABLE subscriptions
+    DROP COLUMN IF EXISTS weekdays_only,
+    DROP COLUMN IF EXISTS active_to,
+    DROP COLUMN IF EXISTS active_from;
@@ -0,0 +1,8 @@
+-- Hourly emails are sent from active_from to active_to (local hours, inclusive) in the subscription's timezone.
+-- The window wraps past midnight if active_from is after active_to.
+ALTER TABLE subscriptions
+    ADD COLUMN IF NOT EXISTS active_from SMALLINT NOT NULL DEFAULT 0
+        CHECK (active_from BETWEEN 0 AND 23),
+    ADD COLUMN IF NOT EXISTS active_to SMALLINT NOT NULL DEFAULT 23
+        CHECK (active_to BETWEEN 0 AND 23),
+    ADD COLUMN IF NOT EXISTS weekdays_only BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,4 @@
+-- Must list the same frequencies as common/frequency.All.
+ALTER TABLE subscriptions
+    ADD CONSTRAINT subscriptions_frequency_check
+    CHECK (frequency IN ('hourly', 'every_3h', 'every_6h', 'daily', 'weekly', 'alerts'));
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS subscriptions_next_run_at_idx;
+ALTER TABLE subscriptions DROP COLUMN IF EXISTS next_run_at;
@@ -0,0 +1,11 @@
+-- The scheduler claims confirmed subscriptions whose next_run_at has come, sends them
+-- and moves next_run_at to their next run. NULL means the subscription isn't scheduled.
+ALTER TABLE subscriptions ADD COLUMN IF NOT EXISTS next_run_at TIMESTAMPTZ;
+
+-- Existing subscriptions are claimed at the next hour and then rescheduled;
+-- the ones which aren't due at that hour are only rescheduled.
+UPDATE subscriptions
+SET next_run_at = date_trunc('hour', now()) + INTERVAL '1 hour'
+WHERE confirmed = true AND frequency <> 'alerts';
+
+CREATE INDEX IF NOT EXISTS subscriptions_next_run_at_idx ON subscriptions (next_run_at) WHERE confirmed = true;
@@ -0,0 +1,3 @@
+-- The scheduler claims due subscriptions until claimed_until and moves next_run_at once their emails are sent.
+-- Subscriptions whose emails failed stay due and are claimed again once the claim expires.
+ALTER TABLE subscriptions ADD COLUMN IF NOT EXISTS claimed_until TIMESTAMPTZ;
@@ -0,0 +1,12 @@
+ALTER TABLE weather_snapshots ADD COLUMN IF NOT EXISTS city VARCHAR(255);
+
+-- Locations created from coordinates have no country and were recorded without a city.
+UPDATE weather_snapshots ws
+SET city = lower(l.name)
+FROM locations l
+WHERE l.id = ws.location_id AND l.country <> '';
+
+DROP INDEX IF EXISTS weather_snapshots_location_id_observed_at_idx;
+ALTER TABLE weather_snapshots DROP COLUMN IF EXISTS location_id;
+
+CREATE INDEX IF NOT EXISTS weather_snapshots_city_observed_at_idx ON weather_snapshots (city, observed_at);
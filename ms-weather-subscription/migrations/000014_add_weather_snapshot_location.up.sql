@@ -0,0 +1,15 @@
+-- Snapshots are recorded under the resolved location, so the history of a city is the same
+-- whichever spelling it was requested by. Snapshots of the cities resolved before are moved
+-- to their locations, the rest expire with the retention period.
+ALTER TABLE weather_snapshots ADD COLUMN IF NOT EXISTS location_id VARCHAR(255);
+
+UPDATE weather_snapshots ws
+SET location_id = la.location_id
+FROM location_aliases la
+WHERE la.alias = ws.city;
+
+DROP INDEX IF EXISTS weather_snapshots_city_observed_at_idx;
+ALTER TABLE weather_snapshots DROP COLUMN IF EXISTS city;
+
+CREATE INDEX IF NOT EXISTS weather_snapshots_location_id_observed_at_idx
+    ON weather_snapshots (location_id, observed_at);
@@ -0,0 +1,3 @@
+ALTER TABLE subscriptions DROP COLUMN IF EXISTS claim_token;
+
+DROP TABLE IF EXISTS fencing_tokens;
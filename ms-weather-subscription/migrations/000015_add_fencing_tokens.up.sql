@@ -0,0 +1,9 @@
+-- The latest fencing token each lock was written with, the writes made with an older token are rejected.
+CREATE TABLE IF NOT EXISTS fencing_tokens (
+    key   VARCHAR(255) PRIMARY KEY,
+    token BIGINT NOT NULL
+);
+
+-- The token of the scheduler run which claimed the subscription, a run whose claim was taken over
+-- after it expired can't reschedule the subscription.
+ALTER TABLE subscriptions ADD COLUMN IF NOT EXISTS claim_token BIGINT;
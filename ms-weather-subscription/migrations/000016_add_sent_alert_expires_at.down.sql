@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS sent_alerts_expires_at_idx;
+
+ALTER TABLE sent_alerts DROP COLUMN IF EXISTS expires_at;
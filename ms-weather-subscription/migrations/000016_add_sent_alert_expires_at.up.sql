@@ -0,0 +1,9 @@
+-- Sent alerts are remembered until the alert expires, then they are deleted by the nightly cleanup.
+-- The alerts sent before are remembered for the 30 days the alerts without an expiry time are.
+ALTER TABLE sent_alerts ADD COLUMN IF NOT EXISTS expires_at TIMESTAMPTZ;
+
+UPDATE sent_alerts SET expires_at = sent_at + INTERVAL '30 days' WHERE expires_at IS NULL;
+
+ALTER TABLE sent_alerts ALTER COLUMN expires_at SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS sent_alerts_expires_at_idx ON sent_alerts (expires_at);
@@ -7,11 +7,21 @@ import (
 	"github.com/redis/go-redis/v9"
 )
 
+// keyNotFoundTTL is what PTTL returns for a missing key; -1 is returned for a key without expiry.
+const keyNotFoundTTL = -2
+
 type Cache interface {
 	Set(ctx context.Context, key string, value string, ttl time.Duration) error
 	Get(ctx context.Context, key string) (string, error)
 }
 
+// ExpiringCache is a Cache which also tells how long the values it returns are kept for.
+type ExpiringCache interface {
+	Cache
+	// GetWithTTL returns the value of key with its remaining TTL, which is negative if the value never expires.
+	GetWithTTL(ctx context.Context, key string) (string, time.Duration, error)
+}
+
 type RedisCache struct {
 	client *redis.Client
 }
@@ -27,3 +37,24 @@ func (r *RedisCache) Set(ctx context.Context, key, value string, ttl time.Durati
 func (r *RedisCache) Get(ctx context.Context, key string) (string, error) {
 	return r.client.Get(ctx, key).Result()
 }
+
+// GetWithTTL reads the value and its PTTL in one round trip.
+// A key expiring between the two reads is reported as a miss.
+func (r *RedisCache) GetWithTTL(ctx context.Context, key string) (string, time.Duration, error) {
+	var (
+		value *redis.StringCmd
+		ttl   *redis.DurationCmd
+	)
+	_, err := r.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		value = pipe.Get(ctx, key)
+		ttl = pipe.PTTL(ctx, key)
+		return nil
+	})
+	if err != nil {
+		return "", 0, err
+	}
+	if ttl.Val() == keyNotFoundTTL {
+		return "", 0, redis.Nil
+	}
+	return value.Val(), ttl.Val(), nil
+}
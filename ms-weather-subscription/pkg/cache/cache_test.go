@@ -0,0 +1,57 @@
+package cache_test
+
+import (
+	"context"
+	"ms-weather-subscription/pkg/cache"
+	"ms-weather-subscription/testutils"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedisCacheGetWithTTL(t *testing.T) {
+	t.Run("Value with TTL", testRedisCacheGetWithTTL)
+	t.Run("Value without expiry", testRedisCacheGetWithoutExpiry)
+	t.Run("Missing value", testRedisCacheGetWithTTLMiss)
+}
+
+func testRedisCacheGetWithTTL(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	redisCache := cache.NewCache(testutils.SetupTestRedis(t))
+	assert.NoError(t, redisCache.Set(ctx, "cache:ttl", "sunny", time.Minute))
+
+	// Execute
+	value, ttl, err := redisCache.GetWithTTL(ctx, "cache:ttl")
+
+	// Verify
+	assert.NoError(t, err)
+	assert.Equal(t, "sunny", value)
+	assert.Greater(t, ttl, 59*time.Second)
+	assert.LessOrEqual(t, ttl, time.Minute)
+}
+
+func testRedisCacheGetWithoutExpiry(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	redisCache := cache.NewCache(testutils.SetupTestRedis(t))
+	assert.NoError(t, redisCache.Set(ctx, "cache:no_expiry", "sunny", 0))
+
+	// Execute
+	value, ttl, err := redisCache.GetWithTTL(ctx, "cache:no_expiry")
+
+	// Verify
+	assert.NoError(t, err)
+	assert.Equal(t, "sunny", value)
+	assert.Negative(t, ttl)
+}
+
+func testRedisCacheGetWithTTLMiss(t *testing.T) {
+	// Execute
+	_, _, err := cache.NewCache(testutils.SetupTestRedis(t)).GetWithTTL(context.Background(), "cache:missing")
+
+	// Verify
+	assert.ErrorIs(t, err, redis.Nil)
+}
@@ -0,0 +1,70 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// CounterWindow is a counter which is allowed to reach Limit. It is removed TTL after it was created.
+type CounterWindow struct {
+	Key   string
+	Limit int64
+	TTL   time.Duration
+}
+
+// Counter counts calls in windows shared between all replicas of the service.
+type Counter interface {
+	// IncrWithinLimits increments the counters of all windows at once, unless any of them has reached its limit,
+	// in which case none of them is incremented. It returns the values of the counters in the order of windows
+	// and whether they were incremented.
+	IncrWithinLimits(ctx context.Context, windows []CounterWindow) ([]int64, bool, error)
+}
+
+// incrWithinLimitsScript checks the counters at KEYS against the limits, ARGV holds the limit and
+// the TTL in milliseconds of each key. It returns 1 followed by the incremented values,
+// or 0 followed by the current values if any counter has reached its limit.
+var incrWithinLimitsScript = redis.NewScript(`
+local values = {}
+local allowed = 1
+for i, key in ipairs(KEYS) do
+	values[i] = tonumber(redis.call("GET", key) or "0")
+	if values[i] >= tonumber(ARGV[2 * i - 1]) then
+		allowed = 0
+	end
+end
+if allowed == 1 then
+	for i, key in ipairs(KEYS) do
+		values[i] = redis.call("INCR", key)
+		if values[i] == 1 then
+			redis.call("PEXPIRE", key, ARGV[2 * i])
+		end
+	end
+end
+table.insert(values, 1, allowed)
+return values
+`)
+
+type RedisCounter struct {
+	client *redis.Client
+}
+
+func NewRedisCounter(redisClient *redis.Client) *RedisCounter {
+	return &RedisCounter{client: redisClient}
+}
+
+func (r *RedisCounter) IncrWithinLimits(ctx context.Context, windows []CounterWindow) ([]int64, bool, error) {
+	keys := make([]string, 0, len(windows))
+	args := make([]any, 0, 2*len(windows))
+	for _, window := range windows {
+		keys = append(keys, window.Key)
+		args = append(args, window.Limit, window.TTL.Milliseconds())
+	}
+
+	result, err := incrWithinLimitsScript.Run(ctx, r.client, keys, args...).Int64Slice()
+	if err != nil {
+		return nil, false, err
+	}
+	return result[1:], result[0] == 1, nil
+}
@@ -0,0 +1,75 @@
+package cache_test
+
+import (
+	"context"
+	"ms-weather-subscription/pkg/cache"
+	"ms-weather-subscription/testutils"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedisCounter(t *testing.T) {
+	t.Run("Counts within limits", testRedisCounterWithinLimits)
+	t.Run("Counts nothing once a limit is reached", testRedisCounterLimitReached)
+	t.Run("Counter expires", testRedisCounterExpires)
+}
+
+func testRedisCounterWithinLimits(t *testing.T) {
+	// Setup
+	counter := cache.NewRedisCounter(testutils.SetupTestRedis(t))
+	windows := []cache.CounterWindow{
+		{Key: "counter:within:rate", Limit: 2, TTL: time.Minute},
+		{Key: "counter:within:day", Limit: 5, TTL: time.Hour},
+	}
+
+	// Execute
+	_, ok, err := counter.IncrWithinLimits(context.Background(), windows)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	values, ok, err := counter.IncrWithinLimits(context.Background(), windows)
+
+	// Verify
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []int64{2, 2}, values)
+}
+
+func testRedisCounterLimitReached(t *testing.T) {
+	// Setup
+	counter := cache.NewRedisCounter(testutils.SetupTestRedis(t))
+	rate := cache.CounterWindow{Key: "counter:reached:rate", Limit: 5, TTL: time.Minute}
+	day := cache.CounterWindow{Key: "counter:reached:day", Limit: 1, TTL: time.Hour}
+
+	_, ok, err := counter.IncrWithinLimits(context.Background(), []cache.CounterWindow{rate, day})
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	// Execute
+	values, ok, err := counter.IncrWithinLimits(context.Background(), []cache.CounterWindow{rate, day})
+
+	// Verify: the rate counter isn't incremented as the daily one has reached its limit
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	assert.Equal(t, []int64{1, 1}, values)
+}
+
+func testRedisCounterExpires(t *testing.T) {
+	// Setup
+	counter := cache.NewRedisCounter(testutils.SetupTestRedis(t))
+	windows := []cache.CounterWindow{{Key: "counter:expires", Limit: 1, TTL: 100 * time.Millisecond}}
+
+	_, ok, err := counter.IncrWithinLimits(context.Background(), windows)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	// Execute
+	time.Sleep(200 * time.Millisecond)
+	values, ok, err := counter.IncrWithinLimits(context.Background(), windows)
+
+	// Verify
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []int64{1}, values)
+}
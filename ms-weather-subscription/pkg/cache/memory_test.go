@@ -0,0 +1,72 @@
+package cache_test
+
+import (
+	"context"
+	"ms-weather-subscription/pkg/cache"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryCache(t *testing.T) {
+	t.Run("Get returns value set before", testMemoryCacheGetSet)
+	t.Run("Expired value is a miss", testMemoryCacheExpiration)
+	t.Run("Least recently used value is evicted", testMemoryCacheEviction)
+}
+
+func testMemoryCacheGetSet(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	memoryCache := cache.NewMemoryCache(10)
+
+	_, err := memoryCache.Get(ctx, "kyiv")
+	assert.ErrorIs(t, err, cache.ErrCacheMiss)
+
+	assert.NoError(t, memoryCache.Set(ctx, "kyiv", "sunny", time.Minute))
+	assert.NoError(t, memoryCache.Set(ctx, "kyiv", "rain", time.Minute))
+
+	value, err := memoryCache.Get(ctx, "kyiv")
+	assert.NoError(t, err)
+	assert.Equal(t, "rain", value)
+	assert.Equal(t, 1, memoryCache.Len())
+}
+
+func testMemoryCacheExpiration(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	memoryCache := cache.NewMemoryCache(10)
+
+	assert.NoError(t, memoryCache.Set(ctx, "kyiv", "sunny", 10*time.Millisecond))
+	time.Sleep(20 * time.Millisecond)
+
+	_, err := memoryCache.Get(ctx, "kyiv")
+	assert.ErrorIs(t, err, cache.ErrCacheMiss)
+	assert.Equal(t, 0, memoryCache.Len())
+}
+
+func testMemoryCacheEviction(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	memoryCache := cache.NewMemoryCache(2)
+
+	assert.NoError(t, memoryCache.Set(ctx, "kyiv", "sunny", time.Minute))
+	assert.NoError(t, memoryCache.Set(ctx, "lviv", "rain", time.Minute))
+
+	// Reading kyiv makes lviv the least recently used entry.
+	_, err := memoryCache.Get(ctx, "kyiv")
+	assert.NoError(t, err)
+
+	assert.NoError(t, memoryCache.Set(ctx, "odesa", "cloudy", time.Minute))
+
+	_, err = memoryCache.Get(ctx, "lviv")
+	assert.ErrorIs(t, err, cache.ErrCacheMiss)
+	_, err = memoryCache.Get(ctx, "kyiv")
+	assert.NoError(t, err)
+	_, err = memoryCache.Get(ctx, "odesa")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, memoryCache.Len())
+}
@@ -0,0 +1,63 @@
+package cache
+
+import (
+	"context"
+	"ms-weather-subscription/pkg/metrics"
+	"time"
+)
+
+const (
+	MemoryTierName = "memory"
+	RedisTierName  = "redis"
+)
+
+// TieredCache checks the in-process cache first and the shared Redis cache after that.
+// Values found in Redis are copied to memory for their remaining TTL in Redis, but for promotedTTL at most,
+// so memory never serves a value which has already expired in Redis.
+// Values are always written to memory too, so they are still served while Redis is down.
+type TieredCache struct {
+	memory      *MemoryCache
+	redis       ExpiringCache
+	promotedTTL time.Duration
+}
+
+func NewTieredCache(memory *MemoryCache, redis ExpiringCache, promotedTTL time.Duration) *TieredCache {
+	return &TieredCache{
+		memory:      memory,
+		redis:       redis,
+		promotedTTL: promotedTTL,
+	}
+}
+
+func (t *TieredCache) Get(ctx context.Context, key string) (string, error) {
+	if value, err := t.memory.Get(ctx, key); err == nil {
+		metrics.CacheTierHitCount.WithLabelValues(MemoryTierName).Inc()
+		return value, nil
+	}
+
+	value, remaining, err := t.redis.GetWithTTL(ctx, key)
+	if err != nil {
+		return "", err
+	}
+	metrics.CacheTierHitCount.WithLabelValues(RedisTierName).Inc()
+
+	if err := t.memory.Set(ctx, key, value, t.promotionTTL(remaining)); err != nil {
+		return "", err
+	}
+	return value, nil
+}
+
+// promotionTTL returns how long a value with the remaining TTL in Redis is kept in memory.
+func (t *TieredCache) promotionTTL(remaining time.Duration) time.Duration {
+	if remaining < 0 {
+		return t.promotedTTL
+	}
+	return min(remaining, t.promotedTTL)
+}
+
+func (t *TieredCache) Set(ctx context.Context, key, value string, ttl time.Duration) error {
+	if err := t.memory.Set(ctx, key, value, ttl); err != nil {
+		return err
+	}
+	return t.redis.Set(ctx, key, value, ttl)
+}
@@ -0,0 +1,136 @@
+package cache_test
+
+import (
+	"context"
+	"ms-weather-subscription/pkg/cache"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTieredCache(t *testing.T) {
+	t.Run("Value from Redis is promoted to memory", testTieredCachePromotesToMemory)
+	t.Run("Promoted value expires with its Redis TTL", testTieredCachePromotedWithRedisTTL)
+	t.Run("Serves from memory while Redis is down", testTieredCacheRedisDown)
+	t.Run("Miss in both tiers", testTieredCacheMiss)
+}
+
+// fakeRedis is an ExpiringCache backed by maps which fails every call while down is set.
+// Values without a TTL never expire, and TTLs don't count down.
+type fakeRedis struct {
+	values map[string]string
+	ttls   map[string]time.Duration
+	gets   int
+	down   bool
+}
+
+func newFakeRedis() *fakeRedis {
+	return &fakeRedis{values: make(map[string]string), ttls: make(map[string]time.Duration)}
+}
+
+func (r *fakeRedis) Set(_ context.Context, key, value string, ttl time.Duration) error {
+	if r.down {
+		return redis.ErrClosed
+	}
+	r.values[key] = value
+	r.ttls[key] = ttl
+	return nil
+}
+
+func (r *fakeRedis) GetWithTTL(ctx context.Context, key string) (string, time.Duration, error) {
+	value, err := r.Get(ctx, key)
+	if err != nil {
+		return "", 0, err
+	}
+	ttl, ok := r.ttls[key]
+	if !ok {
+		return value, -1, nil
+	}
+	return value, ttl, nil
+}
+
+func (r *fakeRedis) Get(_ context.Context, key string) (string, error) {
+	r.gets++
+	if r.down {
+		return "", redis.ErrClosed
+	}
+	value, ok := r.values[key]
+	if !ok {
+		return "", redis.Nil
+	}
+	return value, nil
+}
+
+func testTieredCachePromotesToMemory(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	redisCache := newFakeRedis()
+	redisCache.values["kyiv"] = "sunny"
+	memoryCache := cache.NewMemoryCache(10)
+	tieredCache := cache.NewTieredCache(memoryCache, redisCache, time.Minute)
+
+	for range 3 {
+		value, err := tieredCache.Get(ctx, "kyiv")
+		assert.NoError(t, err)
+		assert.Equal(t, "sunny", value)
+	}
+
+	assert.Equal(t, 1, redisCache.gets, "only the first read must reach Redis")
+	value, err := memoryCache.Get(ctx, "kyiv")
+	assert.NoError(t, err)
+	assert.Equal(t, "sunny", value)
+}
+
+func testTieredCachePromotedWithRedisTTL(t *testing.T) {
+	t.Parallel()
+
+	// Setup: the value expires in Redis long before promotedTTL
+	ctx := context.Background()
+	redisCache := newFakeRedis()
+	redisCache.values["kyiv"] = "sunny"
+	redisCache.ttls["kyiv"] = 50 * time.Millisecond
+	memoryCache := cache.NewMemoryCache(10)
+	tieredCache := cache.NewTieredCache(memoryCache, redisCache, time.Minute)
+
+	// Execute
+	value, err := tieredCache.Get(ctx, "kyiv")
+	assert.NoError(t, err)
+	assert.Equal(t, "sunny", value)
+	time.Sleep(100 * time.Millisecond)
+
+	// Verify
+	_, err = memoryCache.Get(ctx, "kyiv")
+	assert.ErrorIs(t, err, cache.ErrCacheMiss)
+}
+
+func testTieredCacheRedisDown(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	redisCache := newFakeRedis()
+	redisCache.down = true
+	tieredCache := cache.NewTieredCache(cache.NewMemoryCache(10), redisCache, time.Minute)
+
+	err := tieredCache.Set(ctx, "kyiv", "sunny", time.Hour)
+	assert.ErrorIs(t, err, redis.ErrClosed)
+
+	value, err := tieredCache.Get(ctx, "kyiv")
+	assert.NoError(t, err)
+	assert.Equal(t, "sunny", value)
+
+	_, err = tieredCache.Get(ctx, "lviv")
+	assert.ErrorIs(t, err, redis.ErrClosed)
+}
+
+func testTieredCacheMiss(t *testing.T) {
+	t.Parallel()
+
+	tieredCache := cache.NewTieredCache(cache.NewMemoryCache(10), newFakeRedis(), time.Minute)
+
+	_, err := tieredCache.Get(context.Background(), "kyiv")
+
+	assert.ErrorIs(t, err, redis.Nil)
+}
@@ -0,0 +1,171 @@
+package clients_test
+
+import (
+	"context"
+	"fmt"
+	"ms-weather-subscription/internal/domain"
+	"ms-weather-subscription/pkg/clients"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetAPIAirQuality(t *testing.T) {
+	t.Run("WeatherAPI", testWeatherAPIAirQuality)
+	t.Run("WeatherAPI index of the dominant pollutant", testWeatherAPIAirQualityIndex)
+	t.Run("VisualCrossing", testVisualCrossingAirQuality)
+	t.Run("OpenMeteo", testOpenMeteoAirQuality)
+	t.Run("Chain passes request to next provider", testChainAirQualityFallback)
+}
+
+func weatherAPIAirQualityBody(pm25, pm10 float32) string {
+	return fmt.Sprintf(`{
+		"current": {
+			"temp_c": 21,
+			"air_quality": {"co": 230.3, "no2": 13.5, "o3": 68.7, "so2": 4.2, "pm2_5": %g, "pm10": %g, "us-epa-index": 2}
+		}
+	}`, pm25, pm10)
+}
+
+func testWeatherAPIAirQuality(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/current.json", r.URL.Path)
+		assert.Equal(t, "yes", r.URL.Query().Get("aqi"))
+		assert.Equal(t, "Kyiv", r.URL.Query().Get("q"))
+		_, err := w.Write([]byte(weatherAPIAirQualityBody(12.3, 20)))
+		if err != nil {
+			t.Fatalf("failed to write response: %v", err)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	airQuality, err := clients.NewWeatherAPIClient("dummy-key").
+		WithBaseURL(server.URL).
+		WithClient(server.Client()).
+		GetAPIAirQuality(context.Background(), domain.CityQuery("Kyiv"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, &domain.AirQualityResponse{AQI: 57, PM25: 12.3, PM10: 20, O3: 68.7, NO2: 13.5}, airQuality)
+}
+
+func testWeatherAPIAirQualityIndex(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		pm25, pm10 float32
+		aqi        int
+	}{
+		{name: "Clean air", pm25: 0, pm10: 0, aqi: 0},
+		{name: "PM10 dominates", pm25: 2, pm10: 80, aqi: 63},
+		{name: "Upper bound of the category", pm25: 35.4, pm10: 10, aqi: 100},
+		{name: "Beyond the scale", pm25: 600, pm10: 700, aqi: 500},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newStaticServer(t, http.StatusOK, weatherAPIAirQualityBody(tt.pm25, tt.pm10), nil)
+
+			airQuality, err := clients.NewWeatherAPIClient("dummy-key").
+				WithBaseURL(server.URL).
+				WithClient(server.Client()).
+				GetAPIAirQuality(context.Background(), domain.CityQuery("Kyiv"))
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.aqi, airQuality.AQI)
+		})
+	}
+}
+
+func testVisualCrossingAirQuality(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/Kyiv/today", r.URL.Path)
+		assert.Equal(t, "current", r.URL.Query().Get("include"))
+		assert.Equal(t, "aqius,pm2p5,pm10,o3,no2", r.URL.Query().Get("elements"))
+		_, err := w.Write([]byte(`{
+			"currentConditions": {"aqius": 41.6, "pm2p5": 9.8, "pm10": 17.2, "o3": 55.1, "no2": 11.4}
+		}`))
+		if err != nil {
+			t.Fatalf("failed to write response: %v", err)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	airQuality, err := clients.NewVisualCrossingClient("dummy-key").
+		WithBaseURL(server.URL).
+		WithClient(server.Client()).
+		GetAPIAirQuality(context.Background(), domain.CityQuery("Kyiv"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, &domain.AirQualityResponse{AQI: 42, PM25: 9.8, PM10: 17.2, O3: 55.1, NO2: 11.4}, airQuality)
+}
+
+func testOpenMeteoAirQuality(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		switch r.URL.Path {
+		case "/search":
+			_, err = w.Write([]byte(openMeteoKyivGeocodingResponse))
+		case "/air-quality":
+			assert.Equal(t, "50.454660", r.URL.Query().Get("latitude"))
+			assert.Equal(t, "30.523800", r.URL.Query().Get("longitude"))
+			assert.Equal(t, "us_aqi,pm2_5,pm10,ozone,nitrogen_dioxide", r.URL.Query().Get("current"))
+			_, err = w.Write([]byte(`{
+				"current": {
+					"time": "2025-05-17T12:00",
+					"us_aqi": 38,
+					"pm2_5": 7.4,
+					"pm10": 11.9,
+					"ozone": 72.0,
+					"nitrogen_dioxide": 9.3
+				}
+			}`))
+		default:
+			t.Errorf("unexpected request path: %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+		if err != nil {
+			t.Fatalf("failed to write response: %v", err)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	airQuality, err := fakeNewOpenMeteoClient(server).
+		WithAirQualityBaseURL(server.URL).
+		GetAPIAirQuality(context.Background(), domain.CityQuery("Kyiv"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, &domain.AirQualityResponse{AQI: 38, PM25: 7.4, PM10: 11.9, O3: 72, NO2: 9.3}, airQuality)
+}
+
+func testChainAirQualityFallback(t *testing.T) {
+	t.Parallel()
+
+	weatherAPIServer := newStaticServer(t, http.StatusInternalServerError, `{}`, nil)
+	visualCrossingServer := newStaticServer(t, http.StatusOK, `{"currentConditions": {"aqius": 12}}`, nil)
+
+	chainClient, err := clients.NewChainWeatherClient([]clients.ChainWeatherProvider{
+		clients.NewWeatherAPIClient("dummy-key").
+			WithBaseURL(weatherAPIServer.URL).
+			WithClient(weatherAPIServer.Client()),
+		clients.NewVisualCrossingClient("dummy-key").
+			WithBaseURL(visualCrossingServer.URL).
+			WithClient(visualCrossingServer.Client()),
+	})
+	if err != nil {
+		t.Fatalf("failed to create chain weather client: %v", err)
+	}
+
+	airQuality, err := chainClient.GetAPIAirQuality(context.Background(), domain.CityQuery("Kyiv"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, 12, airQuality.AQI)
+}
@@ -0,0 +1,43 @@
+package clients
+
+import (
+	"context"
+	"errors"
+	"ms-weather-subscription/internal/domain"
+)
+
+// AlertsClient returns severe weather alerts currently issued for a place.
+type AlertsClient interface {
+	GetAPIAlerts(ctx context.Context, query domain.WeatherQuery) ([]domain.WeatherAlert, error)
+}
+
+// ChainAlertsProvider is a single provider which can take part in ChainAlertsClient.
+type ChainAlertsProvider interface {
+	AlertsClient
+	Name() string
+}
+
+// ChainAlertsClient queries providers one by one until one of them returns the alerts.
+type ChainAlertsClient struct {
+	providers []ChainAlertsProvider
+}
+
+func NewChainAlertsClient(providers []ChainAlertsProvider) (*ChainAlertsClient, error) {
+	if len(providers) == 0 {
+		return nil, errors.New("cannot create ChainAlertsClient with empty provider list")
+	}
+
+	return &ChainAlertsClient{
+		providers: providers,
+	}, nil
+}
+
+func (c *ChainAlertsClient) GetAPIAlerts(
+	ctx context.Context, query domain.WeatherQuery,
+) ([]domain.WeatherAlert, error) {
+	return callChain(ctx, c.providers, "GetAPIAlerts",
+		func(provider ChainAlertsProvider) ([]domain.WeatherAlert, error) {
+			return provider.GetAPIAlerts(ctx, query)
+		},
+	)
+}
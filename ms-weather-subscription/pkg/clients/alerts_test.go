@@ -0,0 +1,148 @@
+package clients_test
+
+import (
+	"context"
+	"ms-weather-subscription/internal/domain"
+	"ms-weather-subscription/pkg/clients"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetAPIAlerts(t *testing.T) {
+	t.Run("WeatherAPI", testWeatherAPIAlerts)
+	t.Run("WeatherAPI alert id is stable", testWeatherAPIAlertIDStable)
+	t.Run("VisualCrossing", testVisualCrossingAlerts)
+	t.Run("Chain passes request to next provider", testChainAlertsFallback)
+}
+
+const weatherAPIAlertsResponse = `{
+	"alerts": {
+		"alert": [
+			{
+				"headline": "Storm warning issued May 17 at 10:00AM",
+				"severity": "Severe",
+				"areas": "Kyiv",
+				"event": "Storm Warning",
+				"effective": "2025-05-17T10:00:00+00:00",
+				"expires": "2025-05-18T10:00:00+00:00",
+				"desc": "Wind gusts up to 90 km/h.",
+				"instruction": "Stay indoors."
+			}
+		]
+	}
+}`
+
+func testWeatherAPIAlerts(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/forecast.json", r.URL.Path)
+		assert.Equal(t, "yes", r.URL.Query().Get("alerts"))
+		assert.Equal(t, "Kyiv", r.URL.Query().Get("q"))
+		_, err := w.Write([]byte(weatherAPIAlertsResponse))
+		if err != nil {
+			t.Fatalf("failed to write response: %v", err)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	alerts, err := clients.NewWeatherAPIClient("dummy-key").
+		WithBaseURL(server.URL).
+		WithClient(server.Client()).
+		GetAPIAlerts(context.Background(), domain.CityQuery("Kyiv"))
+
+	assert.NoError(t, err)
+	if assert.Len(t, alerts, 1) {
+		alert := alerts[0]
+		assert.Regexp(t, "^weatherapi:[0-9a-f]{16}$", alert.ID)
+		assert.Equal(t, "Storm Warning", alert.Event)
+		assert.Equal(t, "Storm warning issued May 17 at 10:00AM", alert.Headline)
+		assert.Equal(t, "Severe", alert.Severity)
+		assert.Equal(t, "Wind gusts up to 90 km/h.", alert.Description)
+		assert.Equal(t, "Stay indoors.", alert.Instruction)
+		assert.True(t, alert.Effective.Equal(time.Date(2025, 5, 17, 10, 0, 0, 0, time.UTC)))
+		assert.True(t, alert.Expires.Equal(time.Date(2025, 5, 18, 10, 0, 0, 0, time.UTC)))
+	}
+}
+
+func testWeatherAPIAlertIDStable(t *testing.T) {
+	t.Parallel()
+
+	server := newStaticServer(t, http.StatusOK, weatherAPIAlertsResponse, nil)
+	client := clients.NewWeatherAPIClient("dummy-key").WithBaseURL(server.URL).WithClient(server.Client())
+
+	first, err := client.GetAPIAlerts(context.Background(), domain.CityQuery("Kyiv"))
+	assert.NoError(t, err)
+	second, err := client.GetAPIAlerts(context.Background(), domain.CityQuery("Kyiv"))
+	assert.NoError(t, err)
+
+	assert.Equal(t, first[0].ID, second[0].ID, "the same alert must keep its id between polls")
+}
+
+func testVisualCrossingAlerts(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/Kyiv/today", r.URL.Path)
+		assert.Equal(t, "alerts", r.URL.Query().Get("include"))
+		_, err := w.Write([]byte(`{
+			"alerts": [
+				{
+					"event": "Heat Advisory",
+					"headline": "Heat advisory until May 17 at 8:00PM",
+					"description": "Temperatures up to 38 C.",
+					"id": "urn:oid:2.49.0.1.804.0.2025.5.17.10.0.0",
+					"onsetEpoch": 1747476000,
+					"endsEpoch": 1747512000
+				}
+			]
+		}`))
+		if err != nil {
+			t.Fatalf("failed to write response: %v", err)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	alerts, err := clients.NewVisualCrossingClient("dummy-key").
+		WithBaseURL(server.URL).
+		WithClient(server.Client()).
+		GetAPIAlerts(context.Background(), domain.CityQuery("Kyiv"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, []domain.WeatherAlert{{
+		ID:          "visualcrossing:urn:oid:2.49.0.1.804.0.2025.5.17.10.0.0",
+		Event:       "Heat Advisory",
+		Headline:    "Heat advisory until May 17 at 8:00PM",
+		Description: "Temperatures up to 38 C.",
+		Effective:   time.Unix(1747476000, 0).UTC(),
+		Expires:     time.Unix(1747512000, 0).UTC(),
+	}}, alerts)
+}
+
+func testChainAlertsFallback(t *testing.T) {
+	t.Parallel()
+
+	weatherAPIServer := newStaticServer(t, http.StatusInternalServerError, `{}`, nil)
+	visualCrossingServer := newStaticServer(t, http.StatusOK, `{"alerts": []}`, nil)
+
+	chainClient, err := clients.NewChainAlertsClient([]clients.ChainAlertsProvider{
+		clients.NewWeatherAPIClient("dummy-key").
+			WithBaseURL(weatherAPIServer.URL).
+			WithClient(weatherAPIServer.Client()),
+		clients.NewVisualCrossingClient("dummy-key").
+			WithBaseURL(visualCrossingServer.URL).
+			WithClient(visualCrossingServer.Client()),
+	})
+	if err != nil {
+		t.Fatalf("failed to create chain alerts client: %v", err)
+	}
+
+	alerts, err := chainClient.GetAPIAlerts(context.Background(), domain.CityQuery("Kyiv"))
+
+	assert.NoError(t, err)
+	assert.Empty(t, alerts)
+}
@@ -0,0 +1,229 @@
+package clients
+
+import (
+	"common/logger"
+	"context"
+	"errors"
+	"ms-weather-subscription/internal/domain"
+	customErrors "ms-weather-subscription/pkg/errors"
+	"ms-weather-subscription/pkg/metrics"
+	"sync"
+	"time"
+)
+
+type CircuitState int
+
+const (
+	CircuitClosed CircuitState = iota
+	CircuitOpen
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// CircuitBreaker opens after failureThreshold consecutive failures and rejects calls
+// for coolDown. After that a single trial call is let through (half-open state):
+// its success closes the circuit, its failure opens it again.
+type CircuitBreaker struct {
+	mu               sync.Mutex
+	name             string
+	state            CircuitState
+	failures         int
+	failureThreshold int
+	coolDown         time.Duration
+	openedAt         time.Time
+	trialInFlight    bool
+}
+
+func NewCircuitBreaker(name string, failureThreshold int, coolDown time.Duration) *CircuitBreaker {
+	cb := &CircuitBreaker{
+		name:             name,
+		failureThreshold: failureThreshold,
+		coolDown:         coolDown,
+	}
+	metrics.WeatherProviderCircuitState.WithLabelValues(name).Set(float64(CircuitClosed))
+	return cb
+}
+
+func (cb *CircuitBreaker) State() CircuitState {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	return cb.state
+}
+
+// Allow reports whether a call may be made to the protected provider.
+func (cb *CircuitBreaker) Allow() bool {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	switch cb.state {
+	case CircuitOpen:
+		if time.Since(cb.openedAt) < cb.coolDown {
+			return false
+		}
+		cb.setState(CircuitHalfOpen)
+		cb.trialInFlight = true
+		return true
+	case CircuitHalfOpen:
+		if cb.trialInFlight {
+			return false
+		}
+		cb.trialInFlight = true
+		return true
+	default:
+		return true
+	}
+}
+
+func (cb *CircuitBreaker) RecordSuccess() {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.failures = 0
+	cb.trialInFlight = false
+	if cb.state != CircuitClosed {
+		cb.setState(CircuitClosed)
+	}
+}
+
+func (cb *CircuitBreaker) RecordFailure() {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.failures++
+	cb.trialInFlight = false
+	if cb.state == CircuitHalfOpen || cb.failures >= cb.failureThreshold {
+		cb.openedAt = time.Now()
+		cb.setState(CircuitOpen)
+	}
+}
+
+// RecordCanceled releases a half-open trial without changing the state,
+// e.g. when the caller cancelled the request before the provider answered.
+func (cb *CircuitBreaker) RecordCanceled() {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.trialInFlight = false
+}
+
+func (cb *CircuitBreaker) setState(state CircuitState) {
+	if cb.state != state {
+		logger.Warnf("circuit breaker for %s: %s -> %s", cb.name, cb.state, state)
+	}
+	cb.state = state
+	metrics.WeatherProviderCircuitState.WithLabelValues(cb.name).Set(float64(state))
+}
+
+// CircuitBreakerProvider wraps a chain weather provider with a circuit breaker.
+// While the circuit is open the provider is not called at all and
+// ErrWeatherProviderUnavailable is returned, so the chain moves on to the next provider.
+type CircuitBreakerProvider struct {
+	provider ChainWeatherProvider
+	breaker  *CircuitBreaker
+}
+
+func NewCircuitBreakerProvider(
+	provider ChainWeatherProvider, breaker *CircuitBreaker,
+) *CircuitBreakerProvider {
+	return &CircuitBreakerProvider{
+		provider: provider,
+		breaker:  breaker,
+	}
+}
+
+func (p *CircuitBreakerProvider) Name() string {
+	return p.provider.Name()
+}
+
+func (p *CircuitBreakerProvider) GetAPICurrentWeather(
+	ctx context.Context, query domain.WeatherQuery,
+) (*domain.WeatherResponse, error) {
+	return callWithCircuitBreaker(ctx, p.breaker, func() (*domain.WeatherResponse, error) {
+		return p.provider.GetAPICurrentWeather(ctx, query)
+	})
+}
+
+func (p *CircuitBreakerProvider) GetAPIDayWeather(
+	ctx context.Context, query domain.WeatherQuery,
+) (*domain.DayWeatherResponse, error) {
+	return callWithCircuitBreaker(ctx, p.breaker, func() (*domain.DayWeatherResponse, error) {
+		return p.provider.GetAPIDayWeather(ctx, query)
+	})
+}
+
+func (p *CircuitBreakerProvider) GetAPIForecast(
+	ctx context.Context, query domain.WeatherQuery, days int,
+) (*domain.ForecastResponse, error) {
+	return callWithCircuitBreaker(ctx, p.breaker, func() (*domain.ForecastResponse, error) {
+		return p.provider.GetAPIForecast(ctx, query, days)
+	})
+}
+
+func (p *CircuitBreakerProvider) GetAPIAirQuality(
+	ctx context.Context, query domain.WeatherQuery,
+) (*domain.AirQualityResponse, error) {
+	return callWithCircuitBreaker(ctx, p.breaker, func() (*domain.AirQualityResponse, error) {
+		return p.provider.GetAPIAirQuality(ctx, query)
+	})
+}
+
+// CircuitBreakerAlertsProvider wraps a chain alerts provider with a circuit breaker.
+// The breaker is meant to be shared with the CircuitBreakerProvider of the same provider.
+type CircuitBreakerAlertsProvider struct {
+	provider ChainAlertsProvider
+	breaker  *CircuitBreaker
+}
+
+func NewCircuitBreakerAlertsProvider(
+	provider ChainAlertsProvider, breaker *CircuitBreaker,
+) *CircuitBreakerAlertsProvider {
+	return &CircuitBreakerAlertsProvider{
+		provider: provider,
+		breaker:  breaker,
+	}
+}
+
+func (p *CircuitBreakerAlertsProvider) Name() string {
+	return p.provider.Name()
+}
+
+func (p *CircuitBreakerAlertsProvider) GetAPIAlerts(
+	ctx context.Context, query domain.WeatherQuery,
+) ([]domain.WeatherAlert, error) {
+	return callWithCircuitBreaker(ctx, p.breaker, func() ([]domain.WeatherAlert, error) {
+		return p.provider.GetAPIAlerts(ctx, query)
+	})
+}
+
+func callWithCircuitBreaker[T any](ctx context.Context, breaker *CircuitBreaker, call func() (T, error)) (T, error) {
+	var zero T
+
+	if !breaker.Allow() {
+		return zero, customErrors.ErrWeatherProviderUnavailable
+	}
+
+	resp, err := call()
+	switch {
+	case err == nil, errors.Is(err, customErrors.ErrCityNotFound), errors.Is(err, customErrors.ErrForecastIncomplete):
+		// The provider answered, so it is healthy even if it doesn't know the city or forecasts fewer days.
+		breaker.RecordSuccess()
+	case ctx.Err() != nil, errors.Is(err, customErrors.ErrQuotaExhausted):
+		// The provider wasn't really asked, so its health is unknown.
+		breaker.RecordCanceled()
+	default:
+		breaker.RecordFailure()
+	}
+	return resp, err
+}
@@ -0,0 +1,177 @@
+package clients_test
+
+import (
+	"context"
+	"ms-weather-subscription/internal/domain"
+	"ms-weather-subscription/pkg/clients"
+	customErrors "ms-weather-subscription/pkg/errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	t.Run("Opens after failure threshold", testCircuitBreakerOpensAfterThreshold)
+	t.Run("Half-open trial success closes circuit", testCircuitBreakerHalfOpenSuccess)
+	t.Run("Half-open trial failure reopens circuit", testCircuitBreakerHalfOpenFailure)
+	t.Run("Open provider is skipped by chain", testCircuitBreakerProviderSkipsOpenProvider)
+	t.Run("City not found doesn't open circuit", testCircuitBreakerProviderCityNotFound)
+	t.Run("Open provider returns unavailable error", testCircuitBreakerProviderUnavailable)
+	t.Run("Exhausted quota doesn't open circuit", testCircuitBreakerProviderQuotaExhausted)
+}
+
+func testCircuitBreakerOpensAfterThreshold(t *testing.T) {
+	t.Parallel()
+
+	cb := clients.NewCircuitBreaker("test-opens", 2, time.Hour)
+
+	assert.True(t, cb.Allow())
+	cb.RecordFailure()
+	assert.Equal(t, clients.CircuitClosed, cb.State())
+
+	assert.True(t, cb.Allow())
+	cb.RecordFailure()
+	assert.Equal(t, clients.CircuitOpen, cb.State())
+	assert.False(t, cb.Allow())
+}
+
+func testCircuitBreakerHalfOpenSuccess(t *testing.T) {
+	t.Parallel()
+
+	cb := clients.NewCircuitBreaker("test-half-open-success", 1, 10*time.Millisecond)
+	cb.RecordFailure()
+	assert.Equal(t, clients.CircuitOpen, cb.State())
+
+	time.Sleep(20 * time.Millisecond)
+
+	assert.True(t, cb.Allow())
+	assert.Equal(t, clients.CircuitHalfOpen, cb.State())
+	assert.False(t, cb.Allow(), "only one trial call is allowed in half-open state")
+
+	cb.RecordSuccess()
+	assert.Equal(t, clients.CircuitClosed, cb.State())
+	assert.True(t, cb.Allow())
+}
+
+func testCircuitBreakerHalfOpenFailure(t *testing.T) {
+	t.Parallel()
+
+	cb := clients.NewCircuitBreaker("test-half-open-failure", 1, 10*time.Millisecond)
+	cb.RecordFailure()
+
+	time.Sleep(20 * time.Millisecond)
+
+	assert.True(t, cb.Allow())
+	cb.RecordFailure()
+	assert.Equal(t, clients.CircuitOpen, cb.State())
+	assert.False(t, cb.Allow())
+}
+
+func testCircuitBreakerProviderSkipsOpenProvider(t *testing.T) {
+	t.Parallel()
+
+	var primaryCalls atomic.Int32
+	primaryServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		primaryCalls.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, err := w.Write([]byte(`{"error": {"code": 9999, "message": "Internal application error."}}`))
+		if err != nil {
+			t.Fatalf("failed to write response: %v", err)
+		}
+	}))
+	defer primaryServer.Close()
+
+	fallbackServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, err := w.Write([]byte(`{"currentConditions": {"temp": 18.5, "humidity": 60, "conditions": "Rain"}}`))
+		if err != nil {
+			t.Fatalf("failed to write response: %v", err)
+		}
+	}))
+	defer fallbackServer.Close()
+
+	primary := clients.NewCircuitBreakerProvider(
+		clients.NewWeatherAPIClient("dummy-key").
+			WithBaseURL(primaryServer.URL).
+			WithClient(primaryServer.Client()),
+		clients.NewCircuitBreaker("test-skips-open-provider", 2, time.Hour),
+	)
+	fallback := clients.NewVisualCrossingClient("dummy-key").
+		WithBaseURL(fallbackServer.URL).
+		WithClient(fallbackServer.Client())
+
+	chainClient, err := clients.NewChainWeatherClient([]clients.ChainWeatherProvider{primary, fallback})
+	if err != nil {
+		t.Fatalf("failed to create chain weather client: %v", err)
+	}
+
+	for range 5 {
+		weather, err := chainClient.GetAPICurrentWeather(context.Background(), domain.CityQuery("Kyiv"))
+		assert.NoError(t, err)
+		assert.Equal(t, &domain.WeatherResponse{Temperature: 18.5, Humidity: 60, Description: "Rain"}, weather)
+	}
+
+	assert.Equal(t, int32(2), primaryCalls.Load(), "open provider must not be called")
+}
+
+func testCircuitBreakerProviderCityNotFound(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, err := w.Write([]byte(`{"error": {"code": 1006, "message": "No matching location found."}}`))
+		if err != nil {
+			t.Fatalf("failed to write response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	breaker := clients.NewCircuitBreaker("test-city-not-found", 1, time.Hour)
+	provider := clients.NewCircuitBreakerProvider(
+		clients.NewWeatherAPIClient("dummy-key").WithBaseURL(server.URL).WithClient(server.Client()),
+		breaker,
+	)
+
+	_, err := provider.GetAPICurrentWeather(context.Background(), domain.CityQuery("Nowhere"))
+
+	assert.ErrorIs(t, err, customErrors.ErrCityNotFound)
+	assert.Equal(t, clients.CircuitClosed, breaker.State())
+}
+
+func testCircuitBreakerProviderUnavailable(t *testing.T) {
+	t.Parallel()
+
+	breaker := clients.NewCircuitBreaker("test-unavailable", 1, time.Hour)
+	breaker.RecordFailure()
+
+	provider := clients.NewCircuitBreakerProvider(clients.NewWeatherAPIClient("dummy-key"), breaker)
+
+	_, err := provider.GetAPIDayWeather(context.Background(), domain.CityQuery("Kyiv"))
+
+	assert.ErrorIs(t, err, customErrors.ErrWeatherProviderUnavailable)
+}
+
+func testCircuitBreakerProviderQuotaExhausted(t *testing.T) {
+	t.Parallel()
+
+	breaker := clients.NewCircuitBreaker("test-quota-exhausted", 1, time.Hour)
+	provider := clients.NewCircuitBreakerProvider(
+		clients.NewQuotaProvider(
+			newNamedWeatherClient("test-quota-exhausted"), newMemoryCounter(), clients.QuotaLimits{DailyLimit: 1},
+		),
+		breaker,
+	)
+
+	_, err := provider.GetAPIDayWeather(context.Background(), domain.CityQuery("Kyiv"))
+	assert.NoError(t, err)
+	for range 2 {
+		_, err = provider.GetAPIDayWeather(context.Background(), domain.CityQuery("Kyiv"))
+		assert.ErrorIs(t, err, customErrors.ErrQuotaExhausted)
+	}
+
+	assert.Equal(t, clients.CircuitClosed, breaker.State())
+}
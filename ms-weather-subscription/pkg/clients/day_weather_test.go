@@ -0,0 +1,102 @@
+package clients_test
+
+import (
+	"context"
+	"ms-weather-subscription/internal/domain"
+	"ms-weather-subscription/pkg/clients"
+	customErrors "ms-weather-subscription/pkg/errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetAPIDayWeather(t *testing.T) {
+	t.Run("WeatherAPI", testWeatherAPIDayWeather)
+	t.Run("VisualCrossing", testVisualCrossingDayWeather)
+	t.Run("Response without days", testDayWeatherWithoutDays)
+}
+
+var expectedNightAndMorning = &domain.DayWeatherResponse{
+	Hours: []domain.HourlyWeather{
+		{Time: "00:00", WeatherResponse: domain.WeatherResponse{
+			Temperature: 9.5, Humidity: 88, Description: "Clear", FeelsLike: 8,
+			WindSpeed: 6.1, WindDirection: 190, WindGust: 10.8, Precipitation: 0, PrecipitationProbability: 0,
+			UVIndex: 0, Pressure: 1016, Visibility: 10, CloudCover: 3,
+		}},
+		{Time: "06:00", WeatherResponse: domain.WeatherResponse{
+			Temperature: 10.2, Humidity: 91, Description: "Mist", FeelsLike: 9.1,
+			WindSpeed: 4.3, WindDirection: 210, WindGust: 7.9, Precipitation: 0.1, PrecipitationProbability: 30,
+			UVIndex: 0.2, Pressure: 1015, Visibility: 2, CloudCover: 75,
+		}},
+	},
+	Timezone: "Europe/Kyiv",
+}
+
+func testWeatherAPIDayWeather(t *testing.T) {
+	t.Parallel()
+
+	// Precipitation probability is the highest of the chance of rain and snow.
+	server := newStaticServer(t, http.StatusOK, `{
+		"location": {"tz_id": "Europe/Kyiv"},
+		"forecast": {"forecastday": [{"hour": [
+			{"time": "2025-05-17 00:00", "temp_c": 9.5, "humidity": 88, "condition": {"text": "Clear"},
+				"feelslike_c": 8, "wind_kph": 6.1, "wind_degree": 190, "gust_kph": 10.8, "precip_mm": 0,
+				"chance_of_rain": 0, "chance_of_snow": 0, "uv": 0, "pressure_mb": 1016, "vis_km": 10, "cloud": 3},
+			{"time": "2025-05-17 06:00", "temp_c": 10.2, "humidity": 91, "condition": {"text": "Mist"},
+				"feelslike_c": 9.1, "wind_kph": 4.3, "wind_degree": 210, "gust_kph": 7.9, "precip_mm": 0.1,
+				"chance_of_rain": 30, "chance_of_snow": 5, "uv": 0.2, "pressure_mb": 1015, "vis_km": 2, "cloud": 75}
+		]}]}
+	}`, nil)
+
+	weather, err := clients.NewWeatherAPIClient("dummy-key").
+		WithBaseURL(server.URL).
+		WithClient(server.Client()).
+		GetAPIDayWeather(context.Background(), domain.CityQuery("Kyiv"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, expectedNightAndMorning, weather)
+}
+
+func testVisualCrossingDayWeather(t *testing.T) {
+	t.Parallel()
+
+	server := newStaticServer(t, http.StatusOK, `{
+		"timezone": "Europe/Kyiv",
+		"days": [{"hours": [
+			{"datetime": "00:00:00", "temp": 9.5, "humidity": 88, "conditions": "Clear",
+				"feelslike": 8, "windspeed": 6.1, "winddir": 190, "windgust": 10.8, "precip": 0,
+				"precipprob": 0, "uvindex": 0, "pressure": 1016, "visibility": 10, "cloudcover": 3},
+			{"datetime": "06:00:00", "temp": 10.2, "humidity": 91, "conditions": "Mist",
+				"feelslike": 9.1, "windspeed": 4.3, "winddir": 210, "windgust": 7.9, "precip": 0.1,
+				"precipprob": 30, "uvindex": 0.2, "pressure": 1015, "visibility": 2, "cloudcover": 75}
+		]}]
+	}`, nil)
+
+	weather, err := clients.NewVisualCrossingClient("dummy-key").
+		WithBaseURL(server.URL).
+		WithClient(server.Client()).
+		GetAPIDayWeather(context.Background(), domain.CityQuery("Kyiv"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, expectedNightAndMorning, weather)
+}
+
+func testDayWeatherWithoutDays(t *testing.T) {
+	t.Parallel()
+
+	weatherAPIServer := newStaticServer(t, http.StatusOK, `{"forecast": {"forecastday": []}}`, nil)
+	visualCrossingServer := newStaticServer(t, http.StatusOK, `{"timezone": "Europe/Kyiv", "days": []}`, nil)
+
+	_, weatherAPIErr := clients.NewWeatherAPIClient("dummy-key").
+		WithBaseURL(weatherAPIServer.URL).
+		WithClient(weatherAPIServer.Client()).
+		GetAPIDayWeather(context.Background(), domain.CityQuery("Kyiv"))
+	_, visualCrossingErr := clients.NewVisualCrossingClient("dummy-key").
+		WithBaseURL(visualCrossingServer.URL).
+		WithClient(visualCrossingServer.Client()).
+		GetAPIDayWeather(context.Background(), domain.CityQuery("Kyiv"))
+
+	assert.ErrorIs(t, weatherAPIErr, customErrors.ErrWeatherDataError)
+	assert.ErrorIs(t, visualCrossingErr, customErrors.ErrWeatherDataError)
+}
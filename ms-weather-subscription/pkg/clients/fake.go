@@ -0,0 +1,301 @@
+package clients
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"ms-weather-subscription/internal/domain"
+	customErrors "ms-weather-subscription/pkg/errors"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Scenario steps of a fake city. Each call to the fake provider for the city takes the next step.
+const (
+	FakeStepOK          = "ok"
+	FakeStepUnavailable = "unavailable"
+	FakeStepError       = "error"
+	FakeStepNotFound    = "not_found"
+)
+
+// FakeWeatherFixture is the data served by FakeWeatherClient, usually loaded from a YAML or JSON file.
+type FakeWeatherFixture struct {
+	Cities []FakeCity `json:"cities"`
+}
+
+// FakeCity is the weather of a single city of the fixture. Data which isn't listed
+// is reported as ErrWeatherDataError. Weather values are metric, as providers return them.
+//
+// Latency (e.g. "300ms") delays every answer for the city. Scenario lists the steps
+// ("ok", "unavailable", "error", "not_found") taken one per call, starting over after
+// the last one, e.g. ["unavailable", "ok"] fails every other call. Empty scenario always succeeds.
+type FakeCity struct {
+	Name      string   `json:"name"`
+	Country   string   `json:"country"`
+	Latitude  float64  `json:"latitude"`
+	Longitude float64  `json:"longitude"`
+	Timezone  string   `json:"timezone"`
+	Latency   string   `json:"latency"`
+	Scenario  []string `json:"scenario"`
+
+	Current    *domain.WeatherResponse    `json:"current"`
+	Day        []domain.HourlyWeather     `json:"day"`
+	Forecast   []domain.DayForecast       `json:"forecast"`
+	AirQuality *domain.AirQualityResponse `json:"air_quality"`
+	Alerts     []domain.WeatherAlert      `json:"alerts"`
+}
+
+// LoadFakeWeatherFixture reads the fixture from a YAML file. JSON files are read as well,
+// since JSON is valid YAML. Unknown fields are rejected to catch typos in the fixture.
+func LoadFakeWeatherFixture(path string) (*FakeWeatherFixture, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fake weather fixture: %w", err)
+	}
+
+	// Domain types are described by JSON tags, so the YAML document is decoded via JSON.
+	var document any
+	if err = yaml.Unmarshal(content, &document); err != nil {
+		return nil, fmt.Errorf("failed to parse fake weather fixture %s: %w", path, err)
+	}
+	documentJSON, err := json.Marshal(document)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse fake weather fixture %s: %w", path, err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(documentJSON))
+	decoder.DisallowUnknownFields()
+	var fixture FakeWeatherFixture
+	if err = decoder.Decode(&fixture); err != nil {
+		return nil, fmt.Errorf("failed to parse fake weather fixture %s: %w", path, err)
+	}
+
+	return &fixture, nil
+}
+
+// FakeWeatherClient is a weather provider for local development and tests which serves
+// the weather of the fixture instead of calling a weather API. Cities are matched by name,
+// case-insensitively, and coordinates are matched to the city on the same grid cell.
+// Cities which aren't in the fixture are reported as ErrCityNotFound.
+type FakeWeatherClient struct {
+	cities []*fakeCity
+}
+
+type fakeCity struct {
+	FakeCity
+	latency time.Duration
+
+	mu    sync.Mutex
+	calls int
+}
+
+func NewFakeWeatherClient(fixture *FakeWeatherFixture) (*FakeWeatherClient, error) {
+	cities := make([]*fakeCity, 0, len(fixture.Cities))
+	seen := make(map[string]struct{}, len(fixture.Cities))
+
+	for _, city := range fixture.Cities {
+		name := domain.NormalizeLocationQuery(city.Name)
+		if name == "" {
+			return nil, errors.New("fake city must have a name")
+		}
+		if _, duplicate := seen[name]; duplicate {
+			return nil, fmt.Errorf("fake city %q is listed more than once", city.Name)
+		}
+		seen[name] = struct{}{}
+
+		var latency time.Duration
+		if city.Latency != "" {
+			var err error
+			if latency, err = time.ParseDuration(city.Latency); err != nil {
+				return nil, fmt.Errorf("invalid latency of fake city %q: %w", city.Name, err)
+			}
+		}
+
+		for _, step := range city.Scenario {
+			switch step {
+			case FakeStepOK, FakeStepUnavailable, FakeStepError, FakeStepNotFound:
+			default:
+				return nil, fmt.Errorf("unknown scenario step %q of fake city %q", step, city.Name)
+			}
+		}
+
+		cities = append(cities, &fakeCity{FakeCity: city, latency: latency})
+	}
+
+	return &FakeWeatherClient{cities: cities}, nil
+}
+
+func (c *FakeWeatherClient) Name() string {
+	return FakeProviderName
+}
+
+func (c *FakeWeatherClient) SearchLocation(ctx context.Context, query string) (*domain.Location, error) {
+	city, err := c.call(ctx, c.findByName(query))
+	if err != nil {
+		return nil, err
+	}
+
+	location := domain.NewLocation(city.Name, city.Country, city.Latitude, city.Longitude, city.Timezone)
+	return &location, nil
+}
+
+// LookupTimezone returns the timezone of the city on the grid cell of the coordinates.
+func (c *FakeWeatherClient) LookupTimezone(ctx context.Context, coordinates domain.Coordinates) (string, error) {
+	city, err := c.call(ctx, c.find(domain.CoordinatesQuery(coordinates)))
+	if err != nil {
+		return "", err
+	}
+	return city.Timezone, nil
+}
+
+func (c *FakeWeatherClient) GetAPICurrentWeather(
+	ctx context.Context, query domain.WeatherQuery,
+) (*domain.WeatherResponse, error) {
+	city, err := c.call(ctx, c.find(query))
+	if err != nil {
+		return nil, err
+	}
+	if city.Current == nil {
+		return nil, fakeMissingDataError(city, "current weather")
+	}
+
+	current := *city.Current
+	return &current, nil
+}
+
+func (c *FakeWeatherClient) GetAPIDayWeather(
+	ctx context.Context, query domain.WeatherQuery,
+) (*domain.DayWeatherResponse, error) {
+	city, err := c.call(ctx, c.find(query))
+	if err != nil {
+		return nil, err
+	}
+	if len(city.Day) == 0 {
+		return nil, fakeMissingDataError(city, "day weather")
+	}
+
+	return &domain.DayWeatherResponse{
+		Hours:    slices.Clone(city.Day),
+		Timezone: city.Timezone,
+	}, nil
+}
+
+func (c *FakeWeatherClient) GetAPIForecast(
+	ctx context.Context, query domain.WeatherQuery, days int,
+) (*domain.ForecastResponse, error) {
+	city, err := c.call(ctx, c.find(query))
+	if err != nil {
+		return nil, err
+	}
+	if len(city.Forecast) == 0 {
+		return nil, fakeMissingDataError(city, "forecast")
+	}
+
+	return &domain.ForecastResponse{Days: slices.Clone(city.Forecast[:min(days, len(city.Forecast))])}, nil
+}
+
+func (c *FakeWeatherClient) GetAPIAirQuality(
+	ctx context.Context, query domain.WeatherQuery,
+) (*domain.AirQualityResponse, error) {
+	city, err := c.call(ctx, c.find(query))
+	if err != nil {
+		return nil, err
+	}
+	if city.AirQuality == nil {
+		return nil, fakeMissingDataError(city, "air quality")
+	}
+
+	airQuality := *city.AirQuality
+	return &airQuality, nil
+}
+
+func (c *FakeWeatherClient) GetAPIAlerts(
+	ctx context.Context, query domain.WeatherQuery,
+) ([]domain.WeatherAlert, error) {
+	city, err := c.call(ctx, c.find(query))
+	if err != nil {
+		return nil, err
+	}
+
+	return slices.Clone(city.Alerts), nil
+}
+
+// find returns the city of the query, or nil if it isn't in the fixture.
+// Queries by coordinates are matched by coordinates only.
+func (c *FakeWeatherClient) find(query domain.WeatherQuery) *fakeCity {
+	if query.Coordinates == nil {
+		return c.findByName(query.City)
+	}
+
+	gridKey := query.Coordinates.GridKey()
+	for _, city := range c.cities {
+		if (domain.Coordinates{Latitude: city.Latitude, Longitude: city.Longitude}).GridKey() == gridKey {
+			return city
+		}
+	}
+	return nil
+}
+
+func (c *FakeWeatherClient) findByName(name string) *fakeCity {
+	name = domain.NormalizeLocationQuery(name)
+	for _, city := range c.cities {
+		if domain.NormalizeLocationQuery(city.Name) == name {
+			return city
+		}
+	}
+	return nil
+}
+
+// call simulates a request to the provider for the city: it waits for the latency of the city
+// and then fails or succeeds according to the next step of its scenario.
+func (c *FakeWeatherClient) call(ctx context.Context, city *fakeCity) (*fakeCity, error) {
+	if city == nil {
+		return nil, customErrors.ErrCityNotFound
+	}
+
+	step := city.nextStep()
+
+	if city.latency > 0 {
+		timer := time.NewTimer(city.latency)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	switch step {
+	case FakeStepUnavailable:
+		return nil, customErrors.ErrWeatherProviderUnavailable
+	case FakeStepError:
+		return nil, customErrors.ErrWeatherDataError
+	case FakeStepNotFound:
+		return nil, customErrors.ErrCityNotFound
+	default:
+		return city, nil
+	}
+}
+
+func (c *fakeCity) nextStep() string {
+	if len(c.Scenario) == 0 {
+		return FakeStepOK
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	step := c.Scenario[c.calls%len(c.Scenario)]
+	c.calls++
+	return step
+}
+
+func fakeMissingDataError(city *fakeCity, data string) error {
+	return fmt.Errorf("%w: fixture has no %s for %s", customErrors.ErrWeatherDataError, data, city.Name)
+}
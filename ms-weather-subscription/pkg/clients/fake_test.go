@@ -0,0 +1,244 @@
+package clients_test
+
+import (
+	"context"
+	"ms-weather-subscription/internal/domain"
+	"ms-weather-subscription/pkg/clients"
+	customErrors "ms-weather-subscription/pkg/errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeFixturePath is the fixture the fake provider uses in the dev and test environments.
+const fakeFixturePath = "../../configs/fixtures/weather.yaml"
+
+func TestFakeWeatherClient(t *testing.T) {
+	t.Run("Serves weather of the fixture", testFakeWeatherClientServesFixture)
+	t.Run("Matches coordinates to the city", testFakeWeatherClientCoordinates)
+	t.Run("Searches locations", testFakeWeatherClientSearchLocation)
+	t.Run("City not found", testFakeWeatherClientCityNotFound)
+	t.Run("Missing data", testFakeWeatherClientMissingData)
+	t.Run("Follows the scenario", testFakeWeatherClientScenario)
+	t.Run("Simulates latency", testFakeWeatherClientLatency)
+	t.Run("Chain passes request to next provider", testFakeWeatherClientChainFallback)
+	t.Run("Invalid fixture", testFakeWeatherClientInvalidFixture)
+}
+
+func newFixtureFakeClient(t *testing.T) *clients.FakeWeatherClient {
+	t.Helper()
+
+	fixture, err := clients.LoadFakeWeatherFixture(fakeFixturePath)
+	if err != nil {
+		t.Fatalf("failed to load fake weather fixture: %v", err)
+	}
+	client, err := clients.NewFakeWeatherClient(fixture)
+	if err != nil {
+		t.Fatalf("failed to create fake weather client: %v", err)
+	}
+	return client
+}
+
+func newFakeClient(t *testing.T, cities ...clients.FakeCity) *clients.FakeWeatherClient {
+	t.Helper()
+
+	client, err := clients.NewFakeWeatherClient(&clients.FakeWeatherFixture{Cities: cities})
+	if err != nil {
+		t.Fatalf("failed to create fake weather client: %v", err)
+	}
+	return client
+}
+
+func testFakeWeatherClientServesFixture(t *testing.T) {
+	t.Parallel()
+
+	client := newFixtureFakeClient(t)
+	ctx := context.Background()
+
+	current, err := client.GetAPICurrentWeather(ctx, domain.CityQuery(" KYIV"))
+	assert.NoError(t, err)
+	assert.Equal(t, float32(21.5), current.Temperature)
+	assert.Equal(t, "Sunny", current.Description)
+
+	day, err := client.GetAPIDayWeather(ctx, domain.CityQuery("Kyiv"))
+	assert.NoError(t, err)
+	assert.Equal(t, "Europe/Kyiv", day.Timezone)
+	assert.Len(t, day.Hours, 8)
+	assert.Equal(t, "07:00", day.Hours[2].Time)
+	assert.Equal(t, float32(14), day.Hours[2].Temperature)
+
+	forecast, err := client.GetAPIForecast(ctx, domain.CityQuery("Kyiv"), 2)
+	assert.NoError(t, err)
+	assert.Len(t, forecast.Days, 2)
+	assert.Equal(t, "2025-05-17", forecast.Days[0].Date)
+
+	forecast, err = client.GetAPIForecast(ctx, domain.CityQuery("Kyiv"), 14)
+	assert.NoError(t, err)
+	assert.Len(t, forecast.Days, 3, "only the days of the fixture are returned")
+
+	airQuality, err := client.GetAPIAirQuality(ctx, domain.CityQuery("Kyiv"))
+	assert.NoError(t, err)
+	assert.Equal(t, &domain.AirQualityResponse{AQI: 42, PM25: 9.8, PM10: 17.2, O3: 55.1, NO2: 11.4}, airQuality)
+
+	alerts, err := client.GetAPIAlerts(ctx, domain.CityQuery("Odesa"))
+	assert.NoError(t, err)
+	assert.Len(t, alerts, 1)
+	assert.Equal(t, "fake:odesa-storm", alerts[0].ID)
+	assert.Equal(t, time.Date(2025, 5, 17, 12, 0, 0, 0, time.UTC), alerts[0].Effective)
+
+	alerts, err = client.GetAPIAlerts(ctx, domain.CityQuery("Kyiv"))
+	assert.NoError(t, err)
+	assert.Empty(t, alerts)
+}
+
+func testFakeWeatherClientCoordinates(t *testing.T) {
+	t.Parallel()
+
+	client := newFixtureFakeClient(t)
+
+	current, err := client.GetAPICurrentWeather(
+		context.Background(),
+		domain.CoordinatesQuery(domain.Coordinates{Latitude: 50.4547, Longitude: 30.5238}),
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, float32(21.5), current.Temperature)
+
+	_, err = client.GetAPICurrentWeather(
+		context.Background(),
+		domain.CoordinatesQuery(domain.Coordinates{Latitude: 51.5, Longitude: -0.12}),
+	)
+	assert.ErrorIs(t, err, customErrors.ErrCityNotFound)
+}
+
+func testFakeWeatherClientSearchLocation(t *testing.T) {
+	t.Parallel()
+
+	location, err := newFixtureFakeClient(t).SearchLocation(context.Background(), "kyiv")
+
+	assert.NoError(t, err)
+	assert.Equal(t, &domain.Location{
+		ID:        "kyiv,ukraine@50,31",
+		Name:      "Kyiv",
+		Country:   "Ukraine",
+		Latitude:  50.45,
+		Longitude: 30.52,
+		Timezone:  "Europe/Kyiv",
+	}, location)
+}
+
+func testFakeWeatherClientCityNotFound(t *testing.T) {
+	t.Parallel()
+
+	client := newFixtureFakeClient(t)
+
+	_, err := client.GetAPICurrentWeather(context.Background(), domain.CityQuery("Atlantis"))
+	assert.ErrorIs(t, err, customErrors.ErrCityNotFound)
+
+	_, err = client.SearchLocation(context.Background(), "Atlantis")
+	assert.ErrorIs(t, err, customErrors.ErrCityNotFound)
+}
+
+func testFakeWeatherClientMissingData(t *testing.T) {
+	t.Parallel()
+
+	client := newFixtureFakeClient(t)
+
+	_, err := client.GetAPIDayWeather(context.Background(), domain.CityQuery("Odesa"))
+	assert.ErrorIs(t, err, customErrors.ErrWeatherDataError)
+
+	_, err = client.GetAPIAirQuality(context.Background(), domain.CityQuery("Lviv"))
+	assert.ErrorIs(t, err, customErrors.ErrWeatherDataError)
+}
+
+func testFakeWeatherClientScenario(t *testing.T) {
+	t.Parallel()
+
+	client := newFakeClient(t, clients.FakeCity{
+		Name:     "Kyiv",
+		Scenario: []string{clients.FakeStepOK, clients.FakeStepUnavailable, clients.FakeStepError, clients.FakeStepNotFound},
+		Current:  &domain.WeatherResponse{Temperature: 20},
+	})
+
+	for range 2 {
+		_, err := client.GetAPICurrentWeather(context.Background(), domain.CityQuery("Kyiv"))
+		assert.NoError(t, err)
+		_, err = client.GetAPICurrentWeather(context.Background(), domain.CityQuery("Kyiv"))
+		assert.ErrorIs(t, err, customErrors.ErrWeatherProviderUnavailable)
+		_, err = client.GetAPICurrentWeather(context.Background(), domain.CityQuery("Kyiv"))
+		assert.ErrorIs(t, err, customErrors.ErrWeatherDataError)
+		_, err = client.GetAPICurrentWeather(context.Background(), domain.CityQuery("Kyiv"))
+		assert.ErrorIs(t, err, customErrors.ErrCityNotFound)
+	}
+}
+
+func testFakeWeatherClientLatency(t *testing.T) {
+	t.Parallel()
+
+	client := newFakeClient(t, clients.FakeCity{
+		Name:    "Kyiv",
+		Latency: "50ms",
+		Current: &domain.WeatherResponse{Temperature: 20},
+	})
+
+	start := time.Now()
+	_, err := client.GetAPICurrentWeather(context.Background(), domain.CityQuery("Kyiv"))
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = client.GetAPICurrentWeather(ctx, domain.CityQuery("Kyiv"))
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func testFakeWeatherClientChainFallback(t *testing.T) {
+	t.Parallel()
+
+	chainClient, err := clients.NewChainWeatherClient([]clients.ChainWeatherProvider{
+		newFixtureFakeClient(t),
+		newFakeClient(t, clients.FakeCity{Name: "Kharkiv", Current: &domain.WeatherResponse{Temperature: 18}}),
+	})
+	if err != nil {
+		t.Fatalf("failed to create chain weather client: %v", err)
+	}
+
+	current, err := chainClient.GetAPICurrentWeather(context.Background(), domain.CityQuery("Kharkiv"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, float32(18), current.Temperature)
+}
+
+func testFakeWeatherClientInvalidFixture(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		fixture string
+		err     string
+	}{
+		{name: "Unknown field", fixture: `cities: [{name: Kyiv, temperature: 20}]`, err: "unknown field"},
+		{name: "Invalid latency", fixture: `cities: [{name: Kyiv, latency: fast}]`, err: "invalid latency"},
+		{name: "Unknown scenario step", fixture: `cities: [{name: Kyiv, scenario: [down]}]`, err: "unknown scenario step"},
+		{name: "Duplicate city", fixture: `cities: [{name: Kyiv}, {name: kyiv}]`, err: "listed more than once"},
+		{name: "City without name", fixture: `cities: [{country: Ukraine}]`, err: "must have a name"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "weather.yaml")
+			if err := os.WriteFile(path, []byte(tt.fixture), 0o600); err != nil {
+				t.Fatalf("failed to write fixture: %v", err)
+			}
+
+			fixture, err := clients.LoadFakeWeatherFixture(path)
+			if err == nil {
+				_, err = clients.NewFakeWeatherClient(fixture)
+			}
+
+			assert.ErrorContains(t, err, tt.err)
+		})
+	}
+}
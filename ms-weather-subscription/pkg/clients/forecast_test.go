@@ -0,0 +1,167 @@
+package clients_test
+
+import (
+	"context"
+	"ms-weather-subscription/internal/domain"
+	"ms-weather-subscription/pkg/clients"
+	customErrors "ms-weather-subscription/pkg/errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetAPIForecast(t *testing.T) {
+	t.Run("WeatherAPI", testWeatherAPIForecast)
+	t.Run("WeatherAPI fewer days than requested", testWeatherAPIForecastIncomplete)
+	t.Run("VisualCrossing", testVisualCrossingForecast)
+	t.Run("OpenMeteo", testOpenMeteoForecast)
+	t.Run("OpenMeteo incomplete response", testOpenMeteoForecastIncomplete)
+}
+
+var expectedTwoDayForecast = &domain.ForecastResponse{Days: []domain.DayForecast{
+	{
+		Date:                     "2025-05-17",
+		MinTemperature:           9.5,
+		MaxTemperature:           21.3,
+		AvgTemperature:           15.2,
+		Humidity:                 64,
+		PrecipitationProbability: 20,
+		Description:              "Partly cloudy",
+	},
+	{
+		Date:                     "2025-05-18",
+		MinTemperature:           11,
+		MaxTemperature:           18.4,
+		AvgTemperature:           14.1,
+		Humidity:                 81,
+		PrecipitationProbability: 85,
+		Description:              "Slight rain",
+	},
+}}
+
+func testWeatherAPIForecast(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/forecast.json", r.URL.Path)
+		assert.Equal(t, "2", r.URL.Query().Get("days"))
+		w.WriteHeader(http.StatusOK)
+		_, err := w.Write([]byte(`{"forecast": {"forecastday": [
+			{"date": "2025-05-17", "day": {"maxtemp_c": 21.3, "mintemp_c": 9.5, "avgtemp_c": 15.2,
+				"avghumidity": 64, "daily_chance_of_rain": 20, "condition": {"text": "Partly cloudy"}}},
+			{"date": "2025-05-18", "day": {"maxtemp_c": 18.4, "mintemp_c": 11, "avgtemp_c": 14.1,
+				"avghumidity": 81, "daily_chance_of_rain": 85, "condition": {"text": "Slight rain"}}}
+		]}}`))
+		if err != nil {
+			t.Fatalf("failed to write response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	forecast, err := clients.NewWeatherAPIClient("dummy-key").
+		WithBaseURL(server.URL).
+		WithClient(server.Client()).
+		GetAPIForecast(context.Background(), domain.CityQuery("Kyiv"), 2)
+
+	assert.NoError(t, err)
+	assert.Equal(t, expectedTwoDayForecast, forecast)
+}
+
+func testWeatherAPIForecastIncomplete(t *testing.T) {
+	t.Parallel()
+
+	// Setup: the free plan forecasts 3 days at most
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "7", r.URL.Query().Get("days"))
+		w.WriteHeader(http.StatusOK)
+		_, err := w.Write([]byte(`{"forecast": {"forecastday": [
+			{"date": "2025-05-17", "day": {"maxtemp_c": 21.3}},
+			{"date": "2025-05-18", "day": {"maxtemp_c": 18.4}},
+			{"date": "2025-05-19", "day": {"maxtemp_c": 19.1}}
+		]}}`))
+		if err != nil {
+			t.Fatalf("failed to write response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	breaker := clients.NewCircuitBreaker("test-forecast-incomplete", 1, time.Hour)
+	provider := clients.NewCircuitBreakerProvider(
+		clients.NewWeatherAPIClient("dummy-key").WithBaseURL(server.URL).WithClient(server.Client()),
+		breaker,
+	)
+
+	// Execute
+	forecast, err := provider.GetAPIForecast(context.Background(), domain.CityQuery("Kyiv"), 7)
+
+	// Verify: the provider answered, so its circuit stays closed
+	assert.ErrorIs(t, err, customErrors.ErrForecastIncomplete)
+	assert.Nil(t, forecast)
+	assert.Equal(t, clients.CircuitClosed, breaker.State())
+}
+
+func testVisualCrossingForecast(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/Kyiv/next1days", r.URL.Path)
+		w.WriteHeader(http.StatusOK)
+		_, err := w.Write([]byte(`{"days": [
+			{"datetime": "2025-05-17", "tempmax": 21.3, "tempmin": 9.5, "temp": 15.2,
+				"humidity": 64, "precipprob": 20, "conditions": "Partly cloudy"},
+			{"datetime": "2025-05-18", "tempmax": 18.4, "tempmin": 11, "temp": 14.1,
+				"humidity": 81, "precipprob": 85, "conditions": "Slight rain"}
+		]}`))
+		if err != nil {
+			t.Fatalf("failed to write response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	forecast, err := clients.NewVisualCrossingClient("dummy-key").
+		WithBaseURL(server.URL).
+		WithClient(server.Client()).
+		GetAPIForecast(context.Background(), domain.CityQuery("Kyiv"), 2)
+
+	assert.NoError(t, err)
+	assert.Equal(t, expectedTwoDayForecast, forecast)
+}
+
+func testOpenMeteoForecast(t *testing.T) {
+	t.Parallel()
+
+	server := newOpenMeteoServer(t, openMeteoKyivGeocodingResponse, http.StatusOK, `{
+		"daily": {
+			"time": ["2025-05-17", "2025-05-18"],
+			"temperature_2m_max": [21.3, 18.4],
+			"temperature_2m_min": [9.5, 11],
+			"temperature_2m_mean": [15.2, 14.1],
+			"relative_humidity_2m_mean": [64, 81],
+			"precipitation_probability_max": [20, 85],
+			"weather_code": [2, 61]
+		}
+	}`)
+
+	forecast, err := fakeNewOpenMeteoClient(server).GetAPIForecast(context.Background(), domain.CityQuery("Kyiv"), 2)
+
+	assert.NoError(t, err)
+	assert.Equal(t, expectedTwoDayForecast, forecast)
+}
+
+func testOpenMeteoForecastIncomplete(t *testing.T) {
+	t.Parallel()
+
+	server := newOpenMeteoServer(t, openMeteoKyivGeocodingResponse, http.StatusOK, `{
+		"daily": {
+			"time": ["2025-05-17", "2025-05-18"],
+			"temperature_2m_max": [21.3]
+		}
+	}`)
+
+	_, err := fakeNewOpenMeteoClient(server).GetAPIForecast(context.Background(), domain.CityQuery("Kyiv"), 2)
+
+	assert.ErrorIs(t, err, customErrors.ErrWeatherDataError)
+}
@@ -0,0 +1,53 @@
+package clients
+
+import (
+	"context"
+	"errors"
+	"ms-weather-subscription/internal/domain"
+)
+
+// LocationSearcher resolves user input to a location via a provider's search API.
+// The query is the raw text, each provider escapes it for its API.
+// It returns ErrCityNotFound if the provider knows no such city.
+type LocationSearcher interface {
+	SearchLocation(ctx context.Context, query string) (*domain.Location, error)
+	// LookupTimezone returns the IANA timezone at the coordinates, e.g. "Europe/Kyiv".
+	LookupTimezone(ctx context.Context, coordinates domain.Coordinates) (string, error)
+}
+
+// ChainLocationProvider is a single provider which can take part in ChainLocationSearcher.
+type ChainLocationProvider interface {
+	LocationSearcher
+	Name() string
+}
+
+// ChainLocationSearcher queries searchers one by one until one of them resolves the location.
+type ChainLocationSearcher struct {
+	searchers []ChainLocationProvider
+}
+
+func NewChainLocationSearcher(searchers []ChainLocationProvider) (*ChainLocationSearcher, error) {
+	if len(searchers) == 0 {
+		return nil, errors.New("cannot create ChainLocationSearcher with empty searcher list")
+	}
+
+	return &ChainLocationSearcher{
+		searchers: searchers,
+	}, nil
+}
+
+func (c *ChainLocationSearcher) SearchLocation(ctx context.Context, query string) (*domain.Location, error) {
+	return callChain(ctx, c.searchers, "SearchLocation",
+		func(searcher ChainLocationProvider) (*domain.Location, error) {
+			return searcher.SearchLocation(ctx, query)
+		},
+	)
+}
+
+func (c *ChainLocationSearcher) LookupTimezone(ctx context.Context, coordinates domain.Coordinates) (string, error) {
+	return callChain(ctx, c.searchers, "LookupTimezone",
+		func(searcher ChainLocationProvider) (string, error) {
+			return searcher.LookupTimezone(ctx, coordinates)
+		},
+	)
+}
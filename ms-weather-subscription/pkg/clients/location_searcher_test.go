@@ -0,0 +1,212 @@
+package clients_test
+
+import (
+	"context"
+	"ms-weather-subscription/internal/domain"
+	"ms-weather-subscription/pkg/clients"
+	customErrors "ms-weather-subscription/pkg/errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearchLocation(t *testing.T) {
+	t.Run("OpenMeteo", testOpenMeteoSearchLocation)
+	t.Run("WeatherAPI", testWeatherAPISearchLocation)
+	t.Run("WeatherAPI city not found", testWeatherAPISearchLocationNotFound)
+	t.Run("Raw query escaped by each provider", testSearchLocationEscapesQuery)
+	t.Run("Chain passes request to next searcher", testChainSearchLocationFallback)
+	t.Run("Chain stops on city not found", testChainSearchLocationNotFound)
+}
+
+func TestLookupTimezone(t *testing.T) {
+	t.Run("OpenMeteo", testOpenMeteoLookupTimezone)
+	t.Run("WeatherAPI", testWeatherAPILookupTimezone)
+}
+
+// newWeatherAPISearchServer serves search (/search.json) and timezone (/timezone.json) requests.
+func newWeatherAPISearchServer(t *testing.T, searchBody string) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		switch r.URL.Path {
+		case "/search.json":
+			_, err = w.Write([]byte(searchBody))
+		case "/timezone.json":
+			assert.Equal(t, "50.430000,30.520000", r.URL.Query().Get("q"))
+			_, err = w.Write([]byte(`{"location": {"name": "Kiev", "tz_id": "Europe/Kiev"}}`))
+		default:
+			t.Errorf("unexpected request path: %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+		if err != nil {
+			t.Fatalf("failed to write response: %v", err)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func testOpenMeteoSearchLocation(t *testing.T) {
+	t.Parallel()
+
+	server := newOpenMeteoServer(t, openMeteoKyivGeocodingResponse, http.StatusOK, `{}`)
+
+	location, err := fakeNewOpenMeteoClient(server).SearchLocation(context.Background(), "kiev")
+
+	assert.NoError(t, err)
+	assert.Equal(t, &domain.Location{
+		ID:        "kyiv,ukraine@50,31",
+		Name:      "Kyiv",
+		Country:   "Ukraine",
+		Latitude:  50.45466,
+		Longitude: 30.5238,
+		Timezone:  "Europe/Kyiv",
+	}, location)
+}
+
+func testWeatherAPISearchLocation(t *testing.T) {
+	t.Parallel()
+
+	server := newWeatherAPISearchServer(t, `[
+		{"id": 2801268, "name": "Kiev", "region": "Kyyivs'ka Oblast'", "country": "Ukraine", "lat": 50.43, "lon": 30.52}
+	]`)
+
+	location, err := clients.NewWeatherAPIClient("dummy-key").
+		WithBaseURL(server.URL).
+		WithClient(server.Client()).
+		SearchLocation(context.Background(), "kyiv")
+
+	assert.NoError(t, err)
+	assert.Equal(t, &domain.Location{
+		ID:        "kiev,ukraine@50,31",
+		Name:      "Kiev",
+		Country:   "Ukraine",
+		Latitude:  50.43,
+		Longitude: 30.52,
+		Timezone:  "Europe/Kiev",
+	}, location)
+}
+
+func testWeatherAPISearchLocationNotFound(t *testing.T) {
+	t.Parallel()
+
+	server := newWeatherAPISearchServer(t, `[]`)
+
+	_, err := clients.NewWeatherAPIClient("dummy-key").
+		WithBaseURL(server.URL).
+		WithClient(server.Client()).
+		SearchLocation(context.Background(), "atlantis")
+
+	assert.ErrorIs(t, err, customErrors.ErrCityNotFound)
+}
+
+func testSearchLocationEscapesQuery(t *testing.T) {
+	t.Parallel()
+
+	var queries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		switch r.URL.Path {
+		case "/search.json":
+			queries = append(queries, r.URL.Query().Get("q"))
+			_, err = w.Write([]byte(`[]`))
+		case "/search":
+			queries = append(queries, r.URL.Query().Get("name"))
+			_, err = w.Write([]byte(`{"results": []}`))
+		default:
+			t.Errorf("unexpected request path: %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+		if err != nil {
+			t.Fatalf("failed to write response: %v", err)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	_, weatherAPIErr := clients.NewWeatherAPIClient("dummy-key").
+		WithBaseURL(server.URL).
+		WithClient(server.Client()).
+		SearchLocation(context.Background(), "new york & co")
+	_, openMeteoErr := fakeNewOpenMeteoClient(server).SearchLocation(context.Background(), "new york & co")
+
+	assert.ErrorIs(t, weatherAPIErr, customErrors.ErrCityNotFound)
+	assert.ErrorIs(t, openMeteoErr, customErrors.ErrCityNotFound)
+	assert.Equal(t, []string{"new york & co", "new york & co"}, queries)
+}
+
+func testChainSearchLocationFallback(t *testing.T) {
+	t.Parallel()
+
+	weatherAPIServer := newStaticServer(t, http.StatusInternalServerError, `{}`, nil)
+	openMeteoServer := newOpenMeteoServer(t, openMeteoKyivGeocodingResponse, http.StatusOK, `{}`)
+
+	searcher, err := clients.NewChainLocationSearcher([]clients.ChainLocationProvider{
+		clients.NewWeatherAPIClient("dummy-key").
+			WithBaseURL(weatherAPIServer.URL).
+			WithClient(weatherAPIServer.Client()),
+		fakeNewOpenMeteoClient(openMeteoServer),
+	})
+	if err != nil {
+		t.Fatalf("failed to create chain location searcher: %v", err)
+	}
+
+	location, err := searcher.SearchLocation(context.Background(), "kyiv")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "kyiv,ukraine@50,31", location.ID)
+}
+
+func testChainSearchLocationNotFound(t *testing.T) {
+	t.Parallel()
+
+	weatherAPIServer := newWeatherAPISearchServer(t, `[]`)
+	var openMeteoCalls atomic.Int32
+	openMeteoServer := newStaticServer(t, http.StatusOK, openMeteoKyivGeocodingResponse, &openMeteoCalls)
+
+	searcher, err := clients.NewChainLocationSearcher([]clients.ChainLocationProvider{
+		clients.NewWeatherAPIClient("dummy-key").
+			WithBaseURL(weatherAPIServer.URL).
+			WithClient(weatherAPIServer.Client()),
+		fakeNewOpenMeteoClient(openMeteoServer),
+	})
+	if err != nil {
+		t.Fatalf("failed to create chain location searcher: %v", err)
+	}
+
+	_, err = searcher.SearchLocation(context.Background(), "atlantis")
+
+	assert.ErrorIs(t, err, customErrors.ErrCityNotFound)
+	assert.Zero(t, openMeteoCalls.Load(), "city unknown to one provider is not searched further")
+}
+
+func testOpenMeteoLookupTimezone(t *testing.T) {
+	t.Parallel()
+
+	server := newOpenMeteoServer(t, `{}`, http.StatusOK, `{"latitude": 50.45, "timezone": "Europe/Kyiv"}`)
+
+	timezone, err := fakeNewOpenMeteoClient(server).
+		LookupTimezone(context.Background(), domain.Coordinates{Latitude: 50.45466, Longitude: 30.5238})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Europe/Kyiv", timezone)
+}
+
+func testWeatherAPILookupTimezone(t *testing.T) {
+	t.Parallel()
+
+	server := newWeatherAPISearchServer(t, `[]`)
+
+	timezone, err := clients.NewWeatherAPIClient("dummy-key").
+		WithBaseURL(server.URL).
+		WithClient(server.Client()).
+		LookupTimezone(context.Background(), domain.Coordinates{Latitude: 50.43, Longitude: 30.52})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Europe/Kiev", timezone)
+}
@@ -0,0 +1,446 @@
+package clients
+
+import (
+	"common/logger"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"ms-weather-subscription/internal/domain"
+	customErrors "ms-weather-subscription/pkg/errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	openMeteoClientTimeout      = 10 * time.Second
+	openMeteoUnknownDescription = "Unknown"
+)
+
+// openMeteoWeatherCodes maps WMO weather interpretation codes to human-readable descriptions.
+var openMeteoWeatherCodes = map[int]string{
+	0:  "Clear sky",
+	1:  "Mainly clear",
+	2:  "Partly cloudy",
+	3:  "Overcast",
+	45: "Fog",
+	48: "Depositing rime fog",
+	51: "Light drizzle",
+	53: "Moderate drizzle",
+	55: "Dense drizzle",
+	56: "Light freezing drizzle",
+	57: "Dense freezing drizzle",
+	61: "Slight rain",
+	63: "Moderate rain",
+	65: "Heavy rain",
+	66: "Light freezing rain",
+	67: "Heavy freezing rain",
+	71: "Slight snow fall",
+	73: "Moderate snow fall",
+	75: "Heavy snow fall",
+	77: "Snow grains",
+	80: "Slight rain showers",
+	81: "Moderate rain showers",
+	82: "Violent rain showers",
+	85: "Slight snow showers",
+	86: "Heavy snow showers",
+	95: "Thunderstorm",
+	96: "Thunderstorm with slight hail",
+	99: "Thunderstorm with heavy hail",
+}
+
+// OpenMeteoClient is a keyless weather provider. It resolves a city to coordinates
+// via the geocoding API, unless coordinates are given, and then requests the forecast
+// or the air quality for those coordinates.
+type OpenMeteoClient struct {
+	baseURL           string
+	geocodingBaseURL  string
+	airQualityBaseURL string
+	httpClient        *http.Client
+}
+
+func NewOpenMeteoClient() *OpenMeteoClient {
+	return &OpenMeteoClient{
+		baseURL:           "https://api.open-meteo.com/v1",
+		geocodingBaseURL:  "https://geocoding-api.open-meteo.com/v1",
+		airQualityBaseURL: "https://air-quality-api.open-meteo.com/v1",
+		httpClient: &http.Client{
+			Timeout:   openMeteoClientTimeout,
+			Transport: NewLoggingRoundTripper("OpenMeteoClient"),
+		},
+	}
+}
+
+// WithClient mostly used for testing purposes to inject a custom HTTP client.
+func (c *OpenMeteoClient) WithClient(client *http.Client) *OpenMeteoClient {
+	c.httpClient = client
+	return c
+}
+
+// WithBaseURL mostly used for testing purposes to inject a custom forecast base URL.
+func (c *OpenMeteoClient) WithBaseURL(baseURL string) *OpenMeteoClient {
+	c.baseURL = baseURL
+	return c
+}
+
+// WithGeocodingBaseURL mostly used for testing purposes to inject a custom geocoding base URL.
+func (c *OpenMeteoClient) WithGeocodingBaseURL(baseURL string) *OpenMeteoClient {
+	c.geocodingBaseURL = baseURL
+	return c
+}
+
+// WithAirQualityBaseURL mostly used for testing purposes to inject a custom air quality base URL.
+func (c *OpenMeteoClient) WithAirQualityBaseURL(baseURL string) *OpenMeteoClient {
+	c.airQualityBaseURL = baseURL
+	return c
+}
+
+// WithTimeout overrides the default HTTP client timeout.
+func (c *OpenMeteoClient) WithTimeout(timeout time.Duration) *OpenMeteoClient {
+	c.httpClient.Timeout = timeout
+	return c
+}
+
+func (c *OpenMeteoClient) Name() string {
+	return OpenMeteoProviderName
+}
+
+type openMeteoErrorResponse struct {
+	Error  bool   `json:"error"`
+	Reason string `json:"reason"`
+}
+
+type openMeteoLocation struct {
+	Name      string  `json:"name"`
+	Country   string  `json:"country"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Timezone  string  `json:"timezone"`
+}
+
+type openMeteoGeocodingResponse struct {
+	Results []openMeteoLocation `json:"results"`
+}
+
+// openMeteoVariables are requested both for current weather and for the hourly forecast.
+// Wind speed is returned in km/h, pressure in hPa and visibility in meters.
+const openMeteoVariables = "temperature_2m,relative_humidity_2m,apparent_temperature,weather_code," +
+	"wind_speed_10m,wind_direction_10m,wind_gusts_10m,precipitation,precipitation_probability," +
+	"uv_index,pressure_msl,visibility,cloud_cover"
+
+const metersInKilometer = 1000
+
+type openMeteoCurrentResponse struct {
+	Current struct {
+		Temperature              float32 `json:"temperature_2m"`
+		Humidity                 float32 `json:"relative_humidity_2m"`
+		FeelsLike                float32 `json:"apparent_temperature"`
+		WeatherCode              int     `json:"weather_code"`
+		WindSpeed                float32 `json:"wind_speed_10m"`
+		WindDirection            float32 `json:"wind_direction_10m"`
+		WindGust                 float32 `json:"wind_gusts_10m"`
+		Precipitation            float32 `json:"precipitation"`
+		PrecipitationProbability float32 `json:"precipitation_probability"`
+		UVIndex                  float32 `json:"uv_index"`
+		Pressure                 float32 `json:"pressure_msl"`
+		Visibility               float32 `json:"visibility"`
+		CloudCover               float32 `json:"cloud_cover"`
+	} `json:"current"`
+}
+
+type openMeteoHourlyResponse struct {
+	Timezone string `json:"timezone"`
+	Hourly   struct {
+		Time                     []string  `json:"time"` // "2025-05-17T07:00"
+		Temperature              []float32 `json:"temperature_2m"`
+		Humidity                 []float32 `json:"relative_humidity_2m"`
+		FeelsLike                []float32 `json:"apparent_temperature"`
+		WeatherCode              []int     `json:"weather_code"`
+		WindSpeed                []float32 `json:"wind_speed_10m"`
+		WindDirection            []float32 `json:"wind_direction_10m"`
+		WindGust                 []float32 `json:"wind_gusts_10m"`
+		Precipitation            []float32 `json:"precipitation"`
+		PrecipitationProbability []float32 `json:"precipitation_probability"`
+		UVIndex                  []float32 `json:"uv_index"`
+		Pressure                 []float32 `json:"pressure_msl"`
+		Visibility               []float32 `json:"visibility"`
+		CloudCover               []float32 `json:"cloud_cover"`
+	} `json:"hourly"`
+}
+
+func openMeteoDescription(code int) string {
+	if description, ok := openMeteoWeatherCodes[code]; ok {
+		return description
+	}
+	return openMeteoUnknownDescription
+}
+
+func (c *OpenMeteoClient) processErrorResponse(resp *http.Response) error {
+	var apiErr openMeteoErrorResponse
+	if err := json.NewDecoder(resp.Body).Decode(&apiErr); err != nil {
+		logger.Errorf("error parsing OpenMeteo error response: %s", err)
+		return customErrors.ErrWeatherDataError
+	}
+
+	logger.Errorf("OpenMeteo error response (status %d): %s", resp.StatusCode, apiErr.Reason)
+	return customErrors.ErrWeatherDataError
+}
+
+func (c *OpenMeteoClient) geocode(ctx context.Context, city string) (*openMeteoLocation, error) {
+	requestURL := fmt.Sprintf(
+		"%s/search?name=%s&count=1&language=en&format=json", c.geocodingBaseURL, url.QueryEscape(city),
+	)
+
+	var result openMeteoGeocodingResponse
+	if err := getJSON(ctx, c.httpClient, OpenMeteoProviderName, c.processErrorResponse, requestURL, &result); err != nil {
+		return nil, err
+	}
+
+	if len(result.Results) == 0 {
+		return nil, customErrors.ErrCityNotFound
+	}
+
+	return &result.Results[0], nil
+}
+
+// coordinates returns the coordinates of the query, geocoding the city if they aren't given.
+func (c *OpenMeteoClient) coordinates(ctx context.Context, query domain.WeatherQuery) (domain.Coordinates, error) {
+	if query.Coordinates != nil {
+		return *query.Coordinates, nil
+	}
+
+	location, err := c.geocode(ctx, query.City)
+	if err != nil {
+		return domain.Coordinates{}, err
+	}
+	return domain.Coordinates{Latitude: location.Latitude, Longitude: location.Longitude}, nil
+}
+
+func (c *OpenMeteoClient) SearchLocation(ctx context.Context, query string) (*domain.Location, error) {
+	location, err := c.geocode(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	found := domain.NewLocation(
+		location.Name, location.Country, location.Latitude, location.Longitude, location.Timezone,
+	)
+	return &found, nil
+}
+
+type openMeteoTimezoneResponse struct {
+	Timezone string `json:"timezone"`
+}
+
+// LookupTimezone returns the timezone the forecast API resolves for the coordinates, without any weather variables.
+func (c *OpenMeteoClient) LookupTimezone(ctx context.Context, coordinates domain.Coordinates) (string, error) {
+	var result openMeteoTimezoneResponse
+	requestURL := fmt.Sprintf(
+		"%s/forecast?latitude=%f&longitude=%f&forecast_days=1&timezone=auto",
+		c.baseURL, coordinates.Latitude, coordinates.Longitude,
+	)
+	if err := getJSON(ctx, c.httpClient, OpenMeteoProviderName, c.processErrorResponse, requestURL, &result); err != nil {
+		return "", err
+	}
+	return result.Timezone, nil
+}
+
+func (c *OpenMeteoClient) GetAPICurrentWeather(
+	ctx context.Context, query domain.WeatherQuery,
+) (*domain.WeatherResponse, error) {
+	coordinates, err := c.coordinates(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	var result openMeteoCurrentResponse
+	requestURL := fmt.Sprintf(
+		"%s/forecast?latitude=%f&longitude=%f"+
+			"&current=%s&timezone=auto",
+		c.baseURL, coordinates.Latitude, coordinates.Longitude, openMeteoVariables,
+	)
+	if err = getJSON(ctx, c.httpClient, OpenMeteoProviderName, c.processErrorResponse, requestURL, &result); err != nil {
+		return nil, err
+	}
+
+	current := result.Current
+	return &domain.WeatherResponse{
+		Temperature:              current.Temperature,
+		Humidity:                 current.Humidity,
+		Description:              openMeteoDescription(current.WeatherCode),
+		FeelsLike:                current.FeelsLike,
+		WindSpeed:                current.WindSpeed,
+		WindDirection:            current.WindDirection,
+		WindGust:                 current.WindGust,
+		Precipitation:            current.Precipitation,
+		PrecipitationProbability: current.PrecipitationProbability,
+		UVIndex:                  current.UVIndex,
+		Pressure:                 current.Pressure,
+		Visibility:               current.Visibility / metersInKilometer,
+		CloudCover:               current.CloudCover,
+	}, nil
+}
+
+func (c *OpenMeteoClient) GetAPIDayWeather(
+	ctx context.Context, query domain.WeatherQuery,
+) (*domain.DayWeatherResponse, error) {
+	coordinates, err := c.coordinates(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	var result openMeteoHourlyResponse
+	requestURL := fmt.Sprintf(
+		"%s/forecast?latitude=%f&longitude=%f"+
+			"&hourly=%s&forecast_days=1&timezone=auto",
+		c.baseURL, coordinates.Latitude, coordinates.Longitude, openMeteoVariables,
+	)
+	if err = getJSON(ctx, c.httpClient, OpenMeteoProviderName, c.processErrorResponse, requestURL, &result); err != nil {
+		return nil, err
+	}
+
+	return mapOpenMeteoDayWeather(result), nil
+}
+
+func mapOpenMeteoDayWeather(result openMeteoHourlyResponse) *domain.DayWeatherResponse {
+	hourly := result.Hourly
+	hours := make([]domain.HourlyWeather, 0, len(hourly.Time))
+	for i, dateTime := range hourly.Time {
+		// "2025-05-17T07:00" -> "07:00"
+		_, timePart, found := strings.Cut(dateTime, "T")
+		if !found || i >= len(hourly.Temperature) || i >= len(hourly.Humidity) || i >= len(hourly.WeatherCode) {
+			continue
+		}
+
+		hours = append(hours, domain.HourlyWeather{
+			Time: timePart,
+			WeatherResponse: domain.WeatherResponse{
+				Temperature:              hourly.Temperature[i],
+				Humidity:                 hourly.Humidity[i],
+				Description:              openMeteoDescription(hourly.WeatherCode[i]),
+				FeelsLike:                valueAt(hourly.FeelsLike, i),
+				WindSpeed:                valueAt(hourly.WindSpeed, i),
+				WindDirection:            valueAt(hourly.WindDirection, i),
+				WindGust:                 valueAt(hourly.WindGust, i),
+				Precipitation:            valueAt(hourly.Precipitation, i),
+				PrecipitationProbability: valueAt(hourly.PrecipitationProbability, i),
+				UVIndex:                  valueAt(hourly.UVIndex, i),
+				Pressure:                 valueAt(hourly.Pressure, i),
+				Visibility:               valueAt(hourly.Visibility, i) / metersInKilometer,
+				CloudCover:               valueAt(hourly.CloudCover, i),
+			},
+		})
+	}
+
+	return &domain.DayWeatherResponse{
+		Hours:    hours,
+		Timezone: result.Timezone,
+	}
+}
+
+// valueAt returns values[i] or zero if the provider returned fewer values than expected.
+func valueAt(values []float32, i int) float32 {
+	if i >= len(values) {
+		return 0
+	}
+	return values[i]
+}
+
+type openMeteoDailyResponse struct {
+	Daily struct {
+		Time                     []string  `json:"time"` // "2025-05-17"
+		MaxTemperature           []float32 `json:"temperature_2m_max"`
+		MinTemperature           []float32 `json:"temperature_2m_min"`
+		AvgTemperature           []float32 `json:"temperature_2m_mean"`
+		Humidity                 []float32 `json:"relative_humidity_2m_mean"`
+		PrecipitationProbability []float32 `json:"precipitation_probability_max"`
+		WeatherCode              []int     `json:"weather_code"`
+	} `json:"daily"`
+}
+
+func (c *OpenMeteoClient) GetAPIForecast(
+	ctx context.Context, query domain.WeatherQuery, days int,
+) (*domain.ForecastResponse, error) {
+	coordinates, err := c.coordinates(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	var result openMeteoDailyResponse
+	requestURL := fmt.Sprintf(
+		"%s/forecast?latitude=%f&longitude=%f"+
+			"&daily=temperature_2m_max,temperature_2m_min,temperature_2m_mean,relative_humidity_2m_mean,"+
+			"precipitation_probability_max,weather_code&forecast_days=%d&timezone=auto",
+		c.baseURL, coordinates.Latitude, coordinates.Longitude, days,
+	)
+	if err = getJSON(ctx, c.httpClient, OpenMeteoProviderName, c.processErrorResponse, requestURL, &result); err != nil {
+		return nil, err
+	}
+
+	return mapOpenMeteoForecast(result)
+}
+
+func mapOpenMeteoForecast(result openMeteoDailyResponse) (*domain.ForecastResponse, error) {
+	daily := result.Daily
+	days := len(daily.Time)
+	if len(daily.MaxTemperature) < days || len(daily.MinTemperature) < days ||
+		len(daily.AvgTemperature) < days || len(daily.Humidity) < days ||
+		len(daily.PrecipitationProbability) < days || len(daily.WeatherCode) < days {
+		logger.Errorf("OpenMeteo daily forecast has fewer values than days: %d", days)
+		return nil, customErrors.ErrWeatherDataError
+	}
+
+	forecast := &domain.ForecastResponse{Days: make([]domain.DayForecast, 0, days)}
+	for i, date := range daily.Time {
+		forecast.Days = append(forecast.Days, domain.DayForecast{
+			Date:                     date,
+			MinTemperature:           daily.MinTemperature[i],
+			MaxTemperature:           daily.MaxTemperature[i],
+			AvgTemperature:           daily.AvgTemperature[i],
+			Humidity:                 daily.Humidity[i],
+			PrecipitationProbability: daily.PrecipitationProbability[i],
+			Description:              openMeteoDescription(daily.WeatherCode[i]),
+		})
+	}
+
+	return forecast, nil
+}
+
+type openMeteoAirQualityResponse struct {
+	Current struct {
+		USAQI           float32 `json:"us_aqi"`
+		PM25            float32 `json:"pm2_5"`
+		PM10            float32 `json:"pm10"`
+		Ozone           float32 `json:"ozone"`
+		NitrogenDioxide float32 `json:"nitrogen_dioxide"`
+	} `json:"current"`
+}
+
+func (c *OpenMeteoClient) GetAPIAirQuality(
+	ctx context.Context, query domain.WeatherQuery,
+) (*domain.AirQualityResponse, error) {
+	coordinates, err := c.coordinates(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	var result openMeteoAirQualityResponse
+	requestURL := fmt.Sprintf(
+		"%s/air-quality?latitude=%f&longitude=%f&current=us_aqi,pm2_5,pm10,ozone,nitrogen_dioxide",
+		c.airQualityBaseURL, coordinates.Latitude, coordinates.Longitude,
+	)
+	if err = getJSON(ctx, c.httpClient, OpenMeteoProviderName, c.processErrorResponse, requestURL, &result); err != nil {
+		return nil, err
+	}
+
+	current := result.Current
+	return &domain.AirQualityResponse{
+		AQI:  int(math.Round(float64(current.USAQI))),
+		PM25: current.PM25,
+		PM10: current.PM10,
+		O3:   current.Ozone,
+		NO2:  current.NitrogenDioxide,
+	}, nil
+}
@@ -0,0 +1,244 @@
+package clients_test
+
+import (
+	"context"
+	"ms-weather-subscription/internal/domain"
+	"ms-weather-subscription/pkg/clients"
+	customErrors "ms-weather-subscription/pkg/errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOpenMeteoClient(t *testing.T) {
+	t.Run("Current weather success", testOpenMeteoCurrentWeatherSuccess)
+	t.Run("Day weather success", testOpenMeteoDayWeatherSuccess)
+	t.Run("Coordinates skip geocoding", testOpenMeteoCoordinates)
+	t.Run("City not found", testOpenMeteoCityNotFound)
+	t.Run("Forecast error", testOpenMeteoForecastError)
+	t.Run("Passes request to next client in chain", testOpenMeteoPassesToNextClient)
+}
+
+const openMeteoKyivGeocodingResponse = `{
+	"results": [
+		{
+			"id": 703448,
+			"name": "Kyiv",
+			"latitude": 50.45466,
+			"longitude": 30.5238,
+			"timezone": "Europe/Kyiv",
+			"country": "Ukraine"
+		}
+	]
+}`
+
+// newOpenMeteoServer serves geocoding (/search) and forecast (/forecast) requests from a single server.
+func newOpenMeteoServer(t *testing.T, geocodingBody string, forecastStatus int, forecastBody string) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		switch r.URL.Path {
+		case "/search":
+			w.WriteHeader(http.StatusOK)
+			_, err = w.Write([]byte(geocodingBody))
+		case "/forecast":
+			assert.Equal(t, "50.454660", r.URL.Query().Get("latitude"))
+			assert.Equal(t, "30.523800", r.URL.Query().Get("longitude"))
+			w.WriteHeader(forecastStatus)
+			_, err = w.Write([]byte(forecastBody))
+		default:
+			t.Errorf("unexpected request path: %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+		if err != nil {
+			t.Fatalf("failed to write response: %v", err)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func fakeNewOpenMeteoClient(server *httptest.Server) *clients.OpenMeteoClient {
+	return clients.NewOpenMeteoClient().
+		WithBaseURL(server.URL).
+		WithGeocodingBaseURL(server.URL).
+		WithClient(server.Client())
+}
+
+func testOpenMeteoCurrentWeatherSuccess(t *testing.T) {
+	t.Parallel()
+
+	server := newOpenMeteoServer(t, openMeteoKyivGeocodingResponse, http.StatusOK, `{
+		"current": {
+			"time": "2025-05-17T12:00",
+			"temperature_2m": 21.3,
+			"relative_humidity_2m": 48,
+			"apparent_temperature": 20.6,
+			"weather_code": 2,
+			"wind_speed_10m": 12.6,
+			"wind_direction_10m": 270,
+			"wind_gusts_10m": 25.2,
+			"precipitation": 0,
+			"precipitation_probability": 5,
+			"uv_index": 5.5,
+			"pressure_msl": 1012.5,
+			"visibility": 30000,
+			"cloud_cover": 40
+		}
+	}`)
+
+	weather, err := fakeNewOpenMeteoClient(server).GetAPICurrentWeather(context.Background(), domain.CityQuery("Kyiv"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, &domain.WeatherResponse{
+		Temperature:              21.3,
+		Humidity:                 48,
+		Description:              "Partly cloudy",
+		FeelsLike:                20.6,
+		WindSpeed:                12.6,
+		WindDirection:            270,
+		WindGust:                 25.2,
+		PrecipitationProbability: 5,
+		UVIndex:                  5.5,
+		Pressure:                 1012.5,
+		Visibility:               30,
+		CloudCover:               40,
+	}, weather)
+}
+
+func testOpenMeteoDayWeatherSuccess(t *testing.T) {
+	t.Parallel()
+
+	server := newOpenMeteoServer(t, openMeteoKyivGeocodingResponse, http.StatusOK, `{
+		"timezone": "Europe/Kyiv",
+		"hourly": {
+			"time": ["2025-05-17T00:00", "2025-05-17T06:00", "2025-05-17T07:00"],
+			"temperature_2m": [9.4, 10.1, 11.5],
+			"relative_humidity_2m": [92, 90, 85],
+			"apparent_temperature": [8.1, 9.2, 10.9],
+			"weather_code": [0, 45, 61],
+			"wind_speed_10m": [5.4, 7.2, 9],
+			"wind_direction_10m": [180, 200, 225],
+			"wind_gusts_10m": [11.2, 14.4, 18],
+			"precipitation": [0, 0, 0.6],
+			"precipitation_probability": [0, 10, 70],
+			"uv_index": [0, 0.1, 0.8],
+			"pressure_msl": [1015.2, 1014.8, 1014.1],
+			"visibility": [24140, 800, 12000],
+			"cloud_cover": [5, 100, 90]
+		}
+	}`)
+
+	weather, err := fakeNewOpenMeteoClient(server).GetAPIDayWeather(context.Background(), domain.CityQuery("Kyiv"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, &domain.DayWeatherResponse{
+		Hours: []domain.HourlyWeather{
+			{Time: "00:00", WeatherResponse: domain.WeatherResponse{
+				Temperature: 9.4, Humidity: 92, Description: "Clear sky", FeelsLike: 8.1,
+				WindSpeed: 5.4, WindDirection: 180, WindGust: 11.2, Precipitation: 0, PrecipitationProbability: 0,
+				UVIndex: 0, Pressure: 1015.2, Visibility: 24.14, CloudCover: 5,
+			}},
+			{Time: "06:00", WeatherResponse: domain.WeatherResponse{
+				Temperature: 10.1, Humidity: 90, Description: "Fog", FeelsLike: 9.2,
+				WindSpeed: 7.2, WindDirection: 200, WindGust: 14.4, Precipitation: 0, PrecipitationProbability: 10,
+				UVIndex: 0.1, Pressure: 1014.8, Visibility: 0.8, CloudCover: 100,
+			}},
+			{Time: "07:00", WeatherResponse: domain.WeatherResponse{
+				Temperature: 11.5, Humidity: 85, Description: "Slight rain", FeelsLike: 10.9,
+				WindSpeed: 9, WindDirection: 225, WindGust: 18, Precipitation: 0.6, PrecipitationProbability: 70,
+				UVIndex: 0.8, Pressure: 1014.1, Visibility: 12, CloudCover: 90,
+			}},
+		},
+		Timezone: "Europe/Kyiv",
+	}, weather)
+}
+
+func testOpenMeteoCoordinates(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/forecast" {
+			t.Errorf("unexpected request path: %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		assert.Equal(t, "50.450000", r.URL.Query().Get("latitude"))
+		assert.Equal(t, "30.520000", r.URL.Query().Get("longitude"))
+		w.WriteHeader(http.StatusOK)
+		_, err := w.Write([]byte(`{"current": {"temperature_2m": 21.3, "relative_humidity_2m": 48, "weather_code": 0}}`))
+		if err != nil {
+			t.Fatalf("failed to write response: %v", err)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	weather, err := fakeNewOpenMeteoClient(server).GetAPICurrentWeather(
+		context.Background(), domain.CoordinatesQuery(domain.Coordinates{Latitude: 50.45, Longitude: 30.52}),
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, &domain.WeatherResponse{Temperature: 21.3, Humidity: 48, Description: "Clear sky"}, weather)
+}
+
+func testOpenMeteoCityNotFound(t *testing.T) {
+	t.Parallel()
+
+	server := newOpenMeteoServer(t, `{"generationtime_ms": 0.5}`, http.StatusOK, `{}`)
+	client := fakeNewOpenMeteoClient(server)
+
+	_, err := client.GetAPICurrentWeather(context.Background(), domain.CityQuery("Nowhere"))
+	assert.ErrorIs(t, err, customErrors.ErrCityNotFound)
+
+	_, err = client.GetAPIDayWeather(context.Background(), domain.CityQuery("Nowhere"))
+	assert.ErrorIs(t, err, customErrors.ErrCityNotFound)
+}
+
+func testOpenMeteoForecastError(t *testing.T) {
+	t.Parallel()
+
+	server := newOpenMeteoServer(
+		t,
+		openMeteoKyivGeocodingResponse,
+		http.StatusBadRequest,
+		`{"error": true, "reason": "Cannot initialize WeatherVariable from invalid String value"}`,
+	)
+
+	_, err := fakeNewOpenMeteoClient(server).GetAPICurrentWeather(context.Background(), domain.CityQuery("Kyiv"))
+
+	assert.ErrorIs(t, err, customErrors.ErrWeatherDataError)
+}
+
+func testOpenMeteoPassesToNextClient(t *testing.T) {
+	t.Parallel()
+
+	openMeteoServer := newOpenMeteoServer(t, openMeteoKyivGeocodingResponse, http.StatusOK, `{"current": `)
+
+	weatherAPIServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, err := w.Write([]byte(`{"current": {"temp_c": 18, "humidity": 40, "condition": {"text": "Sunny"}}}`))
+		if err != nil {
+			t.Fatalf("failed to write response: %v", err)
+		}
+	}))
+	defer weatherAPIServer.Close()
+
+	chainClient, err := clients.NewChainWeatherClient([]clients.ChainWeatherProvider{
+		fakeNewOpenMeteoClient(openMeteoServer),
+		clients.NewWeatherAPIClient("dummy-key").
+			WithBaseURL(weatherAPIServer.URL).
+			WithClient(weatherAPIServer.Client()),
+	})
+	if err != nil {
+		t.Fatalf("failed to create chain weather client: %v", err)
+	}
+
+	weather, err := chainClient.GetAPICurrentWeather(context.Background(), domain.CityQuery("Kyiv"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, &domain.WeatherResponse{Temperature: 18, Humidity: 40, Description: "Sunny"}, weather)
+}
@@ -0,0 +1,164 @@
+package clients
+
+import (
+	"common/logger"
+	"context"
+	"fmt"
+	"ms-weather-subscription/internal/domain"
+	"ms-weather-subscription/pkg/cache"
+	customErrors "ms-weather-subscription/pkg/errors"
+	"ms-weather-subscription/pkg/metrics"
+	"time"
+)
+
+const (
+	quotaWindowDay  = "day"
+	quotaWindowRate = "rate"
+)
+
+// QuotaLimits are the call limits of a weather provider. Zero value disables a limit.
+type QuotaLimits struct {
+	DailyLimit int
+	RateLimit  int
+	RatePeriod time.Duration
+}
+
+// QuotaProvider wraps a chain weather provider with a daily quota and a rate limit.
+// Calls are counted in a counter shared between replicas, and once a limit is reached
+// the provider is not called and ErrQuotaExhausted is returned, so the chain moves on.
+type QuotaProvider struct {
+	provider ChainWeatherProvider
+	quota    providerQuota
+}
+
+func NewQuotaProvider(provider ChainWeatherProvider, counter cache.Counter, limits QuotaLimits) *QuotaProvider {
+	return &QuotaProvider{
+		provider: provider,
+		quota:    providerQuota{provider: provider.Name(), counter: counter, limits: limits},
+	}
+}
+
+func (p *QuotaProvider) Name() string {
+	return p.provider.Name()
+}
+
+func (p *QuotaProvider) GetAPICurrentWeather(
+	ctx context.Context, query domain.WeatherQuery,
+) (*domain.WeatherResponse, error) {
+	if err := p.quota.reserve(ctx); err != nil {
+		return nil, err
+	}
+	return p.provider.GetAPICurrentWeather(ctx, query)
+}
+
+func (p *QuotaProvider) GetAPIDayWeather(
+	ctx context.Context, query domain.WeatherQuery,
+) (*domain.DayWeatherResponse, error) {
+	if err := p.quota.reserve(ctx); err != nil {
+		return nil, err
+	}
+	return p.provider.GetAPIDayWeather(ctx, query)
+}
+
+func (p *QuotaProvider) GetAPIForecast(
+	ctx context.Context, query domain.WeatherQuery, days int,
+) (*domain.ForecastResponse, error) {
+	if err := p.quota.reserve(ctx); err != nil {
+		return nil, err
+	}
+	return p.provider.GetAPIForecast(ctx, query, days)
+}
+
+func (p *QuotaProvider) GetAPIAirQuality(
+	ctx context.Context, query domain.WeatherQuery,
+) (*domain.AirQualityResponse, error) {
+	if err := p.quota.reserve(ctx); err != nil {
+		return nil, err
+	}
+	return p.provider.GetAPIAirQuality(ctx, query)
+}
+
+// QuotaAlertsProvider wraps a chain alerts provider with the quota of the provider.
+// It shares the counters with QuotaProvider, so alert requests use up the same quota as weather requests.
+type QuotaAlertsProvider struct {
+	provider ChainAlertsProvider
+	quota    providerQuota
+}
+
+func NewQuotaAlertsProvider(
+	provider ChainAlertsProvider, counter cache.Counter, limits QuotaLimits,
+) *QuotaAlertsProvider {
+	return &QuotaAlertsProvider{
+		provider: provider,
+		quota:    providerQuota{provider: provider.Name(), counter: counter, limits: limits},
+	}
+}
+
+func (p *QuotaAlertsProvider) Name() string {
+	return p.provider.Name()
+}
+
+func (p *QuotaAlertsProvider) GetAPIAlerts(
+	ctx context.Context, query domain.WeatherQuery,
+) ([]domain.WeatherAlert, error) {
+	if err := p.quota.reserve(ctx); err != nil {
+		return nil, err
+	}
+	return p.provider.GetAPIAlerts(ctx, query)
+}
+
+// providerQuota counts the calls to a provider in a counter shared between replicas.
+type providerQuota struct {
+	provider string
+	counter  cache.Counter
+	limits   QuotaLimits
+}
+
+// reserve counts a call to the provider and returns ErrQuotaExhausted if any limit is reached.
+// Both limits are checked before either is counted, so a rejected call doesn't use up the other one.
+// If the counter is not available, the call is allowed.
+func (q providerQuota) reserve(ctx context.Context) error {
+	now := time.Now().UTC()
+
+	var names []string
+	var windows []cache.CounterWindow
+	if q.limits.RateLimit > 0 && q.limits.RatePeriod > 0 {
+		windowStart := now.Truncate(q.limits.RatePeriod)
+		names = append(names, quotaWindowRate)
+		windows = append(windows, cache.CounterWindow{
+			Key:   fmt.Sprintf("quota:%s:%s:%d", q.provider, quotaWindowRate, windowStart.Unix()),
+			Limit: int64(q.limits.RateLimit),
+			TTL:   windowStart.Add(q.limits.RatePeriod).Sub(now),
+		})
+	}
+	if q.limits.DailyLimit > 0 {
+		names = append(names, quotaWindowDay)
+		windows = append(windows, cache.CounterWindow{
+			Key:   fmt.Sprintf("quota:%s:%s:%s", q.provider, quotaWindowDay, now.Format("2006-01-02")),
+			Limit: int64(q.limits.DailyLimit),
+			TTL:   untilEndOfDay(now),
+		})
+	}
+	if len(windows) == 0 {
+		return nil
+	}
+
+	used, ok, err := q.counter.IncrWithinLimits(ctx, windows)
+	if err != nil {
+		logger.Errorf("failed to count call to weather provider %s: %v", q.provider, err)
+		return nil
+	}
+
+	for i, window := range windows {
+		remaining := max(window.Limit-used[i], 0)
+		metrics.WeatherProviderQuotaRemaining.WithLabelValues(q.provider, names[i]).Set(float64(remaining))
+		if !ok && remaining == 0 {
+			logger.Warnf("weather provider %s has used up its %s quota of %d calls", q.provider, names[i], window.Limit)
+		}
+	}
+
+	if !ok {
+		return customErrors.ErrQuotaExhausted
+	}
+	return nil
+}
@@ -0,0 +1,216 @@
+package clients_test
+
+import (
+	"context"
+	"errors"
+	"ms-weather-subscription/internal/domain"
+	"ms-weather-subscription/pkg/cache"
+	"ms-weather-subscription/pkg/clients"
+	customErrors "ms-weather-subscription/pkg/errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuotaProvider(t *testing.T) {
+	t.Run("Daily limit", testQuotaProviderDailyLimit)
+	t.Run("Rate limit", testQuotaProviderRateLimit)
+	t.Run("Rejected call doesn't count", testQuotaProviderRejectedCallNotCounted)
+	t.Run("Alerts use the same quota", testQuotaAlertsProviderSharesQuota)
+	t.Run("Exhausted provider is skipped by chain", testQuotaProviderSkippedByChain)
+	t.Run("Counter error doesn't block calls", testQuotaProviderCounterError)
+}
+
+// memoryCounter is a cache.Counter backed by a map. If err is set, it is returned instead.
+type memoryCounter struct {
+	mu     sync.Mutex
+	values map[string]int64
+	err    error
+}
+
+func newMemoryCounter() *memoryCounter {
+	return &memoryCounter{values: make(map[string]int64)}
+}
+
+func (c *memoryCounter) IncrWithinLimits(_ context.Context, windows []cache.CounterWindow) ([]int64, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return nil, false, c.err
+	}
+
+	values := make([]int64, 0, len(windows))
+	allowed := true
+	for _, window := range windows {
+		values = append(values, c.values[window.Key])
+		allowed = allowed && c.values[window.Key] < window.Limit
+	}
+	if !allowed {
+		return values, false, nil
+	}
+
+	for i, window := range windows {
+		c.values[window.Key]++
+		values[i] = c.values[window.Key]
+	}
+	return values, true, nil
+}
+
+type namedWeatherClient struct {
+	*countingWeatherClient
+	name string
+}
+
+func (c namedWeatherClient) Name() string {
+	return c.name
+}
+
+func newNamedWeatherClient(name string) namedWeatherClient {
+	return namedWeatherClient{
+		countingWeatherClient: &countingWeatherClient{dayWeather: &domain.DayWeatherResponse{
+			Hours: []domain.HourlyWeather{
+				{Time: "07:00", WeatherResponse: domain.WeatherResponse{Temperature: 12, Humidity: 80, Description: name}},
+			},
+		}},
+		name: name,
+	}
+}
+
+func testQuotaProviderDailyLimit(t *testing.T) {
+	t.Parallel()
+
+	inner := newNamedWeatherClient("test-daily-limit")
+	provider := clients.NewQuotaProvider(inner, newMemoryCounter(), clients.QuotaLimits{DailyLimit: 2})
+
+	for range 2 {
+		_, err := provider.GetAPIDayWeather(context.Background(), domain.CityQuery("Kyiv"))
+		assert.NoError(t, err)
+	}
+	_, err := provider.GetAPIDayWeather(context.Background(), domain.CityQuery("Kyiv"))
+
+	assert.ErrorIs(t, err, customErrors.ErrQuotaExhausted)
+	assert.Equal(t, 2, inner.dayCalls)
+}
+
+func testQuotaProviderRateLimit(t *testing.T) {
+	t.Parallel()
+
+	inner := newNamedWeatherClient("test-rate-limit")
+	provider := clients.NewQuotaProvider(inner, newMemoryCounter(), clients.QuotaLimits{
+		DailyLimit: 100,
+		RateLimit:  1,
+		RatePeriod: time.Hour,
+	})
+
+	_, err := provider.GetAPIDayWeather(context.Background(), domain.CityQuery("Kyiv"))
+	assert.NoError(t, err)
+	_, err = provider.GetAPIDayWeather(context.Background(), domain.CityQuery("Kyiv"))
+
+	assert.ErrorIs(t, err, customErrors.ErrQuotaExhausted)
+	assert.Equal(t, 1, inner.dayCalls)
+}
+
+func testQuotaProviderRejectedCallNotCounted(t *testing.T) {
+	t.Parallel()
+
+	// Setup
+	counter := newMemoryCounter()
+	inner := newNamedWeatherClient("test-rejected-call")
+	provider := clients.NewQuotaProvider(inner, counter, clients.QuotaLimits{
+		DailyLimit: 1,
+		RateLimit:  5,
+		RatePeriod: time.Hour,
+	})
+
+	// Execute
+	_, err := provider.GetAPIDayWeather(context.Background(), domain.CityQuery("Kyiv"))
+	assert.NoError(t, err)
+	for range 3 {
+		_, err = provider.GetAPIDayWeather(context.Background(), domain.CityQuery("Kyiv"))
+		assert.ErrorIs(t, err, customErrors.ErrQuotaExhausted)
+	}
+
+	// Verify: the calls rejected by the daily limit aren't counted against the rate limit
+	assert.Equal(t, 1, inner.dayCalls)
+	assert.Len(t, counter.values, 2)
+	for key, value := range counter.values {
+		assert.Equal(t, int64(1), value, key)
+	}
+}
+
+// namedAlertsProvider counts the alert requests to it.
+type namedAlertsProvider struct {
+	name  string
+	calls int
+}
+
+func (p *namedAlertsProvider) Name() string {
+	return p.name
+}
+
+func (p *namedAlertsProvider) GetAPIAlerts(context.Context, domain.WeatherQuery) ([]domain.WeatherAlert, error) {
+	p.calls++
+	return nil, nil
+}
+
+func testQuotaAlertsProviderSharesQuota(t *testing.T) {
+	t.Parallel()
+
+	// Setup
+	counter := newMemoryCounter()
+	limits := clients.QuotaLimits{DailyLimit: 2}
+	weather := newNamedWeatherClient("test-shared-quota")
+	alerts := &namedAlertsProvider{name: "test-shared-quota"}
+	weatherProvider := clients.NewQuotaProvider(weather, counter, limits)
+	alertsProvider := clients.NewQuotaAlertsProvider(alerts, counter, limits)
+
+	// Execute
+	_, err := weatherProvider.GetAPIDayWeather(context.Background(), domain.CityQuery("Kyiv"))
+	assert.NoError(t, err)
+	_, err = alertsProvider.GetAPIAlerts(context.Background(), domain.CityQuery("Kyiv"))
+	assert.NoError(t, err)
+
+	// Verify: the alerts request used up the rest of the daily quota
+	_, err = weatherProvider.GetAPIDayWeather(context.Background(), domain.CityQuery("Kyiv"))
+	assert.ErrorIs(t, err, customErrors.ErrQuotaExhausted)
+	_, err = alertsProvider.GetAPIAlerts(context.Background(), domain.CityQuery("Kyiv"))
+	assert.ErrorIs(t, err, customErrors.ErrQuotaExhausted)
+	assert.Equal(t, 1, weather.dayCalls)
+	assert.Equal(t, 1, alerts.calls)
+}
+
+func testQuotaProviderSkippedByChain(t *testing.T) {
+	t.Parallel()
+
+	primary := newNamedWeatherClient("test-skipped-primary")
+	fallback := newNamedWeatherClient("test-skipped-fallback")
+	chainClient := newChainClient(t,
+		clients.NewQuotaProvider(primary, newMemoryCounter(), clients.QuotaLimits{DailyLimit: 1}),
+		fallback,
+	)
+
+	for range 3 {
+		_, err := chainClient.GetAPIDayWeather(context.Background(), domain.CityQuery("Kyiv"))
+		assert.NoError(t, err)
+	}
+
+	assert.Equal(t, 1, primary.dayCalls)
+	assert.Equal(t, 2, fallback.dayCalls)
+}
+
+func testQuotaProviderCounterError(t *testing.T) {
+	t.Parallel()
+
+	counter := newMemoryCounter()
+	counter.err = errors.New("redis is down")
+	inner := newNamedWeatherClient("test-counter-error")
+	provider := clients.NewQuotaProvider(inner, counter, clients.QuotaLimits{DailyLimit: 1})
+
+	for range 3 {
+		_, err := provider.GetAPIDayWeather(context.Background(), domain.CityQuery("Kyiv"))
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, 3, inner.dayCalls)
+}
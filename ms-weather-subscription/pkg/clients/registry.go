@@ -0,0 +1,270 @@
+package clients
+
+import (
+	"errors"
+	"fmt"
+	"ms-weather-subscription/internal/config"
+	"ms-weather-subscription/pkg/cache"
+)
+
+const (
+	WeatherAPIProviderName     = "weatherapi"
+	VisualCrossingProviderName = "visualcrossing"
+	OpenMeteoProviderName      = "openmeteo"
+	FakeProviderName           = config.FakeWeatherProviderName
+)
+
+// ProviderFactory creates a chain weather provider from its config entry.
+type ProviderFactory func(cfg config.WeatherProviderConfig) (ChainWeatherProvider, error)
+
+// ProviderRegistry maps provider names used in the config file to their factories.
+type ProviderRegistry struct {
+	factories     map[string]ProviderFactory
+	quotaCounter  cache.Counter
+	snapshotStore SnapshotStore
+	// breakers are shared by the weather and the alerts requests of each provider.
+	breakers map[string]*CircuitBreaker
+}
+
+func NewProviderRegistry() *ProviderRegistry {
+	return &ProviderRegistry{
+		factories: make(map[string]ProviderFactory),
+		breakers:  make(map[string]*CircuitBreaker),
+	}
+}
+
+// NewDefaultProviderRegistry returns a registry with all built-in weather providers registered.
+func NewDefaultProviderRegistry(thirdPartyCfg config.ThirdPartyConfig) *ProviderRegistry {
+	registry := NewProviderRegistry()
+
+	registry.Register(WeatherAPIProviderName, func(cfg config.WeatherProviderConfig) (ChainWeatherProvider, error) {
+		if thirdPartyCfg.WeatherAPIKey == "" {
+			return nil, errors.New("WEATHER_API_KEY is not set")
+		}
+		client := NewWeatherAPIClient(thirdPartyCfg.WeatherAPIKey)
+		if cfg.BaseURL != "" {
+			client.WithBaseURL(cfg.BaseURL)
+		}
+		if cfg.Timeout > 0 {
+			client.WithTimeout(cfg.Timeout)
+		}
+		return client, nil
+	})
+
+	registry.Register(VisualCrossingProviderName, func(cfg config.WeatherProviderConfig) (ChainWeatherProvider, error) {
+		if thirdPartyCfg.VisualCrossingAPIKey == "" {
+			return nil, errors.New("VISUAL_CROSSING_API_KEY is not set")
+		}
+		client := NewVisualCrossingClient(thirdPartyCfg.VisualCrossingAPIKey)
+		if cfg.BaseURL != "" {
+			client.WithBaseURL(cfg.BaseURL)
+		}
+		if cfg.Timeout > 0 {
+			client.WithTimeout(cfg.Timeout)
+		}
+		return client, nil
+	})
+
+	registry.Register(OpenMeteoProviderName, func(cfg config.WeatherProviderConfig) (ChainWeatherProvider, error) {
+		client := NewOpenMeteoClient()
+		if cfg.BaseURL != "" {
+			client.WithBaseURL(cfg.BaseURL)
+		}
+		if cfg.GeocodingBaseURL != "" {
+			client.WithGeocodingBaseURL(cfg.GeocodingBaseURL)
+		}
+		if cfg.AirQualityBaseURL != "" {
+			client.WithAirQualityBaseURL(cfg.AirQualityBaseURL)
+		}
+		if cfg.Timeout > 0 {
+			client.WithTimeout(cfg.Timeout)
+		}
+		return client, nil
+	})
+
+	registry.Register(FakeProviderName, func(cfg config.WeatherProviderConfig) (ChainWeatherProvider, error) {
+		if cfg.Fixture == "" {
+			return nil, errors.New("fixture is not set")
+		}
+		fixture, err := LoadFakeWeatherFixture(cfg.Fixture)
+		if err != nil {
+			return nil, err
+		}
+		return NewFakeWeatherClient(fixture)
+	})
+
+	return registry
+}
+
+func (r *ProviderRegistry) Register(name string, factory ProviderFactory) {
+	r.factories[name] = factory
+}
+
+// WithQuotaCounter sets the counter used to track provider quotas. It is required
+// if any provider has a quota configured.
+func (r *ProviderRegistry) WithQuotaCounter(counter cache.Counter) *ProviderRegistry {
+	r.quotaCounter = counter
+	return r
+}
+
+// WithSnapshotStore makes the providers store the current weather they return.
+func (r *ProviderRegistry) WithSnapshotStore(store SnapshotStore) *ProviderRegistry {
+	r.snapshotStore = store
+	return r
+}
+
+// Build creates enabled providers in the order they are listed in providersCfg.
+// If a snapshot store is set, providers are wrapped with SnapshotProvider. Providers with
+// a configured quota are wrapped with QuotaProvider, and providers with a configured failure
+// threshold are wrapped with a circuit breaker.
+func (r *ProviderRegistry) Build(providersCfg []config.WeatherProviderConfig) ([]ChainWeatherProvider, error) {
+	providers := make([]ChainWeatherProvider, 0, len(providersCfg))
+	seen := make(map[string]struct{}, len(providersCfg))
+
+	for _, providerCfg := range providersCfg {
+		factory, ok := r.factories[providerCfg.Name]
+		if !ok {
+			return nil, fmt.Errorf("unknown weather provider %q", providerCfg.Name)
+		}
+
+		if _, duplicate := seen[providerCfg.Name]; duplicate {
+			return nil, fmt.Errorf("weather provider %q is configured more than once", providerCfg.Name)
+		}
+		seen[providerCfg.Name] = struct{}{}
+
+		if !providerCfg.Enabled {
+			continue
+		}
+
+		provider, err := factory(providerCfg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create weather provider %q: %w", providerCfg.Name, err)
+		}
+
+		if r.snapshotStore != nil {
+			provider = NewSnapshotProvider(provider, r.snapshotStore)
+		}
+
+		limits, hasQuota, err := r.quotaLimits(providerCfg)
+		if err != nil {
+			return nil, err
+		}
+		if hasQuota {
+			provider = NewQuotaProvider(provider, r.quotaCounter, limits)
+		}
+
+		if breaker := r.circuitBreaker(providerCfg); breaker != nil {
+			provider = NewCircuitBreakerProvider(provider, breaker)
+		}
+		providers = append(providers, provider)
+	}
+
+	return providers, nil
+}
+
+// BuildLocationSearchers creates enabled providers which have a search API, in the order
+// they are listed in providersCfg. Locations are resolved once and then stored, so unlike
+// weather requests, searches aren't counted against provider quotas.
+func (r *ProviderRegistry) BuildLocationSearchers(
+	providersCfg []config.WeatherProviderConfig,
+) ([]ChainLocationProvider, error) {
+	return buildProvidersOf[ChainLocationProvider](r, providersCfg, nil)
+}
+
+// BuildAlertsProviders creates enabled providers which publish weather alerts, in the order
+// they are listed in providersCfg. Alerts are polled for every subscribed location, so unlike searches
+// they are counted against the quotas of the providers and share their circuit breakers with weather requests.
+func (r *ProviderRegistry) BuildAlertsProviders(
+	providersCfg []config.WeatherProviderConfig,
+) ([]ChainAlertsProvider, error) {
+	return buildProvidersOf(r, providersCfg,
+		func(provider ChainAlertsProvider, providerCfg config.WeatherProviderConfig) (ChainAlertsProvider, error) {
+			limits, hasQuota, err := r.quotaLimits(providerCfg)
+			if err != nil {
+				return nil, err
+			}
+			if hasQuota {
+				provider = NewQuotaAlertsProvider(provider, r.quotaCounter, limits)
+			}
+
+			if breaker := r.circuitBreaker(providerCfg); breaker != nil {
+				provider = NewCircuitBreakerAlertsProvider(provider, breaker)
+			}
+			return provider, nil
+		},
+	)
+}
+
+// quotaLimits returns the quota limits of the provider, hasQuota is false if it has none configured.
+func (r *ProviderRegistry) quotaLimits(
+	providerCfg config.WeatherProviderConfig,
+) (limits QuotaLimits, hasQuota bool, err error) {
+	quota := providerCfg.Quota
+	if quota.DailyLimit <= 0 && quota.RateLimit <= 0 {
+		return QuotaLimits{}, false, nil
+	}
+	if r.quotaCounter == nil {
+		return QuotaLimits{}, false, errors.New("weather provider quota is configured, but there is no quota counter")
+	}
+	return QuotaLimits{
+		DailyLimit: quota.DailyLimit,
+		RateLimit:  quota.RateLimit,
+		RatePeriod: quota.RatePeriod,
+	}, true, nil
+}
+
+// circuitBreaker returns the circuit breaker of the provider, the same one each time it is asked for.
+// It returns nil if the provider has no failure threshold configured.
+func (r *ProviderRegistry) circuitBreaker(providerCfg config.WeatherProviderConfig) *CircuitBreaker {
+	if providerCfg.CircuitBreaker.FailureThreshold <= 0 {
+		return nil
+	}
+	breaker, ok := r.breakers[providerCfg.Name]
+	if !ok {
+		breaker = NewCircuitBreaker(
+			providerCfg.Name,
+			providerCfg.CircuitBreaker.FailureThreshold,
+			providerCfg.CircuitBreaker.CoolDown,
+		)
+		r.breakers[providerCfg.Name] = breaker
+	}
+	return breaker
+}
+
+// buildProvidersOf creates enabled providers which implement P. Each of them is wrapped with wrap, unless it is nil.
+func buildProvidersOf[P any](
+	r *ProviderRegistry,
+	providersCfg []config.WeatherProviderConfig,
+	wrap func(provider P, providerCfg config.WeatherProviderConfig) (P, error),
+) ([]P, error) {
+	providers := make([]P, 0, len(providersCfg))
+
+	for _, providerCfg := range providersCfg {
+		if !providerCfg.Enabled {
+			continue
+		}
+
+		factory, ok := r.factories[providerCfg.Name]
+		if !ok {
+			return nil, fmt.Errorf("unknown weather provider %q", providerCfg.Name)
+		}
+
+		provider, err := factory(providerCfg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create weather provider %q: %w", providerCfg.Name, err)
+		}
+
+		capable, ok := provider.(P)
+		if !ok {
+			continue
+		}
+		if wrap != nil {
+			if capable, err = wrap(capable, providerCfg); err != nil {
+				return nil, err
+			}
+		}
+		providers = append(providers, capable)
+	}
+
+	return providers, nil
+}
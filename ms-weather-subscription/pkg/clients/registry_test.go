@@ -0,0 +1,269 @@
+package clients_test
+
+import (
+	"context"
+	"ms-weather-subscription/internal/config"
+	"ms-weather-subscription/internal/domain"
+	"ms-weather-subscription/pkg/clients"
+	customErrors "ms-weather-subscription/pkg/errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProviderRegistry(t *testing.T) {
+	t.Run("Builds providers in configured order", testProviderRegistryBuildOrder)
+	t.Run("Skips disabled providers", testProviderRegistrySkipsDisabled)
+	t.Run("Wraps providers with circuit breaker", testProviderRegistryWrapsCircuitBreaker)
+	t.Run("Wraps providers with quota", testProviderRegistryWrapsQuota)
+	t.Run("Quota without counter", testProviderRegistryQuotaWithoutCounter)
+	t.Run("Wraps providers with snapshot store", testProviderRegistryWrapsSnapshotStore)
+	t.Run("Unknown provider", testProviderRegistryUnknownProvider)
+	t.Run("Duplicate provider", testProviderRegistryDuplicateProvider)
+	t.Run("Builds location searchers", testProviderRegistryBuildLocationSearchers)
+	t.Run("Builds alerts providers", testProviderRegistryBuildAlertsProviders)
+	t.Run("Wraps alerts providers with quota and circuit breaker", testProviderRegistryWrapsAlertsProviders)
+	t.Run("Missing API key", testProviderRegistryMissingAPIKey)
+	t.Run("Builds fake provider", testProviderRegistryBuildsFakeProvider)
+	t.Run("Fake provider without fixture", testProviderRegistryFakeProviderWithoutFixture)
+}
+
+func newTestRegistry() *clients.ProviderRegistry {
+	return clients.NewDefaultProviderRegistry(config.ThirdPartyConfig{
+		WeatherAPIKey:        "weather-api-key",
+		VisualCrossingAPIKey: "visual-crossing-key",
+	})
+}
+
+func testProviderRegistryBuildOrder(t *testing.T) {
+	t.Parallel()
+
+	providers, err := newTestRegistry().Build([]config.WeatherProviderConfig{
+		{Name: clients.OpenMeteoProviderName, Enabled: true, Timeout: time.Second},
+		{Name: clients.VisualCrossingProviderName, Enabled: true, BaseURL: "http://localhost"},
+		{Name: clients.WeatherAPIProviderName, Enabled: true},
+	})
+
+	assert.NoError(t, err)
+	assert.Len(t, providers, 3)
+	assert.IsType(t, &clients.OpenMeteoClient{}, providers[0])
+	assert.IsType(t, &clients.VisualCrossingClient{}, providers[1])
+	assert.IsType(t, &clients.WeatherAPIClient{}, providers[2])
+}
+
+func testProviderRegistrySkipsDisabled(t *testing.T) {
+	t.Parallel()
+
+	providers, err := newTestRegistry().Build([]config.WeatherProviderConfig{
+		{Name: clients.WeatherAPIProviderName, Enabled: false},
+		{Name: clients.VisualCrossingProviderName, Enabled: true},
+	})
+
+	assert.NoError(t, err)
+	assert.Len(t, providers, 1)
+	assert.IsType(t, &clients.VisualCrossingClient{}, providers[0])
+}
+
+func testProviderRegistryWrapsCircuitBreaker(t *testing.T) {
+	t.Parallel()
+
+	providers, err := newTestRegistry().Build([]config.WeatherProviderConfig{
+		{
+			Name:           clients.WeatherAPIProviderName,
+			Enabled:        true,
+			CircuitBreaker: config.CircuitBreakerConfig{FailureThreshold: 3, CoolDown: time.Minute},
+		},
+		{Name: clients.OpenMeteoProviderName, Enabled: true},
+	})
+
+	assert.NoError(t, err)
+	assert.Len(t, providers, 2)
+	assert.IsType(t, &clients.CircuitBreakerProvider{}, providers[0])
+	assert.IsType(t, &clients.OpenMeteoClient{}, providers[1])
+}
+
+func testProviderRegistryWrapsQuota(t *testing.T) {
+	t.Parallel()
+
+	providers, err := newTestRegistry().WithQuotaCounter(newMemoryCounter()).Build([]config.WeatherProviderConfig{
+		{Name: clients.WeatherAPIProviderName, Enabled: true, Quota: config.QuotaConfig{DailyLimit: 100}},
+		{Name: clients.OpenMeteoProviderName, Enabled: true},
+	})
+
+	assert.NoError(t, err)
+	assert.Len(t, providers, 2)
+	assert.IsType(t, &clients.QuotaProvider{}, providers[0])
+	assert.Equal(t, clients.WeatherAPIProviderName, providers[0].Name())
+	assert.IsType(t, &clients.OpenMeteoClient{}, providers[1])
+}
+
+func testProviderRegistryWrapsSnapshotStore(t *testing.T) {
+	t.Parallel()
+
+	providers, err := newTestRegistry().WithSnapshotStore(&memorySnapshotStore{}).Build([]config.WeatherProviderConfig{
+		{Name: clients.WeatherAPIProviderName, Enabled: true},
+	})
+
+	assert.NoError(t, err)
+	assert.Len(t, providers, 1)
+	assert.IsType(t, &clients.SnapshotProvider{}, providers[0])
+	assert.Equal(t, clients.WeatherAPIProviderName, providers[0].Name())
+}
+
+func testProviderRegistryQuotaWithoutCounter(t *testing.T) {
+	t.Parallel()
+
+	_, err := newTestRegistry().Build([]config.WeatherProviderConfig{
+		{Name: clients.WeatherAPIProviderName, Enabled: true, Quota: config.QuotaConfig{DailyLimit: 100}},
+	})
+
+	assert.ErrorContains(t, err, "no quota counter")
+}
+
+func testProviderRegistryUnknownProvider(t *testing.T) {
+	t.Parallel()
+
+	_, err := newTestRegistry().Build([]config.WeatherProviderConfig{
+		{Name: "unknown", Enabled: true},
+	})
+
+	assert.ErrorContains(t, err, `unknown weather provider "unknown"`)
+}
+
+func testProviderRegistryDuplicateProvider(t *testing.T) {
+	t.Parallel()
+
+	_, err := newTestRegistry().Build([]config.WeatherProviderConfig{
+		{Name: clients.WeatherAPIProviderName, Enabled: true},
+		{Name: clients.WeatherAPIProviderName, Enabled: false},
+	})
+
+	assert.ErrorContains(t, err, "configured more than once")
+}
+
+func testProviderRegistryBuildLocationSearchers(t *testing.T) {
+	t.Parallel()
+
+	searchers, err := newTestRegistry().BuildLocationSearchers([]config.WeatherProviderConfig{
+		{Name: clients.OpenMeteoProviderName, Enabled: true},
+		{Name: clients.VisualCrossingProviderName, Enabled: true},
+		{
+			Name:           clients.WeatherAPIProviderName,
+			Enabled:        true,
+			CircuitBreaker: config.CircuitBreakerConfig{FailureThreshold: 3, CoolDown: time.Minute},
+		},
+	})
+
+	assert.NoError(t, err)
+	assert.Len(t, searchers, 2, "VisualCrossing has no search API")
+	assert.IsType(t, &clients.OpenMeteoClient{}, searchers[0])
+	assert.IsType(t, &clients.WeatherAPIClient{}, searchers[1])
+}
+
+func testProviderRegistryBuildAlertsProviders(t *testing.T) {
+	t.Parallel()
+
+	providers, err := newTestRegistry().BuildAlertsProviders([]config.WeatherProviderConfig{
+		{Name: clients.OpenMeteoProviderName, Enabled: true},
+		{Name: clients.VisualCrossingProviderName, Enabled: true},
+		{Name: clients.WeatherAPIProviderName, Enabled: false},
+	})
+
+	assert.NoError(t, err)
+	assert.Len(t, providers, 1, "OpenMeteo has no alerts, WeatherAPI is disabled")
+	assert.IsType(t, &clients.VisualCrossingClient{}, providers[0])
+}
+
+func testProviderRegistryWrapsAlertsProviders(t *testing.T) {
+	t.Parallel()
+
+	// Setup
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	providersCfg := []config.WeatherProviderConfig{
+		{
+			Name:           clients.WeatherAPIProviderName,
+			Enabled:        true,
+			BaseURL:        server.URL,
+			Quota:          config.QuotaConfig{DailyLimit: 100},
+			CircuitBreaker: config.CircuitBreakerConfig{FailureThreshold: 1, CoolDown: time.Hour},
+		},
+		{Name: clients.VisualCrossingProviderName, Enabled: true, Quota: config.QuotaConfig{DailyLimit: 100}},
+	}
+	registry := newTestRegistry().WithQuotaCounter(newMemoryCounter())
+
+	// Execute
+	weatherProviders, err := registry.Build(providersCfg)
+	assert.NoError(t, err)
+	alertsProviders, err := registry.BuildAlertsProviders(providersCfg)
+
+	// Verify
+	assert.NoError(t, err)
+	if assert.Len(t, alertsProviders, 2) {
+		assert.IsType(t, &clients.CircuitBreakerAlertsProvider{}, alertsProviders[0])
+		assert.Equal(t, clients.WeatherAPIProviderName, alertsProviders[0].Name())
+		assert.IsType(t, &clients.QuotaAlertsProvider{}, alertsProviders[1])
+	}
+
+	// The weather requests open the circuit the alerts requests go through as well
+	_, err = weatherProviders[0].GetAPICurrentWeather(context.Background(), domain.CityQuery("Kyiv"))
+	assert.Error(t, err)
+	_, err = alertsProviders[0].GetAPIAlerts(context.Background(), domain.CityQuery("Kyiv"))
+	assert.ErrorIs(t, err, customErrors.ErrWeatherProviderUnavailable)
+}
+
+func testProviderRegistryMissingAPIKey(t *testing.T) {
+	t.Parallel()
+
+	registry := clients.NewDefaultProviderRegistry(config.ThirdPartyConfig{WeatherAPIKey: "weather-api-key"})
+
+	providers, err := registry.Build([]config.WeatherProviderConfig{
+		{Name: clients.WeatherAPIProviderName, Enabled: true},
+		{Name: clients.VisualCrossingProviderName, Enabled: false},
+	})
+	assert.NoError(t, err, "a key is needed only if the provider is enabled")
+	assert.Len(t, providers, 1)
+
+	_, err = registry.Build([]config.WeatherProviderConfig{
+		{Name: clients.VisualCrossingProviderName, Enabled: true},
+	})
+	assert.ErrorContains(t, err, "VISUAL_CROSSING_API_KEY is not set")
+}
+
+func testProviderRegistryBuildsFakeProvider(t *testing.T) {
+	t.Parallel()
+
+	providersCfg := []config.WeatherProviderConfig{
+		{Name: clients.FakeProviderName, Enabled: true, Fixture: fakeFixturePath},
+	}
+	registry := clients.NewDefaultProviderRegistry(config.ThirdPartyConfig{})
+
+	providers, err := registry.Build(providersCfg)
+	assert.NoError(t, err)
+	assert.Len(t, providers, 1)
+	assert.IsType(t, &clients.FakeWeatherClient{}, providers[0])
+
+	searchers, err := registry.BuildLocationSearchers(providersCfg)
+	assert.NoError(t, err)
+	assert.Len(t, searchers, 1)
+
+	alertsProviders, err := registry.BuildAlertsProviders(providersCfg)
+	assert.NoError(t, err)
+	assert.Len(t, alertsProviders, 1)
+}
+
+func testProviderRegistryFakeProviderWithoutFixture(t *testing.T) {
+	t.Parallel()
+
+	_, err := newTestRegistry().Build([]config.WeatherProviderConfig{
+		{Name: clients.FakeProviderName, Enabled: true},
+	})
+
+	assert.ErrorContains(t, err, "fixture is not set")
+}
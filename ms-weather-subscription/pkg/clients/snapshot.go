@@ -0,0 +1,74 @@
+package clients
+
+import (
+	"common/logger"
+	"context"
+	"ms-weather-subscription/internal/domain"
+	"time"
+)
+
+// SnapshotStore persists the current weather returned by providers.
+type SnapshotStore interface {
+	Create(ctx context.Context, snapshot domain.WeatherSnapshot) error
+}
+
+// SnapshotProvider wraps a chain weather provider and stores every current weather it returns
+// under the location of the query, so that the weather emails and API responses were based on
+// can be looked up later. The weather of queries without a resolved location isn't stored.
+// A failure to store the snapshot is logged and doesn't fail the request.
+type SnapshotProvider struct {
+	provider ChainWeatherProvider
+	store    SnapshotStore
+}
+
+func NewSnapshotProvider(provider ChainWeatherProvider, store SnapshotStore) *SnapshotProvider {
+	return &SnapshotProvider{
+		provider: provider,
+		store:    store,
+	}
+}
+
+func (p *SnapshotProvider) Name() string {
+	return p.provider.Name()
+}
+
+func (p *SnapshotProvider) GetAPICurrentWeather(
+	ctx context.Context, query domain.WeatherQuery,
+) (*domain.WeatherResponse, error) {
+	resp, err := p.provider.GetAPICurrentWeather(ctx, query)
+	if err != nil || query.LocationID == "" {
+		return resp, err
+	}
+
+	snapshot := domain.WeatherSnapshot{
+		LocationID:  query.LocationID,
+		Coordinates: query.Coordinates,
+		Provider:    p.Name(),
+		ObservedAt:  time.Now().UTC(),
+		Weather:     *resp,
+	}
+	// The response is stored even if the caller has given up on it, e.g. a hedged request which lost.
+	if err := p.store.Create(context.WithoutCancel(ctx), snapshot); err != nil {
+		logger.Errorf("failed to store weather snapshot of %s from %s: %v", query, p.Name(), err)
+	}
+
+	return resp, nil
+}
+
+func (p *SnapshotProvider) GetAPIDayWeather(
+	ctx context.Context, query domain.WeatherQuery,
+) (*domain.DayWeatherResponse, error) {
+	return p.provider.GetAPIDayWeather(ctx, query)
+}
+
+func (p *SnapshotProvider) GetAPIForecast(
+	ctx context.Context, query domain.WeatherQuery, days int,
+) (*domain.ForecastResponse, error) {
+	return p.provider.GetAPIForecast(ctx, query, days)
+}
+
+func (p *SnapshotProvider) GetAPIAirQuality(
+	ctx context.Context, query domain.WeatherQuery,
+) (*domain.AirQualityResponse, error) {
+	return p.provider.GetAPIAirQuality(ctx, query)
+}
@@ -0,0 +1,161 @@
+package clients_test
+
+import (
+	"context"
+	"errors"
+	"ms-weather-subscription/internal/domain"
+	"ms-weather-subscription/pkg/clients"
+	customErrors "ms-weather-subscription/pkg/errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnapshotProvider(t *testing.T) {
+	t.Run("Stores current weather", testSnapshotProviderStoresCurrentWeather)
+	t.Run("Keeps coordinates of query", testSnapshotProviderCoordinatesQuery)
+	t.Run("Stores coordinates query through caching client", testSnapshotProviderBehindCachingClient)
+	t.Run("Doesn't store query without location", testSnapshotProviderQueryWithoutLocation)
+	t.Run("Doesn't store failed request", testSnapshotProviderFailedRequest)
+	t.Run("Store failure doesn't fail request", testSnapshotProviderStoreFailure)
+}
+
+// memorySnapshotStore keeps the stored snapshots in memory. If err is set, it is returned instead.
+type memorySnapshotStore struct {
+	mu        sync.Mutex
+	snapshots []domain.WeatherSnapshot
+	err       error
+}
+
+func (s *memorySnapshotStore) Create(_ context.Context, snapshot domain.WeatherSnapshot) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+	s.snapshots = append(s.snapshots, snapshot)
+	return nil
+}
+
+func testSnapshotProviderStoresCurrentWeather(t *testing.T) {
+	t.Parallel()
+
+	weather := &domain.WeatherResponse{Temperature: 21, Humidity: 50, Description: "Sunny"}
+	store := &memorySnapshotStore{}
+	provider := clients.NewSnapshotProvider(namedWeatherClient{
+		countingWeatherClient: &countingWeatherClient{weather: weather},
+		name:                  "test-provider",
+	}, store)
+
+	query := domain.CityQuery(" New  York")
+	query.LocationID = "new york,united states of america@41,-74"
+	resp, err := provider.GetAPICurrentWeather(context.Background(), query)
+
+	assert.NoError(t, err)
+	assert.Equal(t, weather, resp)
+	if assert.Len(t, store.snapshots, 1) {
+		snapshot := store.snapshots[0]
+		assert.Equal(t, query.LocationID, snapshot.LocationID)
+		assert.Nil(t, snapshot.Coordinates)
+		assert.Equal(t, "test-provider", snapshot.Provider)
+		assert.False(t, snapshot.ObservedAt.IsZero())
+		assert.Equal(t, *weather, snapshot.Weather)
+	}
+}
+
+func testSnapshotProviderCoordinatesQuery(t *testing.T) {
+	t.Parallel()
+
+	store := &memorySnapshotStore{}
+	provider := clients.NewSnapshotProvider(namedWeatherClient{
+		countingWeatherClient: &countingWeatherClient{weather: &domain.WeatherResponse{Temperature: 21}},
+		name:                  "test-provider",
+	}, store)
+
+	coordinates := domain.Coordinates{Latitude: 50.45, Longitude: 30.52}
+	query := domain.CoordinatesQuery(coordinates)
+	query.City = "Kyiv"
+	query.LocationID = "kyiv,ukraine@50,31"
+	_, err := provider.GetAPICurrentWeather(context.Background(), query)
+
+	assert.NoError(t, err)
+	if assert.Len(t, store.snapshots, 1) {
+		assert.Equal(t, "kyiv,ukraine@50,31", store.snapshots[0].LocationID)
+		assert.Equal(t, &coordinates, store.snapshots[0].Coordinates)
+	}
+}
+
+func testSnapshotProviderBehindCachingClient(t *testing.T) {
+	t.Parallel()
+
+	// Setup
+	store := &memorySnapshotStore{}
+	client := clients.NewCachingWeatherClient(clients.NewSnapshotProvider(namedWeatherClient{
+		countingWeatherClient: &countingWeatherClient{weather: &domain.WeatherResponse{Temperature: 21}},
+		name:                  "test-provider",
+	}, store), newMemoryCache())
+
+	query := domain.CoordinatesQuery(domain.Coordinates{Latitude: 50.4501, Longitude: 30.5234})
+	query.City = "Kyiv"
+	query.LocationID = "kyiv,ukraine@50,31"
+
+	// Execute
+	_, err := client.GetAPICurrentWeather(context.Background(), query)
+
+	// Verify: the query snapped to the cache grid keeps its location
+	assert.NoError(t, err)
+	if assert.Len(t, store.snapshots, 1) {
+		assert.Equal(t, "kyiv,ukraine@50,31", store.snapshots[0].LocationID)
+		assert.NotNil(t, store.snapshots[0].Coordinates)
+	}
+}
+
+func testSnapshotProviderQueryWithoutLocation(t *testing.T) {
+	t.Parallel()
+
+	weather := &domain.WeatherResponse{Temperature: 21}
+	store := &memorySnapshotStore{}
+	provider := clients.NewSnapshotProvider(namedWeatherClient{
+		countingWeatherClient: &countingWeatherClient{weather: weather},
+		name:                  "test-provider",
+	}, store)
+
+	resp, err := provider.GetAPICurrentWeather(context.Background(), domain.CityQuery("Kyiv"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, weather, resp)
+	assert.Empty(t, store.snapshots, "the snapshot couldn't be found in the history of any location")
+}
+
+func testSnapshotProviderFailedRequest(t *testing.T) {
+	t.Parallel()
+
+	store := &memorySnapshotStore{}
+	provider := clients.NewSnapshotProvider(namedWeatherClient{
+		countingWeatherClient: &countingWeatherClient{err: customErrors.ErrWeatherProviderUnavailable},
+		name:                  "test-provider",
+	}, store)
+
+	_, err := provider.GetAPICurrentWeather(context.Background(), domain.CityQuery("Kyiv"))
+
+	assert.ErrorIs(t, err, customErrors.ErrWeatherProviderUnavailable)
+	assert.Empty(t, store.snapshots)
+}
+
+func testSnapshotProviderStoreFailure(t *testing.T) {
+	t.Parallel()
+
+	weather := &domain.WeatherResponse{Temperature: 21}
+	provider := clients.NewSnapshotProvider(namedWeatherClient{
+		countingWeatherClient: &countingWeatherClient{weather: weather},
+		name:                  "test-provider",
+	}, &memorySnapshotStore{err: errors.New("database is down")})
+
+	query := domain.CityQuery("Kyiv")
+	query.LocationID = "kyiv,ukraine@50,31"
+	resp, err := provider.GetAPICurrentWeather(context.Background(), query)
+
+	assert.NoError(t, err)
+	assert.Equal(t, weather, resp)
+}
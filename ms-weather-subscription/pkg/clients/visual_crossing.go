@@ -3,13 +3,13 @@ package clients
 import (
 	"common/logger"
 	"context"
-	"encoding/json"
 	"fmt"
 	"io"
+	"math"
 	"ms-weather-subscription/internal/domain"
 	customErrors "ms-weather-subscription/pkg/errors"
 	"net/http"
-	"reflect"
+	"strings"
 	"time"
 )
 
@@ -22,7 +22,6 @@ type VisualCrossingClient struct {
 	apiKey     string
 	baseURL    string
 	httpClient *http.Client
-	next       WeatherClient
 }
 
 func NewVisualCrossingClient(apiKey string) *VisualCrossingClient {
@@ -48,23 +47,59 @@ func (c *VisualCrossingClient) WithBaseURL(baseURL string) *VisualCrossingClient
 	return c
 }
 
-func (c *VisualCrossingClient) setNext(next ChainWeatherProvider) {
-	c.next = next
+// WithTimeout overrides the default HTTP client timeout.
+func (c *VisualCrossingClient) WithTimeout(timeout time.Duration) *VisualCrossingClient {
+	c.httpClient.Timeout = timeout
+	return c
+}
+
+func (c *VisualCrossingClient) Name() string {
+	return VisualCrossingProviderName
+}
+
+// visualCrossingConditions are the fields shared by current conditions and hourly forecast.
+type visualCrossingConditions struct {
+	Temp       float32 `json:"temp"`
+	FeelsLike  float32 `json:"feelslike"`
+	Humidity   float32 `json:"humidity"`
+	WindSpeed  float32 `json:"windspeed"`
+	WindDir    float32 `json:"winddir"`
+	WindGust   float32 `json:"windgust"`
+	Precip     float32 `json:"precip"`
+	PrecipProb float32 `json:"precipprob"`
+	Pressure   float32 `json:"pressure"`
+	Visibility float32 `json:"visibility"`
+	CloudCover float32 `json:"cloudcover"`
+	UVIndex    float32 `json:"uvindex"`
+	Conditions string  `json:"conditions"`
+}
+
+func (c visualCrossingConditions) toDomain() domain.WeatherResponse {
+	return domain.WeatherResponse{
+		Temperature:              c.Temp,
+		Humidity:                 c.Humidity,
+		Description:              c.Conditions,
+		FeelsLike:                c.FeelsLike,
+		WindSpeed:                c.WindSpeed,
+		WindDirection:            c.WindDir,
+		WindGust:                 c.WindGust,
+		Precipitation:            c.Precip,
+		PrecipitationProbability: c.PrecipProb,
+		UVIndex:                  c.UVIndex,
+		Pressure:                 c.Pressure,
+		Visibility:               c.Visibility,
+		CloudCover:               c.CloudCover,
+	}
 }
 
 type visualCrossingResponse struct {
-	CurrentConditions struct {
-		Temp       float32 `json:"temp"`
-		Humidity   float32 `json:"humidity"`
-		Conditions string  `json:"conditions"`
-	} `json:"currentConditions"`
-	Days []struct {
+	Timezone          string                   `json:"timezone"`
+	CurrentConditions visualCrossingConditions `json:"currentConditions"`
+	Days              []struct {
 		Datetime string `json:"datetime"`
 		Hours    []struct {
-			Datetime   string  `json:"datetime"`
-			Temp       float32 `json:"temp"`
-			Humidity   float32 `json:"humidity"`
-			Conditions string  `json:"conditions"`
+			Datetime string `json:"datetime"`
+			visualCrossingConditions
 		} `json:"hours"`
 	} `json:"days"`
 }
@@ -84,115 +119,171 @@ func (c *VisualCrossingClient) processErrorResponse(resp *http.Response) error {
 }
 
 func (c *VisualCrossingClient) GetAPICurrentWeather(
-	ctx context.Context, city string,
+	ctx context.Context, query domain.WeatherQuery,
 ) (*domain.WeatherResponse, error) {
 	requestURL := fmt.Sprintf(
-		"%s/%s/today?unitGroup=metric&include=current&key=%s", c.baseURL, city, c.apiKey,
+		"%s/%s/today?unitGroup=metric&include=current&key=%s", c.baseURL, queryParam(query), c.apiKey,
 	)
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
+	var result visualCrossingResponse
+	err := getJSON(ctx, c.httpClient, VisualCrossingProviderName, c.processErrorResponse, requestURL, &result)
 	if err != nil {
 		return nil, err
 	}
 
-	resp, err := c.httpClient.Do(req)
+	weather := result.CurrentConditions.toDomain()
+	return &weather, nil
+}
+
+func (c *VisualCrossingClient) GetAPIDayWeather(
+	ctx context.Context, query domain.WeatherQuery,
+) (*domain.DayWeatherResponse, error) {
+	requestURL := fmt.Sprintf(
+		"%s/%s/today?unitGroup=metric&include=hours&key=%s", c.baseURL, queryParam(query), c.apiKey,
+	)
+
+	var result visualCrossingResponse
+	err := getJSON(ctx, c.httpClient, VisualCrossingProviderName, c.processErrorResponse, requestURL, &result)
 	if err != nil {
 		return nil, err
 	}
-	defer closeBody(resp.Body, &err)
-
-	if resp.StatusCode != http.StatusOK {
-		err = c.processErrorResponse(resp)
-
-		if c.next != nil {
-			nextClientName := reflect.TypeOf(c.next).Elem().Name()
-			logger.Warnf(
-				"VisualCrossingClient.GetAPICurrentWeather() error: %s.\n"+
-					"Passing request to next weather client in chain: %s",
-				err,
-				nextClientName,
-			)
-			return c.next.GetAPICurrentWeather(ctx, city)
-		}
-		return nil, err
-	}
 
-	var result visualCrossingResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		logger.Errorf("error decoding VisualCrossing current weather: %s", err)
+	if len(result.Days) == 0 {
+		logger.Errorf("VisualCrossing day weather response has no days")
 		return nil, customErrors.ErrWeatherDataError
 	}
 
-	return &domain.WeatherResponse{
-		Temperature: result.CurrentConditions.Temp,
-		Humidity:    result.CurrentConditions.Humidity,
-		Description: result.CurrentConditions.Conditions,
+	hours := make([]domain.HourlyWeather, 0, len(result.Days[0].Hours))
+	for _, hour := range result.Days[0].Hours {
+		hours = append(hours, domain.HourlyWeather{
+			Time:            strings.TrimSuffix(hour.Datetime, ":00"), // "07:00:00" -> "07:00"
+			WeatherResponse: hour.toDomain(),
+		})
+	}
+
+	return &domain.DayWeatherResponse{
+		Hours:    hours,
+		Timezone: result.Timezone,
 	}, nil
 }
 
-func (c *VisualCrossingClient) GetAPIDayWeather(
-	ctx context.Context, city string,
-) (*domain.DayWeatherResponse, error) {
+type visualCrossingForecastResponse struct {
+	Days []struct {
+		Datetime   string  `json:"datetime"`
+		TempMax    float32 `json:"tempmax"`
+		TempMin    float32 `json:"tempmin"`
+		Temp       float32 `json:"temp"`
+		Humidity   float32 `json:"humidity"`
+		PrecipProb float32 `json:"precipprob"`
+		Conditions string  `json:"conditions"`
+	} `json:"days"`
+}
+
+func (c *VisualCrossingClient) GetAPIForecast(
+	ctx context.Context, query domain.WeatherQuery, days int,
+) (*domain.ForecastResponse, error) {
+	// "nextNdays" period includes today, so it covers N+1 days.
 	requestURL := fmt.Sprintf(
-		"%s/%s/today?unitGroup=metric&include=hours&key=%s", c.baseURL, city, c.apiKey,
+		"%s/%s/next%ddays?unitGroup=metric&include=days&key=%s", c.baseURL, queryParam(query), days-1, c.apiKey,
 	)
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
+	var result visualCrossingForecastResponse
+	err := getJSON(ctx, c.httpClient, VisualCrossingProviderName, c.processErrorResponse, requestURL, &result)
 	if err != nil {
 		return nil, err
 	}
 
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, err
+	forecast := &domain.ForecastResponse{Days: make([]domain.DayForecast, 0, len(result.Days))}
+	for _, day := range result.Days[:min(days, len(result.Days))] {
+		forecast.Days = append(forecast.Days, domain.DayForecast{
+			Date:                     day.Datetime,
+			MinTemperature:           day.TempMin,
+			MaxTemperature:           day.TempMax,
+			AvgTemperature:           day.Temp,
+			Humidity:                 day.Humidity,
+			PrecipitationProbability: day.PrecipProb,
+			Description:              day.Conditions,
+		})
 	}
-	defer closeBody(resp.Body, &err)
-
-	if resp.StatusCode != http.StatusOK {
-		err = c.processErrorResponse(resp)
-
-		if c.next != nil {
-			nextClientName := reflect.TypeOf(c.next).Elem().Name()
-			logger.Warnf(
-				"VisualCrossingClient.GetAPIDayWeather() error: %s. "+
-					"Passing request to next weather client in chain: %s",
-				err,
-				nextClientName,
-			)
-			return c.next.GetAPIDayWeather(ctx, city)
-		}
+
+	return forecast, nil
+}
+
+type visualCrossingAirQualityResponse struct {
+	CurrentConditions struct {
+		AQIUS float32 `json:"aqius"`
+		PM25  float32 `json:"pm2p5"`
+		PM10  float32 `json:"pm10"`
+		O3    float32 `json:"o3"`
+		NO2   float32 `json:"no2"`
+	} `json:"currentConditions"`
+}
+
+func (c *VisualCrossingClient) GetAPIAirQuality(
+	ctx context.Context, query domain.WeatherQuery,
+) (*domain.AirQualityResponse, error) {
+	requestURL := fmt.Sprintf(
+		"%s/%s/today?unitGroup=metric&include=current&elements=aqius,pm2p5,pm10,o3,no2&key=%s",
+		c.baseURL, queryParam(query), c.apiKey,
+	)
+
+	var result visualCrossingAirQualityResponse
+	err := getJSON(ctx, c.httpClient, VisualCrossingProviderName, c.processErrorResponse, requestURL, &result)
+	if err != nil {
 		return nil, err
 	}
 
-	var result visualCrossingResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		logger.Errorf("error decoding VisualCrossing forecast: %s", err)
-		return nil, customErrors.ErrWeatherDataError
-	}
+	current := result.CurrentConditions
+	return &domain.AirQualityResponse{
+		AQI:  int(math.Round(float64(current.AQIUS))),
+		PM25: current.PM25,
+		PM10: current.PM10,
+		O3:   current.O3,
+		NO2:  current.NO2,
+	}, nil
+}
 
-	targetHours := map[string]*domain.WeatherResponse{
-		"07:00:00": {},
-		"10:00:00": {},
-		"13:00:00": {},
-		"16:00:00": {},
-		"19:00:00": {},
-		"22:00:00": {},
+type visualCrossingAlertsResponse struct {
+	Alerts []struct {
+		ID          string `json:"id"`
+		Event       string `json:"event"`
+		Headline    string `json:"headline"`
+		Description string `json:"description"`
+		OnsetEpoch  int64  `json:"onsetEpoch"`
+		EndsEpoch   int64  `json:"endsEpoch"`
+	} `json:"alerts"`
+}
+
+// GetAPIAlerts returns alerts issued for the place. VisualCrossing doesn't grade alert severity.
+func (c *VisualCrossingClient) GetAPIAlerts(
+	ctx context.Context, query domain.WeatherQuery,
+) ([]domain.WeatherAlert, error) {
+	requestURL := fmt.Sprintf(
+		"%s/%s/today?unitGroup=metric&include=alerts&key=%s", c.baseURL, queryParam(query), c.apiKey,
+	)
+
+	var result visualCrossingAlertsResponse
+	err := getJSON(ctx, c.httpClient, VisualCrossingProviderName, c.processErrorResponse, requestURL, &result)
+	if err != nil {
+		return nil, err
 	}
 
-	for _, hour := range result.Days[0].Hours {
-		if target, ok := targetHours[hour.Datetime]; ok {
-			target.Temperature = hour.Temp
-			target.Humidity = hour.Humidity
-			target.Description = hour.Conditions
+	alerts := make([]domain.WeatherAlert, 0, len(result.Alerts))
+	for _, alert := range result.Alerts {
+		weatherAlert := domain.WeatherAlert{
+			ID:          VisualCrossingProviderName + ":" + alert.ID,
+			Event:       alert.Event,
+			Headline:    alert.Headline,
+			Description: alert.Description,
 		}
+		if alert.OnsetEpoch > 0 {
+			weatherAlert.Effective = time.Unix(alert.OnsetEpoch, 0).UTC()
+		}
+		if alert.EndsEpoch > 0 {
+			weatherAlert.Expires = time.Unix(alert.EndsEpoch, 0).UTC()
+		}
+		alerts = append(alerts, weatherAlert)
 	}
 
-	return &domain.DayWeatherResponse{
-		SevenAM: *targetHours["07:00:00"],
-		TenAM:   *targetHours["10:00:00"],
-		OnePM:   *targetHours["13:00:00"],
-		FourPM:  *targetHours["16:00:00"],
-		SevenPM: *targetHours["19:00:00"],
-		TenPM:   *targetHours["22:00:00"],
-	}, nil
+	return alerts, nil
 }
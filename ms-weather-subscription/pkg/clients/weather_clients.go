@@ -1,41 +1,178 @@
 package clients
 
 import (
+	"common/logger"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"ms-weather-subscription/internal/domain"
+	customErrors "ms-weather-subscription/pkg/errors"
+	"net/http"
+	"net/url"
 )
 
 type WeatherClient interface {
-	GetAPICurrentWeather(ctx context.Context, city string) (*domain.WeatherResponse, error)
-	GetAPIDayWeather(ctx context.Context, city string) (*domain.DayWeatherResponse, error)
+	GetAPICurrentWeather(ctx context.Context, query domain.WeatherQuery) (*domain.WeatherResponse, error)
+	GetAPIDayWeather(ctx context.Context, query domain.WeatherQuery) (*domain.DayWeatherResponse, error)
+	GetAPIForecast(ctx context.Context, query domain.WeatherQuery, days int) (*domain.ForecastResponse, error)
+	GetAPIAirQuality(ctx context.Context, query domain.WeatherQuery) (*domain.AirQualityResponse, error)
 }
 
+// ChainWeatherProvider is a single weather provider which can take part in ChainWeatherClient.
 type ChainWeatherProvider interface {
 	WeatherClient
-	setNext(ChainWeatherProvider)
+	Name() string
 }
 
+// ChainWeatherClient queries providers one by one until one of them returns weather data.
 type ChainWeatherClient struct {
-	WeatherClient
+	providers []ChainWeatherProvider
 }
 
-func NewChainWeatherClient(clients []ChainWeatherProvider) (*ChainWeatherClient, error) {
-	if len(clients) == 0 {
+func NewChainWeatherClient(providers []ChainWeatherProvider) (*ChainWeatherClient, error) {
+	if len(providers) == 0 {
 		return nil, errors.New("cannot create ChainWeatherClient with empty client list")
 	}
 
-	for i := 0; i < len(clients)-1; i++ {
-		clients[i].setNext(clients[i+1])
-	}
-
 	return &ChainWeatherClient{
-		WeatherClient: clients[0],
+		providers: providers,
 	}, nil
 }
 
+func (c *ChainWeatherClient) GetAPICurrentWeather(
+	ctx context.Context, query domain.WeatherQuery,
+) (*domain.WeatherResponse, error) {
+	return callChain(ctx, c.providers, "GetAPICurrentWeather",
+		func(provider ChainWeatherProvider) (*domain.WeatherResponse, error) {
+			return provider.GetAPICurrentWeather(ctx, query)
+		},
+	)
+}
+
+func (c *ChainWeatherClient) GetAPIDayWeather(
+	ctx context.Context, query domain.WeatherQuery,
+) (*domain.DayWeatherResponse, error) {
+	return callChain(ctx, c.providers, "GetAPIDayWeather",
+		func(provider ChainWeatherProvider) (*domain.DayWeatherResponse, error) {
+			return provider.GetAPIDayWeather(ctx, query)
+		},
+	)
+}
+
+func (c *ChainWeatherClient) GetAPIForecast(
+	ctx context.Context, query domain.WeatherQuery, days int,
+) (*domain.ForecastResponse, error) {
+	return callChain(ctx, c.providers, "GetAPIForecast",
+		func(provider ChainWeatherProvider) (*domain.ForecastResponse, error) {
+			return provider.GetAPIForecast(ctx, query, days)
+		},
+	)
+}
+
+func (c *ChainWeatherClient) GetAPIAirQuality(
+	ctx context.Context, query domain.WeatherQuery,
+) (*domain.AirQualityResponse, error) {
+	return callChain(ctx, c.providers, "GetAPIAirQuality",
+		func(provider ChainWeatherProvider) (*domain.AirQualityResponse, error) {
+			return provider.GetAPIAirQuality(ctx, query)
+		},
+	)
+}
+
+// callChain passes the request down the chain while providers fail with a retryable error.
+// If no provider succeeds, the returned error lists what each queried provider returned.
+func callChain[P interface{ Name() string }, T any](
+	ctx context.Context,
+	providers []P,
+	method string,
+	call func(provider P) (T, error),
+) (T, error) {
+	var zero T
+	providerErrors := make([]error, 0, len(providers))
+
+	for i, provider := range providers {
+		resp, err := call(provider)
+		if err == nil {
+			return resp, nil
+		}
+
+		providerErrors = append(providerErrors, &customErrors.WeatherProviderError{
+			Provider: provider.Name(),
+			Err:      err,
+		})
+
+		if !isRetryableError(ctx, err) {
+			break
+		}
+
+		if i+1 < len(providers) {
+			logger.Warnf(
+				"%s.%s() error: %s. Passing request to next provider in chain: %s",
+				provider.Name(),
+				method,
+				err,
+				providers[i+1].Name(),
+			)
+		}
+	}
+
+	return zero, &customErrors.WeatherChainError{Errors: providerErrors}
+}
+
+// isRetryableError reports whether the next provider in chain should be queried.
+// A city unknown to the provider is a definitive answer, and there is no point
+// in querying further once the caller's context is done.
+func isRetryableError(ctx context.Context, err error) bool {
+	if errors.Is(err, customErrors.ErrCityNotFound) {
+		return false
+	}
+	return ctx.Err() == nil
+}
+
+// queryParam formats the query for provider APIs which accept either a city name or "lat,lon".
+func queryParam(query domain.WeatherQuery) string {
+	if query.Coordinates != nil {
+		return fmt.Sprintf("%f,%f", query.Coordinates.Latitude, query.Coordinates.Longitude)
+	}
+	return url.QueryEscape(query.City)
+}
+
+// getJSON performs a GET request to the provider and decodes a successful response into result.
+// The other responses are turned into errors by processErrorResponse,
+// the bodies which can't be decoded are reported as ErrWeatherDataError.
+func getJSON(
+	ctx context.Context,
+	httpClient *http.Client,
+	provider string,
+	processErrorResponse func(resp *http.Response) error,
+	requestURL string,
+	result any,
+) (err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer closeBody(resp.Body, &err)
+
+	if resp.StatusCode != http.StatusOK {
+		return processErrorResponse(resp)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		logger.Errorf("error parsing %s response: %s", provider, err.Error())
+		return customErrors.ErrWeatherDataError
+	}
+
+	return nil
+}
+
 func closeBody(body io.Closer, errPtr *error) {
 	if closeErr := body.Close(); closeErr != nil {
 		if *errPtr != nil {
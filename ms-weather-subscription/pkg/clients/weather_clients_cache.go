@@ -8,22 +8,37 @@ import (
 	"fmt"
 	redisCache "ms-weather-subscription/pkg/cache"
 	"ms-weather-subscription/pkg/metrics"
-	"net/url"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/redis/go-redis/v9"
+	"golang.org/x/sync/singleflight"
 
 	"ms-weather-subscription/internal/domain"
 )
 
 const (
 	oneHourDuration = time.Hour
+	// cityTimezoneTTL is how long the timezone of a city is remembered to build day forecast keys.
+	cityTimezoneTTL = 30 * 24 * time.Hour
+	// lastKnownWeatherTTL is how long the last successful response is kept
+	// to be served as stale data when every weather provider fails.
+	lastKnownWeatherTTL = 24 * time.Hour
+	// refreshAheadWindow is how long before the end of the hour the current weather
+	// for the next hour is fetched in background.
+	refreshAheadWindow       = 5 * time.Minute
+	backgroundRefreshTimeout = 30 * time.Second
 )
 
 type CachingWeatherClient struct {
 	WeatherClient
 	cache redisCache.Cache
+
+	// refreshing holds the keys of the cache entries which are being refreshed in background.
+	refreshing sync.Map
+	// inFlight makes concurrent cache misses for the same key share one upstream request.
+	inFlight singleflight.Group
 }
 
 func NewCachingWeatherClient(client WeatherClient, cache redisCache.Cache) *CachingWeatherClient {
@@ -33,39 +48,338 @@ func NewCachingWeatherClient(client WeatherClient, cache redisCache.Cache) *Cach
 	}
 }
 
+// GetAPICurrentWeather caches the current weather per place and hour.
+// Close to the end of the hour the weather for the next hour is fetched in background,
+// and if no provider can answer, the last known weather of the place is returned as stale.
 func (s *CachingWeatherClient) GetAPICurrentWeather(
-	ctx context.Context, city string,
+	ctx context.Context, query domain.WeatherQuery,
 ) (*domain.WeatherResponse, error) {
 	now := time.Now().UTC()
-	key := fmt.Sprintf("%s:%s", strings.ToLower(city), now.Format("2006-01-02:15-00"))
+	query, placeKey := onGrid(query)
+
+	var res domain.WeatherResponse
+	if s.getCached(ctx, currentWeatherKey(placeKey, now), &res, "weather current") {
+		metrics.WeatherCacheHitCount.Inc()
+		s.refreshAhead(ctx, query, placeKey, now)
+		return &res, nil
+	}
+
+	key := currentWeatherKey(placeKey, now)
+	resp, err := coalesce(ctx, &s.inFlight, "current:"+key, func(ctx context.Context) (*domain.WeatherResponse, error) {
+		return s.fetchCurrentWeather(ctx, query, placeKey, key, oneHourDuration)
+	})
+	if err != nil {
+		return s.lastKnownCurrentWeather(ctx, placeKey, err)
+	}
+	return resp, nil
+}
+
+func (s *CachingWeatherClient) fetchCurrentWeather(
+	ctx context.Context, query domain.WeatherQuery, placeKey, key string, ttl time.Duration,
+) (*domain.WeatherResponse, error) {
+	resp, err := s.WeatherClient.GetAPICurrentWeather(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	s.setCached(ctx, key, resp, ttl, "weather current")
+
+	lastKnown := *resp
+	lastKnown.ObservedAt = time.Now().UTC()
+	s.setCached(ctx, lastKnownCurrentWeatherKey(placeKey), lastKnown, lastKnownWeatherTTL, "weather current")
 
-	if cached, err := s.cache.Get(ctx, key); err == nil {
-		var res domain.WeatherResponse
-		if err := json.Unmarshal([]byte(cached), &res); err == nil {
-			metrics.WeatherCacheHitCount.Inc()
-			return &res, nil
+	return resp, nil
+}
+
+// refreshAhead fetches the current weather for the next hour in background,
+// so that requests at the beginning of the hour (e.g. hourly emails) are served from cache.
+func (s *CachingWeatherClient) refreshAhead(
+	ctx context.Context, query domain.WeatherQuery, placeKey string, now time.Time,
+) {
+	nextHour := now.Truncate(time.Hour).Add(time.Hour)
+	if nextHour.Sub(now) > refreshAheadWindow {
+		return
+	}
+
+	key := currentWeatherKey(placeKey, nextHour)
+	if _, inProgress := s.refreshing.LoadOrStore(key, struct{}{}); inProgress {
+		return
+	}
+
+	go func() {
+		defer s.refreshing.Delete(key)
+
+		refreshCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), backgroundRefreshTimeout)
+		defer cancel()
+
+		if _, err := s.cache.Get(refreshCtx, key); err == nil {
+			return
 		}
 
-		logger.Warnf("cache unmarshal error (weather current): %v", err)
+		ttl := nextHour.Add(time.Hour).Sub(now)
+		if _, err := s.fetchCurrentWeather(refreshCtx, query, placeKey, key, ttl); err != nil {
+			logger.Warnf("background refresh of current weather for %s failed: %v", query, err)
+		}
+	}()
+}
+
+func (s *CachingWeatherClient) lastKnownCurrentWeather(
+	ctx context.Context, placeKey string, err error,
+) (*domain.WeatherResponse, error) {
+	if !isRetryableError(ctx, err) {
+		return nil, err
+	}
+
+	var res domain.WeatherResponse
+	if !s.getCached(ctx, lastKnownCurrentWeatherKey(placeKey), &res, "weather current") {
+		return nil, err
+	}
+
+	logger.Warnf("serving stale current weather for %s observed at %s: %v", placeKey, res.ObservedAt, err)
+	metrics.WeatherStaleCacheServedCount.Inc()
+	res.Stale = true
+	return &res, nil
+}
+
+// GetAPIDayWeather caches the day forecast per place and local date of the place.
+// The cached forecast expires at the end of that day in the place's timezone.
+// If no provider can answer, the last known forecast of the place is returned as stale.
+func (s *CachingWeatherClient) GetAPIDayWeather(
+	ctx context.Context, query domain.WeatherQuery,
+) (*domain.DayWeatherResponse, error) {
+	now := time.Now()
+	query, placeKey := onGrid(query)
+
+	// The timezone of the place is known only after its forecast was fetched at least once.
+	location := time.UTC
+	if timezone, err := s.cache.Get(ctx, cityTimezoneKey(placeKey)); err == nil {
+		location = loadLocationOrUTC(timezone)
 	} else {
 		HandleRedisError(err)
 	}
 
-	resp, err := s.WeatherClient.GetAPICurrentWeather(ctx, url.QueryEscape(city))
+	var res domain.DayWeatherResponse
+	if s.getCached(ctx, dayWeatherKey(placeKey, now.In(location)), &res, "weather day") {
+		metrics.WeatherDayCacheHitCount.Inc()
+		return &res, nil
+	}
+	metrics.WeatherDayCacheMissCount.Inc()
+
+	resp, err := coalesce(ctx, &s.inFlight, "day:"+placeKey,
+		func(ctx context.Context) (*domain.DayWeatherResponse, error) {
+			return s.fetchDayWeather(ctx, query, placeKey)
+		},
+	)
 	if err != nil {
+		return s.lastKnownDayWeather(ctx, placeKey, err)
+	}
+	return resp, nil
+}
+
+func (s *CachingWeatherClient) fetchDayWeather(
+	ctx context.Context, query domain.WeatherQuery, placeKey string,
+) (*domain.DayWeatherResponse, error) {
+	resp, err := s.WeatherClient.GetAPIDayWeather(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	location := loadLocationOrUTC(resp.Timezone)
+	if resp.Timezone != "" {
+		if err := s.cache.Set(ctx, cityTimezoneKey(placeKey), resp.Timezone, cityTimezoneTTL); err != nil {
+			HandleRedisError(err)
+		}
+	}
+
+	localNow := now.In(location)
+	s.setCached(ctx, dayWeatherKey(placeKey, localNow), resp, untilEndOfDay(localNow), "weather day")
+
+	lastKnown := *resp
+	lastKnown.ObservedAt = now.UTC()
+	s.setCached(ctx, lastKnownDayWeatherKey(placeKey), lastKnown, lastKnownWeatherTTL, "weather day")
+
+	return resp, nil
+}
+
+func (s *CachingWeatherClient) lastKnownDayWeather(
+	ctx context.Context, placeKey string, err error,
+) (*domain.DayWeatherResponse, error) {
+	if !isRetryableError(ctx, err) {
+		return nil, err
+	}
+
+	var res domain.DayWeatherResponse
+	if !s.getCached(ctx, lastKnownDayWeatherKey(placeKey), &res, "weather day") {
 		return nil, err
 	}
 
-	data, err := json.Marshal(resp)
+	logger.Warnf("serving stale day weather for %s observed at %s: %v", placeKey, res.ObservedAt, err)
+	metrics.WeatherStaleCacheServedCount.Inc()
+	res.Stale = true
+	return &res, nil
+}
+
+// GetAPIForecast caches the multi-day forecast per place, number of days and hour.
+func (s *CachingWeatherClient) GetAPIForecast(
+	ctx context.Context, query domain.WeatherQuery, days int,
+) (*domain.ForecastResponse, error) {
+	query, placeKey := onGrid(query)
+	key := forecastKey(placeKey, days, time.Now().UTC())
+
+	var res domain.ForecastResponse
+	if s.getCached(ctx, key, &res, "weather forecast") {
+		metrics.WeatherCacheHitCount.Inc()
+		return &res, nil
+	}
+
+	return coalesce(ctx, &s.inFlight, key, func(ctx context.Context) (*domain.ForecastResponse, error) {
+		resp, err := s.WeatherClient.GetAPIForecast(ctx, query, days)
+		if err != nil {
+			return nil, err
+		}
+
+		s.setCached(ctx, key, resp, oneHourDuration, "weather forecast")
+		return resp, nil
+	})
+}
+
+// GetAPIAirQuality caches the air quality per place and hour.
+func (s *CachingWeatherClient) GetAPIAirQuality(
+	ctx context.Context, query domain.WeatherQuery,
+) (*domain.AirQualityResponse, error) {
+	query, placeKey := onGrid(query)
+	key := airQualityKey(placeKey, time.Now().UTC())
+
+	var res domain.AirQualityResponse
+	if s.getCached(ctx, key, &res, "air quality") {
+		metrics.WeatherCacheHitCount.Inc()
+		return &res, nil
+	}
+
+	return coalesce(ctx, &s.inFlight, key, func(ctx context.Context) (*domain.AirQualityResponse, error) {
+		resp, err := s.WeatherClient.GetAPIAirQuality(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+
+		s.setCached(ctx, key, resp, oneHourDuration, "air quality")
+		return resp, nil
+	})
+}
+
+// coalesce runs fetch once for all concurrent callers with the same key.
+// fetch gets a context which isn't cancelled together with the caller's one,
+// so that a caller which gave up doesn't fail the request for the others.
+func coalesce[T any](
+	ctx context.Context, group *singleflight.Group, key string, fetch func(ctx context.Context) (T, error),
+) (T, error) {
+	var zero T
+
+	leader := false
+	resultCh := group.DoChan(key, func() (any, error) {
+		leader = true
+		return fetch(context.WithoutCancel(ctx))
+	})
+
+	select {
+	case result := <-resultCh:
+		if !leader {
+			metrics.WeatherCoalescedRequestCount.Inc()
+		}
+		if result.Err != nil {
+			return zero, result.Err
+		}
+		return result.Val.(T), nil
+	case <-ctx.Done():
+		return zero, ctx.Err()
+	}
+}
+
+// getCached reads a JSON-encoded value from cache into dst and reports whether it was found.
+func (s *CachingWeatherClient) getCached(ctx context.Context, key string, dst any, kind string) bool {
+	cached, err := s.cache.Get(ctx, key)
+	if err != nil {
+		HandleRedisError(err)
+		return false
+	}
+
+	if err := json.Unmarshal([]byte(cached), dst); err != nil {
+		logger.Warnf("cache unmarshal error (%s): %v", kind, err)
+		return false
+	}
+	return true
+}
+
+func (s *CachingWeatherClient) setCached(ctx context.Context, key string, value any, ttl time.Duration, kind string) {
+	data, err := json.Marshal(value)
 	if err != nil {
-		logger.Errorf("cache marshal error (weather current): %s", err)
+		logger.Errorf("cache marshal error (%s): %s", kind, err)
+		return
 	}
 
-	if err := s.cache.Set(ctx, key, string(data), oneHourDuration); err != nil {
+	if err := s.cache.Set(ctx, key, string(data), ttl); err != nil {
 		HandleRedisError(err)
 	}
+}
 
-	return resp, nil
+// onGrid snaps the coordinates of the query to the grid, so that nearby coordinates share
+// one cache entry and one upstream request, and returns the query with the cache key of the place.
+func onGrid(query domain.WeatherQuery) (domain.WeatherQuery, string) {
+	if query.Coordinates == nil {
+		return query, strings.ToLower(query.City)
+	}
+	key := query.Coordinates.GridKey()
+	coordinates := query.Coordinates.OnGrid()
+	query.Coordinates = &coordinates
+	return query, key
+}
+
+func currentWeatherKey(city string, t time.Time) string {
+	return fmt.Sprintf("%s:%s", city, t.Format("2006-01-02:15-00"))
+}
+
+func forecastKey(city string, days int, t time.Time) string {
+	return fmt.Sprintf("%s:forecast:%d:%s", city, days, t.Format("2006-01-02:15-00"))
+}
+
+func airQualityKey(city string, t time.Time) string {
+	return fmt.Sprintf("%s:air_quality:%s", city, t.Format("2006-01-02:15-00"))
+}
+
+func lastKnownCurrentWeatherKey(city string) string {
+	return fmt.Sprintf("%s:current:last", city)
+}
+
+func dayWeatherKey(city string, localNow time.Time) string {
+	return fmt.Sprintf("%s:day:%s", city, localNow.Format("2006-01-02"))
+}
+
+func lastKnownDayWeatherKey(city string) string {
+	return fmt.Sprintf("%s:day:last", city)
+}
+
+func cityTimezoneKey(city string) string {
+	return fmt.Sprintf("%s:timezone", city)
+}
+
+func loadLocationOrUTC(timezone string) *time.Location {
+	if timezone == "" {
+		return time.UTC
+	}
+
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		logger.Warnf("unknown city timezone %q: %v", timezone, err)
+		return time.UTC
+	}
+	return location
+}
+
+// untilEndOfDay returns the duration from t till the next midnight in t's location.
+func untilEndOfDay(t time.Time) time.Duration {
+	year, month, day := t.Date()
+	return time.Date(year, month, day+1, 0, 0, 0, 0, t.Location()).Sub(t)
 }
 
 func HandleRedisError(err error) {
@@ -73,7 +387,7 @@ func HandleRedisError(err error) {
 		return
 	}
 
-	if errors.Is(err, redis.Nil) {
+	if errors.Is(err, redis.Nil) || errors.Is(err, redisCache.ErrCacheMiss) {
 		return
 	}
 
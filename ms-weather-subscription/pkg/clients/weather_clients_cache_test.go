@@ -0,0 +1,413 @@
+package clients_test
+
+import (
+	"context"
+	"ms-weather-subscription/internal/domain"
+	"ms-weather-subscription/pkg/clients"
+	customErrors "ms-weather-subscription/pkg/errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCachingWeatherClientDayWeather(t *testing.T) {
+	t.Run("Caches forecast till city's end of day", testDayWeatherCachedTillEndOfDay)
+	t.Run("Forecast without timezone expires at UTC end of day", testDayWeatherWithoutTimezone)
+	t.Run("Nearby coordinates share one grid cell", testDayWeatherCachedByGridCell)
+}
+
+func TestCachingWeatherClientCoalescing(t *testing.T) {
+	t.Run("Concurrent misses share one upstream request", testCoalescedCurrentWeather)
+	t.Run("Cancelled caller doesn't fail the others", testCoalescedCallerCancellation)
+}
+
+func TestCachingWeatherClientStaleWeather(t *testing.T) {
+	t.Run("Serves last known current weather when providers fail", testStaleCurrentWeather)
+	t.Run("Serves last known day weather when providers fail", testStaleDayWeather)
+	t.Run("Doesn't serve stale weather for unknown city", testStaleWeatherCityNotFound)
+	t.Run("Fails without last known weather", testStaleWeatherMissing)
+}
+
+func TestCachingWeatherClientAirQuality(t *testing.T) {
+	t.Run("Caches air quality per place and hour", testAirQualityCached)
+}
+
+type cacheEntry struct {
+	value string
+	ttl   time.Duration
+}
+
+// memoryCache is a cache.Cache which keeps entries in a map and never expires them.
+type memoryCache struct {
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+func newMemoryCache() *memoryCache {
+	return &memoryCache{entries: make(map[string]cacheEntry)}
+}
+
+func (c *memoryCache) Set(_ context.Context, key, value string, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = cacheEntry{value: value, ttl: ttl}
+	return nil
+}
+
+func (c *memoryCache) Get(_ context.Context, key string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok {
+		return "", redis.Nil
+	}
+	return entry.value, nil
+}
+
+func (c *memoryCache) entry(key string) (cacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	return entry, ok
+}
+
+func (c *memoryCache) delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, key)
+}
+
+// countingWeatherClient returns the same weather and counts how many times it was asked for day weather
+// and air quality. If err is set, it is returned instead of the weather.
+type countingWeatherClient struct {
+	weather         *domain.WeatherResponse
+	dayWeather      *domain.DayWeatherResponse
+	forecast        *domain.ForecastResponse
+	airQuality      *domain.AirQualityResponse
+	err             error
+	dayCalls        int
+	dayQuery        domain.WeatherQuery
+	airQualityCalls int
+}
+
+func (c *countingWeatherClient) GetAPICurrentWeather(
+	context.Context, domain.WeatherQuery,
+) (*domain.WeatherResponse, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	return c.weather, nil
+}
+
+func (c *countingWeatherClient) GetAPIDayWeather(
+	_ context.Context, query domain.WeatherQuery,
+) (*domain.DayWeatherResponse, error) {
+	c.dayCalls++
+	c.dayQuery = query
+	if c.err != nil {
+		return nil, c.err
+	}
+	return c.dayWeather, nil
+}
+
+func (c *countingWeatherClient) GetAPIForecast(
+	context.Context, domain.WeatherQuery, int,
+) (*domain.ForecastResponse, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	return c.forecast, nil
+}
+
+func (c *countingWeatherClient) GetAPIAirQuality(
+	context.Context, domain.WeatherQuery,
+) (*domain.AirQualityResponse, error) {
+	c.airQualityCalls++
+	if c.err != nil {
+		return nil, c.err
+	}
+	return c.airQuality, nil
+}
+
+var cloudyMorning = []domain.HourlyWeather{
+	{Time: "07:00", WeatherResponse: domain.WeatherResponse{Temperature: 12, Humidity: 80, Description: "Cloudy"}},
+}
+
+func testDayWeatherCachedTillEndOfDay(t *testing.T) {
+	t.Parallel()
+
+	inner := &countingWeatherClient{dayWeather: &domain.DayWeatherResponse{
+		Hours:    cloudyMorning,
+		Timezone: "Asia/Tokyo",
+	}}
+	cache := newMemoryCache()
+	client := clients.NewCachingWeatherClient(inner, cache)
+
+	requestedAt := time.Now()
+	first, err := client.GetAPIDayWeather(context.Background(), domain.CityQuery("Tokyo"))
+	assert.NoError(t, err)
+	second, err := client.GetAPIDayWeather(context.Background(), domain.CityQuery("Tokyo"))
+	assert.NoError(t, err)
+
+	assert.Equal(t, inner.dayWeather, first)
+	assert.Equal(t, inner.dayWeather, second)
+	assert.Equal(t, 1, inner.dayCalls, "second request must be served from cache")
+
+	location, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatalf("failed to load location: %v", err)
+	}
+	localNow := requestedAt.In(location)
+	year, month, day := localNow.Date()
+	localMidnight := time.Date(year, month, day+1, 0, 0, 0, 0, location)
+
+	entry, ok := cache.entry("tokyo:day:" + localNow.Format("2006-01-02"))
+	if assert.True(t, ok, "forecast must be cached under city's local date") {
+		assert.WithinDuration(t, localMidnight, requestedAt.Add(entry.ttl), time.Second)
+	}
+}
+
+func testDayWeatherCachedByGridCell(t *testing.T) {
+	t.Parallel()
+
+	inner := &countingWeatherClient{dayWeather: &domain.DayWeatherResponse{Hours: cloudyMorning}}
+	cache := newMemoryCache()
+	client := clients.NewCachingWeatherClient(inner, cache)
+
+	requestedAt := time.Now().UTC()
+	_, err := client.GetAPIDayWeather(
+		context.Background(), domain.CoordinatesQuery(domain.Coordinates{Latitude: 50.4547, Longitude: 30.5238}),
+	)
+	assert.NoError(t, err)
+	_, err = client.GetAPIDayWeather(
+		context.Background(), domain.CoordinatesQuery(domain.Coordinates{Latitude: 50.4521, Longitude: 30.5176}),
+	)
+	assert.NoError(t, err)
+
+	assert.Equal(t, 1, inner.dayCalls, "second request must be served from cache")
+	assert.Equal(t, domain.CoordinatesQuery(domain.Coordinates{Latitude: 50.45, Longitude: 30.52}), inner.dayQuery,
+		"provider must be asked for the weather of the grid cell")
+	_, ok := cache.entry("50.45,30.52:day:" + requestedAt.Format("2006-01-02"))
+	assert.True(t, ok)
+}
+
+func testDayWeatherWithoutTimezone(t *testing.T) {
+	t.Parallel()
+
+	inner := &countingWeatherClient{dayWeather: &domain.DayWeatherResponse{
+		Hours: cloudyMorning,
+	}}
+	cache := newMemoryCache()
+	client := clients.NewCachingWeatherClient(inner, cache)
+
+	requestedAt := time.Now().UTC()
+	_, err := client.GetAPIDayWeather(context.Background(), domain.CityQuery("Kyiv"))
+	assert.NoError(t, err)
+
+	entry, ok := cache.entry("kyiv:day:" + requestedAt.Format("2006-01-02"))
+	if assert.True(t, ok) {
+		year, month, day := requestedAt.Date()
+		utcMidnight := time.Date(year, month, day+1, 0, 0, 0, 0, time.UTC)
+		assert.WithinDuration(t, utcMidnight, requestedAt.Add(entry.ttl), time.Second)
+	}
+	_, ok = cache.entry("kyiv:timezone")
+	assert.False(t, ok)
+}
+
+// blockingWeatherClient answers current weather requests once release is closed.
+type blockingWeatherClient struct {
+	countingWeatherClient
+	release chan struct{}
+	calls   atomic.Int32
+}
+
+func (c *blockingWeatherClient) GetAPICurrentWeather(
+	ctx context.Context, _ domain.WeatherQuery,
+) (*domain.WeatherResponse, error) {
+	c.calls.Add(1)
+	select {
+	case <-c.release:
+		return c.weather, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func testCoalescedCurrentWeather(t *testing.T) {
+	t.Parallel()
+
+	inner := &blockingWeatherClient{
+		countingWeatherClient: countingWeatherClient{
+			weather: &domain.WeatherResponse{Temperature: 21, Humidity: 50, Description: "Sunny"},
+		},
+		release: make(chan struct{}),
+	}
+	client := clients.NewCachingWeatherClient(inner, newMemoryCache())
+
+	const callers = 10
+	var wg sync.WaitGroup
+	results := make(chan *domain.WeatherResponse, callers)
+	for range callers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			weather, err := client.GetAPICurrentWeather(context.Background(), domain.CityQuery("Kyiv"))
+			assert.NoError(t, err)
+			results <- weather
+		}()
+	}
+
+	// Let every caller reach the upstream request before it is answered.
+	time.Sleep(50 * time.Millisecond)
+	close(inner.release)
+	wg.Wait()
+	close(results)
+
+	assert.Equal(t, int32(1), inner.calls.Load())
+	for weather := range results {
+		assert.Equal(t, inner.weather, weather)
+	}
+}
+
+func testCoalescedCallerCancellation(t *testing.T) {
+	t.Parallel()
+
+	inner := &blockingWeatherClient{
+		countingWeatherClient: countingWeatherClient{
+			weather: &domain.WeatherResponse{Temperature: 21, Humidity: 50, Description: "Sunny"},
+		},
+		release: make(chan struct{}),
+	}
+	client := clients.NewCachingWeatherClient(inner, newMemoryCache())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	canceledErr := make(chan error, 1)
+	go func() {
+		_, err := client.GetAPICurrentWeather(ctx, domain.CityQuery("Kyiv"))
+		canceledErr <- err
+	}()
+
+	otherResult := make(chan *domain.WeatherResponse, 1)
+	go func() {
+		weather, err := client.GetAPICurrentWeather(context.Background(), domain.CityQuery("Kyiv"))
+		assert.NoError(t, err)
+		otherResult <- weather
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	assert.ErrorIs(t, <-canceledErr, context.Canceled)
+
+	close(inner.release)
+	assert.Equal(t, inner.weather, <-otherResult)
+	assert.Equal(t, int32(1), inner.calls.Load())
+}
+
+func currentWeatherKey(city string) string {
+	return city + ":" + time.Now().UTC().Format("2006-01-02:15-00")
+}
+
+func testStaleCurrentWeather(t *testing.T) {
+	t.Parallel()
+
+	inner := &countingWeatherClient{weather: &domain.WeatherResponse{Temperature: 21, Humidity: 50, Description: "Sunny"}}
+	cache := newMemoryCache()
+	client := clients.NewCachingWeatherClient(inner, cache)
+
+	fresh, err := client.GetAPICurrentWeather(context.Background(), domain.CityQuery("Kyiv"))
+	assert.NoError(t, err)
+	assert.False(t, fresh.Stale)
+	assert.True(t, fresh.ObservedAt.IsZero())
+
+	// The hourly entry is gone and every provider fails.
+	cache.delete(currentWeatherKey("kyiv"))
+	inner.err = &customErrors.WeatherChainError{Errors: []error{customErrors.ErrWeatherDataError}}
+
+	stale, err := client.GetAPICurrentWeather(context.Background(), domain.CityQuery("Kyiv"))
+
+	assert.NoError(t, err)
+	assert.True(t, stale.Stale)
+	assert.WithinDuration(t, time.Now(), stale.ObservedAt, time.Minute)
+	assert.Equal(t, float32(21), stale.Temperature)
+	assert.Equal(t, "Sunny", stale.Description)
+}
+
+func testStaleDayWeather(t *testing.T) {
+	t.Parallel()
+
+	inner := &countingWeatherClient{dayWeather: &domain.DayWeatherResponse{
+		Hours: cloudyMorning,
+	}}
+	cache := newMemoryCache()
+	client := clients.NewCachingWeatherClient(inner, cache)
+
+	_, err := client.GetAPIDayWeather(context.Background(), domain.CityQuery("Kyiv"))
+	assert.NoError(t, err)
+
+	cache.delete("kyiv:day:" + time.Now().UTC().Format("2006-01-02"))
+	inner.err = customErrors.ErrWeatherProviderUnavailable
+
+	stale, err := client.GetAPIDayWeather(context.Background(), domain.CityQuery("Kyiv"))
+
+	assert.NoError(t, err)
+	assert.True(t, stale.Stale)
+	assert.False(t, stale.ObservedAt.IsZero())
+	assert.Equal(t, "Cloudy", stale.Hours[0].Description)
+}
+
+func testStaleWeatherCityNotFound(t *testing.T) {
+	t.Parallel()
+
+	inner := &countingWeatherClient{weather: &domain.WeatherResponse{Temperature: 21, Humidity: 50, Description: "Sunny"}}
+	cache := newMemoryCache()
+	client := clients.NewCachingWeatherClient(inner, cache)
+
+	_, err := client.GetAPICurrentWeather(context.Background(), domain.CityQuery("Kyiv"))
+	assert.NoError(t, err)
+
+	cache.delete(currentWeatherKey("kyiv"))
+	inner.err = customErrors.ErrCityNotFound
+
+	_, err = client.GetAPICurrentWeather(context.Background(), domain.CityQuery("Kyiv"))
+
+	assert.ErrorIs(t, err, customErrors.ErrCityNotFound)
+}
+
+func testStaleWeatherMissing(t *testing.T) {
+	t.Parallel()
+
+	inner := &countingWeatherClient{err: customErrors.ErrWeatherProviderUnavailable}
+	client := clients.NewCachingWeatherClient(inner, newMemoryCache())
+
+	_, err := client.GetAPICurrentWeather(context.Background(), domain.CityQuery("Kyiv"))
+
+	assert.ErrorIs(t, err, customErrors.ErrWeatherProviderUnavailable)
+}
+
+func testAirQualityCached(t *testing.T) {
+	t.Parallel()
+
+	inner := &countingWeatherClient{
+		airQuality: &domain.AirQualityResponse{AQI: 42, PM25: 10.1, PM10: 18, O3: 61, NO2: 12.4},
+	}
+	cache := newMemoryCache()
+	client := clients.NewCachingWeatherClient(inner, cache)
+
+	first, err := client.GetAPIAirQuality(context.Background(), domain.CityQuery("Kyiv"))
+	assert.NoError(t, err)
+	second, err := client.GetAPIAirQuality(context.Background(), domain.CityQuery("KYIV"))
+	assert.NoError(t, err)
+
+	assert.Equal(t, inner.airQuality, first)
+	assert.Equal(t, inner.airQuality, second)
+	assert.Equal(t, 1, inner.airQualityCalls, "second request must be served from cache")
+
+	entry, ok := cache.entry("kyiv:air_quality:" + time.Now().UTC().Format("2006-01-02:15-00"))
+	if assert.True(t, ok) {
+		assert.Equal(t, time.Hour, entry.ttl)
+	}
+}
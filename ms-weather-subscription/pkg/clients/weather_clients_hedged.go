@@ -0,0 +1,101 @@
+package clients
+
+import (
+	"common/logger"
+	"context"
+	"ms-weather-subscription/internal/domain"
+	customErrors "ms-weather-subscription/pkg/errors"
+	"ms-weather-subscription/pkg/metrics"
+	"time"
+)
+
+// HedgedWeatherClient queries chain providers for current weather in a hedged way:
+// if a provider hasn't answered within hedgeDelay, the next one is queried in parallel
+// and the first successful response wins. Requests still in flight are cancelled.
+// Day weather is requested sequentially, the same way as by ChainWeatherClient.
+type HedgedWeatherClient struct {
+	*ChainWeatherClient
+	hedgeDelay time.Duration
+}
+
+func NewHedgedWeatherClient(chain *ChainWeatherClient, hedgeDelay time.Duration) *HedgedWeatherClient {
+	return &HedgedWeatherClient{
+		ChainWeatherClient: chain,
+		hedgeDelay:         hedgeDelay,
+	}
+}
+
+type hedgedResult struct {
+	provider string
+	resp     *domain.WeatherResponse
+	err      error
+}
+
+func (c *HedgedWeatherClient) GetAPICurrentWeather(
+	ctx context.Context, query domain.WeatherQuery,
+) (*domain.WeatherResponse, error) {
+	hedgeCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	providers := c.providers
+	results := make(chan hedgedResult, len(providers))
+	timer := time.NewTimer(c.hedgeDelay)
+	defer timer.Stop()
+
+	launched, pending := 0, 0
+	launchNext := func() {
+		provider := providers[launched]
+		go func() {
+			resp, err := provider.GetAPICurrentWeather(hedgeCtx, query)
+			results <- hedgedResult{provider: provider.Name(), resp: resp, err: err}
+		}()
+		launched++
+		pending++
+		timer.Reset(c.hedgeDelay)
+	}
+
+	launchNext()
+
+	providerErrors := make([]error, 0, len(providers))
+	for pending > 0 {
+		select {
+		case <-timer.C:
+			if launched < len(providers) {
+				logger.Warnf(
+					"%s.GetAPICurrentWeather() has not answered within %s. Sending hedged request to %s",
+					providers[launched-1].Name(),
+					c.hedgeDelay,
+					providers[launched].Name(),
+				)
+				metrics.WeatherHedgedRequestCount.WithLabelValues(providers[launched].Name()).Inc()
+				launchNext()
+			}
+		case result := <-results:
+			pending--
+			if result.err == nil {
+				return result.resp, nil
+			}
+
+			providerErrors = append(providerErrors, &customErrors.WeatherProviderError{
+				Provider: result.provider,
+				Err:      result.err,
+			})
+			if !isRetryableError(ctx, result.err) {
+				return nil, &customErrors.WeatherChainError{Errors: providerErrors}
+			}
+
+			// Don't wait for the hedge delay when nothing else is in flight.
+			if pending == 0 && launched < len(providers) {
+				logger.Warnf(
+					"%s.GetAPICurrentWeather() error: %s. Passing request to next weather client in chain: %s",
+					result.provider,
+					result.err,
+					providers[launched].Name(),
+				)
+				launchNext()
+			}
+		}
+	}
+
+	return nil, &customErrors.WeatherChainError{Errors: providerErrors}
+}
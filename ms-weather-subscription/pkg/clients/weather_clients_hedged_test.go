@@ -0,0 +1,118 @@
+package clients_test
+
+import (
+	"context"
+	"ms-weather-subscription/internal/domain"
+	"ms-weather-subscription/pkg/clients"
+	customErrors "ms-weather-subscription/pkg/errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHedgedWeatherClient(t *testing.T) {
+	t.Run("Fast primary provider wins", testHedgedFastPrimary)
+	t.Run("Slow primary provider is hedged and cancelled", testHedgedSlowPrimary)
+	t.Run("Failed primary provider is not waited for", testHedgedFailedPrimary)
+	t.Run("Stops on city not found", testHedgedCityNotFound)
+}
+
+func newHedgedClient(
+	t *testing.T, hedgeDelay time.Duration, primaryServer, fallbackServer *httptest.Server,
+) *clients.HedgedWeatherClient {
+	t.Helper()
+
+	chainClient := newChainClient(t,
+		clients.NewWeatherAPIClient("dummy-key").
+			WithBaseURL(primaryServer.URL).
+			WithClient(primaryServer.Client()),
+		clients.NewVisualCrossingClient("dummy-key").
+			WithBaseURL(fallbackServer.URL).
+			WithClient(fallbackServer.Client()),
+	)
+	return clients.NewHedgedWeatherClient(chainClient, hedgeDelay)
+}
+
+func testHedgedFastPrimary(t *testing.T) {
+	t.Parallel()
+
+	primaryServer := newStaticServer(t, http.StatusOK,
+		`{"current": {"temp_c": 18, "humidity": 40, "condition": {"text": "Sunny"}}}`, nil)
+
+	var fallbackCalls atomic.Int32
+	fallbackServer := newStaticServer(t, http.StatusOK, visualCrossingCurrentResponse, &fallbackCalls)
+
+	weather, err := newHedgedClient(t, time.Second, primaryServer, fallbackServer).
+		GetAPICurrentWeather(context.Background(), domain.CityQuery("Kyiv"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, &domain.WeatherResponse{Temperature: 18, Humidity: 40, Description: "Sunny"}, weather)
+	assert.Equal(t, int32(0), fallbackCalls.Load(), "fallback provider must not be queried")
+}
+
+func testHedgedSlowPrimary(t *testing.T) {
+	t.Parallel()
+
+	primaryCanceled := make(chan struct{})
+	primaryServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+			close(primaryCanceled)
+		case <-time.After(5 * time.Second):
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	t.Cleanup(primaryServer.Close)
+
+	fallbackServer := newStaticServer(t, http.StatusOK, visualCrossingCurrentResponse, nil)
+
+	start := time.Now()
+	weather, err := newHedgedClient(t, 20*time.Millisecond, primaryServer, fallbackServer).
+		GetAPICurrentWeather(context.Background(), domain.CityQuery("Kyiv"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, &domain.WeatherResponse{Temperature: 18.5, Humidity: 60, Description: "Rain"}, weather)
+	assert.Less(t, time.Since(start), time.Second)
+
+	select {
+	case <-primaryCanceled:
+	case <-time.After(time.Second):
+		t.Error("slow primary request must be cancelled")
+	}
+}
+
+func testHedgedFailedPrimary(t *testing.T) {
+	t.Parallel()
+
+	primaryServer := newStaticServer(t, http.StatusInternalServerError,
+		`{"error": {"code": 9999, "message": "Internal application error."}}`, nil)
+	fallbackServer := newStaticServer(t, http.StatusOK, visualCrossingCurrentResponse, nil)
+
+	start := time.Now()
+	weather, err := newHedgedClient(t, time.Hour, primaryServer, fallbackServer).
+		GetAPICurrentWeather(context.Background(), domain.CityQuery("Kyiv"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, &domain.WeatherResponse{Temperature: 18.5, Humidity: 60, Description: "Rain"}, weather)
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func testHedgedCityNotFound(t *testing.T) {
+	t.Parallel()
+
+	primaryServer := newStaticServer(t, http.StatusBadRequest,
+		`{"error": {"code": 1006, "message": "No matching location found."}}`, nil)
+
+	var fallbackCalls atomic.Int32
+	fallbackServer := newStaticServer(t, http.StatusOK, visualCrossingCurrentResponse, &fallbackCalls)
+
+	_, err := newHedgedClient(t, time.Hour, primaryServer, fallbackServer).
+		GetAPICurrentWeather(context.Background(), domain.CityQuery("Nowhere"))
+
+	assert.ErrorIs(t, err, customErrors.ErrCityNotFound)
+	assert.Equal(t, int32(0), fallbackCalls.Load(), "fallback provider must not be queried")
+}
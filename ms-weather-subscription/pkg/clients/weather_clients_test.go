@@ -0,0 +1,213 @@
+package clients_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"ms-weather-subscription/internal/domain"
+	"ms-weather-subscription/pkg/clients"
+	customErrors "ms-weather-subscription/pkg/errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChainWeatherClient(t *testing.T) {
+	t.Run("Falls through on transport error", testChainFallsThroughOnTransportError)
+	t.Run("Falls through on decode error", testChainFallsThroughOnDecodeError)
+	t.Run("Stops on city not found", testChainStopsOnCityNotFound)
+	t.Run("Aggregates provider errors", testChainAggregatesErrors)
+	t.Run("Stops when context is done", testChainStopsOnCanceledContext)
+}
+
+func TestVisualCrossingClient(t *testing.T) {
+	t.Run("Returns body close error", testVisualCrossingReturnsCloseError)
+}
+
+// failingCloseBody is a response body which fails to close.
+type failingCloseBody struct {
+	io.Reader
+}
+
+func (failingCloseBody) Close() error {
+	return errors.New("connection reset")
+}
+
+// failingCloseTransport returns the responses of the server with bodies which fail to close.
+type failingCloseTransport struct {
+	transport http.RoundTripper
+}
+
+func (t failingCloseTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = failingCloseBody{Reader: resp.Body}
+	return resp, nil
+}
+
+func testVisualCrossingReturnsCloseError(t *testing.T) {
+	t.Parallel()
+
+	server := newStaticServer(t, http.StatusOK, visualCrossingCurrentResponse, nil)
+	client := clients.NewVisualCrossingClient("dummy-key").
+		WithBaseURL(server.URL).
+		WithClient(&http.Client{Transport: failingCloseTransport{transport: server.Client().Transport}})
+
+	_, err := client.GetAPICurrentWeather(context.Background(), domain.CityQuery("Kyiv"))
+
+	assert.ErrorContains(t, err, "failed to close response body: connection reset")
+}
+
+const visualCrossingCurrentResponse = `{"currentConditions": {"temp": 18.5, "humidity": 60, "conditions": "Rain"}}`
+
+func newStaticServer(t *testing.T, status int, body string, calls *atomic.Int32) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls != nil {
+			calls.Add(1)
+		}
+		w.WriteHeader(status)
+		_, err := w.Write([]byte(body))
+		if err != nil {
+			t.Fatalf("failed to write response: %v", err)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func newChainClient(t *testing.T, providers ...clients.ChainWeatherProvider) *clients.ChainWeatherClient {
+	t.Helper()
+
+	chainClient, err := clients.NewChainWeatherClient(providers)
+	if err != nil {
+		t.Fatalf("failed to create chain weather client: %v", err)
+	}
+	return chainClient
+}
+
+func testChainFallsThroughOnTransportError(t *testing.T) {
+	t.Parallel()
+
+	// The server is closed right away, so the request fails before any HTTP status is received.
+	brokenServer := httptest.NewServer(http.NotFoundHandler())
+	brokenServer.Close()
+
+	fallbackServer := newStaticServer(t, http.StatusOK, visualCrossingCurrentResponse, nil)
+
+	chainClient := newChainClient(t,
+		clients.NewWeatherAPIClient("dummy-key").WithBaseURL(brokenServer.URL),
+		clients.NewVisualCrossingClient("dummy-key").
+			WithBaseURL(fallbackServer.URL).
+			WithClient(fallbackServer.Client()),
+	)
+
+	weather, err := chainClient.GetAPICurrentWeather(context.Background(), domain.CityQuery("Kyiv"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, &domain.WeatherResponse{Temperature: 18.5, Humidity: 60, Description: "Rain"}, weather)
+}
+
+func testChainFallsThroughOnDecodeError(t *testing.T) {
+	t.Parallel()
+
+	primaryServer := newStaticServer(t, http.StatusOK, `{"current": `, nil)
+	fallbackServer := newStaticServer(t, http.StatusOK, visualCrossingCurrentResponse, nil)
+
+	chainClient := newChainClient(t,
+		clients.NewWeatherAPIClient("dummy-key").
+			WithBaseURL(primaryServer.URL).
+			WithClient(primaryServer.Client()),
+		clients.NewVisualCrossingClient("dummy-key").
+			WithBaseURL(fallbackServer.URL).
+			WithClient(fallbackServer.Client()),
+	)
+
+	weather, err := chainClient.GetAPICurrentWeather(context.Background(), domain.CityQuery("Kyiv"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, &domain.WeatherResponse{Temperature: 18.5, Humidity: 60, Description: "Rain"}, weather)
+}
+
+func testChainStopsOnCityNotFound(t *testing.T) {
+	t.Parallel()
+
+	primaryServer := newStaticServer(t, http.StatusBadRequest,
+		`{"error": {"code": 1006, "message": "No matching location found."}}`, nil)
+
+	var fallbackCalls atomic.Int32
+	fallbackServer := newStaticServer(t, http.StatusOK, visualCrossingCurrentResponse, &fallbackCalls)
+
+	chainClient := newChainClient(t,
+		clients.NewWeatherAPIClient("dummy-key").
+			WithBaseURL(primaryServer.URL).
+			WithClient(primaryServer.Client()),
+		clients.NewVisualCrossingClient("dummy-key").
+			WithBaseURL(fallbackServer.URL).
+			WithClient(fallbackServer.Client()),
+	)
+
+	_, err := chainClient.GetAPICurrentWeather(context.Background(), domain.CityQuery("Nowhere"))
+
+	assert.ErrorIs(t, err, customErrors.ErrCityNotFound)
+	assert.Equal(t, int32(0), fallbackCalls.Load(), "next provider must not be queried")
+}
+
+func testChainAggregatesErrors(t *testing.T) {
+	t.Parallel()
+
+	primaryServer := newStaticServer(t, http.StatusInternalServerError,
+		`{"error": {"code": 9999, "message": "Internal application error."}}`, nil)
+	fallbackServer := newStaticServer(t, http.StatusOK, `not json`, nil)
+
+	chainClient := newChainClient(t,
+		clients.NewWeatherAPIClient("dummy-key").
+			WithBaseURL(primaryServer.URL).
+			WithClient(primaryServer.Client()),
+		clients.NewVisualCrossingClient("dummy-key").
+			WithBaseURL(fallbackServer.URL).
+			WithClient(fallbackServer.Client()),
+	)
+
+	_, err := chainClient.GetAPIDayWeather(context.Background(), domain.CityQuery("Kyiv"))
+
+	var chainErr *customErrors.WeatherChainError
+	if assert.ErrorAs(t, err, &chainErr) {
+		assert.Len(t, chainErr.Errors, 2)
+	}
+	assert.ErrorIs(t, err, customErrors.ErrWeatherDataError)
+	assert.ErrorContains(t, err, clients.WeatherAPIProviderName+": ")
+	assert.ErrorContains(t, err, clients.VisualCrossingProviderName+": ")
+}
+
+func testChainStopsOnCanceledContext(t *testing.T) {
+	t.Parallel()
+
+	var fallbackCalls atomic.Int32
+	primaryServer := newStaticServer(t, http.StatusOK, `{}`, nil)
+	fallbackServer := newStaticServer(t, http.StatusOK, visualCrossingCurrentResponse, &fallbackCalls)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	chainClient := newChainClient(t,
+		clients.NewWeatherAPIClient("dummy-key").
+			WithBaseURL(primaryServer.URL).
+			WithClient(primaryServer.Client()),
+		clients.NewVisualCrossingClient("dummy-key").
+			WithBaseURL(fallbackServer.URL).
+			WithClient(fallbackServer.Client()),
+	)
+
+	_, err := chainClient.GetAPICurrentWeather(ctx, domain.CityQuery("Kyiv"))
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, int32(0), fallbackCalls.Load(), "next provider must not be queried")
+}
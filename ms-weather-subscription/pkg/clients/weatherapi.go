@@ -3,12 +3,14 @@ package clients
 import (
 	"common/logger"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"ms-weather-subscription/internal/domain"
 	customErrors "ms-weather-subscription/pkg/errors"
 	"net/http"
-	"reflect"
+	"net/url"
 	"strings"
 	"time"
 )
@@ -22,7 +24,6 @@ type WeatherAPIClient struct {
 	apiKey     string
 	baseURL    string
 	httpClient *http.Client
-	next       WeatherClient
 }
 
 func NewWeatherAPIClient(apiKey string) *WeatherAPIClient {
@@ -48,8 +49,14 @@ func (c *WeatherAPIClient) WithBaseURL(baseURL string) *WeatherAPIClient {
 	return c
 }
 
-func (c *WeatherAPIClient) setNext(next ChainWeatherProvider) {
-	c.next = next
+// WithTimeout overrides the default HTTP client timeout.
+func (c *WeatherAPIClient) WithTimeout(timeout time.Duration) *WeatherAPIClient {
+	c.httpClient.Timeout = timeout
+	return c
+}
+
+func (c *WeatherAPIClient) Name() string {
+	return WeatherAPIProviderName
 }
 
 type weatherAPIErrorResponse struct {
@@ -59,26 +66,57 @@ type weatherAPIErrorResponse struct {
 	} `json:"error"`
 }
 
+// weatherAPIConditions are the fields shared by current weather and hourly forecast.
+type weatherAPIConditions struct {
+	TempC        float32 `json:"temp_c"`
+	FeelsLikeC   float32 `json:"feelslike_c"`
+	Humidity     float32 `json:"humidity"`
+	WindKph      float32 `json:"wind_kph"`
+	WindDegree   float32 `json:"wind_degree"`
+	GustKph      float32 `json:"gust_kph"`
+	PrecipMm     float32 `json:"precip_mm"`
+	ChanceOfRain float32 `json:"chance_of_rain"` // hourly forecast only
+	ChanceOfSnow float32 `json:"chance_of_snow"` // hourly forecast only
+	PressureMb   float32 `json:"pressure_mb"`
+	VisKm        float32 `json:"vis_km"`
+	Cloud        float32 `json:"cloud"`
+	UV           float32 `json:"uv"`
+	Condition    struct {
+		Text string `json:"text"`
+	} `json:"condition"`
+}
+
+func (c weatherAPIConditions) toDomain() domain.WeatherResponse {
+	return domain.WeatherResponse{
+		Temperature:              c.TempC,
+		Humidity:                 c.Humidity,
+		Description:              c.Condition.Text,
+		FeelsLike:                c.FeelsLikeC,
+		WindSpeed:                c.WindKph,
+		WindDirection:            c.WindDegree,
+		WindGust:                 c.GustKph,
+		Precipitation:            c.PrecipMm,
+		PrecipitationProbability: max(c.ChanceOfRain, c.ChanceOfSnow),
+		UVIndex:                  c.UV,
+		Pressure:                 c.PressureMb,
+		Visibility:               c.VisKm,
+		CloudCover:               c.Cloud,
+	}
+}
+
 type currentWeatherAPIResponse struct {
-	Current struct {
-		TempC     float32 `json:"temp_c"`
-		Humidity  float32 `json:"humidity"`
-		Condition struct {
-			Text string `json:"text"`
-		} `json:"condition"`
-	} `json:"current"`
+	Current weatherAPIConditions `json:"current"`
 }
 
 type dayWeatherAPIResponse struct {
+	Location struct {
+		TzID string `json:"tz_id"`
+	} `json:"location"`
 	Forecast struct {
 		ForecastDay []struct {
 			Hour []struct {
-				Time      string  `json:"time"` // "2025-05-17 07:00"
-				TempC     float32 `json:"temp_c"`
-				Humidity  float32 `json:"humidity"`
-				Condition struct {
-					Text string `json:"text"`
-				} `json:"condition"`
+				Time string `json:"time"` // "2025-05-17 07:00"
+				weatherAPIConditions
 			} `json:"hour"`
 		} `json:"forecastday"`
 	} `json:"forecast"`
@@ -99,119 +137,230 @@ func (c *WeatherAPIClient) processErrorResponse(resp *http.Response) error {
 }
 
 func (c *WeatherAPIClient) GetAPICurrentWeather(
-	ctx context.Context, city string,
+	ctx context.Context, query domain.WeatherQuery,
 ) (*domain.WeatherResponse, error) {
-	requestURL := fmt.Sprintf("%s/current.json?key=%s&q=%s", c.baseURL, c.apiKey, city)
+	requestURL := fmt.Sprintf("%s/current.json?key=%s&q=%s", c.baseURL, c.apiKey, queryParam(query))
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
-	if err != nil {
+	var result currentWeatherAPIResponse
+	if err := getJSON(ctx, c.httpClient, WeatherAPIProviderName, c.processErrorResponse, requestURL, &result); err != nil {
 		return nil, err
 	}
 
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
+	weather := result.Current.toDomain()
+	return &weather, nil
+}
+
+func (c *WeatherAPIClient) GetAPIDayWeather(
+	ctx context.Context, query domain.WeatherQuery,
+) (*domain.DayWeatherResponse, error) {
+	requestURL := fmt.Sprintf("%s/forecast.json?key=%s&q=%s&days=1", c.baseURL, c.apiKey, queryParam(query))
+
+	var result dayWeatherAPIResponse
+	if err := getJSON(ctx, c.httpClient, WeatherAPIProviderName, c.processErrorResponse, requestURL, &result); err != nil {
 		return nil, err
 	}
-	defer closeBody(resp.Body, &err)
 
-	if resp.StatusCode != http.StatusOK {
-		err = c.processErrorResponse(resp)
+	if len(result.Forecast.ForecastDay) == 0 {
+		logger.Errorf("WeatherAPI day weather response has no forecast days")
+		return nil, customErrors.ErrWeatherDataError
+	}
 
-		if c.next != nil {
-			nextClientName := reflect.TypeOf(c.next).Elem().Name()
-			logger.Warnf(
-				"WeatherAPIClient.GetAPICurrentWeather() error: %s. "+
-					"Passing request to next weather client in chain: %s",
-				err,
-				nextClientName,
-			)
-			return c.next.GetAPICurrentWeather(ctx, city)
+	forecastDay := result.Forecast.ForecastDay[0]
+	hours := make([]domain.HourlyWeather, 0, len(forecastDay.Hour))
+	for _, hourData := range forecastDay.Hour {
+		// "2025-05-17 07:00" -> "07:00"
+		_, timePart, found := strings.Cut(hourData.Time, " ")
+		if !found {
+			continue
 		}
+
+		hours = append(hours, domain.HourlyWeather{
+			Time:            timePart,
+			WeatherResponse: hourData.toDomain(),
+		})
+	}
+
+	return &domain.DayWeatherResponse{
+		Hours:    hours,
+		Timezone: result.Location.TzID,
+	}, nil
+}
+
+type forecastWeatherAPIResponse struct {
+	Forecast struct {
+		ForecastDay []struct {
+			Date string `json:"date"`
+			Day  struct {
+				MaxTempC          float32 `json:"maxtemp_c"`
+				MinTempC          float32 `json:"mintemp_c"`
+				AvgTempC          float32 `json:"avgtemp_c"`
+				AvgHumidity       float32 `json:"avghumidity"`
+				DailyChanceOfRain float32 `json:"daily_chance_of_rain"`
+				Condition         struct {
+					Text string `json:"text"`
+				} `json:"condition"`
+			} `json:"day"`
+		} `json:"forecastday"`
+	} `json:"forecast"`
+}
+
+func (c *WeatherAPIClient) GetAPIForecast(
+	ctx context.Context, query domain.WeatherQuery, days int,
+) (*domain.ForecastResponse, error) {
+	requestURL := fmt.Sprintf("%s/forecast.json?key=%s&q=%s&days=%d", c.baseURL, c.apiKey, queryParam(query), days)
+
+	var result forecastWeatherAPIResponse
+	if err := getJSON(ctx, c.httpClient, WeatherAPIProviderName, c.processErrorResponse, requestURL, &result); err != nil {
 		return nil, err
 	}
+	// The free plan forecasts only 3 days whatever is requested, the next provider of the chain is asked then.
+	if len(result.Forecast.ForecastDay) < days {
+		logger.Errorf("WeatherAPI forecast has %d of %d requested days", len(result.Forecast.ForecastDay), days)
+		return nil, customErrors.ErrForecastIncomplete
+	}
 
-	var result currentWeatherAPIResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		logger.Errorf("error parsing WeatherAPI response: %s", err.Error())
-		return nil, customErrors.ErrWeatherDataError
+	forecast := &domain.ForecastResponse{Days: make([]domain.DayForecast, 0, len(result.Forecast.ForecastDay))}
+	for _, forecastDay := range result.Forecast.ForecastDay {
+		forecast.Days = append(forecast.Days, domain.DayForecast{
+			Date:                     forecastDay.Date,
+			MinTemperature:           forecastDay.Day.MinTempC,
+			MaxTemperature:           forecastDay.Day.MaxTempC,
+			AvgTemperature:           forecastDay.Day.AvgTempC,
+			Humidity:                 forecastDay.Day.AvgHumidity,
+			PrecipitationProbability: forecastDay.Day.DailyChanceOfRain,
+			Description:              forecastDay.Day.Condition.Text,
+		})
 	}
 
-	return &domain.WeatherResponse{
-		Temperature: result.Current.TempC,
-		Humidity:    result.Current.Humidity,
-		Description: result.Current.Condition.Text,
-	}, nil
+	return forecast, nil
 }
 
-func (c *WeatherAPIClient) GetAPIDayWeather(
-	ctx context.Context, city string,
-) (*domain.DayWeatherResponse, error) {
-	requestURL := fmt.Sprintf("%s/forecast.json?key=%s&q=%s&days=1", c.baseURL, c.apiKey, city)
+type weatherAPIAlertsResponse struct {
+	Alerts struct {
+		Alert []struct {
+			Headline    string    `json:"headline"`
+			Severity    string    `json:"severity"`
+			Areas       string    `json:"areas"`
+			Event       string    `json:"event"`
+			Effective   time.Time `json:"effective"`
+			Expires     time.Time `json:"expires"`
+			Desc        string    `json:"desc"`
+			Instruction string    `json:"instruction"`
+		} `json:"alert"`
+	} `json:"alerts"`
+}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
-	if err != nil {
+// GetAPIAlerts returns alerts listed in the forecast response. WeatherAPI doesn't return
+// alert ids, so an alert is identified by its event, areas and the time it takes effect.
+func (c *WeatherAPIClient) GetAPIAlerts(
+	ctx context.Context, query domain.WeatherQuery,
+) ([]domain.WeatherAlert, error) {
+	requestURL := fmt.Sprintf(
+		"%s/forecast.json?key=%s&q=%s&days=1&alerts=yes", c.baseURL, c.apiKey, queryParam(query),
+	)
+
+	var result weatherAPIAlertsResponse
+	if err := getJSON(ctx, c.httpClient, WeatherAPIProviderName, c.processErrorResponse, requestURL, &result); err != nil {
 		return nil, err
 	}
 
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
+	alerts := make([]domain.WeatherAlert, 0, len(result.Alerts.Alert))
+	for _, alert := range result.Alerts.Alert {
+		id := sha256.Sum256([]byte(alert.Event + "|" + alert.Areas + "|" + alert.Effective.UTC().String()))
+		alerts = append(alerts, domain.WeatherAlert{
+			ID:          WeatherAPIProviderName + ":" + hex.EncodeToString(id[:8]),
+			Event:       alert.Event,
+			Headline:    alert.Headline,
+			Severity:    alert.Severity,
+			Description: alert.Desc,
+			Instruction: alert.Instruction,
+			Effective:   alert.Effective,
+			Expires:     alert.Expires,
+		})
+	}
+
+	return alerts, nil
+}
+
+type weatherAPIAirQualityResponse struct {
+	Current struct {
+		AirQuality struct {
+			PM25 float32 `json:"pm2_5"`
+			PM10 float32 `json:"pm10"`
+			O3   float32 `json:"o3"`
+			NO2  float32 `json:"no2"`
+		} `json:"air_quality"`
+	} `json:"current"`
+}
+
+// GetAPIAirQuality returns pollutant concentrations from the current weather response.
+// WeatherAPI grades air quality by category only, so the index is calculated from particulate matter.
+func (c *WeatherAPIClient) GetAPIAirQuality(
+	ctx context.Context, query domain.WeatherQuery,
+) (*domain.AirQualityResponse, error) {
+	requestURL := fmt.Sprintf("%s/current.json?key=%s&q=%s&aqi=yes", c.baseURL, c.apiKey, queryParam(query))
+
+	var result weatherAPIAirQualityResponse
+	if err := getJSON(ctx, c.httpClient, WeatherAPIProviderName, c.processErrorResponse, requestURL, &result); err != nil {
 		return nil, err
 	}
-	defer closeBody(resp.Body, &err)
 
-	if resp.StatusCode != http.StatusOK {
-		err = c.processErrorResponse(resp)
+	airQuality := result.Current.AirQuality
+	return &domain.AirQualityResponse{
+		AQI:  domain.USAQIFromParticulates(airQuality.PM25, airQuality.PM10),
+		PM25: airQuality.PM25,
+		PM10: airQuality.PM10,
+		O3:   airQuality.O3,
+		NO2:  airQuality.NO2,
+	}, nil
+}
 
-		if c.next != nil {
-			nextClientName := reflect.TypeOf(c.next).Elem().Name()
-			logger.Warnf(
-				"WeatherAPIClient.GetAPIDayWeather() error: %s. "+
-					"Passing request to next weather client in chain: %s",
-				err,
-				nextClientName,
-			)
-			return c.next.GetAPIDayWeather(ctx, city)
-		}
+type weatherAPISearchResult struct {
+	Name    string  `json:"name"`
+	Country string  `json:"country"`
+	Lat     float64 `json:"lat"`
+	Lon     float64 `json:"lon"`
+}
+
+type weatherAPITimezoneResponse struct {
+	Location struct {
+		TzID string `json:"tz_id"`
+	} `json:"location"`
+}
+
+// SearchLocation resolves the query via the search API and looks up the timezone
+// of the best match, as search results don't include it.
+func (c *WeatherAPIClient) SearchLocation(ctx context.Context, query string) (*domain.Location, error) {
+	var results []weatherAPISearchResult
+	requestURL := fmt.Sprintf("%s/search.json?key=%s&q=%s", c.baseURL, c.apiKey, url.QueryEscape(query))
+	err := getJSON(ctx, c.httpClient, WeatherAPIProviderName, c.processErrorResponse, requestURL, &results)
+	if err != nil {
 		return nil, err
 	}
 
-	var result dayWeatherAPIResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		logger.Errorf("error parsing WeatherAPI response: %s", err.Error())
-		return nil, customErrors.ErrWeatherDataError
+	if len(results) == 0 {
+		return nil, customErrors.ErrCityNotFound
 	}
+	match := results[0]
 
-	// Map of required times
-	targetHours := map[string]*domain.WeatherResponse{
-		"07:00": {},
-		"10:00": {},
-		"13:00": {},
-		"16:00": {},
-		"19:00": {},
-		"22:00": {},
+	timezone, err := c.LookupTimezone(ctx, domain.Coordinates{Latitude: match.Lat, Longitude: match.Lon})
+	if err != nil {
+		return nil, err
 	}
 
-	const dateTimeSplitStrLength = 2 // "2025-05-17 07:00" -> ["2025-05-17", "07:00"]
-	for _, hourData := range result.Forecast.ForecastDay[0].Hour {
-		dateTimeParts := strings.Split(hourData.Time, " ")
-		if len(dateTimeParts) != dateTimeSplitStrLength {
-			continue
-		}
-		timePart := dateTimeParts[1]
+	location := domain.NewLocation(match.Name, match.Country, match.Lat, match.Lon, timezone)
+	return &location, nil
+}
 
-		if target, ok := targetHours[timePart]; ok {
-			target.Temperature = hourData.TempC
-			target.Humidity = hourData.Humidity
-			target.Description = hourData.Condition.Text
-		}
+// LookupTimezone returns the timezone of the coordinates via the time zone API.
+func (c *WeatherAPIClient) LookupTimezone(ctx context.Context, coordinates domain.Coordinates) (string, error) {
+	var timezone weatherAPITimezoneResponse
+	requestURL := fmt.Sprintf(
+		"%s/timezone.json?key=%s&q=%f,%f", c.baseURL, c.apiKey, coordinates.Latitude, coordinates.Longitude,
+	)
+	err := getJSON(ctx, c.httpClient, WeatherAPIProviderName, c.processErrorResponse, requestURL, &timezone)
+	if err != nil {
+		return "", err
 	}
-
-	return &domain.DayWeatherResponse{
-		SevenAM: *targetHours["07:00"],
-		TenAM:   *targetHours["10:00"],
-		OnePM:   *targetHours["13:00"],
-		FourPM:  *targetHours["16:00"],
-		SevenPM: *targetHours["19:00"],
-		TenPM:   *targetHours["22:00"],
-	}, nil
+	return timezone.Location.TzID, nil
 }
@@ -2,6 +2,8 @@ package errors
 
 import (
 	"errors"
+	"fmt"
+	"strings"
 
 	"github.com/lib/pq"
 )
@@ -13,11 +15,49 @@ const (
 var (
 	ErrSubscriptionNotFound      = errors.New("subscription doesn't exists")
 	ErrSubscriptionAlreadyExists = errors.New("subscription with such email already exists")
+	ErrSubscriptionNeverDue      = errors.New("subscription emails are never due within its active window")
 
 	ErrCityNotFound     = errors.New("city doesn't exists")
+	ErrLocationNotFound = errors.New("location isn't resolved yet")
 	ErrWeatherDataError = errors.New("failed to get weather data")
+	// ErrForecastIncomplete is returned by a provider whose plan forecasts fewer days than requested.
+	ErrForecastIncomplete = errors.New("weather provider forecasts fewer days than requested")
+
+	ErrWeatherProviderUnavailable = errors.New("weather provider is temporarily unavailable")
+	ErrQuotaExhausted             = errors.New("weather provider quota is exhausted")
 )
 
+// WeatherProviderError is an error returned by a single provider of the weather client chain.
+type WeatherProviderError struct {
+	Provider string
+	Err      error
+}
+
+func (e *WeatherProviderError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Provider, e.Err)
+}
+
+func (e *WeatherProviderError) Unwrap() error {
+	return e.Err
+}
+
+// WeatherChainError aggregates what each queried provider of the weather client chain returned.
+type WeatherChainError struct {
+	Errors []error
+}
+
+func (e *WeatherChainError) Error() string {
+	messages := make([]string, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		messages = append(messages, err.Error())
+	}
+	return "weather providers failed: " + strings.Join(messages, "; ")
+}
+
+func (e *WeatherChainError) Unwrap() []error {
+	return e.Errors
+}
+
 func IsDuplicateDBError(err error) bool {
 	var pqErr *pq.Error
 	if errors.As(err, &pqErr) {
@@ -0,0 +1,178 @@
+// Package lock provides locks shared between all replicas of the service.
+package lock
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	// ErrNotAcquired means the lock is held by another holder.
+	ErrNotAcquired = errors.New("lock is held by another holder")
+	// ErrNotHeld means the lock expired and may have been acquired by another holder since.
+	ErrNotHeld = errors.New("lock is no longer held")
+	// ErrStaleToken means a write was rejected as it was made with an older fencing token than the latest one.
+	ErrStaleToken = errors.New("fencing token is stale")
+)
+
+type Locker interface {
+	// TryAcquire acquires the lock at key unless it is held. The lock expires after ttl
+	// unless it is extended, so it is released even if its holder crashes.
+	TryAcquire(ctx context.Context, key string, ttl time.Duration) (Lock, error)
+}
+
+type Lock interface {
+	Extend(ctx context.Context, ttl time.Duration) error
+	Release(ctx context.Context) error
+	// Token returns the fencing token issued when the lock was acquired.
+	Token() Token
+}
+
+// TokenIssuer issues fencing tokens without a lock, e.g. to tell apart the claims of concurrent runs.
+type TokenIssuer interface {
+	// NextToken issues a token of key greater than all the tokens of key issued before.
+	NextToken(ctx context.Context, key string) (Token, error)
+}
+
+// Token is a fencing token. Each acquisition of a lock issues a greater token than the previous ones,
+// so the writes made under a lock which expired while its holder was paused can be rejected
+// once a write was made under a later acquisition.
+type Token struct {
+	Key   string
+	Value int64
+}
+
+type tokenContextKey struct{}
+
+// WithToken returns a copy of ctx carrying the fencing token of the lock the work is done under.
+func WithToken(ctx context.Context, token Token) context.Context {
+	return context.WithValue(ctx, tokenContextKey{}, token)
+}
+
+// TokenFrom returns the fencing token carried by ctx, if any.
+func TokenFrom(ctx context.Context) (Token, bool) {
+	token, ok := ctx.Value(tokenContextKey{}).(Token)
+	return token, ok
+}
+
+// acquireScript sets the lock unless it exists and returns {1, fencing token}, otherwise {0, value of the holder}.
+// The holder is empty if the lock expired in between.
+var acquireScript = redis.NewScript(`
+if redis.call("SET", KEYS[1], ARGV[1], "NX", "PX", ARGV[2]) then
+	return {1, redis.call("INCR", KEYS[2])}
+end
+return {0, redis.call("GET", KEYS[1]) or ""}
+`)
+
+var extendScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+var releaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// RedisLocker acquires the locks on behalf of holder, e.g. the instance of the service.
+// Each lock stores the holder with a random ID, so it is only extended and released by the one who acquired it,
+// not by a previous run of the same holder whose lock expired.
+type RedisLocker struct {
+	client *redis.Client
+	holder string
+}
+
+func NewRedisLocker(redisClient *redis.Client, holder string) *RedisLocker {
+	return &RedisLocker{client: redisClient, holder: holder}
+}
+
+func (l *RedisLocker) TryAcquire(ctx context.Context, key string, ttl time.Duration) (Lock, error) {
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+	value := l.holder + "#" + hex.EncodeToString(id)
+
+	keys := []string{lockKey(key), tokenKey(key)}
+	result, err := acquireScript.Run(ctx, l.client, keys, value, ttl.Milliseconds()).Slice()
+	if err != nil {
+		return nil, err
+	}
+	if len(result) != 2 {
+		return nil, fmt.Errorf("unexpected lock acquisition result %v", result)
+	}
+	if acquired, _ := result[0].(int64); acquired == 0 {
+		if holder, _ := result[1].(string); holder != "" {
+			return nil, fmt.Errorf("%w: %s", ErrNotAcquired, holder)
+		}
+		return nil, ErrNotAcquired
+	}
+	token, ok := result[1].(int64)
+	if !ok {
+		return nil, fmt.Errorf("unexpected fencing token %v", result[1])
+	}
+
+	return &RedisLock{client: l.client, key: lockKey(key), value: value, token: Token{Key: key, Value: token}}, nil
+}
+
+// NextToken issues a token from the same sequence as the tokens of the lock at key.
+func (l *RedisLocker) NextToken(ctx context.Context, key string) (Token, error) {
+	token, err := l.client.Incr(ctx, tokenKey(key)).Result()
+	if err != nil {
+		return Token{}, err
+	}
+	return Token{Key: key, Value: token}, nil
+}
+
+type RedisLock struct {
+	client *redis.Client
+	key    string
+	value  string
+	token  Token
+}
+
+func (l *RedisLock) Token() Token {
+	return l.token
+}
+
+func (l *RedisLock) Extend(ctx context.Context, ttl time.Duration) error {
+	extended, err := extendScript.Run(ctx, l.client, []string{l.key}, l.value, ttl.Milliseconds()).Int()
+	if err != nil {
+		return err
+	}
+	if extended == 0 {
+		return ErrNotHeld
+	}
+	return nil
+}
+
+func (l *RedisLock) Release(ctx context.Context) error {
+	released, err := releaseScript.Run(ctx, l.client, []string{l.key}, l.value).Int()
+	if err != nil {
+		return err
+	}
+	if released == 0 {
+		return ErrNotHeld
+	}
+	return nil
+}
+
+func lockKey(key string) string {
+	return "lock:" + key
+}
+
+// tokenKey is the key of the fencing token sequence of the lock at key. It never expires,
+// so the tokens keep growing after the lock is released.
+func tokenKey(key string) string {
+	return "lock_token:" + key
+}
@@ -0,0 +1,123 @@
+package lock_test
+
+import (
+	"context"
+	"ms-weather-subscription/pkg/lock"
+	"ms-weather-subscription/testutils"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedisLocker(t *testing.T) {
+	t.Run("Lock is held by one instance at a time", testRedisLockerOneHolder)
+	t.Run("Expired lock isn't released by its previous holder", testRedisLockerPreviousHolder)
+	t.Run("Lock expires if it isn't extended", testRedisLockerExpires)
+	t.Run("Extended lock doesn't expire", testRedisLockerExtend)
+	t.Run("Each acquisition issues a greater token", testRedisLockerTokens)
+}
+
+func testRedisLockerOneHolder(t *testing.T) {
+	// Setup
+	redisConn := testutils.SetupTestRedis(t)
+	first := lock.NewRedisLocker(redisConn, "instance-1")
+	second := lock.NewRedisLocker(redisConn, "instance-2")
+	ctx := context.Background()
+
+	// Execute
+	held, err := first.TryAcquire(ctx, "task", time.Minute)
+	assert.NoError(t, err)
+	_, err = second.TryAcquire(ctx, "task", time.Minute)
+
+	// Verify
+	assert.ErrorIs(t, err, lock.ErrNotAcquired)
+	assert.ErrorContains(t, err, "instance-1", "the error names the holder")
+
+	assert.NoError(t, held.Release(ctx))
+	_, err = second.TryAcquire(ctx, "task", time.Minute)
+	assert.NoError(t, err, "the released lock can be acquired")
+}
+
+func testRedisLockerPreviousHolder(t *testing.T) {
+	// Setup
+	redisConn := testutils.SetupTestRedis(t)
+	locker := lock.NewRedisLocker(redisConn, "instance-1")
+	ctx := context.Background()
+
+	previous, err := locker.TryAcquire(ctx, "task", 100*time.Millisecond)
+	assert.NoError(t, err)
+	time.Sleep(200 * time.Millisecond)
+	current, err := locker.TryAcquire(ctx, "task", time.Minute)
+	assert.NoError(t, err)
+
+	// Execute
+	err = previous.Release(ctx)
+
+	// Verify
+	assert.ErrorIs(t, err, lock.ErrNotHeld)
+	_, err = locker.TryAcquire(ctx, "task", time.Minute)
+	assert.ErrorIs(t, err, lock.ErrNotAcquired, "the current lock is kept")
+	assert.NoError(t, current.Release(ctx))
+}
+
+func testRedisLockerExpires(t *testing.T) {
+	// Setup
+	redisConn := testutils.SetupTestRedis(t)
+	crashed := lock.NewRedisLocker(redisConn, "instance-1")
+	other := lock.NewRedisLocker(redisConn, "instance-2")
+	ctx := context.Background()
+
+	held, err := crashed.TryAcquire(ctx, "task", 100*time.Millisecond)
+	assert.NoError(t, err)
+
+	// Execute
+	time.Sleep(200 * time.Millisecond)
+	_, err = other.TryAcquire(ctx, "task", time.Minute)
+
+	// Verify
+	assert.NoError(t, err)
+	assert.ErrorIs(t, held.Extend(ctx, time.Minute), lock.ErrNotHeld)
+}
+
+func testRedisLockerExtend(t *testing.T) {
+	// Setup
+	redisConn := testutils.SetupTestRedis(t)
+	holder := lock.NewRedisLocker(redisConn, "instance-1")
+	other := lock.NewRedisLocker(redisConn, "instance-2")
+	ctx := context.Background()
+
+	held, err := holder.TryAcquire(ctx, "task", 100*time.Millisecond)
+	assert.NoError(t, err)
+
+	// Execute
+	assert.NoError(t, held.Extend(ctx, time.Minute))
+	time.Sleep(200 * time.Millisecond)
+	_, err = other.TryAcquire(ctx, "task", time.Minute)
+
+	// Verify
+	assert.ErrorIs(t, err, lock.ErrNotAcquired)
+}
+
+func testRedisLockerTokens(t *testing.T) {
+	// Setup
+	redisConn := testutils.SetupTestRedis(t)
+	first := lock.NewRedisLocker(redisConn, "instance-1")
+	second := lock.NewRedisLocker(redisConn, "instance-2")
+	ctx := context.Background()
+
+	previous, err := first.TryAcquire(ctx, "task", 100*time.Millisecond)
+	assert.NoError(t, err)
+	time.Sleep(200 * time.Millisecond)
+
+	// Execute
+	current, err := second.TryAcquire(ctx, "task", time.Minute)
+	assert.NoError(t, err)
+	next, err := first.NextToken(ctx, "task")
+	assert.NoError(t, err)
+
+	// Verify
+	assert.Equal(t, "task", current.Token().Key)
+	assert.Greater(t, current.Token().Value, previous.Token().Value)
+	assert.Greater(t, next.Value, current.Token().Value)
+}
@@ -10,4 +10,64 @@ var (
 		Name: "weather_cache_hit_count",
 		Help: "Total cache hits for weather data",
 	})
+
+	CacheTierHitCount = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "cache_tier_hit_count",
+		Help: "Total cache hits per cache tier (memory, redis)",
+	}, []string{"tier"})
+
+	WeatherDayCacheHitCount = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "weather_day_cache_hit_count",
+		Help: "Total cache hits for day weather forecasts",
+	})
+
+	WeatherDayCacheMissCount = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "weather_day_cache_miss_count",
+		Help: "Total cache misses for day weather forecasts",
+	})
+
+	WeatherCoalescedRequestCount = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "weather_coalesced_request_count",
+		Help: "Total weather cache misses served by an upstream request already in flight for the same key",
+	})
+
+	WeatherStaleCacheServedCount = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "weather_stale_cache_served_count",
+		Help: "Total responses served from the last known weather because all providers failed",
+	})
+
+	WeatherProviderCircuitState = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "weather_provider_circuit_state",
+		Help: "Circuit breaker state per weather provider (0 - closed, 1 - open, 2 - half-open)",
+	}, []string{"provider"})
+
+	WeatherProviderQuotaRemaining = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "weather_provider_quota_remaining",
+		Help: "Calls left to a weather provider in the current quota window (day, rate)",
+	}, []string{"provider", "window"})
+
+	WeatherHedgedRequestCount = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "weather_hedged_request_count",
+		Help: "Total hedged requests sent to a weather provider because the previous one was too slow",
+	}, []string{"provider"})
+
+	ScheduledEmailFailedCount = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "scheduled_email_failed_count",
+		Help: "Total scheduled forecast emails which failed to be sent and are retried once their claim expires",
+	}, []string{"frequency"})
+
+	CronTaskRunCount = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "cron_task_run_count",
+		Help: "Total cron task runs per instance",
+	}, []string{"task", "instance"})
+
+	CronTaskSkippedCount = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "cron_task_skipped_count",
+		Help: "Total cron task runs skipped because another instance held the task lock",
+	}, []string{"task", "instance"})
+
+	CronTaskLockErrorCount = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "cron_task_lock_error_count",
+		Help: "Total failures to acquire, extend or release a cron task lock",
+	}, []string{"task", "instance"})
 )
@@ -0,0 +1,53 @@
+package migrations_test
+
+import (
+	"common/frequency"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const migrationsDir = "../../migrations"
+
+func TestSubscriptionFrequencyCheck(t *testing.T) {
+	t.Run("Lists every frequency", testSubscriptionFrequencyCheckListsAll)
+}
+
+var (
+	frequencyCheckPattern = regexp.MustCompile(
+		`(?s)ADD CONSTRAINT subscriptions_frequency_check\s+CHECK \(frequency IN \(([^)]*)\)\)`,
+	)
+	quotedValuePattern = regexp.MustCompile(`'([^']*)'`)
+)
+
+// testSubscriptionFrequencyCheckListsAll makes sure the frequencies the database accepts are exactly
+// frequency.All, so a frequency added to the code can't be forgotten in the migrations.
+func testSubscriptionFrequencyCheckListsAll(t *testing.T) {
+	// Setup: the check of the latest migration adding it is the one in force
+	paths, err := filepath.Glob(filepath.Join(migrationsDir, "*.up.sql"))
+	if err != nil {
+		t.Fatalf("failed to list migrations: %v", err)
+	}
+
+	var check []string
+	for _, path := range paths {
+		migration, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("failed to read migration %s: %v", path, err)
+		}
+		if match := frequencyCheckPattern.FindSubmatch(migration); match != nil {
+			check = check[:0]
+			for _, value := range quotedValuePattern.FindAllSubmatch(match[1], -1) {
+				check = append(check, string(value[1]))
+			}
+		}
+	}
+
+	// Verify
+	if assert.NotEmpty(t, check, "no migration adds subscriptions_frequency_check") {
+		assert.ElementsMatch(t, frequency.All, check)
+	}
+}
@@ -1,6 +1,7 @@
 package publisher
 
 import (
+	"common/frequency"
 	"common/logger"
 	"encoding/json"
 
@@ -8,9 +9,13 @@ import (
 )
 
 const (
-	EmailConfirmationQueue   = "email.confirmation"
-	EmailDailyForecastQueue  = "email.daily_forecast"
-	EmailHourlyForecastQueue = "email.hourly_forecast"
+	EmailConfirmationQueue        = "email.confirmation"
+	EmailHourlyForecastQueue      = frequency.HourlyQueue
+	EmailEvery3HoursForecastQueue = frequency.Every3HoursQueue
+	EmailEvery6HoursForecastQueue = frequency.Every6HoursQueue
+	EmailDailyForecastQueue       = frequency.DailyQueue
+	EmailWeeklyForecastQueue      = frequency.WeeklyQueue
+	EmailWeatherAlertQueue        = frequency.AlertsQueue
 )
 
 //go:generate mockgen -source=email_publisher.go -destination=mocks/mock_email_publisher.go
@@ -30,7 +35,7 @@ func NewEmailPublisher(conn *amqp.Connection) (*EmailPub, error) {
 		return nil, err
 	}
 
-	queues := []string{EmailConfirmationQueue, EmailDailyForecastQueue, EmailHourlyForecastQueue}
+	queues := append([]string{EmailConfirmationQueue}, frequency.Queues()...)
 	for _, q := range queues {
 		_, err := ch.QueueDeclare(q, true, false, false, false, nil)
 		if err != nil {
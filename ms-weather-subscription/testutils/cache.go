@@ -6,18 +6,26 @@ import (
 	"ms-weather-subscription/pkg/cache"
 	"ms-weather-subscription/pkg/clients"
 	"testing"
+
+	"github.com/redis/go-redis/v9"
 )
 
 func SetupTestCache(t *testing.T) *cache.RedisCache {
 	t.Helper()
 
+	return cache.NewCache(SetupTestRedis(t))
+}
+
+// SetupTestRedis connects to the test Redis, which is flushed once the test is done.
+func SetupTestRedis(t *testing.T) *redis.Client {
+	t.Helper()
+
 	cfg, err := config.Init(config.ConfigsDir, config.TestEnvironment)
 	if err != nil {
 		t.Fatalf("failed to load config: %v", err)
 	}
 
 	redisConn := clients.NewRedisConnection(cfg.Redis)
-	redisCache := cache.NewCache(redisConn)
 
 	t.Cleanup(func() {
 		if err := redisConn.FlushDB(context.Background()).Err(); err != nil {
@@ -28,5 +36,5 @@ func SetupTestCache(t *testing.T) *cache.RedisCache {
 		}
 	})
 
-	return redisCache
+	return redisConn
 }
@@ -0,0 +1,44 @@
+package testutils
+
+import (
+	"ms-weather-subscription/pkg/clients"
+	"testing"
+)
+
+// SetupTestWeatherClient returns the weather client of the test environment. It is backed by
+// the fake provider, so it serves the weather of configs/fixtures/weather.yaml.
+func SetupTestWeatherClient(t *testing.T) *clients.ChainWeatherClient {
+	t.Helper()
+
+	cfg := SetupTestConfig(t)
+
+	providers, err := clients.NewDefaultProviderRegistry(cfg.ThirdParty).Build(cfg.WeatherProviders)
+	if err != nil {
+		t.Fatalf("failed to create weather providers: %v", err)
+	}
+	weatherClient, err := clients.NewChainWeatherClient(providers)
+	if err != nil {
+		t.Fatalf("failed to create chain weather client: %v", err)
+	}
+
+	return weatherClient
+}
+
+// SetupTestLocationSearcher returns the location searcher of the test environment,
+// backed by the fake provider like SetupTestWeatherClient.
+func SetupTestLocationSearcher(t *testing.T) *clients.ChainLocationSearcher {
+	t.Helper()
+
+	cfg := SetupTestConfig(t)
+
+	searchers, err := clients.NewDefaultProviderRegistry(cfg.ThirdParty).BuildLocationSearchers(cfg.WeatherProviders)
+	if err != nil {
+		t.Fatalf("failed to create location searchers: %v", err)
+	}
+	locationSearcher, err := clients.NewChainLocationSearcher(searchers)
+	if err != nil {
+		t.Fatalf("failed to create chain location searcher: %v", err)
+	}
+
+	return locationSearcher
+}
@@ -0,0 +1,56 @@
+// Package frequency lists the subscription frequencies and the email queues they are sent through.
+// It is shared by the subscription and the notification services, so a frequency added here
+// also needs the subscriptions frequency check migration, a schedule or a cron task and a notification handler.
+package frequency
+
+import "slices"
+
+const (
+	Hourly      = "hourly"
+	Every3Hours = "every_3h"
+	Every6Hours = "every_6h"
+	Daily       = "daily"
+	// Weekly subscriptions get the 7-day outlook on Mondays.
+	Weekly = "weekly"
+	// Alerts subscriptions are emailed only when a new severe weather alert is issued.
+	Alerts = "alerts"
+)
+
+const (
+	HourlyQueue      = "email.hourly_forecast"
+	Every3HoursQueue = "email.every_3h_forecast"
+	Every6HoursQueue = "email.every_6h_forecast"
+	DailyQueue       = "email.daily_forecast"
+	WeeklyQueue      = "email.weekly_forecast"
+	AlertsQueue      = "email.weather_alert"
+)
+
+// All lists the frequencies in the order they are offered to subscribers.
+var All = []string{Hourly, Every3Hours, Every6Hours, Daily, Weekly, Alerts}
+
+var queues = map[string]string{
+	Hourly:      HourlyQueue,
+	Every3Hours: Every3HoursQueue,
+	Every6Hours: Every6HoursQueue,
+	Daily:       DailyQueue,
+	Weekly:      WeeklyQueue,
+	Alerts:      AlertsQueue,
+}
+
+func IsValid(frequency string) bool {
+	return slices.Contains(All, frequency)
+}
+
+// Queue returns the queue the emails of the frequency are published to.
+func Queue(frequency string) string {
+	return queues[frequency]
+}
+
+// Queues returns the queues of all frequencies.
+func Queues() []string {
+	result := make([]string, 0, len(All))
+	for _, frequency := range All {
+		result = append(result, queues[frequency])
+	}
+	return result
+}